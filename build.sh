@@ -13,8 +13,10 @@ echo "Running Test"
 pkgs="\
   github.com/codingeasygo/crud\
   github.com/codingeasygo/crud/gen\
+  github.com/codingeasygo/crud/mocker\
   github.com/codingeasygo/crud/sqlx\
   github.com/codingeasygo/crud/pgx\
+  github.com/codingeasygo/crud/pgx5\
 "
 echo "mode: set" >a.out
 for p in $pkgs; do
@@ -0,0 +1,96 @@
+// Command crudgen will generate crud code by json/yaml config file
+//
+// Usage:
+//
+//	crudgen -config crudgen.json
+//
+// The database is not connected when tables_from_ddl is setted in config, the tables is parsed from ddl files
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/codingeasygo/crud/gen"
+	"github.com/codingeasygo/crud/pgx"
+	"github.com/codingeasygo/crud/sqlx"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	gen.YAMLUnmarshal = yaml.Unmarshal
+}
+
+func main() {
+	var configFile, dsn string
+	flag.StringVar(&configFile, "config", "crudgen.json", "the json/yaml config file")
+	flag.StringVar(&dsn, "dsn", "", "the data source name to override config dsn")
+	flag.Parse()
+	err := run(configFile, dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "crudgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configFile, dsn string) (err error) {
+	config, err := gen.LoadAutoConfig(configFile)
+	if err != nil {
+		return
+	}
+	if len(dsn) > 0 {
+		config.DSN = dsn
+	}
+	autoGen, err := config.AutoGen()
+	if err != nil {
+		return
+	}
+	switch {
+	case len(autoGen.TablesFromDDL) > 0:
+		//generate from ddl files, database is not needed
+	case config.Driver == "pgx":
+		pool, xerr := pgx.Bootstrap(config.DSN)
+		if xerr != nil {
+			err = xerr
+			return
+		}
+		defer pool.Close()
+		autoGen.Queryer = pgx.Shared
+	case config.Driver == "postgres" || config.Driver == "sqlite3":
+		db, xerr := sqlx.Bootstrap(config.Driver, config.DSN)
+		if xerr != nil {
+			err = xerr
+			return
+		}
+		defer db.Close()
+		autoGen.Queryer = sqlx.Shared
+	default:
+		err = fmt.Errorf("driver %v is not supported", config.Driver)
+		return
+	}
+	files, written, err := autoGen.GenerateFiles()
+	if err != nil {
+		return
+	}
+	action := "written"
+	if autoGen.DryRun {
+		action = "changed"
+		names := []string{}
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("generated %v (%v bytes)\n", name, len(files[name]))
+		}
+	}
+	for _, name := range written {
+		fmt.Printf("%v %v\n", action, name)
+	}
+	return
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunYAML(t *testing.T) {
+	dir := t.TempDir()
+	ddlFile, err := filepath.Abs("../../testsql/pg_latest.sql")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	configFile := filepath.Join(dir, "crudgen.yaml")
+	ioutil.WriteFile(configFile, []byte(`
+driver: postgres
+tables_from_ddl:
+  - `+ddlFile+`
+table_include:
+  - crud_object
+out: `+dir+`
+out_package: autogen
+`), os.ModePerm)
+	err = run(configFile, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if err != nil || !strings.Contains(string(data), "package autogen") || !strings.Contains(string(data), "CrudObject") {
+		t.Error(err)
+		return
+	}
+	//bad yaml
+	ioutil.WriteFile(configFile, []byte("driver: [postgres"), os.ModePerm)
+	if err = run(configFile, ""); err == nil {
+		t.Error(err)
+		return
+	}
+}
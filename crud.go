@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"log"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/codingeasygo/util/attrscan"
 	"github.com/codingeasygo/util/xsql"
@@ -37,6 +40,14 @@ type FilterGetterF func(args ...interface{}) string
 
 func (f FilterGetterF) GetFilter(args ...interface{}) string { return f(args...) }
 
+type FromArgsGetter interface {
+	GetFromArgs(args ...interface{}) []interface{}
+}
+
+type FromArgsGetterF func(args ...interface{}) []interface{}
+
+func (f FromArgsGetterF) GetFromArgs(args ...interface{}) []interface{} { return f(args...) }
+
 func jsonString(v interface{}) string {
 	data, err := json.Marshal(v)
 	if err != nil {
@@ -45,17 +56,36 @@ func jsonString(v interface{}) string {
 	return string(data)
 }
 
+// BuildOrderby will build order by sql when order key is in supported, the order is +key for asc and -key for desc.
+// The supported key can having options like name#ci,create_time#nullslast, ci is order by lower(key), nullslast/nullsfirst is appending nulls last/first
 func BuildOrderby(supported string, order string) (orderby string) {
 	if len(order) > 0 {
 		orderAsc := order[0:1]
 		orderKey := order[1:]
-		if xsql.AsStringArray(supported).HavingOne(orderKey) {
-			orderby = "order by " + orderKey
-		}
-		if len(orderby) > 0 && orderAsc == "+" {
-			orderby += " asc"
-		} else if len(orderby) > 0 && orderAsc == "-" {
-			orderby += " desc"
+		for _, spec := range strings.Split(supported, ",") {
+			options := strings.Split(strings.TrimSpace(spec), "#")
+			if options[0] != orderKey {
+				continue
+			}
+			field, nulls := orderKey, ""
+			for _, option := range options[1:] {
+				switch option {
+				case "ci":
+					field = "lower(" + orderKey + ")"
+				case "nullslast":
+					nulls = " nulls last"
+				case "nullsfirst":
+					nulls = " nulls first"
+				}
+			}
+			orderby = "order by " + field
+			if orderAsc == "+" {
+				orderby += " asc"
+			} else if orderAsc == "-" {
+				orderby += " desc"
+			}
+			orderby += nulls
+			break
 		}
 	}
 	return
@@ -68,6 +98,11 @@ func NewValue(v interface{}) (value reflect.Value) {
 			if _, ok := f.(TableName); ok {
 				continue
 			}
+			if meta, ok := f.(MetaField); ok {
+				item := reflect.New(reflect.TypeOf(meta.Value))
+				result = append(result, MetaField{Name: meta.Name, Value: item.Interface()})
+				continue
+			}
 			item := reflect.New(reflect.ValueOf(v[i]).Type())
 			result = append(result, item.Interface())
 		}
@@ -97,6 +132,42 @@ func MetaWith(o interface{}, fields ...interface{}) (v []interface{}) {
 	return
 }
 
+// MetaField is the named meta value used by MetaWith, the name is used as field when it is not on filter
+type MetaField struct {
+	Name  string
+	Value interface{}
+}
+
+// Int64Field will return int64 MetaField by name, like Int64Field("max(tid)")
+func Int64Field(name string) MetaField {
+	return MetaField{Name: name, Value: int64(0)}
+}
+
+// StringField will return string MetaField by name
+func StringField(name string) MetaField {
+	return MetaField{Name: name, Value: ""}
+}
+
+// TimeField will return xsql.Time MetaField by name
+func TimeField(name string) MetaField {
+	return MetaField{Name: name, Value: xsql.Time{}}
+}
+
+// metaField will return the field item of meta v by filter fields on offset or MetaField name, and the meta value
+func metaField(filterFields []string, offset int, v interface{}) (item string, value interface{}, ok bool) {
+	value = v
+	if offset < len(filterFields) && len(filterFields[offset]) > 0 {
+		item, ok = filterFields[offset], true
+	}
+	if meta, isMeta := v.(MetaField); isMeta {
+		value = meta.Value
+		if !ok {
+			item, ok = meta.Name, true
+		}
+	}
+	return
+}
+
 var Default = &CRUD{
 	Scanner: attrscan.Scanner{
 		Tag: "json",
@@ -106,6 +177,7 @@ var Default = &CRUD{
 	},
 	ArgFormat: "$%v",
 	ErrNoRows: nil,
+	MaxParams: 65535,
 	Log: func(caller int, format string, args ...interface{}) {
 		log.Output(caller+3, fmt.Sprintf(format, args...))
 	},
@@ -116,7 +188,15 @@ var Default = &CRUD{
 
 type NameConv func(on, name string, field reflect.StructField) string
 type ParmConv func(on, fieldName, fieldFunc string, field reflect.StructField, value interface{}) interface{}
+
+type skipField struct{}
+
+// SkipField is the value returned by ParmConv to omit the field and placeholder from insert/update/where,
+// the field is scanned to throwaway dest when it is returned on scan, so the select list is kept aligned
+var SkipField interface{} = skipField{}
+
 type LogF func(caller int, format string, args ...interface{})
+type LogCtxF func(ctx context.Context, caller int, format string, args ...interface{})
 type TableName string
 type FilterValue string
 
@@ -124,14 +204,40 @@ func Args(args ...interface{}) []interface{} {
 	return args
 }
 
+// Stmt is the sql statement with args
+type Stmt struct {
+	SQL  string
+	Args []interface{}
+}
+
 type CRUD struct {
 	attrscan.Scanner
-	ArgFormat   string
-	ErrNoRows   error
-	Verbose     bool
-	Log         LogF
-	TablePrefix string
-	ParmConv    ParmConv
+	ArgFormat     string
+	ErrNoRows     error
+	Verbose       bool
+	Log           LogF
+	LogCtx        LogCtxF // log with ctx instead of Log when it is setted, the ctx is context.Background() on the sql builders without ctx
+	TablePrefix   string
+	ParmConv      ParmConv
+	StrictFilter  bool
+	LastInsertID  bool            // use Exec and LastInsertId to scan the insert id instead of returning
+	MaxParams     int             // the max params count of one statement, BulkInsertFilter/UpdateWhereIn/QueryByKeys is split by it, other statement over it is returned ErrTooManyParams, zero is not limited
+	InsertDefault bool            // generate insert into table default values when no fields is matched on insert instead of returning ErrNoFields, it is not supported by mysql
+	StrictDest    bool            // return error when some dest is never setted on Scan/ScanRow, it is mostly caused by missing pattern or always nil/zero value
+	ScanAlloc     bool            // allocate new value and resolve scan args for each row on Scan, disable the value reusing when dests is not retaining it
+	QuoteIdents   bool            // quote table and field name which is reserved word or having upper case/special char, the filter and dest pattern is still using unquoted name
+	Dialect       string          // the sql dialect like postgres/mysql/sqlite, it is postgres if empty
+	IdentQuote    string          // the identifier quote char, default is "
+	ReservedWords map[string]bool // the reserved words to quote, default is ReservedPG
+}
+
+// logf will log by LogCtx when it is setted, else by Log
+func (c *CRUD) logf(ctx context.Context, caller int, format string, args ...interface{}) {
+	if c.LogCtx != nil {
+		c.LogCtx(ctx, caller+1, format, args...)
+	} else {
+		c.Log(caller+1, format, args...)
+	}
 }
 
 func (c *CRUD) getErrNoRows() (err error) {
@@ -143,12 +249,49 @@ func (c *CRUD) getErrNoRows() (err error) {
 	return
 }
 
+type tablePrefixKey struct{}
+
+// WithTablePrefix will return ctx with table prefix, the CRUD operation on ctx use it instead of CRUD.TablePrefix
+func WithTablePrefix(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, tablePrefixKey{}, prefix)
+}
+
+// withContext will return the CRUD copy with table prefix in ctx, it return c if ctx is not having table prefix
+func (c *CRUD) withContext(ctx context.Context) *CRUD {
+	if ctx == nil {
+		return c
+	}
+	prefix, ok := ctx.Value(tablePrefixKey{}).(string)
+	if !ok || prefix == c.TablePrefix {
+		return c
+	}
+	cc := *c
+	cc.TablePrefix = prefix
+	return &cc
+}
+
 func Table(v interface{}) (table string) {
 	table = Default.Table(v)
 	return
 }
 
+func TableContext(ctx context.Context, v interface{}) (table string) {
+	table = Default.TableContext(ctx, v)
+	return
+}
+
+// TableContext will return the table name of v with table prefix in ctx or CRUD.TablePrefix
+func (c *CRUD) TableContext(ctx context.Context, v interface{}) (table string) {
+	table = c.withContext(ctx).Table(v)
+	return
+}
+
 func (c *CRUD) Table(v interface{}) (table string) {
+	table = c.QuoteIdent(c.table(v))
+	return
+}
+
+func (c *CRUD) table(v interface{}) (table string) {
 	if v, ok := v.([]interface{}); ok {
 		for _, f := range v {
 			if tableName, ok := f.(TableName); ok {
@@ -190,6 +333,9 @@ func (c *CRUD) Table(v interface{}) (table string) {
 }
 
 func (c *CRUD) Sprintf(format string, v int) string {
+	if !strings.Contains(c.ArgFormat, "%") { //not index arg, like ?
+		return format
+	}
 	args := []interface{}{}
 	arg := fmt.Sprintf("%d", v)
 	n := strings.Count(format, c.ArgFormat)
@@ -205,6 +351,9 @@ func FilterFieldCall(on string, v interface{}, filter string, call func(fieldNam
 }
 
 func (c *CRUD) FilterFieldCall(on string, v interface{}, filter string, call func(fieldName, fieldFunc string, field reflect.StructField, value interface{})) (table string) {
+	if err := c.checkFilterOnce(v, filter); err != nil {
+		panic(err)
+	}
 	filters := strings.Split(filter, "|")
 	called := map[string]bool{}
 	recordCall := func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {
@@ -243,17 +392,18 @@ func (c *CRUD) filterFieldOnceCall(on string, v interface{}, filter string, call
 				}
 				continue
 			}
-			if offset >= len(filterFields) {
-				panic(fmt.Sprintf("meta v[%v] is not found on filter", offset))
+			fieldItem, fieldValue, ok := metaField(filterFields, offset, f)
+			if !ok {
+				panic(newFilterError(v, filter, "", fmt.Sprintf("meta v[%v] is not found", offset)))
 			}
-			fieldParts := strings.SplitN(strings.Trim(filterFields[offset], ")"), "(", 2)
+			fieldParts := strings.SplitN(strings.Trim(fieldItem, ")"), "(", 2)
 			fieldName := fieldParts[0]
 			fieldFunc := ""
 			if len(fieldParts) > 1 {
 				fieldName = fieldParts[1]
 				fieldFunc = fieldParts[0]
 			}
-			call(fieldAlias+fieldName, fieldFunc, reflect.StructField{}, f)
+			call(fieldAlias+fieldName, fieldFunc, reflect.StructField{}, fieldValue)
 			offset++
 		}
 		return
@@ -280,42 +430,255 @@ func (c *CRUD) filterFieldOnceCall(on string, v interface{}, filter string, call
 	if len(parts) > 1 {
 		table = table + " " + parts[0]
 	}
+	filter, _ = filterOnly(filter)
 	c.Scanner.FilterFieldCall(on, v, filter, call)
 	return
 }
 
+// filterOnly will return filter with #only option replaced by all, the #only is selecting exactly the listed fields
+// without checking nil/zero value, and the listed fields is checked by CheckFilter
+func filterOnly(filter string) (filter_ string, only bool) {
+	filter_ = filter
+	parts := strings.SplitN(filter, "#", 2)
+	if len(parts) < 2 {
+		return
+	}
+	options := strings.Split(parts[1], ",")
+	for i, option := range options {
+		if strings.TrimSpace(option) == "only" {
+			options[i] = "all"
+			only = true
+		}
+	}
+	if only {
+		filter_ = parts[0] + "#" + strings.Join(options, ",")
+	}
+	return
+}
+
+// CheckFilter will check all fields in filter is exists on v, it return error with fields not found
+func CheckFilter(v interface{}, filter string) (err error) {
+	err = Default.CheckFilter(v, filter)
+	return
+}
+
+// CheckFilter will check all fields in filter is exists on v, it return error with fields not found,
+// the filter with #only option must list fields and not be combined with ^ exclusion
+func (c *CRUD) CheckFilter(v interface{}, filter string) (err error) {
+	if _, ok := v.([]interface{}); ok {
+		return
+	}
+	reflectValue := reflect.Indirect(reflect.ValueOf(v))
+	if reflectValue.Kind() != reflect.Struct {
+		return
+	}
+	fieldAll := map[string]bool{}
+	c.filterFieldNames(reflectValue.Type(), fieldAll)
+	missing := []string{}
+	for _, part := range strings.Split(filter, "|") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "*")
+		if parts := strings.SplitN(part, ".", 2); len(parts) > 1 {
+			part = parts[1]
+		}
+		_, only := filterOnly(part)
+		part = strings.SplitN(part, "#", 2)[0]
+		if only && strings.HasPrefix(part, "^") {
+			filterErr := newFilterError(v, filter, "", "#only is not supported with ^ exclusion")
+			filterErr.Pos = strings.Index(filter, "^")
+			err = filterErr
+			return
+		}
+		if only && len(strings.TrimSpace(part)) < 1 {
+			err = newFilterError(v, filter, "", "#only fields is empty")
+			return
+		}
+		part = strings.TrimPrefix(part, "^")
+		for _, fieldItem := range strings.Split(part, ",") {
+			fieldParts := strings.SplitN(strings.Trim(strings.TrimSpace(fieldItem), ")"), "(", 2)
+			fieldName := fieldParts[len(fieldParts)-1]
+			if len(fieldName) > 0 && !fieldAll[fieldName] {
+				missing = append(missing, fieldName)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		err = newFilterError(v, filter, strings.Join(missing, ","), "is not found")
+	}
+	return
+}
+
+type filterCheckKey struct {
+	typ    reflect.Type
+	tag    string
+	filter string
+	strict bool
+}
+
+var filterCheckCache = sync.Map{}
+
+// checkFilterOnce will check filter on v by CheckFilter when StrictFilter is enabled or only #only parts when not,
+// the result is cached by struct type and filter, it is called by error-returning entry points before sql is built
+func (c *CRUD) checkFilterOnce(v interface{}, filter string) (err error) {
+	if _, ok := v.([]interface{}); ok {
+		return
+	}
+	reflectType := reflect.TypeOf(v)
+	for reflectType != nil && reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	if reflectType == nil || reflectType.Kind() != reflect.Struct {
+		return
+	}
+	key := filterCheckKey{typ: reflectType, tag: c.Scanner.Tag, filter: filter, strict: c.StrictFilter}
+	if cached, ok := filterCheckCache.Load(key); ok {
+		err, _ = cached.(error)
+		return
+	}
+	if c.StrictFilter {
+		err = c.CheckFilter(v, filter)
+	} else {
+		for _, part := range strings.Split(filter, "|") {
+			if _, only := filterOnly(part); only {
+				if err = c.CheckFilter(v, part); err != nil {
+					break
+				}
+			}
+		}
+	}
+	filterCheckCache.Store(key, err)
+	return
+}
+
+func (c *CRUD) filterFieldNames(reflectType reflect.Type, fieldAll map[string]bool) {
+	numField := reflectType.NumField()
+	for i := 0; i < numField; i++ {
+		fieldType := reflectType.Field(i)
+		fieldFilter := strings.TrimSpace(strings.TrimPrefix(fieldType.Tag.Get("filter"), "#"))
+		if strings.Contains(","+fieldFilter+",", ",inline,") {
+			c.filterFieldNames(reflect.Indirect(reflect.New(fieldType.Type)).Type(), fieldAll)
+			continue
+		}
+		fieldName := strings.SplitN(fieldType.Tag.Get(c.Scanner.Tag), ",", 2)[0]
+		if len(fieldName) > 0 && fieldName != "-" {
+			fieldAll[fieldName] = true
+		}
+	}
+}
+
 func FilterFormatCall(formats string, args []interface{}, call func(format string, arg interface{})) {
 	Default.FilterFormatCall(formats, args, call)
 }
 
+// FilterFormatCall will call each format with arg which is not nil/zero, the nil/zero arg is included by #nil,#zero,#all options.
+// The format having #keepzero option like price=$%v#keepzero is always included when arg is zero, but it is still skipped when arg is nil without #nil.
 func (c *CRUD) FilterFormatCall(formats string, args []interface{}, call func(format string, arg interface{})) {
-	formatParts := strings.SplitN(formats, "#", 2)
+	formatList, keepZero, options := splitFormats(formats)
 	var incNil, incZero bool
-	if len(formatParts) > 1 && len(formatParts[1]) > 0 {
-		incNil = strings.Contains(","+formatParts[1]+",", ",nil,") || strings.Contains(","+formatParts[1]+",", ",all,")
-		incZero = strings.Contains(","+formatParts[1]+",", ",zero,") || strings.Contains(","+formatParts[1]+",", ",all,")
+	if len(options) > 0 {
+		incNil = strings.Contains(","+options+",", ",nil,") || strings.Contains(","+options+",", ",all,")
+		incZero = strings.Contains(","+options+",", ",zero,") || strings.Contains(","+options+",", ",all,")
 	}
-	formatList := strings.Split(formatParts[0], ",")
 	if len(formatList) != len(args) {
 		panic(fmt.Sprintf("count formats=%v  is not equal to args=%v", len(formatList), len(args)))
 	}
 	for i, format := range formatList {
 		arg := args[i]
-		if !c.Scanner.CheckValue(reflect.ValueOf(arg), incNil, incZero) {
+		if !c.CheckValue(arg, incNil, incZero || keepZero[i]) {
 			continue
 		}
 		call(format, arg)
 	}
 }
 
+// splitFormats will split formats to format list and options, the #keepzero option on one format is returned by keepZero
+func splitFormats(formats string) (formatList []string, keepZero []bool, options string) {
+	entries := strings.Split(formats, ",")
+	for i, entry := range entries {
+		parts := strings.Split(entry, "#")
+		formatList = append(formatList, parts[0])
+		keepZero = append(keepZero, len(parts) > 1 && parts[1] == "keepzero")
+		if keepZero[i] {
+			parts = parts[1:]
+		}
+		if len(parts) > 1 {
+			options = strings.Join(append(parts[1:2], entries[i+1:]...), ",")
+			break
+		}
+	}
+	return
+}
+
+func CheckValue(arg interface{}, incNil, incZero bool) bool {
+	return Default.CheckValue(arg, incNil, incZero)
+}
+
+var zeroCheckerType = reflect.TypeOf((*ZeroChecker)(nil)).Elem()
+
+// CheckValue will check whether arg should be included by incNil/incZero,
+// the NilChecker/ZeroChecker on arg is honored before kind based checks, so custom types control their own nil/zero
+func (c *CRUD) CheckValue(arg interface{}, incNil, incZero bool) bool {
+	value := reflect.ValueOf(arg)
+	if !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return incNil
+	}
+	if checker, ok := arg.(NilChecker); ok && checker.IsNil() {
+		return incNil
+	}
+	if _, ok := arg.(ZeroChecker); !ok && value.Kind() != reflect.Ptr && reflect.PtrTo(value.Type()).Implements(zeroCheckerType) {
+		//copy to addressable value for checker implemented on pointer receiver
+		addr := reflect.New(value.Type())
+		addr.Elem().Set(value)
+		arg = addr.Interface()
+	}
+	if checker, ok := arg.(ZeroChecker); ok {
+		return incZero || !checker.IsZero()
+	}
+	return c.Scanner.CheckValue(value, incNil, incZero)
+}
+
+func FilterWhere(args []interface{}, v interface{}, filter string) (where_ []string, args_ []interface{}) {
+	where_, args_ = Default.FilterWhere(args, v, filter)
+	return
+}
+
+// FilterWhere will generate where by v fields and filter, the struct field having join tag is generated as group like (a or b),
+// the empty group is skipped. If v having _ field with join tag, all where of v is combined to one group by it
 func (c *CRUD) FilterWhere(args []interface{}, v interface{}, filter string) (where_ []string, args_ []interface{}) {
+	where_, args_ = c.filterWhere(args, v, filter)
+	if join := c.whereJoin(v); len(join) > 0 && len(where_) > 1 {
+		where_ = []string{"(" + strings.Join(where_, " "+join+" ") + ")"}
+	}
+	return
+}
+
+// whereJoin will return the join tag of _ field on struct v
+func (c *CRUD) whereJoin(v interface{}) (join string) {
+	reflectType := reflect.TypeOf(v)
+	for reflectType != nil && reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	if reflectType == nil || reflectType.Kind() != reflect.Struct {
+		return
+	}
+	if field, ok := reflectType.FieldByName("_"); ok {
+		join = field.Tag.Get("join")
+	}
+	return
+}
+
+func (c *CRUD) filterWhere(args []interface{}, v interface{}, filter string) (where_ []string, args_ []interface{}) {
 	args_ = args
 	c.FilterFieldCall("where", v, filter, func(fieldName, fieldFunc string, field reflect.StructField, fieldValue interface{}) {
 		join := field.Tag.Get("join")
+		if field.Type.Kind() == reflect.Struct && len(join) < 1 {
+			join = c.whereJoin(fieldValue)
+		}
 		if field.Type.Kind() == reflect.Struct && len(join) > 0 {
 			var cmpInner []string
-			cmpInner, args_ = c.FilterWhere(args_, fieldValue, field.Tag.Get("filter"))
-			where_ = append(where_, "("+strings.Join(cmpInner, " "+join+" ")+")")
+			cmpInner, args_ = c.filterWhere(args_, fieldValue, field.Tag.Get("filter"))
+			if len(cmpInner) > 0 {
+				where_ = append(where_, "("+strings.Join(cmpInner, " "+join+" ")+")")
+			}
 			return
 		}
 		cmp := field.Tag.Get("cmp")
@@ -323,7 +686,7 @@ func (c *CRUD) FilterWhere(args []interface{}, v interface{}, filter string) (wh
 			return
 		}
 		if len(cmp) < 1 {
-			cmp = fieldName + " = " + c.ArgFormat
+			cmp = c.fieldIdent(fieldName, field) + " = " + c.ArgFormat
 		}
 		if !strings.Contains(cmp, c.ArgFormat) {
 			cmp += " " + c.ArgFormat
@@ -331,7 +694,11 @@ func (c *CRUD) FilterWhere(args []interface{}, v interface{}, filter string) (wh
 		if (strings.Contains(cmp, " or ") || strings.Contains(cmp, " and ")) && !strings.HasPrefix(cmp, "(") {
 			cmp = "(" + cmp + ")"
 		}
-		args_ = append(args_, c.ParmConv("where", fieldName, fieldFunc, field, fieldValue))
+		arg := c.ParmConv("where", fieldName, fieldFunc, field, fieldValue)
+		if arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
 		where_ = append(where_, c.Sprintf(cmp, len(args_)))
 	})
 	return
@@ -345,10 +712,12 @@ func AppendInsert(fields, param []string, args []interface{}, ok bool, format st
 func (c *CRUD) AppendInsert(fields, param []string, args []interface{}, ok bool, format string, v interface{}) (fields_, param_ []string, args_ []interface{}) {
 	fields_, param_, args_ = fields, param, args
 	if ok {
-		args_ = append(args_, c.ParmConv("insert", format, "", reflect.StructField{}, v))
-		parts := strings.SplitN(format, "=", 2)
-		param_ = append(param_, c.Sprintf(parts[1], len(args_)))
-		fields_ = append(fields_, parts[0])
+		if arg := c.ParmConv("insert", format, "", reflect.StructField{}, v); arg != SkipField {
+			args_ = append(args_, arg)
+			parts := strings.SplitN(format, "=", 2)
+			param_ = append(param_, c.Sprintf(parts[1], len(args_)))
+			fields_ = append(fields_, parts[0])
+		}
 	}
 	return
 }
@@ -361,7 +730,10 @@ func AppendInsertf(fields, param []string, args []interface{}, formats string, v
 func (c *CRUD) AppendInsertf(fields, param []string, args []interface{}, formats string, v ...interface{}) (fields_, param_ []string, args_ []interface{}) {
 	fields_, param_, args_ = fields, param, args
 	c.FilterFormatCall(formats, v, func(format string, arg interface{}) {
-		args_ = append(args_, c.ParmConv("insert", format, "", reflect.StructField{}, arg))
+		if arg = c.ParmConv("insert", format, "", reflect.StructField{}, arg); arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
 		parts := strings.SplitN(format, "=", 2)
 		param_ = append(param_, c.Sprintf(parts[1], len(args_)))
 		fields_ = append(fields_, parts[0])
@@ -377,8 +749,10 @@ func AppendSet(sets []string, args []interface{}, ok bool, format string, v inte
 func (c *CRUD) AppendSet(sets []string, args []interface{}, ok bool, format string, v interface{}) (sets_ []string, args_ []interface{}) {
 	sets_, args_ = sets, args
 	if ok {
-		args_ = append(args_, c.ParmConv("update", format, "", reflect.StructField{}, v))
-		sets_ = append(sets_, c.Sprintf(format, len(args_)))
+		if arg := c.ParmConv("update", format, "", reflect.StructField{}, v); arg != SkipField {
+			args_ = append(args_, arg)
+			sets_ = append(sets_, c.Sprintf(format, len(args_)))
+		}
 	}
 	return
 }
@@ -391,7 +765,10 @@ func AppendSetf(sets []string, args []interface{}, formats string, v ...interfac
 func (c *CRUD) AppendSetf(sets []string, args []interface{}, formats string, v ...interface{}) (sets_ []string, args_ []interface{}) {
 	sets_, args_ = sets, args
 	c.FilterFormatCall(formats, v, func(format string, arg interface{}) {
-		args_ = append(args_, c.ParmConv("update", format, "", reflect.StructField{}, arg))
+		if arg = c.ParmConv("update", format, "", reflect.StructField{}, arg); arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
 		sets_ = append(sets_, c.Sprintf(format, len(args_)))
 	})
 	return
@@ -405,8 +782,10 @@ func AppendWhere(where []string, args []interface{}, ok bool, format string, v i
 func (c *CRUD) AppendWhere(where []string, args []interface{}, ok bool, format string, v interface{}) (where_ []string, args_ []interface{}) {
 	where_, args_ = where, args
 	if ok {
-		args_ = append(args_, c.ParmConv("where", format, "", reflect.StructField{}, v))
-		where_ = append(where_, c.Sprintf(format, len(args_)))
+		if arg := c.ParmConv("where", format, "", reflect.StructField{}, v); arg != SkipField {
+			args_ = append(args_, arg)
+			where_ = append(where_, c.Sprintf(format, len(args_)))
+		}
 	}
 	return
 }
@@ -419,12 +798,50 @@ func AppendWheref(where []string, args []interface{}, format string, v ...interf
 func (c *CRUD) AppendWheref(where []string, args []interface{}, formats string, v ...interface{}) (where_ []string, args_ []interface{}) {
 	where_, args_ = where, args
 	c.FilterFormatCall(formats, v, func(format string, arg interface{}) {
-		args_ = append(args_, c.ParmConv("where", format, "", reflect.StructField{}, arg))
+		if arg = c.ParmConv("where", format, "", reflect.StructField{}, arg); arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
 		where_ = append(where_, c.Sprintf(format, len(args_)))
 	})
 	return
 }
 
+func AppendWhereIn(where []string, args []interface{}, field string, values interface{}) (where_ []string, args_ []interface{}) {
+	where_, args_ = Default.AppendWhereIn(where, args, field, values)
+	return
+}
+
+// AppendWhereIn will append field in ($1,$2...) to where by each item of values slice, it is portable on dialect not supporting any($1),
+// the 1=0 is appended when values is nil or empty or all skipped to match nothing.
+// The single statement over MaxParams is returned ErrTooManyParams on run, use UpdateWhereIn/QueryByKeys to split large values by MaxParams
+func (c *CRUD) AppendWhereIn(where []string, args []interface{}, field string, values interface{}) (where_ []string, args_ []interface{}) {
+	where_, args_ = where, args
+	reflectValue := reflect.Indirect(reflect.ValueOf(values))
+	if reflectValue.IsValid() && reflectValue.Kind() != reflect.Slice && reflectValue.Kind() != reflect.Array {
+		panic(fmt.Sprintf("values must be slice, but %v", reflect.TypeOf(values)))
+	}
+	n := 0
+	if reflectValue.IsValid() {
+		n = reflectValue.Len()
+	}
+	param := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		arg := c.ParmConv("where", field, "", reflect.StructField{}, reflectValue.Index(i).Interface())
+		if arg == SkipField {
+			continue
+		}
+		args_ = append(args_, arg)
+		param = append(param, c.Sprintf(c.ArgFormat, len(args_)))
+	}
+	if len(param) < 1 {
+		where_ = append(where_, "1=0")
+		return
+	}
+	where_ = append(where_, c.QuoteIdent(field)+" in ("+strings.Join(param, ",")+")")
+	return
+}
+
 func AppendWhereUnify(where []string, args []interface{}, v interface{}, enabled ...string) (where_ []string, args_ []interface{}) {
 	where_, args_ = Default.AppendWhereUnify(where, args, v, enabled...)
 	return
@@ -442,24 +859,24 @@ func (c *CRUD) AppendWhereUnify(where []string, args []interface{}, v interface{
 			continue
 		}
 		modelType, _ := reflectValue.Type().FieldByName(key)
-		filterWhere, filterArgs := c.FilterWhere(args, modelValue.Addr().Interface(), modelType.Tag.Get("filter"))
+		var filterWhere []string
+		filterWhere, args_ = c.FilterWhere(args_, modelValue.Addr().Interface(), modelType.Tag.Get("filter"))
 		where_ = append(where_, filterWhere...)
-		args_ = append(args_, filterArgs...)
 	}
 	return
 }
 
 func JoinWhere(sql string, where []string, sep string, suffix ...string) (sql_ string) {
-	sql_ = Default.joinWhere(1, sql, where, sep, suffix...)
+	sql_ = Default.joinWhere(1, context.Background(), sql, where, sep, suffix...)
 	return
 }
 
 func (c *CRUD) JoinWhere(sql string, where []string, sep string, suffix ...string) (sql_ string) {
-	sql_ = c.joinWhere(1, sql, where, sep, suffix...)
+	sql_ = c.joinWhere(1, context.Background(), sql, where, sep, suffix...)
 	return
 }
 
-func (c *CRUD) joinWhere(caller int, sql string, where []string, sep string, suffix ...string) (sql_ string) {
+func (c *CRUD) joinWhere(caller int, ctx context.Context, sql string, where []string, sep string, suffix ...string) (sql_ string) {
 	sql_ = sql
 	if len(where) > 0 {
 		sql_ += " where " + strings.Join(where, " "+sep+" ")
@@ -468,31 +885,37 @@ func (c *CRUD) joinWhere(caller int, sql string, where []string, sep string, suf
 		sql_ += " " + strings.Join(suffix, " ")
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD join where done with sql:%v", sql_)
+		c.logf(ctx, caller, "CRUD join where done with sql:%v", sql_)
 	}
 	return
 }
 
 func JoinWheref(sql string, args []interface{}, formats string, formatArgs ...interface{}) (sql_ string, args_ []interface{}) {
-	sql_, args_ = Default.joinWheref(1, sql, args, formats, formatArgs...)
+	sql_, args_ = Default.joinWheref(1, context.Background(), sql, args, formats, formatArgs...)
 	return
 }
 
 func (c *CRUD) JoinWheref(sql string, args []interface{}, formats string, formatArgs ...interface{}) (sql_ string, args_ []interface{}) {
-	sql_, args_ = c.joinWheref(1, sql, args, formats, formatArgs...)
+	sql_, args_ = c.joinWheref(1, context.Background(), sql, args, formats, formatArgs...)
 	return
 }
 
-func (c *CRUD) joinWheref(caller int, sql string, args []interface{}, formats string, formatArgs ...interface{}) (sql_ string, args_ []interface{}) {
+func (c *CRUD) joinWheref(caller int, ctx context.Context, sql string, args []interface{}, formats string, formatArgs ...interface{}) (sql_ string, args_ []interface{}) {
 	sql_, args_ = sql, args
 	if len(formats) < 1 {
 		return
 	}
 	var where []string
-	sep := "and"
-	formatParts := strings.SplitN(formats, "#", 2)
-	if len(formatParts) > 1 {
-		optionParts := strings.Split(formatParts[1], ",")
+	where, args_ = c.AppendWheref(nil, args_, formats, formatArgs...)
+	sql_ = c.joinWhere(caller+1, ctx, sql, where, wherefSep(formats))
+	return
+}
+
+// wherefSep will return the where sep by +sep option of formats like #+or, default is and
+func wherefSep(formats string) (sep string) {
+	sep = "and"
+	if _, _, options := splitFormats(formats); len(options) > 0 {
+		optionParts := strings.Split(options, ",")
 		for _, part := range optionParts {
 			if strings.HasPrefix(part, "+") {
 				sep = strings.TrimPrefix(part, "+")
@@ -500,49 +923,78 @@ func (c *CRUD) joinWheref(caller int, sql string, args []interface{}, formats st
 			}
 		}
 	}
-	where, args_ = c.AppendWheref(nil, args_, formats, formatArgs...)
-	sql_ = c.joinWhere(caller+1, sql, where, sep)
 	return
 }
 
 func JoinWhereUnify(sql string, args []interface{}, v interface{}, enabled ...string) (sql_ string, args_ []interface{}) {
-	sql_, args_ = Default.joinWhereUnify(1, sql, args, v, enabled...)
+	sql_, args_ = Default.joinWhereUnify(1, context.Background(), sql, args, v, enabled...)
 	return
 }
 
 func (c *CRUD) JoinWhereUnify(sql string, args []interface{}, v interface{}, enabled ...string) (sql_ string, args_ []interface{}) {
-	sql_, args_ = c.joinWhereUnify(1, sql, args, v, enabled...)
+	sql_, args_ = c.joinWhereUnify(1, context.Background(), sql, args, v, enabled...)
 	return
 }
 
-func (c *CRUD) joinWhereUnify(caller int, sql string, args []interface{}, v interface{}, enabled ...string) (sql_ string, args_ []interface{}) {
+func (c *CRUD) joinWhereUnify(caller int, ctx context.Context, sql string, args []interface{}, v interface{}, enabled ...string) (sql_ string, args_ []interface{}) {
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
 	reflectType := reflectValue.Type()
 	if len(enabled) < 1 {
 		enabled = append(enabled, "Where")
 	}
-	whereJoin := ""
+	args_ = args
+	var sections [][]string
+	var sectionJoins []string
 	for _, key := range enabled {
+		whereValue := reflectValue.FieldByName(key)
+		if !whereValue.IsValid() {
+			continue
+		}
 		whereType, _ := reflectType.FieldByName(key)
-		whereJoin += " " + whereType.Tag.Get("join")
+		var where []string
+		where, args_ = c.FilterWhere(args_, whereValue.Addr().Interface(), whereType.Tag.Get("filter"))
+		if len(where) < 1 {
+			continue
+		}
+		join := strings.TrimSpace(whereType.Tag.Get("join"))
+		if len(join) < 1 {
+			join = "and"
+		}
+		sections = append(sections, where)
+		sectionJoins = append(sectionJoins, join)
+	}
+	switch len(sections) {
+	case 0:
+		sql_ = c.joinWhere(caller+1, ctx, sql, nil, "")
+	case 1:
+		sql_ = c.joinWhere(caller+1, ctx, sql, sections[0], sectionJoins[0])
+	default:
+		//multi sections is wrapped by parentheses and joined by joinsections tag on Model field, default is and
+		modelType, _ := reflectType.FieldByName("Model")
+		join := strings.TrimSpace(modelType.Tag.Get("joinsections"))
+		if len(join) < 1 {
+			join = "and"
+		}
+		where := []string{}
+		for i, section := range sections {
+			where = append(where, "("+strings.Join(section, " "+sectionJoins[i]+" ")+")")
+		}
+		sql_ = c.joinWhere(caller+1, ctx, sql, where, join)
 	}
-	args_ = args
-	where, args_ := c.AppendWhereUnify(nil, args_, v)
-	sql_ = c.joinWhere(caller+1, sql, where, whereJoin)
 	return
 }
 
 func JoinPage(sql, orderby string, offset, limit int) (sql_ string) {
-	sql_ = Default.joinPage(1, sql, orderby, offset, limit)
+	sql_ = Default.joinPage(1, context.Background(), sql, orderby, offset, limit)
 	return
 }
 
 func (c *CRUD) JoinPage(sql, orderby string, offset, limit int) (sql_ string) {
-	sql_ = c.joinPage(1, sql, orderby, offset, limit)
+	sql_ = c.joinPage(1, context.Background(), sql, orderby, offset, limit)
 	return
 }
 
-func (c *CRUD) joinPage(caller int, sql, orderby string, offset, limit int) (sql_ string) {
+func (c *CRUD) joinPage(caller int, ctx context.Context, sql, orderby string, offset, limit int) (sql_ string) {
 	sql_ = sql
 	if len(orderby) > 0 && (offset >= 0 || limit > 0) {
 		sql_ += " " + orderby
@@ -551,59 +1003,79 @@ func (c *CRUD) joinPage(caller int, sql, orderby string, offset, limit int) (sql
 		sql_ += fmt.Sprintf(" limit %v offset %v", limit, offset)
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD join page done with sql:%v", sql_)
+		c.logf(ctx, caller, "CRUD join page done with sql:%v", sql_)
 	}
 	return
 }
 
 func JoinPageUnify(sql string, v interface{}) (sql_ string) {
-	sql_ = Default.joinPageUnify(1, sql, v)
+	sql_ = Default.joinPageUnify(1, context.Background(), sql, v)
 	return
 }
 
 func (c *CRUD) JoinPageUnify(sql string, v interface{}) (sql_ string) {
-	sql_ = c.joinPageUnify(1, sql, v)
+	sql_ = c.joinPageUnify(1, context.Background(), sql, v)
 	return
 }
 
-func (c *CRUD) joinPageUnify(caller int, sql string, v interface{}) (sql_ string) {
+func (c *CRUD) joinPageUnify(caller int, ctx context.Context, sql string, v interface{}) (sql_ string) {
 	sql_ = sql
-	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
-	pageValue := reflectValue.FieldByName("Page")
-	pageType, _ := reflectType.FieldByName("Page")
-	if !pageValue.IsValid() {
+	plan := c.planUnify(v)
+	if plan.Page == nil {
 		return
 	}
+	pageValue := reflect.Indirect(reflect.ValueOf(v)).FieldByIndex(plan.Page.Index)
 	order := ""
-	orderType, _ := pageType.Type.FieldByName("Order")
-	orderValue := pageValue.FieldByName("Order")
-	if orderValue.IsValid() {
-		order = orderValue.String()
+	if plan.Order != nil {
+		order = pageValue.FieldByIndex(plan.Order.Index).String()
+		if len(plan.Order.Supported) > 0 {
+			order = BuildOrderby(plan.Order.Supported, order)
+		}
 		if len(order) < 1 {
-			order = orderType.Tag.Get("default")
+			order = plan.Order.Default
 		}
 	}
 	offset := 0
-	if offsetValue := pageValue.FieldByName("Offset"); offsetValue.IsValid() {
-		offset = int(offsetValue.Int())
-	} else if skipValue := pageValue.FieldByName("Skip"); skipValue.IsValid() {
-		offset = int(skipValue.Int())
+	if plan.Page.OffsetIndex != nil {
+		offset = int(pageValue.FieldByIndex(plan.Page.OffsetIndex).Int())
 	}
 	limit := 0
-	limitValue := pageValue.FieldByName("Limit")
-	if limitValue.IsValid() {
-		limit = int(limitValue.Int())
+	if plan.Page.LimitIndex != nil {
+		limit = int(pageValue.FieldByIndex(plan.Page.LimitIndex).Int())
+	}
+	if limit < 1 {
+		limit = plan.Page.LimitDefault
+	}
+	sql_ = c.joinPage(caller+1, ctx, sql_, order, offset, limit)
+	return
+}
+
+// errNoFields will return ErrNoFields with filter and struct type
+func (c *CRUD) errNoFields(v interface{}, filter string) error {
+	return fmt.Errorf("%w matched filter %v for struct %v", ErrNoFields, filter, reflect.TypeOf(v))
+}
+
+// checkParams will return ErrTooManyParams when args count is over MaxParams
+func (c *CRUD) checkParams(args []interface{}) (err error) {
+	if c.MaxParams > 0 && len(args) > c.MaxParams {
+		err = fmt.Errorf("%w, the statement params count %v is over MaxParams %v", ErrTooManyParams, len(args), c.MaxParams)
 	}
-	sql_ = c.joinPage(caller+1, sql_, order, offset, limit)
 	return
 }
 
 func (c *CRUD) queryerExec(queryer interface{}, ctx context.Context, sql string, args []interface{}) (insertId, affected int64, err error) {
+	if err = c.checkParams(args); err != nil {
+		return
+	}
 	reflectValue := reflect.ValueOf(queryer)
 	if reflectValue.Kind() == reflect.Func {
 		queryer = reflectValue.Call(nil)[0].Interface()
 	}
+	recorder := capturedSQL(ctx)
+	var begin time.Time
+	if recorder != nil {
+		begin = time.Now()
+	}
 	if q, ok := queryer.(Queryer); ok {
 		insertId, affected, err = q.Exec(ctx, sql, args...)
 	} else if q, ok := queryer.(CrudQueryer); ok {
@@ -611,14 +1083,25 @@ func (c *CRUD) queryerExec(queryer interface{}, ctx context.Context, sql string,
 	} else {
 		panic("queryer is not supported")
 	}
+	if recorder != nil {
+		recorder.record("exec", sql, args, time.Since(begin), err)
+	}
 	return
 }
 
 func (c *CRUD) queryerQuery(queryer interface{}, ctx context.Context, sql string, args []interface{}) (rows Rows, err error) {
+	if err = c.checkParams(args); err != nil {
+		return
+	}
 	reflectValue := reflect.ValueOf(queryer)
 	if reflectValue.Kind() == reflect.Func {
 		queryer = reflectValue.Call(nil)[0].Interface()
 	}
+	recorder := capturedSQL(ctx)
+	var begin time.Time
+	if recorder != nil {
+		begin = time.Now()
+	}
 	if q, ok := queryer.(Queryer); ok {
 		rows, err = q.Query(ctx, sql, args...)
 	} else if q, ok := queryer.(CrudQueryer); ok {
@@ -626,14 +1109,26 @@ func (c *CRUD) queryerQuery(queryer interface{}, ctx context.Context, sql string
 	} else {
 		panic(fmt.Sprintf("queryer %v is not supported", reflect.TypeOf(queryer)))
 	}
+	if recorder != nil {
+		recorder.record("query", sql, args, time.Since(begin), err)
+	}
 	return
 }
 
 func (c *CRUD) queryerQueryRow(queryer interface{}, ctx context.Context, sql string, args []interface{}) (row Row) {
+	if err := c.checkParams(args); err != nil {
+		row = &errRow{err: err}
+		return
+	}
 	reflectValue := reflect.ValueOf(queryer)
 	if reflectValue.Kind() == reflect.Func {
 		queryer = reflectValue.Call(nil)[0].Interface()
 	}
+	recorder := capturedSQL(ctx)
+	var begin time.Time
+	if recorder != nil {
+		begin = time.Now()
+	}
 	if q, ok := queryer.(Queryer); ok {
 		row = q.QueryRow(ctx, sql, args...)
 	} else if q, ok := queryer.(CrudQueryer); ok {
@@ -641,47 +1136,97 @@ func (c *CRUD) queryerQueryRow(queryer interface{}, ctx context.Context, sql str
 	} else {
 		panic(fmt.Sprintf("queryer %v is not supported", reflect.TypeOf(queryer)))
 	}
+	if recorder != nil {
+		recorder.record("queryRow", sql, args, time.Since(begin), nil)
+	}
 	return
 }
 
 func InsertArgs(v interface{}, filter string, args []interface{}) (table string, fields, param []string, args_ []interface{}) {
-	table, fields, param, args_ = Default.insertArgs(1, v, filter, args)
+	table, fields, param, args_ = Default.insertArgs(1, context.Background(), v, filter, args)
 	return
 }
 
 func (c *CRUD) InsertArgs(v interface{}, filter string, args []interface{}) (table string, fields, param []string, args_ []interface{}) {
-	table, fields, param, args_ = c.insertArgs(1, v, filter, args)
+	table, fields, param, args_ = c.insertArgs(1, context.Background(), v, filter, args)
 	return
 }
 
-func (c *CRUD) insertArgs(caller int, v interface{}, filter string, args []interface{}) (table string, fields, param []string, args_ []interface{}) {
+func (c *CRUD) insertArgs(caller int, ctx context.Context, v interface{}, filter string, args []interface{}) (table string, fields, param []string, args_ []interface{}) {
 	args_ = args
 	table = c.FilterFieldCall("insert", v, filter, func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {
-		args_ = append(args_, c.ParmConv("insert", fieldName, fieldFunc, field, value))
-		fields = append(fields, fieldName)
-		param = append(param, fmt.Sprintf(c.ArgFormat, len(args_)))
+		if len(field.Tag.Get("jsonpath")) > 0 { //json path field is only for query
+			return
+		}
+		arg := c.ParmConv("insert", fieldName, fieldFunc, field, value)
+		if arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
+		fields = append(fields, c.QuoteIdent(fieldName))
+		param = append(param, c.Sprintf(c.ArgFormat, len(args_)))
 	})
 	if c.Verbose {
-		c.Log(caller, "CRUD generate insert args by struct:%v,filter:%v, result is fields:%v,param:%v,args:%v", reflect.TypeOf(v), filter, fields, param, jsonString(args))
+		c.logf(ctx, caller, "CRUD generate insert args by struct:%v,filter:%v, result is fields:%v,param:%v,args:%v", reflect.TypeOf(v), filter, fields, param, jsonString(args))
 	}
 	return
 }
 
-func InsertSQL(v interface{}, filter string, suffix ...string) (sql string, args []interface{}) {
-	sql, args = Default.insertSQL(1, v, filter, suffix...)
+func InsertSQL(v interface{}, filter string, suffix ...string) (sql string, args []interface{}, err error) {
+	sql, args, err = Default.insertSQL(1, context.Background(), v, filter, suffix...)
 	return
 }
 
-func (c *CRUD) InsertSQL(v interface{}, filter string, suffix ...string) (sql string, args []interface{}) {
-	sql, args = c.insertSQL(1, v, filter, suffix...)
+// InsertSQL will return the insert sql with suffix and args of v, it return ErrNoFields when no fields is matched and InsertDefault is not enabled
+func (c *CRUD) InsertSQL(v interface{}, filter string, suffix ...string) (sql string, args []interface{}, err error) {
+	sql, args, err = c.insertSQL(1, context.Background(), v, filter, suffix...)
 	return
 }
 
-func (c *CRUD) insertSQL(caller int, v interface{}, filter string, suffix ...string) (sql string, args []interface{}) {
-	table, fields, param, args := c.insertArgs(caller+1, v, filter, nil)
-	sql = fmt.Sprintf(`insert into %v(%v) values(%v) %v`, table, strings.Join(fields, ","), strings.Join(param, ","), strings.Join(suffix, " "))
+func (c *CRUD) insertSQL(caller int, ctx context.Context, v interface{}, filter string, suffix ...string) (sql string, args []interface{}, err error) {
+	table, fields, param, args := c.insertArgs(caller+1, ctx, v, filter, nil)
+	sql, err = c.insertValues(table, fields, param, v, filter)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate insert sql by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	sql += " " + strings.Join(suffix, " ")
 	if c.Verbose {
-		c.Log(caller, "CRUD generate insert sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
+		c.logf(ctx, caller, "CRUD generate insert sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
+	}
+	return
+}
+
+func InsertSQLReturning(v interface{}, filter, scan string) (sql string, args, scanArgs []interface{}, err error) {
+	sql, args, scanArgs, err = Default.insertSQLReturning(1, context.Background(), v, filter, scan)
+	return
+}
+
+// InsertSQLReturning will return the insert sql with returning scan fields, args and the scan args of v to run by custom queryer, the returning is omitted when scan fields is empty,
+// it return ErrNoFields when no fields is matched and InsertDefault is not enabled
+func (c *CRUD) InsertSQLReturning(v interface{}, filter, scan string) (sql string, args, scanArgs []interface{}, err error) {
+	sql, args, scanArgs, err = c.insertSQLReturning(1, context.Background(), v, filter, scan)
+	return
+}
+
+func (c *CRUD) insertSQLReturning(caller int, ctx context.Context, v interface{}, filter, scan string) (sql string, args, scanArgs []interface{}, err error) {
+	table, fields, param, args := c.insertArgs(caller+1, ctx, v, filter, nil)
+	sql, err = c.insertValues(table, fields, param, v, filter)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate insert returning sql by struct:%v,filter:%v,scan:%v, result is fail:%v", reflect.TypeOf(v), filter, scan, err)
+		}
+		return
+	}
+	_, scanFields := c.queryField(caller+1, ctx, v, scan)
+	scanArgs = c.ScanArgs(v, scan)
+	if len(scanFields) > 0 {
+		sql += " returning " + strings.Join(scanFields, ",")
+	}
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD generate insert returning sql by struct:%v,filter:%v,scan:%v, result is sql:%v", reflect.TypeOf(v), filter, scan, sql)
 	}
 	return
 }
@@ -697,8 +1242,18 @@ func (c *CRUD) InsertFilter(queryer interface{}, ctx context.Context, v interfac
 }
 
 func (c *CRUD) insertFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, join, scan string) (insertId int64, err error) {
-	table, fields, param, args := c.insertArgs(caller+1, v, filter, nil)
-	sql := fmt.Sprintf(`insert into %v(%v) values(%v)`, table, strings.Join(fields, ","), strings.Join(param, ","))
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	table, fields, param, args := c.insertArgs(caller+1, ctx, v, filter, nil)
+	sql, err := c.insertValues(table, fields, param, v, filter)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD insert filter by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
 	if len(scan) < 1 {
 		if len(join) > 0 {
 			sql += " " + join
@@ -706,16 +1261,210 @@ func (c *CRUD) insertFilter(caller int, queryer interface{}, ctx context.Context
 		insertId, _, err = c.queryerExec(queryer, ctx, sql, args)
 		if err != nil {
 			if c.Verbose {
-				c.Log(caller, "CRUD insert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
+				c.logf(ctx, caller, "CRUD insert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
 			}
 		} else {
 			if c.Verbose {
-				c.Log(caller, "CRUD insert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
+				c.logf(ctx, caller, "CRUD insert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
 			}
 		}
 		return
 	}
-	_, scanFields := c.queryField(caller+1, v, scan)
+	_, scanFields := c.queryField(caller+1, ctx, v, scan)
+	scanArgs := c.ScanArgs(v, scan)
+	if c.LastInsertID {
+		insertId, err = c.insertLastID(queryer, ctx, sql, args, join, scanArgs)
+	} else {
+		if len(join) > 0 {
+			sql += " " + join
+		}
+		sql += " " + strings.Join(scanFields, ",")
+		err = c.queryerQueryRow(queryer, ctx, sql, args).Scan(scanArgs...)
+	}
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD insert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
+		}
+		return
+	}
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD insert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
+	}
+	return
+}
+
+func BulkInsertFilter(queryer interface{}, ctx context.Context, slice interface{}, filter string) (affected int64, err error) {
+	affected, err = Default.bulkInsertFilter(1, queryer, ctx, slice, filter)
+	return
+}
+
+// BulkInsertFilter will insert struct slice by multi values insert sql, it is split to multi statements when params count is over MaxParams.
+// All items must having same fields by filter, the queryer should be tx if all statements must be success or fail together
+func (c *CRUD) BulkInsertFilter(queryer interface{}, ctx context.Context, slice interface{}, filter string) (affected int64, err error) {
+	affected, err = c.bulkInsertFilter(1, queryer, ctx, slice, filter)
+	return
+}
+
+func (c *CRUD) bulkInsertFilter(caller int, queryer interface{}, ctx context.Context, slice interface{}, filter string) (affected int64, err error) {
+	c = c.withContext(ctx)
+	sliceValue := reflect.Indirect(reflect.ValueOf(slice))
+	if sliceValue.Kind() != reflect.Slice {
+		err = fmt.Errorf("slice %v is not supported", reflect.TypeOf(slice))
+		return
+	}
+	var table, sql string
+	var columns, fields, param, values []string
+	var args []interface{}
+	exec := func() (xerr error) {
+		if len(values) < 1 {
+			return
+		}
+		sql = fmt.Sprintf(`insert into %v(%v) values%v`, table, strings.Join(columns, ","), strings.Join(values, ","))
+		_, n, xerr := c.queryerExec(queryer, ctx, sql, args)
+		if xerr == nil {
+			affected += n
+		}
+		values, args = nil, nil
+		return
+	}
+	n := sliceValue.Len()
+	for i := 0; i < n; i++ {
+		item := sliceValue.Index(i).Interface()
+		if err = c.checkFilterOnce(item, filter); err != nil {
+			break
+		}
+		prev := len(args)
+		table, fields, param, args = c.insertArgs(caller+1, ctx, item, filter, args)
+		if i == 0 {
+			columns = fields
+		}
+		if len(fields) < 1 {
+			err = c.errNoFields(item, filter)
+			break
+		}
+		if strings.Join(fields, ",") != strings.Join(columns, ",") {
+			err = fmt.Errorf("slice[%v] fields %v is not equal to %v", i, fields, columns)
+			break
+		}
+		if c.MaxParams > 0 && len(fields) > c.MaxParams {
+			err = fmt.Errorf("%w, the slice[%v] params count %v is over MaxParams %v", ErrTooManyParams, i, len(fields), c.MaxParams)
+			break
+		}
+		if c.MaxParams > 0 && len(args) > c.MaxParams {
+			args = args[:prev]
+			if err = exec(); err != nil {
+				break
+			}
+			_, _, param, args = c.insertArgs(caller+1, ctx, item, filter, nil)
+		}
+		values = append(values, "("+strings.Join(param, ",")+")")
+	}
+	if err == nil {
+		err = exec()
+	}
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD bulk insert filter by slice:%v,sql:%v, result is fail:%v", reflect.TypeOf(slice), sql, err)
+		}
+		return
+	}
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD bulk insert filter by slice:%v,sql:%v, result is success with %v affected", reflect.TypeOf(slice), sql, affected)
+	}
+	return
+}
+
+// insertValues will return insert sql by fields and param, it return ErrNoFields when fields is empty and InsertDefault is not enabled
+func (c *CRUD) insertValues(table string, fields, param []string, v interface{}, filter string) (sql string, err error) {
+	if len(fields) > 0 {
+		sql = fmt.Sprintf(`insert into %v(%v) values(%v)`, table, strings.Join(fields, ","), strings.Join(param, ","))
+	} else if c.InsertDefault {
+		sql = fmt.Sprintf(`insert into %v default values`, table)
+	} else {
+		err = c.errNoFields(v, filter)
+	}
+	return
+}
+
+// insertLastID will exec insert sql and set the LastInsertId to the single scan arg, the returning join is skipped
+func (c *CRUD) insertLastID(queryer interface{}, ctx context.Context, sql string, args []interface{}, join string, scanArgs []interface{}) (insertId int64, err error) {
+	if len(scanArgs) != 1 {
+		err = fmt.Errorf("last insert id only support scan one field, but %v", len(scanArgs))
+		return
+	}
+	if join = strings.TrimSpace(join); len(join) > 0 && !strings.EqualFold(join, "returning") {
+		sql += " " + join
+	}
+	insertId, _, err = c.queryerExec(queryer, ctx, sql, args)
+	if err != nil {
+		return
+	}
+	target := reflect.Indirect(reflect.ValueOf(scanArgs[0]))
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	idValue := reflect.ValueOf(insertId)
+	if target.Kind() == reflect.String || !idValue.CanConvert(target.Type()) {
+		err = fmt.Errorf("last insert id can't be set to %v", target.Type())
+		return
+	}
+	target.Set(idValue.Convert(target.Type()))
+	return
+}
+
+func UpsertFilter(queryer interface{}, ctx context.Context, v interface{}, filter, conflict, update, join, scan string) (insertId int64, err error) {
+	insertId, err = Default.upsertFilter(1, queryer, ctx, v, filter, conflict, update, join, scan)
+	return
+}
+
+func (c *CRUD) UpsertFilter(queryer interface{}, ctx context.Context, v interface{}, filter, conflict, update, join, scan string) (insertId int64, err error) {
+	insertId, err = c.upsertFilter(1, queryer, ctx, v, filter, conflict, update, join, scan)
+	return
+}
+
+func (c *CRUD) upsertFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, conflict, update, join, scan string) (insertId int64, err error) {
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	if err = c.checkFilterOnce(v, update); err != nil {
+		return
+	}
+	table, fields, param, args := c.insertArgs(caller+1, ctx, v, filter, nil)
+	_, sets, args := c.updateArgs(caller+1, ctx, v, update, args)
+	sql, err := c.insertValues(table, fields, param, v, filter)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD upsert filter by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	sql += fmt.Sprintf(` on conflict(%v)`, conflict)
+	if len(sets) > 0 {
+		sql += " do update set " + strings.Join(sets, ",")
+	} else {
+		sql += " do nothing"
+	}
+	if len(scan) < 1 {
+		if len(join) > 0 {
+			sql += " " + join
+		}
+		insertId, _, err = c.queryerExec(queryer, ctx, sql, args)
+		if err != nil {
+			if c.Verbose {
+				c.logf(ctx, caller, "CRUD upsert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
+			}
+		} else {
+			if c.Verbose {
+				c.logf(ctx, caller, "CRUD upsert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
+			}
+		}
+		return
+	}
+	_, scanFields := c.queryField(caller+1, ctx, v, scan)
 	scanArgs := c.ScanArgs(v, scan)
 	if len(join) > 0 {
 		sql += " " + join
@@ -724,53 +1473,103 @@ func (c *CRUD) insertFilter(caller int, queryer interface{}, ctx context.Context
 	err = c.queryerQueryRow(queryer, ctx, sql, args).Scan(scanArgs...)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD insert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
+			c.logf(ctx, caller, "CRUD upsert filter by struct:%v,sql:%v, result is fail:%v", reflect.TypeOf(v), sql, err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD insert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
+		c.logf(ctx, caller, "CRUD upsert filter by struct:%v,sql:%v, result is success", reflect.TypeOf(v), sql)
 	}
 	return
 }
 
 func UpdateArgs(v interface{}, filter string, args []interface{}) (table string, sets []string, args_ []interface{}) {
-	table, sets, args_ = Default.updateArgs(1, v, filter, args)
+	table, sets, args_ = Default.updateArgs(1, context.Background(), v, filter, args)
 	return
 }
 
 func (c *CRUD) UpdateArgs(v interface{}, filter string, args []interface{}) (table string, sets []string, args_ []interface{}) {
-	table, sets, args_ = c.updateArgs(1, v, filter, args)
+	table, sets, args_ = c.updateArgs(1, context.Background(), v, filter, args)
 	return
 }
 
-func (c *CRUD) updateArgs(caller int, v interface{}, filter string, args []interface{}) (table string, sets []string, args_ []interface{}) {
+func (c *CRUD) updateArgs(caller int, ctx context.Context, v interface{}, filter string, args []interface{}) (table string, sets []string, args_ []interface{}) {
 	args_ = args
 	table = c.FilterFieldCall("update", v, filter, func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {
-		args_ = append(args_, c.ParmConv("update", fieldName, fieldFunc, field, value))
-		sets = append(sets, fmt.Sprintf("%v="+c.ArgFormat, fieldName, len(args_)))
+		if len(field.Tag.Get("jsonpath")) > 0 { //json path field is only for query
+			return
+		}
+		arg := c.ParmConv("update", fieldName, fieldFunc, field, value)
+		if arg == SkipField {
+			return
+		}
+		args_ = append(args_, arg)
+		sets = append(sets, c.QuoteIdent(fieldName)+"="+c.Sprintf(c.ArgFormat, len(args_)))
 	})
 	if c.Verbose {
-		c.Log(caller, "CRUD generate update args by struct:%v,filter:%v, result is sets:%v,args:%v", reflect.TypeOf(v), filter, sets, jsonString(args_))
+		c.logf(ctx, caller, "CRUD generate update args by struct:%v,filter:%v, result is sets:%v,args:%v", reflect.TypeOf(v), filter, sets, jsonString(args_))
+	}
+	return
+}
+
+func UpdateSQL(v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}, err error) {
+	sql, args_, err = Default.updateSQL(1, context.Background(), v, filter, args, suffix...)
+	return
+}
+
+// UpdateSQL will return the update sql with suffix and args of v, it return ErrNoFields when no fields is matched
+func (c *CRUD) UpdateSQL(v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}, err error) {
+	sql, args_, err = c.updateSQL(1, context.Background(), v, filter, args, suffix...)
+	return
+}
+
+func (c *CRUD) updateSQL(caller int, ctx context.Context, v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}, err error) {
+	table, sets, args_ := c.updateArgs(caller+1, ctx, v, filter, args)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate update sql by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	sql = fmt.Sprintf(`update %v set %v %v`, table, strings.Join(sets, ","), strings.Join(suffix, " "))
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD generate update sql by struct:%v,filter:%v, result is sql:%v,args:%v", reflect.TypeOf(v), filter, sql, jsonString(args_))
 	}
 	return
 }
 
-func UpdateSQL(v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}) {
-	sql, args_ = Default.updateSQL(1, v, filter, args, suffix...)
+func UpdateSQLReturning(v interface{}, filter string, where []string, sep string, args []interface{}, scan string) (sql string, args_, scanArgs []interface{}, err error) {
+	sql, args_, scanArgs, err = Default.updateSQLReturning(1, context.Background(), v, filter, where, sep, args, scan)
 	return
 }
 
-func (c *CRUD) UpdateSQL(v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}) {
-	sql, args_ = c.updateSQL(1, v, filter, args, suffix...)
+// UpdateSQLReturning will return the update sql with where and returning scan fields, args and the scan args of v to run by custom queryer, the returning is omitted when scan fields is empty,
+// it return ErrNoFields when no fields is matched
+func (c *CRUD) UpdateSQLReturning(v interface{}, filter string, where []string, sep string, args []interface{}, scan string) (sql string, args_, scanArgs []interface{}, err error) {
+	sql, args_, scanArgs, err = c.updateSQLReturning(1, context.Background(), v, filter, where, sep, args, scan)
 	return
 }
 
-func (c *CRUD) updateSQL(caller int, v interface{}, filter string, args []interface{}, suffix ...string) (sql string, args_ []interface{}) {
-	table, sets, args_ := c.updateArgs(caller+1, v, filter, args)
-	sql = fmt.Sprintf(`update %v set %v %v`, table, strings.Join(sets, ","), strings.Join(suffix, " "))
+func (c *CRUD) updateSQLReturning(caller int, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, scan string) (sql string, args_, scanArgs []interface{}, err error) {
+	table, sets, args_ := c.updateArgs(caller+1, ctx, v, filter, args)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate update returning sql by struct:%v,filter:%v,scan:%v, result is fail:%v", reflect.TypeOf(v), filter, scan, err)
+		}
+		return
+	}
+	_, scanFields := c.queryField(caller+1, ctx, v, scan)
+	scanArgs = c.ScanArgs(v, scan)
+	sql = fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
+	suffix := []string{}
+	if len(scanFields) > 0 {
+		suffix = append(suffix, "returning", strings.Join(scanFields, ","))
+	}
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep, suffix...)
 	if c.Verbose {
-		c.Log(caller, "CRUD generate update sql by struct:%v,filter:%v, result is sql:%v,args:%v", reflect.TypeOf(v), filter, sql, jsonString(args_))
+		c.logf(ctx, caller, "CRUD generate update returning sql by struct:%v,filter:%v,scan:%v, result is sql:%v,args:%v", reflect.TypeOf(v), filter, scan, sql, jsonString(args_))
 	}
 	return
 }
@@ -786,16 +1585,17 @@ func (c *CRUD) Update(queryer interface{}, ctx context.Context, v interface{}, s
 }
 
 func (c *CRUD) update(caller int, queryer interface{}, ctx context.Context, v interface{}, sql string, where []string, sep string, args []interface{}) (affected int64, err error) {
-	sql = c.joinWhere(caller+1, sql, where, sep)
+	c = c.withContext(ctx)
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep)
 	_, affected, err = c.queryerExec(queryer, ctx, sql, args)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD update by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD update by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD update by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
+		c.logf(ctx, caller, "CRUD update by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
 	}
 	return
 }
@@ -811,6 +1611,7 @@ func (c *CRUD) UpdateRow(queryer interface{}, ctx context.Context, v interface{}
 }
 
 func (c *CRUD) updateRow(caller int, queryer interface{}, ctx context.Context, v interface{}, sql string, where []string, sep string, args []interface{}) (err error) {
+	c = c.withContext(ctx)
 	affected, err := c.update(caller+1, queryer, ctx, v, sql, where, sep, args)
 	if err == nil && affected < 1 {
 		err = c.getErrNoRows()
@@ -829,18 +1630,26 @@ func (c *CRUD) UpdateSet(queryer interface{}, ctx context.Context, v interface{}
 }
 
 func (c *CRUD) updateSet(caller int, queryer interface{}, ctx context.Context, v interface{}, sets, where []string, sep string, args []interface{}) (affected int64, err error) {
+	c = c.withContext(ctx)
+	if len(sets) < 1 {
+		err = fmt.Errorf("%w to set for struct %v", ErrNoFields, reflect.TypeOf(v))
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD update by struct:%v, result is fail:%v", reflect.TypeOf(v), err)
+		}
+		return
+	}
 	table := c.Table(v)
 	sql := fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
-	sql = c.joinWhere(caller+1, sql, where, sep)
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep)
 	_, affected, err = c.queryerExec(queryer, ctx, sql, args)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD update by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD update by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD update by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
+		c.logf(ctx, caller, "CRUD update by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
 	}
 	return
 }
@@ -856,6 +1665,7 @@ func (c *CRUD) UpdateRowSet(queryer interface{}, ctx context.Context, v interfac
 }
 
 func (c *CRUD) updateRowSet(caller int, queryer interface{}, ctx context.Context, v interface{}, sets, where []string, sep string, args []interface{}) (err error) {
+	c = c.withContext(ctx)
 	affected, err := c.updateSet(caller+1, queryer, ctx, v, sets, where, sep, args)
 	if err == nil && affected < 1 {
 		err = c.getErrNoRows()
@@ -874,17 +1684,29 @@ func (c *CRUD) UpdateFilter(queryer interface{}, ctx context.Context, v interfac
 }
 
 func (c *CRUD) updateFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}) (affected int64, err error) {
-	sql, args := c.updateSQL(caller+1, v, filter, args)
-	sql = c.joinWhere(caller+1, sql, where, sep)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	table, sets, args := c.updateArgs(caller+1, ctx, v, filter, args)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD update filter by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	sql := fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep)
 	_, affected, err = c.queryerExec(queryer, ctx, sql, args)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD update filter by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD update filter by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD update filter by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
+		c.logf(ctx, caller, "CRUD update filter by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(args), affected)
 	}
 	return
 }
@@ -900,6 +1722,7 @@ func (c *CRUD) UpdateRowFilter(queryer interface{}, ctx context.Context, v inter
 }
 
 func (c *CRUD) updateRowFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}) (err error) {
+	c = c.withContext(ctx)
 	affected, err := c.updateFilter(caller+1, queryer, ctx, v, filter, where, sep, args)
 	if err == nil && affected < 1 {
 		err = c.getErrNoRows()
@@ -918,17 +1741,29 @@ func (c *CRUD) UpdateWheref(queryer interface{}, ctx context.Context, v interfac
 }
 
 func (c *CRUD) updateWheref(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, formats string, args ...interface{}) (affected int64, err error) {
-	sql, sqlArgs := c.updateSQL(caller+1, v, filter, nil)
-	sql, sqlArgs = c.joinWheref(caller+1, sql, sqlArgs, formats, args...)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	table, sets, sqlArgs := c.updateArgs(caller+1, ctx, v, filter, nil)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD update wheref by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	sql := fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
+	sql, sqlArgs = c.joinWheref(caller+1, ctx, sql, sqlArgs, formats, args...)
 	_, affected, err = c.queryerExec(queryer, ctx, sql, sqlArgs)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD update wheref by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(sqlArgs), err)
+			c.logf(ctx, caller, "CRUD update wheref by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(sqlArgs), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD update wheref by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(sqlArgs), affected)
+		c.logf(ctx, caller, "CRUD update wheref by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(sqlArgs), affected)
 	}
 	return
 }
@@ -944,6 +1779,7 @@ func (c *CRUD) UpdateRowWheref(queryer interface{}, ctx context.Context, v inter
 }
 
 func (c *CRUD) updateRowWheref(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, formats string, args ...interface{}) (err error) {
+	c = c.withContext(ctx)
 	affected, err := c.updateWheref(caller+1, queryer, ctx, v, filter, formats, args...)
 	if err == nil && affected < 1 {
 		err = c.getErrNoRows()
@@ -951,43 +1787,106 @@ func (c *CRUD) updateRowWheref(caller int, queryer interface{}, ctx context.Cont
 	return
 }
 
+func UpdateWhereIn(queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, field string, values interface{}) (affected int64, err error) {
+	affected, err = Default.updateWhereIn(1, queryer, ctx, v, filter, where, sep, args, field, values)
+	return
+}
+
+// UpdateWhereIn will update struct by filter on rows matched by where and field in values, the values is split to multi statements
+// when params count is over MaxParams and the affected is aggregated, use transaction queryer if all statements must be applied together
+func (c *CRUD) UpdateWhereIn(queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, field string, values interface{}) (affected int64, err error) {
+	affected, err = c.updateWhereIn(1, queryer, ctx, v, filter, where, sep, args, field, values)
+	return
+}
+
+func (c *CRUD) updateWhereIn(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, field string, values interface{}) (affected int64, err error) {
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	valuesValue := reflect.Indirect(reflect.ValueOf(values))
+	if valuesValue.Kind() != reflect.Slice && valuesValue.Kind() != reflect.Array {
+		err = fmt.Errorf("values must be slice, but %v", reflect.TypeOf(values))
+		return
+	}
+	table, sets, sqlArgs := c.updateArgs(caller+1, ctx, v, filter, args)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD update where in by struct:%v,filter:%v, result is fail:%v", reflect.TypeOf(v), filter, err)
+		}
+		return
+	}
+	n := valuesValue.Len()
+	size := n
+	if c.MaxParams > 0 && c.MaxParams-len(sqlArgs) < size {
+		size = c.MaxParams - len(sqlArgs) //the values is appended after set and where args
+	}
+	if n > 0 && size < 1 {
+		err = fmt.Errorf("%w, the update params count %v is not having room for values by MaxParams %v", ErrTooManyParams, len(sqlArgs), c.MaxParams)
+		return
+	}
+	updateSQL := fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
+	for start := 0; start < n; start += size {
+		chunk := []interface{}{}
+		for i := start; i < n && i < start+size; i++ {
+			chunk = append(chunk, valuesValue.Index(i).Interface())
+		}
+		chunkWhere, chunkArgs := c.AppendWhereIn(append([]string{}, where...), append([]interface{}{}, sqlArgs...), field, chunk)
+		sql := c.joinWhere(caller+1, ctx, updateSQL, chunkWhere, sep)
+		var chunkAffected int64
+		_, chunkAffected, err = c.queryerExec(queryer, ctx, sql, chunkArgs)
+		if err != nil {
+			if c.Verbose {
+				c.logf(ctx, caller, "CRUD update where in by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(chunkArgs), err)
+			}
+			return
+		}
+		affected += chunkAffected
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD update where in by struct:%v,sql:%v,args:%v, result is success affected:%v", reflect.TypeOf(v), sql, jsonString(chunkArgs), chunkAffected)
+		}
+	}
+	return
+}
+
 func QueryField(v interface{}, filter string) (table string, fields []string) {
-	table, fields = Default.queryField(1, v, filter)
+	table, fields = Default.queryField(1, context.Background(), v, filter)
 	return
 }
 
 func (c *CRUD) QueryField(v interface{}, filter string) (table string, fields []string) {
-	table, fields = c.queryField(1, v, filter)
+	table, fields = c.queryField(1, context.Background(), v, filter)
 	return
 }
 
-func (c *CRUD) queryField(caller int, v interface{}, filter string) (table string, fields []string) {
+func (c *CRUD) queryField(caller int, ctx context.Context, v interface{}, filter string) (table string, fields []string) {
 	table = c.FilterFieldCall("query", v, filter, func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {
 		conv := field.Tag.Get("conv")
 		if len(fieldFunc) > 0 {
-			fields = append(fields, fmt.Sprintf("%v(%v%v)", fieldFunc, fieldName, conv))
+			fields = append(fields, fmt.Sprintf("%v(%v%v)", fieldFunc, c.fieldIdent(fieldName, field), conv))
 		} else {
-			fields = append(fields, fmt.Sprintf("%v%v", fieldName, conv))
+			fields = append(fields, fmt.Sprintf("%v%v", c.fieldIdent(fieldName, field), conv))
 		}
 	})
 	if c.Verbose {
-		c.Log(caller, "CRUD generate query field by struct:%v,filter:%v, result is fields:%v", reflect.TypeOf(v), filter, fields)
+		c.logf(ctx, caller, "CRUD generate query field by struct:%v,filter:%v, result is fields:%v", reflect.TypeOf(v), filter, fields)
 	}
 	return
 }
 
 func QuerySQL(v interface{}, filter string, suffix ...string) (sql string) {
-	sql = Default.querySQL(1, v, "", filter, suffix...)
+	sql = Default.querySQL(1, context.Background(), v, "", filter, suffix...)
 	return
 }
 
 func (c *CRUD) QuerySQL(v interface{}, filter string, suffix ...string) (sql string) {
-	sql = c.querySQL(1, v, "", filter, suffix...)
+	sql = c.querySQL(1, context.Background(), v, "", filter, suffix...)
 	return
 }
 
-func (c *CRUD) querySQL(caller int, v interface{}, from, filter string, suffix ...string) (sql string) {
-	table, fields := c.queryField(caller+1, v, filter)
+func (c *CRUD) querySQL(caller int, ctx context.Context, v interface{}, from, filter string, suffix ...string) (sql string) {
+	table, fields := c.queryField(caller+1, ctx, v, filter)
 	if len(from) > 0 {
 		table = from
 	}
@@ -996,60 +1895,87 @@ func (c *CRUD) querySQL(caller int, v interface{}, from, filter string, suffix .
 		sql += " " + strings.Join(suffix, " ")
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD generate query sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
+		c.logf(ctx, caller, "CRUD generate query sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
 	}
 	return
 }
 
-func QueryUnifySQL(v interface{}, field string) (sql string, args []interface{}) {
-	sql, args = Default.queryUnifySQL(1, v, field)
+func QueryUnifySQL(v interface{}, field string) (sql string, args []interface{}, err error) {
+	sql, args, err = Default.queryUnifySQL(1, context.Background(), v, field)
 	return
 }
 
-func (c *CRUD) QueryUnifySQL(v interface{}, field string) (sql string, args []interface{}) {
-	sql, args = c.queryUnifySQL(1, v, field)
+func (c *CRUD) QueryUnifySQL(v interface{}, field string) (sql string, args []interface{}, err error) {
+	sql, args, err = c.queryUnifySQL(1, context.Background(), v, field)
 	return
 }
 
-func (c *CRUD) queryUnifySQL(caller int, v interface{}, field string) (sql string, args []interface{}) {
+func (c *CRUD) queryUnifySQL(caller int, ctx context.Context, v interface{}, field string) (sql string, args []interface{}, err error) {
+	plan := c.planUnify(v)
+	target := plan.Target(field)
+	if target == nil {
+		panic(newFilterError(v, "", field, "unify target is not exists"))
+	}
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
-	modelValue := reflectValue.FieldByName("Model")
-	modelType, _ := reflectType.FieldByName("Model")
-	modelFrom := modelType.Tag.Get("from")
-	queryType, _ := reflectType.FieldByName(field)
-	queryValue := reflectValue.FieldByName(field)
-	if queryFrom := queryType.Tag.Get("from"); len(queryFrom) > 0 {
-		modelFrom = queryFrom
-	}
-	queryFilter := queryType.Tag.Get("filter")
-	querySelect := queryType.Tag.Get("select")
-	queryGroup := queryType.Tag.Get("group")
-	queryNum := queryType.Type.NumField()
-	for i := 0; i < queryNum; i++ {
-		fieldValue := queryValue.Field(i)
-		fieldType := queryType.Type.Field(i)
-		if value, ok := fieldValue.Interface().(FilterValue); ok && fieldType.Name == "Filter" && len(value) > 0 {
-			queryFilter = string(value)
-			continue
-		}
-		if getter, ok := fieldValue.Interface().(FilterGetter); ok && getter != nil {
-			queryFilter = getter.GetFilter(v, fieldValue, fieldValue)
-			continue
+	modelValue := reflectValue.FieldByIndex(plan.ModelIndex)
+	targetValue := reflectValue.FieldByIndex(target.Index)
+	modelFrom := plan.ModelFrom
+	if len(target.From) > 0 {
+		modelFrom = target.From
+	}
+	queryFilter := target.filter(v, targetValue)
+	modelFrom, args, err = c.unifyFrom(v, modelFrom, target, targetValue)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate query unify sql by struct:%v,target:%v, result is fail:%v", reflect.TypeOf(v), field, err)
 		}
+		return
 	}
-	if len(querySelect) > 0 {
-		sql = querySelect
-		if strings.Contains(querySelect, "%v") {
-			_, fields := c.queryField(caller+1, modelValue.Addr().Interface(), queryFilter)
-			sql = fmt.Sprintf(querySelect, strings.Join(fields, ","))
+	if len(target.Select) > 0 {
+		sql = target.Select
+		if strings.Contains(target.Select, "%v") {
+			_, fields := c.queryField(caller+1, ctx, modelValue.Addr().Interface(), queryFilter)
+			sql = fmt.Sprintf(target.Select, strings.Join(fields, ","))
 		}
 	} else {
-		sql = c.querySQL(caller+1, modelValue.Addr().Interface(), modelFrom, queryFilter)
+		sql = c.querySQL(caller+1, ctx, modelValue.Addr().Interface(), modelFrom, queryFilter)
+	}
+	sql, args = c.joinWhereUnify(caller+1, ctx, sql, args, v)
+	sql += " " + target.Group
+	sql = c.joinPageUnify(caller+1, ctx, sql, v)
+	return
+}
+
+var fromVerbRegexp = regexp.MustCompile(`%[-+# 0-9.]*[vsdqxXT]`)
+
+// unifyFrom will number the placeholders in from tag by FromArgs field of target section, the from args is placed before where args.
+// It will return error if from is having format verb or placeholders count is not equal to FromArgs
+func (c *CRUD) unifyFrom(v interface{}, from string, target *UnifyTarget, targetValue reflect.Value) (from_ string, args []interface{}, err error) {
+	from_ = from
+	if target != nil && target.FromArgsIndex != nil {
+		fieldValue := targetValue.FieldByIndex(target.FromArgsIndex)
+		if value, ok := fieldValue.Interface().([]interface{}); ok {
+			args = value
+		} else if getter, ok := fieldValue.Interface().(FromArgsGetter); ok && getter != nil {
+			args = getter.GetFromArgs(v, fieldValue, fieldValue)
+		}
+	}
+	if len(from) < 1 && len(args) < 1 {
+		return
+	}
+	if verb := fromVerbRegexp.FindString(strings.ReplaceAll(from, c.ArgFormat, "")); len(verb) > 0 {
+		err = fmt.Errorf("from %v is having format verb %v, use %v placeholder with FromArgs instead", from, verb, c.ArgFormat)
+		return
+	}
+	if n := strings.Count(from, c.ArgFormat); n != len(args) {
+		err = fmt.Errorf("from %v placeholders=%v is not equal to FromArgs=%v", from, n, len(args))
+		return
+	}
+	if strings.Contains(c.ArgFormat, "%") {
+		for i := range args {
+			from_ = strings.Replace(from_, c.ArgFormat, fmt.Sprintf(c.ArgFormat, i+1), 1)
+		}
 	}
-	sql, args = c.joinWhereUnify(caller+1, sql, nil, v)
-	sql += " " + queryGroup
-	sql = c.joinPageUnify(caller+1, sql, v)
 	return
 }
 
@@ -1058,9 +1984,18 @@ func ScanArgs(v interface{}, filter string) (args []interface{}) {
 	return
 }
 
+// ScanArgs will return the scan args of v by filter, the field with scan:"-" tag or SkipField by ParmConv is scanned to throwaway dest
 func (c *CRUD) ScanArgs(v interface{}, filter string) (args []interface{}) {
 	c.FilterFieldCall("scan", v, filter, func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {
-		args = append(args, c.ParmConv("scan", fieldName, fieldFunc, field, value))
+		if field.Tag.Get("scan") == "-" {
+			args = append(args, new(interface{}))
+			return
+		}
+		arg := c.ParmConv("scan", fieldName, fieldFunc, field, value)
+		if arg == SkipField {
+			arg = new(interface{})
+		}
+		args = append(args, arg)
 	})
 	return
 }
@@ -1071,120 +2006,116 @@ func ScanUnifyDest(v interface{}, queryName string) (modelValue interface{}, que
 }
 
 func (c *CRUD) ScanUnifyDest(v interface{}, queryName string) (modelValue interface{}, queryFilter string, dests []interface{}) {
+	plan := c.planUnify(v)
+	target := plan.Target(queryName)
+	if target == nil {
+		panic(newFilterError(v, "", queryName, "unify target is not exists"))
+	}
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
-	modelValue = reflectValue.FieldByName("Model").Addr().Interface()
-	queryType, _ := reflectType.FieldByName(queryName)
-	queryValue := reflectValue.FieldByName(queryName)
-	if !queryValue.IsValid() {
-		panic(fmt.Sprintf("%v is not exits in %v", queryName, reflectType))
-	}
-	queryFilter = queryType.Tag.Get("filter")
-	queryNum := queryType.Type.NumField()
-	for i := 0; i < queryNum; i++ {
-		fieldValue := queryValue.Field(i)
-		fieldType := queryType.Type.Field(i)
-		if value, ok := fieldValue.Interface().(FilterValue); ok && fieldType.Name == "Filter" {
-			if len(value) > 0 {
-				queryFilter = string(value)
-			}
-			continue
-		}
-		if getter, ok := fieldValue.Interface().(FilterGetter); ok {
-			if getter != nil {
-				queryFilter = getter.GetFilter(v, fieldValue, fieldValue)
-			}
-			continue
-		}
-		scan := fieldType.Tag.Get("scan")
-		if scan == "-" {
-			continue
-		}
-		dests = append(dests, queryValue.Field(i).Addr().Interface())
-		if len(scan) > 0 {
-			dests = append(dests, scan)
+	modelValue = reflectValue.FieldByIndex(plan.ModelIndex).Addr().Interface()
+	targetValue := reflectValue.FieldByIndex(target.Index)
+	queryFilter = target.filter(v, targetValue)
+	for _, dest := range target.Dests {
+		dests = append(dests, targetValue.FieldByIndex(dest.Index).Addr().Interface())
+		if len(dest.Scan) > 0 {
+			dests = append(dests, dest.Scan)
 		}
 	}
 	return
 }
 
-func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{}) (err error) {
+const (
+	destUnwritten int8 = 1
+	destWritten   int8 = 2
+)
+
+// destSet will set value to dests by pattern, the written is used to track the dest state by index when it is not nil,
+// the index of pattern string is kept zero, destUnwritten is the dest never setted, destWritten is the dest setted
+func (c *CRUD) destSet(value reflect.Value, filter string, written []int8, dests ...interface{}) (err error) {
 	if len(dests) < 1 {
-		err = fmt.Errorf("scan dest is empty")
+		err = &DestError{Index: -1, Hint: "is empty"}
 		return
 	}
-	valueField := func(key string) (v reflect.Value, e error) {
-		if _, ok := value.Interface().([]interface{}); ok {
+	valueField := func(index int, pattern, key string) (v reflect.Value, e error) {
+		if meta, ok := value.Interface().([]interface{}); ok {
 			parts := strings.SplitN(filter, ".", 2)
 			if len(parts) > 1 {
 				filter = parts[1]
 			}
 			filterFields := strings.Split(strings.TrimSpace(strings.SplitN(filter, "#", 2)[0]), ",")
-			indexField := -1
-			for i, filterField := range filterFields {
+			for i, f := range meta {
+				filterField, fieldValue, _ := metaField(filterFields, i, f)
 				fieldParts := strings.SplitN(strings.Trim(filterField, ")"), "(", 2)
 				fieldName := fieldParts[0]
 				if len(fieldParts) > 1 {
 					fieldName = fieldParts[1]
 				}
-				if fieldName == key {
-					indexField = i
-					break
+				if fieldName == key || jsonPathKey(fieldName) == key {
+					v = reflect.Indirect(reflect.ValueOf(fieldValue))
+					return
 				}
 			}
-			if indexField < 0 {
-				e = fmt.Errorf("field %v is not exists", key)
-				return
-			}
-			v = reflect.Indirect(value.Index(indexField).Elem())
+			e = &DestError{Index: index, Pattern: pattern, Hint: fmt.Sprintf("field %v is not exists on meta", key)}
 			return
 		}
 		targetValue := reflect.Indirect(value)
 		targetType := targetValue.Type()
 		if targetType.Kind() != reflect.Struct {
-			e = fmt.Errorf("field %v is not struct", key)
+			e = &DestError{Index: index, Pattern: pattern, Hint: fmt.Sprintf("field %v is not exists on not struct %v", key, targetType)}
 			return
 		}
 		k := targetValue.NumField()
 		for i := 0; i < k; i++ {
 			field := targetType.Field(i)
 			fieldName := strings.SplitN(field.Tag.Get(c.Tag), ",", 2)[0]
-			if fieldName == key {
+			if fieldName == key || (field.Tag.Get("scan") == key && key != "-") {
 				v = targetValue.Field(i)
 				return
 			}
 		}
-		e = fmt.Errorf("field %v is not exists", key)
+		e = &DestError{Index: index, Pattern: pattern, Hint: fmt.Sprintf("field %v is not exists on %v", key, targetType)}
 		return
 	}
-	scanMap := func(i int, mapType reflect.Type, scan string, skipNil, skipZero bool) (v reflect.Value, e error) {
+	scanMap := func(index int, mapType reflect.Type, scan string, skipNil, skipZero bool) (v reflect.Value, e error) {
 		v = reflect.MakeMap(mapType)
 		for _, field := range strings.Split(scan, ",") {
 			parts := strings.SplitN(field, ":", 2)
 			key := reflect.ValueOf(parts[0])
 			var val reflect.Value
 			if len(parts) < 2 {
-				val, e = valueField(parts[0])
+				val, e = valueField(index, scan, parts[0])
 			} else {
-				val, e = valueField(parts[1])
+				val, e = valueField(index, scan, parts[1])
 			}
 			if e != nil {
 				break
 			}
 			if !key.CanConvert(mapType.Key()) {
-				e = fmt.Errorf("not supported on dests[%v].%v key to set %v=>%v", i-1, field, key.Type(), mapType.Key())
+				e = &DestError{Index: index, Pattern: scan, WantType: mapType.Key().String(), GotType: key.Type().String(), Hint: fmt.Sprintf("key %v is not supported", field)}
 				break
 			}
 			if !val.CanConvert(mapType.Elem()) {
-				e = fmt.Errorf("not supported on dests[%v].%v value to set %v=>%v", i-1, field, val.Type(), mapType.Elem())
+				e = &DestError{Index: index, Pattern: scan, WantType: mapType.Elem().String(), GotType: val.Type().String(), Hint: fmt.Sprintf("value %v is not supported", field)}
 				break
 			}
 			v.SetMapIndex(key.Convert(mapType.Key()), val.Convert(mapType.Elem()))
 		}
 		return
 	}
+	var single reflect.Value //the only value of meta, it can be setted to dest without pattern
+	if meta, ok := value.Interface().([]interface{}); ok && len(meta) == 1 {
+		_, fieldValue, _ := metaField(nil, 0, meta[0])
+		single = reflect.Indirect(reflect.ValueOf(fieldValue))
+	}
 	n := len(dests)
 	for i := 0; i < n; i++ {
+		destIndex, destPrev := i, destUnwritten
+		if written != nil {
+			if written[i] == destWritten {
+				destPrev = destWritten
+			}
+			written[i] = destWritten
+		}
 		if scanner, ok := dests[i].(Scanner); ok {
 			scanner.Scan(value.Interface())
 			continue
@@ -1196,6 +2127,20 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 			destValue.Set(value)
 			continue
 		}
+		patterned := false
+		if i+1 < n {
+			_, patterned = dests[i+1].(string)
+		}
+		if single.IsValid() && !patterned {
+			if destType == single.Type() {
+				destValue.Set(single)
+				continue
+			}
+			if destKind == reflect.Slice && destType.Elem() == single.Type() {
+				destValue.Set(reflect.Append(destValue, single))
+				continue
+			}
+		}
 		// if value.Kind() == reflect.Ptr && !value.IsZero() {
 		// 	indirectValue := reflect.Indirect(value)
 		// 	if destType == indirectValue.Type() {
@@ -1208,34 +2153,38 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 			destValue.Call([]reflect.Value{value})
 		case reflect.Map:
 			if i+1 >= len(dests) {
-				err = fmt.Errorf("dest[%v] pattern is not setted", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is not setted"}
 				break
 			}
 			v, ok := dests[i+1].(string)
 			if !ok {
-				err = fmt.Errorf("dest[%v] pattern is not string", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is not string"}
 				break
 			}
 			if len(v) < 1 {
-				err = fmt.Errorf("dest[%v] pattern is empty", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is empty"}
 				break
 			}
 			i++
 			parts := strings.Split(v, "#")
 			kvs := strings.SplitN(parts[0], ":", 2)
-			targetKey, xerr := valueField(kvs[0])
+			targetKey, xerr := valueField(destIndex, v, kvs[0])
 			if xerr != nil {
 				err = xerr
 				break
 			}
 			targetValue := value
 			if len(kvs) > 1 {
-				targetValue, xerr = valueField(kvs[1])
+				targetValue, xerr = valueField(destIndex, v, kvs[1])
 				if xerr != nil {
 					err = xerr
 					break
 				}
 			}
+			if !targetKey.Type().AssignableTo(destType.Key()) {
+				err = &DestError{Index: destIndex, Pattern: v, WantType: destType.Key().String(), GotType: targetKey.Type().String(), Hint: "key is not supported"}
+				break
+			}
 			if destValue.IsNil() {
 				destValue.Set(reflect.MakeMap(destType))
 			}
@@ -1255,16 +2204,16 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 				continue
 			}
 			if i+1 >= len(dests) {
-				err = fmt.Errorf("dest[%v] pattern is not setted", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is not setted"}
 				break
 			}
 			v, ok := dests[i+1].(string)
 			if !ok {
-				err = fmt.Errorf("dest[%v] pattern is not string", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is not string"}
 				break
 			}
 			if len(v) < 1 {
-				err = fmt.Errorf("dest[%v] pattern is empty", i)
+				err = &DestError{Index: destIndex, Hint: "pattern is empty"}
 				break
 			}
 			parts := strings.Split(v, "#")
@@ -1274,7 +2223,7 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 			}
 			i++
 			if destKind == reflect.Slice && destType.Elem().Kind() == reflect.Map {
-				targetValue, xerr := scanMap(i, destType.Elem(), parts[0], skipNil, skipZero)
+				targetValue, xerr := scanMap(destIndex, destType.Elem(), parts[0], skipNil, skipZero)
 				if xerr != nil {
 					err = xerr
 					break
@@ -1282,7 +2231,7 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 				destValue.Set(reflect.Append(destValue, targetValue))
 				continue
 			}
-			targetValue, xerr := valueField(parts[0])
+			targetValue, xerr := valueField(destIndex, v, parts[0])
 			if xerr != nil {
 				err = xerr
 				break
@@ -1290,12 +2239,18 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 			checkValue := targetValue
 			targetKind := targetValue.Kind()
 			if targetKind == reflect.Ptr && checkValue.IsNil() && skipNil {
+				if written != nil {
+					written[destIndex] = destPrev
+				}
 				continue
 			}
 			if targetKind == reflect.Ptr && !checkValue.IsNil() {
 				checkValue = reflect.Indirect(checkValue)
 			}
 			if checkValue.IsZero() && skipZero {
+				if written != nil {
+					written[destIndex] = destPrev
+				}
 				continue
 			}
 			if destKind == reflect.Slice && destType.Elem() == targetValue.Type() {
@@ -1303,7 +2258,7 @@ func (c *CRUD) destSet(value reflect.Value, filter string, dests ...interface{})
 			} else if destType == targetValue.Type() {
 				destValue.Set(targetValue)
 			} else {
-				err = fmt.Errorf("not supported on dests[%v] to set %v=>%v", i-1, targetValue.Type(), destType)
+				err = &DestError{Index: destIndex, Pattern: v, WantType: destType.String(), GotType: targetValue.Type().String(), Hint: "is not supported"}
 			}
 		}
 		if err != nil {
@@ -1321,20 +2276,140 @@ func Scan(rows Rows, v interface{}, filter string, dest ...interface{}) (err err
 func (c *CRUD) Scan(rows Rows, v interface{}, filter string, dest ...interface{}) (err error) {
 	isPtr := reflect.ValueOf(v).Kind() == reflect.Ptr
 	isStruct := reflect.Indirect(reflect.ValueOf(v)).Kind() == reflect.Struct
+	var target, zero reflect.Value
+	var args []interface{}
+	var index [][]int
+	var written []int8
+	if c.StrictDest {
+		written = make([]int8, len(dest))
+	}
+	reusable, first := false, true
 	for rows.Next() {
-		value := NewValue(v)
-		err = rows.Scan(c.ScanArgs(value.Interface(), filter)...)
+		if reusable {
+			target.Elem().Set(zero)
+		} else if first || index == nil {
+			target = NewValue(v)
+			args = c.ScanArgs(target.Interface(), filter)
+		} else {
+			target = NewValue(v)
+			elem := target.Elem()
+			for i, field := range index {
+				args[i] = elem.FieldByIndex(field).Addr().Interface()
+			}
+		}
+		if first && isStruct && !c.ScanAlloc {
+			reusable = c.scanReusable(target.Type(), isPtr, dest...)
+			index = scanIndex(target, args)
+			zero = reflect.Zero(target.Elem().Type())
+		}
+		first = false
+		err = rows.Scan(args...)
 		if err != nil {
 			break
 		}
+		value := target
 		if !isPtr || !isStruct {
 			value = reflect.Indirect(value)
 		}
-		err = c.destSet(value, filter, dest...)
+		err = c.destSet(value, filter, written, dest...)
 		if err != nil {
 			break
 		}
 	}
+	if rowsErr, ok := rows.(RowsErr); ok && err == nil {
+		err = rowsErr.Err()
+	}
+	if err == nil && !first {
+		err = c.checkDestWritten(written, dest...)
+	}
+	return
+}
+
+// checkDestWritten will return error with the dests which is never setted when CRUD.StrictDest is true
+func (c *CRUD) checkDestWritten(written []int8, dests ...interface{}) (err error) {
+	unused := []string{}
+	for i, state := range written {
+		if state != destUnwritten {
+			continue
+		}
+		info := fmt.Sprintf("dests[%v](%v)", i, reflect.TypeOf(dests[i]))
+		if i+1 < len(dests) {
+			if pattern, ok := dests[i+1].(string); ok {
+				info += fmt.Sprintf(" by pattern %v is always nil/zero, try pattern %v#all", pattern, strings.SplitN(pattern, "#", 2)[0])
+			}
+		}
+		unused = append(unused, info)
+	}
+	if len(unused) > 0 {
+		err = fmt.Errorf("%v is never setted", strings.Join(unused, ","))
+	}
+	return
+}
+
+// scanReusable will check whether the scan value can be reused for next row, it is only when all dests is copying the value or the value field
+func (c *CRUD) scanReusable(valueType reflect.Type, isPtr bool, dests ...interface{}) bool {
+	if c.ScanAlloc {
+		return false
+	}
+	n := len(dests)
+	for i := 0; i < n; i++ {
+		if _, ok := dests[i].(Scanner); ok {
+			return false
+		}
+		destValue := reflect.Indirect(reflect.ValueOf(dests[i]))
+		destKind := destValue.Kind()
+		destType := destValue.Type()
+		if destType == valueType || (destKind == reflect.Slice && destType.Elem() == valueType) {
+			return false
+		}
+		if destType == valueType.Elem() || (destKind == reflect.Slice && destType.Elem() == valueType.Elem()) || destKind == reflect.Func {
+			if isPtr {
+				return false
+			}
+			continue
+		}
+		pattern := ""
+		if i+1 < n {
+			pattern, _ = dests[i+1].(string)
+		}
+		i++
+		if destKind == reflect.Map && len(strings.SplitN(strings.Split(pattern, "#")[0], ":", 2)) < 2 && isPtr {
+			return false
+		}
+	}
+	return true
+}
+
+// scanIndex will resolve the struct field index of scan args, it return nil if some args is not pointer to value field
+func scanIndex(value reflect.Value, args []interface{}) (index [][]int) {
+	type fieldKey struct {
+		addr uintptr
+		typ  reflect.Type
+	}
+	fields := map[fieldKey][]int{}
+	var walk func(v reflect.Value, path []int)
+	walk = func(v reflect.Value, path []int) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			fieldPath := append(append([]int{}, path...), i)
+			fields[fieldKey{addr: field.UnsafeAddr(), typ: field.Type()}] = fieldPath
+			if field.Kind() == reflect.Struct {
+				walk(field, fieldPath)
+			}
+		}
+	}
+	walk(value.Elem(), nil)
+	for _, arg := range args {
+		argValue := reflect.ValueOf(arg)
+		if argValue.Kind() != reflect.Ptr || argValue.IsNil() {
+			return nil
+		}
+		field, ok := fields[fieldKey{addr: argValue.Pointer(), typ: argValue.Type().Elem()}]
+		if !ok {
+			return nil
+		}
+		index = append(index, field)
+	}
 	return
 }
 
@@ -1375,16 +2450,20 @@ func (c *CRUD) Query(queryer interface{}, ctx context.Context, v interface{}, fi
 }
 
 func (c *CRUD) query(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, sql string, args []interface{}, dest ...interface{}) (err error) {
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
 	rows, err := c.queryerQuery(queryer, ctx, sql, args)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
 		}
 		return
 	}
 	defer rows.Close()
 	if c.Verbose {
-		c.Log(caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
 	}
 	err = c.Scan(rows, v, filter, dest...)
 	return
@@ -1401,9 +2480,13 @@ func (c *CRUD) QueryFilter(queryer interface{}, ctx context.Context, v interface
 }
 
 func (c *CRUD) queryFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, orderby string, offset, limit int, dest ...interface{}) (err error) {
-	sql := c.querySQL(caller+1, v, "", filter)
-	sql = c.joinWhere(caller+1, sql, where, sep)
-	sql = c.joinPage(caller+1, sql, orderby, offset, limit)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.querySQL(caller+1, ctx, v, "", filter)
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep)
+	sql = c.joinPage(caller+1, ctx, sql, orderby, offset, limit)
 	err = c.query(caller+1, queryer, ctx, v, filter, sql, args, dest...)
 	return
 }
@@ -1419,13 +2502,76 @@ func (c *CRUD) QueryWheref(queryer interface{}, ctx context.Context, v interface
 }
 
 func (c *CRUD) queryWheref(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, formats string, args []interface{}, orderby string, offset, limit int, dest ...interface{}) (err error) {
-	sql := c.querySQL(caller+1, v, "", filter)
-	sql, sqlArgs := c.joinWheref(caller+1, sql, nil, formats, args...)
-	sql = c.joinPage(caller+1, sql, orderby, offset, limit)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.querySQL(caller+1, ctx, v, "", filter)
+	sql, sqlArgs := c.joinWheref(caller+1, ctx, sql, nil, formats, args...)
+	sql = c.joinPage(caller+1, ctx, sql, orderby, offset, limit)
 	err = c.query(caller+1, queryer, ctx, v, filter, sql, sqlArgs, dest...)
 	return
 }
 
+func QueryByKeys(queryer interface{}, ctx context.Context, v interface{}, filter, keyField string, keys interface{}, dest interface{}) (missing []interface{}, err error) {
+	missing, err = Default.queryByKeys(1, queryer, ctx, v, filter, keyField, keys, dest)
+	return
+}
+
+func (c *CRUD) QueryByKeys(queryer interface{}, ctx context.Context, v interface{}, filter, keyField string, keys interface{}, dest interface{}) (missing []interface{}, err error) {
+	missing, err = c.queryByKeys(1, queryer, ctx, v, filter, keyField, keys, dest)
+	return
+}
+
+// queryByKeys will query rows by keyField in keys and scan to dest map keyed by keyField, the keys is split to multi statements
+// when params count is over MaxParams, the keys not found in dest map is returned as missing in input key type
+func (c *CRUD) queryByKeys(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, keyField string, keys interface{}, dest interface{}) (missing []interface{}, err error) {
+	keysValue := reflect.Indirect(reflect.ValueOf(keys))
+	if keysValue.Kind() != reflect.Slice && keysValue.Kind() != reflect.Array {
+		err = fmt.Errorf("keys must be slice, but %v", reflect.TypeOf(keys))
+		return
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Map {
+		err = fmt.Errorf("dest must be pointer to map, but %v", reflect.TypeOf(dest))
+		return
+	}
+	destValue = destValue.Elem()
+	keyType := destValue.Type().Key()
+	n := keysValue.Len()
+	if n > 0 {
+		c = c.withContext(ctx)
+		querySQL := c.querySQL(caller+1, ctx, v, "", filter)
+		size := n
+		if c.MaxParams > 0 && c.MaxParams < size {
+			size = c.MaxParams //the keys is the only args of statement
+		}
+		for start := 0; start < n; start += size {
+			chunk := []interface{}{}
+			for i := start; i < n && i < start+size; i++ {
+				chunk = append(chunk, keysValue.Index(i).Interface())
+			}
+			where, args := c.AppendWhereIn(nil, nil, keyField, chunk)
+			sql := c.joinWhere(caller+1, ctx, querySQL, where, "and")
+			err = c.query(caller+1, queryer, ctx, v, filter, sql, args, dest, keyField)
+			if err != nil {
+				return
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		key := keysValue.Index(i)
+		if !key.CanConvert(keyType) {
+			err = fmt.Errorf("key %v can not convert to %v", key.Type(), keyType)
+			return
+		}
+		if !destValue.MapIndex(key.Convert(keyType)).IsValid() {
+			missing = append(missing, key.Interface())
+		}
+	}
+	return
+}
+
 func QueryUnify(queryer interface{}, ctx context.Context, v interface{}) (err error) {
 	err = Default.queryUnify(1, queryer, ctx, v, "Query")
 	return
@@ -1447,17 +2593,21 @@ func (c *CRUD) QueryUnifyTarget(queryer interface{}, ctx context.Context, v inte
 }
 
 func (c *CRUD) queryUnify(caller int, queryer interface{}, ctx context.Context, v interface{}, target string) (err error) {
-	sql, args := c.queryUnifySQL(caller+1, v, target)
+	c = c.withContext(ctx)
+	sql, args, err := c.queryUnifySQL(caller+1, ctx, v, target)
+	if err != nil {
+		return
+	}
 	rows, err := c.queryerQuery(queryer, ctx, sql, args)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD query unify by struct:%v,sql:%v,args:%v result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD query unify by struct:%v,sql:%v,args:%v result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	defer rows.Close()
 	if c.Verbose {
-		c.Log(caller, "CRUD query unify by struct:%v,sql:%v,args:%v result is success", reflect.TypeOf(v), sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD query unify by struct:%v,sql:%v,args:%v result is success", reflect.TypeOf(v), sql, jsonString(args))
 	}
 	err = c.scanUnify(rows, v, target)
 	return
@@ -1479,10 +2629,15 @@ func (c *CRUD) ScanRow(row Row, v interface{}, filter string, dest ...interface{
 	if !isPtr || !isStruct {
 		value = reflect.Indirect(value)
 	}
-	err = c.destSet(value, filter, dest...)
+	var written []int8
+	if c.StrictDest {
+		written = make([]int8, len(dest))
+	}
+	err = c.destSet(value, filter, written, dest...)
 	if err != nil {
 		return
 	}
+	err = c.checkDestWritten(written, dest...)
 	return
 }
 
@@ -1523,15 +2678,19 @@ func (c *CRUD) QueryRow(queryer interface{}, ctx context.Context, v interface{},
 }
 
 func (c *CRUD) queryRow(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, sql string, args []interface{}, dest ...interface{}) (err error) {
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
 	err = c.ScanRow(c.queryerQueryRow(queryer, ctx, sql, args), v, filter, dest...)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD query by struct:%v,filter:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
 	}
 	return
 }
@@ -1547,8 +2706,12 @@ func (c *CRUD) QueryRowFilter(queryer interface{}, ctx context.Context, v interf
 }
 
 func (c *CRUD) queryRowFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, dest ...interface{}) (err error) {
-	sql := c.querySQL(caller+1, v, "", filter)
-	sql = c.joinWhere(caller+1, sql, where, sep)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.querySQL(caller+1, ctx, v, "", filter)
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep)
 	err = c.queryRow(caller+1, queryer, ctx, v, filter, sql, args, dest...)
 	return
 }
@@ -1564,8 +2727,12 @@ func (c *CRUD) QueryRowWheref(queryer interface{}, ctx context.Context, v interf
 }
 
 func (c *CRUD) queryRowWheref(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, formats string, args []interface{}, dest ...interface{}) (err error) {
-	sql := c.querySQL(caller+1, v, "", filter)
-	sql, sqlArgs := c.joinWheref(caller+1, sql, nil, formats, args...)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.querySQL(caller+1, ctx, v, "", filter)
+	sql, sqlArgs := c.joinWheref(caller+1, ctx, sql, nil, formats, args...)
 	err = c.queryRow(caller+1, queryer, ctx, v, filter, sql, sqlArgs, dest...)
 	return
 }
@@ -1591,38 +2758,42 @@ func (c *CRUD) QueryRowUnifyTarget(queryer interface{}, ctx context.Context, v i
 }
 
 func (c *CRUD) queryRowUnify(caller int, queryer interface{}, ctx context.Context, v interface{}, target string) (err error) {
-	sql, args := c.queryUnifySQL(caller+1, v, target)
+	c = c.withContext(ctx)
+	sql, args, err := c.queryUnifySQL(caller+1, ctx, v, target)
+	if err != nil {
+		return
+	}
 	err = c.scanRowUnify(c.queryerQueryRow(queryer, ctx, sql, args), v, target)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD query unify row by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD query unify row by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD query unify row by struct:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD query unify row by struct:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), sql, jsonString(args))
 	}
 	return
 }
 
 func CountSQL(v interface{}, filter string, suffix ...string) (sql string) {
-	sql = Default.countSQL(1, v, "", filter, suffix...)
+	sql = Default.countSQL(1, context.Background(), v, "", filter, suffix...)
 	return
 }
 
 func (c *CRUD) CountSQL(v interface{}, filter string, suffix ...string) (sql string) {
-	sql = c.countSQL(1, v, "", filter, suffix...)
+	sql = c.countSQL(1, context.Background(), v, "", filter, suffix...)
 	return
 }
 
-func (c *CRUD) countSQL(caller int, v interface{}, from string, filter string, suffix ...string) (sql string) {
+func (c *CRUD) countSQL(caller int, ctx context.Context, v interface{}, from string, filter string, suffix ...string) (sql string) {
 	var table string
 	var fields []string
 	if len(filter) < 1 || filter == "*" || filter == "count(*)" || filter == "count(*)#all" {
 		table = c.Table(v)
 		fields = []string{"count(*)"}
 	} else {
-		table, fields = c.queryField(caller+1, v, filter)
+		table, fields = c.queryField(caller+1, ctx, v, filter)
 	}
 	if len(from) > 0 {
 		table = from
@@ -1632,45 +2803,52 @@ func (c *CRUD) countSQL(caller int, v interface{}, from string, filter string, s
 		sql += " " + strings.Join(suffix, " ")
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD generate count sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
+		c.logf(ctx, caller, "CRUD generate count sql by struct:%v,filter:%v, result is sql:%v", reflect.TypeOf(v), filter, sql)
 	}
 	return
 }
 
-func CountUnifySQL(v interface{}) (sql string, args []interface{}) {
-	sql, args = Default.countUnifySQL(1, v, "Count")
+func CountUnifySQL(v interface{}) (sql string, args []interface{}, err error) {
+	sql, args, err = Default.countUnifySQL(1, context.Background(), v, "Count")
 	return
 }
 
-func (c *CRUD) CountUnifySQL(v interface{}) (sql string, args []interface{}) {
-	sql, args = c.countUnifySQL(1, v, "Count")
+func (c *CRUD) CountUnifySQL(v interface{}) (sql string, args []interface{}, err error) {
+	sql, args, err = c.countUnifySQL(1, context.Background(), v, "Count")
 	return
 }
 
-func (c *CRUD) countUnifySQL(caller int, v interface{}, key string) (sql string, args []interface{}) {
+func (c *CRUD) countUnifySQL(caller int, ctx context.Context, v interface{}, key string) (sql string, args []interface{}, err error) {
+	plan := c.planUnify(v)
+	target := plan.Target(key)
+	if target == nil {
+		panic(newFilterError(v, "", key, "unify target is not exists"))
+	}
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
-	modelValue := reflectValue.FieldByName("Model").Addr().Interface()
-	modelType, _ := reflectType.FieldByName("Model")
-	modelFrom := modelType.Tag.Get("from")
-	queryType, _ := reflectType.FieldByName(key)
-	queryFilter := queryType.Tag.Get("filter")
-	if queryFrom := queryType.Tag.Get("from"); len(queryFrom) > 0 {
-		modelFrom = queryFrom
-	}
-	querySelect := queryType.Tag.Get("select")
-	queryGroup := queryType.Tag.Get("group")
-	if len(querySelect) > 0 {
-		sql = querySelect
-		if strings.Contains(querySelect, "%v") {
-			_, fields := c.queryField(caller+1, modelValue, queryFilter)
-			sql = fmt.Sprintf(querySelect, strings.Join(fields, ","))
+	modelValue := reflectValue.FieldByIndex(plan.ModelIndex).Addr().Interface()
+	targetValue := reflectValue.FieldByIndex(target.Index)
+	modelFrom := plan.ModelFrom
+	if len(target.From) > 0 {
+		modelFrom = target.From
+	}
+	modelFrom, args, err = c.unifyFrom(v, modelFrom, target, targetValue)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD generate count unify sql by struct:%v,target:%v, result is fail:%v", reflect.TypeOf(v), key, err)
+		}
+		return
+	}
+	if len(target.Select) > 0 {
+		sql = target.Select
+		if strings.Contains(target.Select, "%v") {
+			_, fields := c.queryField(caller+1, ctx, modelValue, target.Filter)
+			sql = fmt.Sprintf(target.Select, strings.Join(fields, ","))
 		}
 	} else {
-		sql = c.countSQL(caller+1, modelValue, modelFrom, queryFilter)
+		sql = c.countSQL(caller+1, ctx, modelValue, modelFrom, target.Filter)
 	}
-	sql, args = c.joinWhereUnify(caller+1, sql, nil, v)
-	sql += " " + queryGroup
+	sql, args = c.joinWhereUnify(caller+1, ctx, sql, args, v)
+	sql += " " + target.Group
 	return
 }
 
@@ -1694,25 +2872,24 @@ func (c *CRUD) CountUnifyDestTarget(v interface{}, target string) (modelValue in
 	return
 }
 
-func (c *CRUD) countUnifyDest(v interface{}, target string) (modelValue interface{}, queryFilter string, dests []interface{}) {
+func (c *CRUD) countUnifyDest(v interface{}, key string) (modelValue interface{}, queryFilter string, dests []interface{}) {
+	plan := c.planUnify(v)
+	target := plan.Target(key)
+	if target == nil {
+		panic(newFilterError(v, "", key, "unify target is not exists"))
+	}
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
 	modelValueList := []interface{}{
-		TableName(c.Table(reflectValue.FieldByName("Model").Addr().Interface())),
-	}
-	queryType, _ := reflectType.FieldByName(target)
-	queryValue := reflectValue.FieldByName(target)
-	queryFilter = queryType.Tag.Get("filter")
-	queryNum := queryType.Type.NumField()
-	for i := 0; i < queryNum; i++ {
-		scan := queryType.Type.Field(i).Tag.Get("scan")
-		if scan == "-" {
-			continue
-		}
-		modelValueList = append(modelValueList, queryValue.Field(i).Interface())
-		dests = append(dests, queryValue.Field(i).Addr().Interface())
-		if len(scan) > 0 {
-			dests = append(dests, scan)
+		TableName(c.Table(reflectValue.FieldByIndex(plan.ModelIndex).Addr().Interface())),
+	}
+	targetValue := reflectValue.FieldByIndex(target.Index)
+	queryFilter = target.Filter
+	for _, dest := range target.Dests {
+		fieldValue := targetValue.FieldByIndex(dest.Index)
+		modelValueList = append(modelValueList, fieldValue.Interface())
+		dests = append(dests, fieldValue.Addr().Interface())
+		if len(dest.Scan) > 0 {
+			dests = append(dests, dest.Scan)
 		}
 	}
 	modelValue = modelValueList
@@ -1730,15 +2907,19 @@ func (c *CRUD) Count(queryer interface{}, ctx context.Context, v interface{}, fi
 }
 
 func (c *CRUD) count(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, sql string, args []interface{}, dest ...interface{}) (err error) {
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
 	err = c.ScanRow(c.queryerQueryRow(queryer, ctx, sql, args), v, filter, dest...)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD count by struct:%v,filter:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD count by struct:%v,filter:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), filter, sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD count by struct:%v,filter:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD count by struct:%v,filter:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), filter, sql, jsonString(args))
 	}
 	return
 }
@@ -1754,8 +2935,12 @@ func (c *CRUD) CountFilter(queryer interface{}, ctx context.Context, v interface
 }
 
 func (c *CRUD) countFilter(caller int, queryer interface{}, ctx context.Context, v interface{}, filter string, where []string, sep string, args []interface{}, suffix string, dest ...interface{}) (err error) {
-	sql := c.countSQL(caller+1, v, "", filter)
-	sql = c.joinWhere(caller+1, sql, where, sep, suffix)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.countSQL(caller+1, ctx, v, "", filter)
+	sql = c.joinWhere(caller+1, ctx, sql, where, sep, suffix)
 	err = c.count(caller+1, queryer, ctx, v, filter, sql, args, dest...)
 	return
 }
@@ -1771,8 +2956,12 @@ func (c *CRUD) CountWheref(queryer interface{}, ctx context.Context, v interface
 }
 
 func (c *CRUD) countWheref(caller int, queryer interface{}, ctx context.Context, v interface{}, filter, formats string, args []interface{}, suffix string, dest ...interface{}) (err error) {
-	sql := c.countSQL(caller+1, v, "", filter)
-	sql, sqlArgs := c.joinWheref(caller+1, sql, nil, formats, args...)
+	c = c.withContext(ctx)
+	if err = c.checkFilterOnce(v, filter); err != nil {
+		return
+	}
+	sql := c.countSQL(caller+1, ctx, v, "", filter)
+	sql, sqlArgs := c.joinWheref(caller+1, ctx, sql, nil, formats, args...)
 	if len(suffix) > 0 {
 		sql += " " + suffix
 	}
@@ -1780,6 +2969,54 @@ func (c *CRUD) countWheref(caller int, queryer interface{}, ctx context.Context,
 	return
 }
 
+// Wheref is the where condition by formats and args, it is used to share same condition on query and count
+type Wheref struct {
+	crud    *CRUD
+	formats string
+	args    []interface{}
+}
+
+func NewWheref(formats string, args ...interface{}) (w *Wheref) {
+	w = Default.NewWheref(formats, args...)
+	return
+}
+
+// NewWheref will return Wheref by formats and args, it will panic if formats count is not equal to args
+func (c *CRUD) NewWheref(formats string, args ...interface{}) (w *Wheref) {
+	if len(formats) > 0 {
+		formatList, _, _ := splitFormats(formats)
+		if len(formatList) != len(args) {
+			panic(fmt.Sprintf("count formats=%v  is not equal to args=%v", len(formatList), len(args)))
+		}
+	}
+	w = &Wheref{crud: c, formats: formats, args: args}
+	return
+}
+
+// Apply will join where condition to sql and append args
+func (w *Wheref) Apply(sql string, args []interface{}) (sql_ string, args_ []interface{}) {
+	sql_, args_ = w.crud.joinWheref(1, context.Background(), sql, args, w.formats, w.args...)
+	return
+}
+
+// Query will query v by where condition, it is same as CRUD.QueryWheref
+func (w *Wheref) Query(queryer interface{}, ctx context.Context, v interface{}, filter, orderby string, offset, limit int, dest ...interface{}) (err error) {
+	err = w.crud.queryWheref(1, queryer, ctx, v, filter, w.formats, w.args, orderby, offset, limit, dest...)
+	return
+}
+
+// QueryRow will query one row of v by where condition, it is same as CRUD.QueryRowWheref
+func (w *Wheref) QueryRow(queryer interface{}, ctx context.Context, v interface{}, filter string, dest ...interface{}) (err error) {
+	err = w.crud.queryRowWheref(1, queryer, ctx, v, filter, w.formats, w.args, dest...)
+	return
+}
+
+// Count will count v by where condition, it is same as CRUD.CountWheref
+func (w *Wheref) Count(queryer interface{}, ctx context.Context, v interface{}, filter string, dest ...interface{}) (err error) {
+	err = w.crud.countWheref(1, queryer, ctx, v, filter, w.formats, w.args, "", dest...)
+	return
+}
+
 func CountUnify(queryer interface{}, ctx context.Context, v interface{}) (err error) {
 	err = Default.countUnify(1, queryer, ctx, v, "Count")
 	return
@@ -1801,17 +3038,21 @@ func (c *CRUD) CountUnifyTarget(queryer interface{}, ctx context.Context, v inte
 }
 
 func (c *CRUD) countUnify(caller int, queryer interface{}, ctx context.Context, v interface{}, target string) (err error) {
-	sql, args := c.countUnifySQL(caller+1, v, target)
+	c = c.withContext(ctx)
+	sql, args, err := c.countUnifySQL(caller+1, ctx, v, target)
+	if err != nil {
+		return
+	}
 	modelValue, queryFilter, dests := c.countUnifyDest(v, target)
 	err = c.ScanRow(c.queryerQueryRow(queryer, ctx, sql, args), modelValue, queryFilter, dests...)
 	if err != nil {
 		if c.Verbose {
-			c.Log(caller, "CRUD count unify by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
+			c.logf(ctx, caller, "CRUD count unify by struct:%v,sql:%v,args:%v, result is fail:%v", reflect.TypeOf(v), sql, jsonString(args), err)
 		}
 		return
 	}
 	if c.Verbose {
-		c.Log(caller, "CRUD count unify by struct:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), sql, jsonString(args))
+		c.logf(ctx, caller, "CRUD count unify by struct:%v,sql:%v,args:%v, result is success", reflect.TypeOf(v), sql, jsonString(args))
 	}
 	return
 }
@@ -1827,54 +3068,36 @@ func (c *CRUD) ApplyUnify(queryer interface{}, ctx context.Context, v interface{
 }
 
 func (c *CRUD) applyUnify(caller int, queryer interface{}, ctx context.Context, v interface{}, enabled ...string) (err error) {
+	c = c.withContext(ctx)
+	plan := c.planUnify(v)
 	reflectValue := reflect.Indirect(reflect.ValueOf(v))
-	reflectType := reflectValue.Type()
 	enabledAll := xsql.StringArray(enabled)
-	isEnabled := func(key string) bool {
-		return len(enabledAll) < 1 || enabledAll.HavingOne(key)
-	}
-	if value := reflectValue.FieldByName("Query"); value.IsValid() && err == nil && isEnabled("Query") {
-		enabled := value.FieldByName("Enabled")
-		if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-			err = c.queryUnify(caller+1, queryer, ctx, v, "Query")
+	apply := func(target *UnifyTarget) {
+		if !target.enabled(reflectValue.FieldByIndex(target.Index)) {
+			return
 		}
-	}
-	if value := reflectValue.FieldByName("QueryRow"); value.IsValid() && err == nil && isEnabled("QueryRow") {
-		enabled := value.FieldByName("Enabled")
-		if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-			err = c.queryRowUnify(caller+1, queryer, ctx, v, "QueryRow")
+		switch target.Apply {
+		case "Query":
+			err = c.queryUnify(caller+2, queryer, ctx, v, target.Name)
+		case "QueryRow":
+			err = c.queryRowUnify(caller+2, queryer, ctx, v, target.Name)
+		case "Count":
+			err = c.countUnify(caller+2, queryer, ctx, v, target.Name)
 		}
 	}
-	if value := reflectValue.FieldByName("Count"); value.IsValid() && err == nil && isEnabled("Count") {
-		enabled := value.FieldByName("Enabled")
-		if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-			err = c.countUnify(caller+1, queryer, ctx, v, "Count")
+	for _, key := range []string{"Query", "QueryRow", "Count"} {
+		if target := plan.Target(key); target != nil && err == nil && (len(enabledAll) < 1 || enabledAll.HavingOne(key)) {
+			apply(target)
 		}
 	}
-	for i := 0; i < reflectType.NumField(); i++ {
-		fieldType := reflectType.Field(i)
-		fieldValue := reflectValue.Field(i)
-		apply := fieldType.Tag.Get("apply")
-		if len(apply) < 1 || !enabledAll.HavingOne(fieldType.Name) {
-			continue
+	for _, target := range plan.Targets {
+		if err != nil {
+			break
 		}
-		switch apply {
-		case "Query":
-			enabled := fieldValue.FieldByName("Enabled")
-			if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-				err = c.queryUnify(caller+1, queryer, ctx, v, fieldType.Name)
-			}
-		case "QueryRow":
-			enabled := fieldValue.FieldByName("Enabled")
-			if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-				err = c.queryRowUnify(caller+1, queryer, ctx, v, fieldType.Name)
-			}
-		case "Count":
-			enabled := fieldValue.FieldByName("Enabled")
-			if !enabled.IsValid() || (enabled.IsValid() && enabled.Bool()) {
-				err = c.countUnify(caller+1, queryer, ctx, v, fieldType.Name)
-			}
+		if target.Name == target.Apply || !enabledAll.HavingOne(target.Name) {
+			continue
 		}
+		apply(target)
 	}
 	return
 }
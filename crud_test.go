@@ -2,12 +2,15 @@ package crud
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/codingeasygo/util/converter"
@@ -58,6 +61,31 @@ func TestBuildOrderby(t *testing.T) {
 		t.Error(v)
 		return
 	}
+	supported := "tid,name#ci,create_time#nullslast,update_time#nullsfirst, title#ci#nullslast"
+	if v := BuildOrderby(supported, "+name"); v != "order by lower(name) asc" {
+		t.Error(v)
+		return
+	}
+	if v := BuildOrderby(supported, "-create_time"); v != "order by create_time desc nulls last" {
+		t.Error(v)
+		return
+	}
+	if v := BuildOrderby(supported, "+update_time"); v != "order by update_time asc nulls first" {
+		t.Error(v)
+		return
+	}
+	if v := BuildOrderby(supported, "-title"); v != "order by lower(title) desc nulls last" {
+		t.Error(v)
+		return
+	}
+	if v := BuildOrderby(supported, "+tid"); v != "order by tid asc" {
+		t.Error(v)
+		return
+	}
+	if v := BuildOrderby(supported, "+ci"); len(v) > 0 {
+		t.Error(v)
+		return
+	}
 }
 
 func TestQueryCall(t *testing.T) {
@@ -511,6 +539,206 @@ func TestFilterFormatCall(t *testing.T) {
 	}
 }
 
+func TestCheckValue(t *testing.T) {
+	var nilTime *xsql.Time
+	var nilStr *string
+	emptyStr := ""
+	zeroInt := xsql.Int64NilZero{}
+	type checkCase struct {
+		Arg     interface{}
+		Skip    bool //skip with incNil=false,incZero=false
+		IncNil  bool //included with incNil=true,incZero=false
+		IncZero bool //included with incNil=false,incZero=true
+	}
+	cases := []checkCase{
+		{Arg: nil, Skip: true, IncNil: true, IncZero: false},
+		{Arg: nilStr, Skip: true, IncNil: true, IncZero: false},
+		{Arg: nilTime, Skip: true, IncNil: true, IncZero: false},
+		{Arg: IsNilArray(nil), Skip: true, IncNil: true, IncZero: false},
+		{Arg: &emptyStr, Skip: true, IncNil: false, IncZero: true},
+		{Arg: "", Skip: true, IncNil: false, IncZero: true},
+		{Arg: 0, Skip: true, IncNil: false, IncZero: true},
+		{Arg: decimal.Zero, Skip: true, IncNil: false, IncZero: true},
+		{Arg: xsql.Time{}, Skip: true, IncNil: false, IncZero: true},
+		{Arg: &xsql.Time{}, Skip: true, IncNil: false, IncZero: true},
+		{Arg: IsZeroArray(nil), Skip: true, IncNil: false, IncZero: true},
+		{Arg: zeroInt, Skip: false, IncNil: true, IncZero: true}, //zero value is not zero by IsZero
+		{Arg: &zeroInt, Skip: false, IncNil: true, IncZero: true},
+		{Arg: "1", Skip: false, IncNil: true, IncZero: true},
+		{Arg: decimal.NewFromInt(1), Skip: false, IncNil: true, IncZero: true},
+		{Arg: xsql.TimeNow(), Skip: false, IncNil: true, IncZero: true},
+	}
+	for i, c := range cases {
+		if CheckValue(c.Arg, false, false) == c.Skip || CheckValue(c.Arg, true, false) != c.IncNil || CheckValue(c.Arg, false, true) != c.IncZero || !CheckValue(c.Arg, true, true) {
+			t.Errorf("%v,%v", i, c.Arg)
+			return
+		}
+	}
+	var formats []string
+	call := func(format string, arg interface{}) { formats = append(formats, format) }
+	FilterFormatCall("price=$%v,price=$%v#keepzero,status=$%v#keepzero,title=$%v", []interface{}{decimal.Zero, decimal.Zero, nil, ""}, call)
+	if strings.Join(formats, ",") != "price=$%v" {
+		t.Error(formats)
+		return
+	}
+	formats = nil
+	FilterFormatCall("price=$%v#keepzero,status=$%v#keepzero#nil", []interface{}{decimal.Zero, nil}, call)
+	if strings.Join(formats, ",") != "price=$%v,status=$%v" {
+		t.Error(formats)
+		return
+	}
+	formats = nil
+	FilterFormatCall("price=$%v#keepzero,status=$%v,title=$%v#nil,zero", []interface{}{decimal.Zero, nil, ""}, call)
+	if strings.Join(formats, ",") != "price=$%v,status=$%v,title=$%v" {
+		t.Error(formats)
+		return
+	}
+	where, args := AppendWheref(nil, nil, "price=$%v#keepzero,status=$%v#keepzero#+or", decimal.Zero, 0)
+	if strings.Join(where, ",") != "price=$1,status=$2" || len(args) != 2 || wherefSep("price=$%v#keepzero,status=$%v#keepzero#+or") != "or" {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	if allocs := testing.AllocsPerRun(100, func() { CheckValue(1, false, false) }); allocs > 0 {
+		t.Errorf("alloc %v", allocs)
+		return
+	}
+}
+
+func TestFilterWhere(t *testing.T) {
+	userID := int64(100)
+	where := struct {
+		UserID *int64                `json:"user_id" cmp:"user_id=$%v"`
+		Title  string                `json:"title" cmp:"title like $%v"`
+		Status CrudObjectStatusArray `json:"status" cmp:"status=any($%v)"`
+	}{
+		UserID: &userID,
+		Status: CrudObjectStatusAll,
+	}
+	whereList, args := FilterWhere([]interface{}{"x"}, &where, "")
+	if len(whereList) != 2 || len(args) != 3 || whereList[0] != "user_id=$2" || whereList[1] != "status=any($3)" {
+		t.Errorf("where is %v, args is %v", whereList, args)
+		return
+	}
+}
+
+func TestCheckFilter(t *testing.T) {
+	object := &struct {
+		CrudObject `filter:"#inline"`
+		Count      int64 `json:"count"`
+		Ignore     int64 `json:"-"`
+	}{}
+	for _, filter := range []string{"", "#all", "tid,type#all", "o.tid,count(tid)|count", "^title,status#all", "*"} {
+		if err := CheckFilter(object, filter); err != nil {
+			t.Errorf("%v:%v", filter, err)
+			return
+		}
+	}
+	if err := CheckFilter([]interface{}{TableName("crud_object")}, "xx"); err != nil {
+		t.Error(err)
+		return
+	}
+	err := CheckFilter(object, "tid,xx#all|o.^yy")
+	if err == nil || !strings.Contains(err.Error(), "fields xx,yy is not found") {
+		t.Error(err)
+		return
+	}
+	strict := *Default
+	strict.StrictFilter = true
+	var where []string
+	where, _ = strict.FilterWhere(nil, &struct {
+		UserID int64 `json:"user_id" cmp:"user_id=$%v"`
+	}{UserID: 100}, "user_id")
+	if len(where) != 1 {
+		t.Errorf("where is %v", where)
+		return
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil || !strings.Contains(fmt.Sprintf("%v", perr), "fields xx is not found") {
+				t.Errorf("panic is %v", perr)
+			}
+		}()
+		strict.FilterFieldCall("test", object, "tid,xx", func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {})
+	}()
+	//only
+	if sql := QuerySQL(&CrudObject{}, "tid,title#only"); sql != "select tid,title from crud_object" {
+		t.Error(sql)
+		return
+	}
+	if sql := QuerySQL(&CrudObject{}, "o.tid#only|count(title)#all"); sql != "select o.tid,count(title) from crud_object o" {
+		t.Error(sql)
+		return
+	}
+	if _, args, err := InsertSQL(&CrudObject{Title: "title"}, "title,level#only"); err != nil || len(args) != 2 {
+		t.Errorf("args is %v", args)
+		return
+	}
+	for filter, message := range map[string]string{
+		"tid,xx#only":        "fields xx is not found",
+		"^tid#only":          "#only is not supported with ^ exclusion",
+		"o.#only":            "#only fields is empty",
+		"tid#only|o.^yy#all": "fields yy is not found",
+	} {
+		if err = CheckFilter(object, filter); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%v:%v", filter, err)
+			return
+		}
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil || !strings.Contains(fmt.Sprintf("%v", perr), "fields xx is not found") {
+				t.Errorf("panic is %v", perr)
+			}
+		}()
+		FilterFieldCall("test", object, "tid#all|title,xx#only", func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {})
+	}()
+	//not only part is not checked without StrictFilter
+	FilterFieldCall("test", object, "tid,xx#all|title#only", func(fieldName, fieldFunc string, field reflect.StructField, value interface{}) {})
+	//error is returned by entry points without sql executed
+	strict.Verbose = false
+	queryer := &testRecordQueryer{}
+	ctx := context.Background()
+	var objects []*CrudObject
+	for name, call := range map[string]func() error{
+		"QueryWheref": func() error {
+			return strict.QueryWheref(queryer, ctx, &CrudObject{}, "tid,xx#all", "tid=$%v", []interface{}{1}, "", 0, 0, &objects)
+		},
+		"QueryRowWheref": func() error {
+			return QueryRowWheref(queryer, ctx, &CrudObject{}, "tid,xx#only", "tid=$%v", []interface{}{1})
+		},
+		"CountWheref": func() error {
+			return strict.CountWheref(queryer, ctx, &CrudObject{}, "count(xx)#all", "", nil, "")
+		},
+		"InsertFilter": func() error {
+			_, err := strict.InsertFilter(queryer, ctx, object, "title,xx", "", "")
+			return err
+		},
+		"BulkInsertFilter": func() error {
+			_, err := BulkInsertFilter(queryer, ctx, []*CrudObject{&object.CrudObject}, "title,xx#only")
+			return err
+		},
+		"UpsertFilter": func() error {
+			_, err := strict.UpsertFilter(queryer, ctx, object, "title", "tid", "xx", "", "")
+			return err
+		},
+		"UpdateWheref": func() error {
+			_, err := strict.UpdateWheref(queryer, ctx, object, "title,xx", "tid=$%v", 1)
+			return err
+		},
+	} {
+		for i := 0; i < 2; i++ {
+			if err = call(); !errors.Is(err, ErrFilter) {
+				t.Errorf("%v:%v", name, err)
+				return
+			}
+		}
+	}
+	if len(queryer.sqls) > 0 {
+		t.Errorf("sqls is %v", queryer.sqls)
+		return
+	}
+}
+
 func newTestObject() (object *CrudObject) {
 	object = &CrudObject{
 		Type:   "test",
@@ -572,7 +800,7 @@ func testInsert(t *testing.T, queryer Queryer) {
 	var err error
 	{
 		object := newTestObject()
-		insertSQL, insertArg := InsertSQL(object, "", "returning tid")
+		insertSQL, insertArg, _ := InsertSQL(object, "", "returning tid")
 		fmt.Printf("insert\n")
 		fmt.Printf("   --->%v\n", insertSQL)
 		fmt.Printf("   --->%v\n", insertArg)
@@ -584,7 +812,7 @@ func testInsert(t *testing.T, queryer Queryer) {
 	}
 	{
 		object := newTestObject()
-		insertSQL, insertArg := Default.InsertSQL(object, "", "returning tid")
+		insertSQL, insertArg, _ := Default.InsertSQL(object, "", "returning tid")
 		fmt.Printf("insert\n")
 		fmt.Printf("   --->%v\n", insertSQL)
 		fmt.Printf("   --->%v\n", insertArg)
@@ -693,6 +921,55 @@ func testInsert(t *testing.T, queryer Queryer) {
 	}
 }
 
+func TestUpsert(t *testing.T) {
+	clearPG()
+	testUpsert(t, getPG())
+}
+
+func testUpsert(t *testing.T, queryer Queryer) {
+	var err error
+	{
+		object := newTestObject()
+		object.TID = 0
+		_, err = InsertFilter(queryer, context.Background(), object, "^tid#all", "returning", "tid#all")
+		if err != nil || object.TID < 1 {
+			t.Error(err)
+			return
+		}
+		tid := object.TID
+		object.Title = "upsert"
+		_, err = UpsertFilter(queryer, context.Background(), object, "#all", "tid", "title", "returning", "tid#all")
+		if err != nil || object.TID != tid {
+			t.Error(err)
+			return
+		}
+		var title string
+		err = QueryRow(queryer, context.Background(), object, "title#all", "select title from crud_object where tid=$1", []interface{}{tid}, &title, "title")
+		if err != nil || title != "upsert" {
+			t.Errorf("%v,%v", err, title)
+			return
+		}
+		_, err = Default.UpsertFilter(queryer, context.Background(), object, "#all", "tid", "", "", "")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	{ //error
+		object := newTestObject()
+		_, err = UpsertFilter(queryer, context.Background(), object, "#all", "not_exists", "title", "returning", "tid#all")
+		if err == nil {
+			t.Error(err)
+			return
+		}
+		_, err = UpsertFilter(queryer, context.Background(), object, "#all", "not_exists", "title", "", "")
+		if err == nil {
+			t.Error(err)
+			return
+		}
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	clearPG()
 	testUpdate(t, getPG())
@@ -714,7 +991,7 @@ func testUpdate(t *testing.T, queryer Queryer) {
 		var updateSQL string
 		var where []string
 		var args []interface{}
-		updateSQL, args = UpdateSQL(object, "title,image,update_time,status#all", nil)
+		updateSQL, args, _ = UpdateSQL(object, "title,image,update_time,status#all", nil)
 		if strings.Contains(updateSQL, "tid") {
 			err = fmt.Errorf("error")
 			t.Error(err)
@@ -733,7 +1010,7 @@ func testUpdate(t *testing.T, queryer Queryer) {
 		}
 	}
 	{
-		updateSQL, args := UpdateSQL(object, "", nil)
+		updateSQL, args, _ := UpdateSQL(object, "", nil)
 		fmt.Printf("update\n")
 		fmt.Printf("   --->%v\n", updateSQL)
 		fmt.Printf("   --->%v\n", args)
@@ -742,7 +1019,7 @@ func testUpdate(t *testing.T, queryer Queryer) {
 			t.Error(err)
 			return
 		}
-		updateSQL, args = Default.UpdateSQL(object, "", nil)
+		updateSQL, args, _ = Default.UpdateSQL(object, "", nil)
 		fmt.Printf("update\n")
 		fmt.Printf("   --->%v\n", updateSQL)
 		fmt.Printf("   --->%v\n", args)
@@ -753,7 +1030,7 @@ func testUpdate(t *testing.T, queryer Queryer) {
 		}
 	}
 	{
-		sql, args := UpdateSQL(object, "", nil)
+		sql, args, _ := UpdateSQL(object, "", nil)
 		if len(args) < 1 {
 			err = fmt.Errorf("table error")
 			t.Error(err)
@@ -774,7 +1051,7 @@ func testUpdate(t *testing.T, queryer Queryer) {
 		}
 	}
 	{
-		sql, args := Default.UpdateSQL(object, "", nil)
+		sql, args, _ := Default.UpdateSQL(object, "", nil)
 		if len(args) < 1 {
 			err = fmt.Errorf("table error")
 			t.Error(err)
@@ -1869,6 +2146,171 @@ type ModelSelectCrudObjectUnify struct {
 	} `json:"count" select:"select count(distinct %v) from crud_object o" filter:"o.tid#all"`
 }
 
+type FromArgsCrudObjectUnify struct {
+	Model CrudObject `json:"model" from:"(select * from crud_object where user_id=$%v) o"`
+	Where struct {
+		Type   CrudObjectType        `json:"o.type"`
+		Status CrudObjectStatusArray `json:"o.status" cmp:"o.status=any($%v)"`
+	} `json:"where" join:"and"`
+	Query struct {
+		FromArgs []interface{} `json:"from_args"`
+		Objects  []*CrudObject `json:"objects"`
+	} `json:"query" filter:"#all"`
+	Count struct {
+		FromArgs FromArgsGetter `json:"from_args"`
+		All      int64          `json:"all" scan:"tid"`
+	} `json:"count" from:"(select * from crud_object where user_id=$%v and level>$%v) o" filter:"o.count(tid)#all"`
+}
+
+func TestUnifyFromArgs(t *testing.T) {
+	search := &FromArgsCrudObjectUnify{}
+	search.Where.Type = CrudObjectTypeA
+	search.Where.Status = CrudObjectStatusShow
+	search.Query.FromArgs = []interface{}{100}
+	search.Count.FromArgs = FromArgsGetterF(func(args ...interface{}) []interface{} { return []interface{}{100, 1} })
+	querySQL, queryArgs, _ := QueryUnifySQL(search, "Query")
+	if !strings.Contains(querySQL, "from (select * from crud_object where user_id=$1) o where o.type = $2") || !strings.Contains(querySQL, "o.status=any($3)") || len(queryArgs) != 3 || queryArgs[0] != 100 {
+		t.Errorf("%v,%v", querySQL, queryArgs)
+		return
+	}
+	countSQL, countArgs, _ := CountUnifySQL(search)
+	if !strings.Contains(countSQL, "from (select * from crud_object where user_id=$1 and level>$2) o where o.type = $3") || !strings.Contains(countSQL, "o.status=any($4)") || len(countArgs) != 4 {
+		t.Errorf("%v,%v", countSQL, countArgs)
+		return
+	}
+	modelValue, _, dests := ScanUnifyDest(search, "Query")
+	if modelValue == nil || len(dests) != 1 {
+		t.Error(dests)
+		return
+	}
+	search.Query.FromArgs = nil
+	if _, _, err := QueryUnifySQL(search, "Query"); err == nil || !strings.Contains(err.Error(), "placeholders=1 is not equal to FromArgs=0") {
+		t.Error(err)
+		return
+	}
+	search.Count.FromArgs = FromArgsGetterF(func(args ...interface{}) []interface{} { return []interface{}{100} })
+	if _, _, err := CountUnifySQL(search); err == nil {
+		t.Error(err)
+		return
+	}
+	queryer := &testRecordQueryer{}
+	if err := QueryUnifyTarget(queryer, context.Background(), search, "Query"); err == nil {
+		t.Error(err)
+		return
+	}
+	if err := QueryRowUnifyTarget(queryer, context.Background(), search, "Query"); err == nil {
+		t.Error(err)
+		return
+	}
+	if err := CountUnify(queryer, context.Background(), search); err == nil || len(queryer.sqls) > 0 {
+		t.Error(err, queryer.sqls)
+		return
+	}
+	if _, _, err := Default.unifyFrom(search, "crud_object where user_id=%v", nil, reflect.Value{}); err == nil || !strings.Contains(err.Error(), "format verb") {
+		t.Error(err)
+		return
+	}
+}
+
+type PageCrudObjectUnify struct {
+	Model CrudObject `json:"model"`
+	Page  struct {
+		Order  string `json:"order" default:"order by tid desc" supported:"tid,title#ci"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit" default:"10"`
+	} `json:"page"`
+	Query struct {
+		Objects []*CrudObject `json:"objects"`
+	} `json:"query" filter:"#all"`
+}
+
+func TestPlanUnify(t *testing.T) {
+	plan, err := PlanUnify(&SearchCrudObjectUnifySkip{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if plan.ModelType != reflect.TypeOf(CrudObject{}) || plan.WhereJoin != "and" || len(plan.Where) != 5 || plan.Where[3].Cmp != "status=any($%v)" || plan.Where[2].Join != "or" {
+		t.Errorf("%v", jsonString(plan))
+		return
+	}
+	if where := plan.Where[1]; where.Name != "Type" || where.Field != "type" || where.Filter != "#all" {
+		t.Errorf("%v", jsonString(where))
+		return
+	}
+	if plan.Order == nil || plan.Order.Default != "order by tid desc" || len(plan.Order.Supported) > 0 {
+		t.Errorf("%v", jsonString(plan.Order))
+		return
+	}
+	if plan.Page == nil || plan.Page.OffsetIndex == nil || plan.Page.LimitIndex == nil || plan.Page.LimitDefault != 0 {
+		t.Errorf("%v", jsonString(plan.Page))
+		return
+	}
+	if len(plan.Targets) != 3 || plan.Target("Query").Filter != "#all" || len(plan.Target("Query").Dests) != 2 || plan.Target("Query").EnabledIndex == nil {
+		t.Errorf("%v", jsonString(plan.Targets))
+		return
+	}
+	if target := plan.Target("ApplyCount"); target == nil || target.Apply != "Count" || target.Dests[0].Scan != "tid" || plan.Target("None") != nil {
+		t.Errorf("%v", jsonString(target))
+		return
+	}
+	if cached, _ := Default.PlanUnify(SearchCrudObjectUnifySkip{}); cached != plan {
+		t.Error("not cached")
+		return
+	}
+	plan, _ = PlanUnify(&FilterGetterCrudObjectUnify{})
+	if target := plan.Target("Query"); target.FilterIndex == nil || len(target.Dests) != 1 || plan.Page != nil || plan.Order != nil {
+		t.Errorf("%v", jsonString(target))
+		return
+	}
+	//order and page
+	plan, _ = PlanUnify(&PageCrudObjectUnify{})
+	if plan.Order == nil || plan.Order.Supported != "tid,title#ci" || plan.Order.Default != "order by tid desc" || plan.Page.LimitDefault != 10 {
+		t.Errorf("%v,%v", jsonString(plan.Order), jsonString(plan.Page))
+		return
+	}
+	page := &PageCrudObjectUnify{}
+	if sql := JoinPageUnify("select tid from crud_object", page); sql != "select tid from crud_object order by tid desc limit 10 offset 0" {
+		t.Error(sql)
+		return
+	}
+	page.Page.Order, page.Page.Offset, page.Page.Limit = "+title", 10, 20
+	if sql := JoinPageUnify("select tid from crud_object", page); sql != "select tid from crud_object order by lower(title) asc limit 20 offset 10" {
+		t.Error(sql)
+		return
+	}
+	page.Page.Order = "tid;drop table crud_object"
+	if sql := JoinPageUnify("select tid from crud_object", page); sql != "select tid from crud_object order by tid desc limit 20 offset 10" {
+		t.Error(sql)
+		return
+	}
+	if _, err = PlanUnify(&struct {
+		Model CrudObject
+		Page  struct {
+			Limit int `default:"x"`
+		}
+	}{}); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, err = PlanUnify(&CrudObject{}); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, err = PlanUnify("abc"); err == nil {
+		t.Error(err)
+		return
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		QueryUnifySQL(&SearchCrudObjectUnifyMini{}, "None")
+	}()
+}
+
 func TestUnify(t *testing.T) {
 	clearPG()
 	testUnify(t, getPG())
@@ -2108,7 +2550,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		search := newSearch()
-		querySQL, queryArgs := QueryUnifySQL(search, "Query")
+		querySQL, queryArgs, _ := QueryUnifySQL(search, "Query")
 		rows, err := queryer.Query(context.Background(), querySQL, queryArgs...)
 		if err != nil {
 			t.Error(err)
@@ -2120,7 +2562,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		rows.Close()
-		countSQL, countArgs := CountUnifySQL(search)
+		countSQL, countArgs, _ := CountUnifySQL(search)
 		row := queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests := CountUnifyDest(search)
 		err = ScanRow(row, modelValue, queryFilter, dests...)
@@ -2135,7 +2577,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		search := newSearch()
-		querySQL, queryArgs := QueryUnifySQL(search, "Query")
+		querySQL, queryArgs, _ := QueryUnifySQL(search, "Query")
 		rows, err := queryer.Query(context.Background(), querySQL, queryArgs...)
 		if err != nil {
 			t.Error(err)
@@ -2147,7 +2589,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		rows.Close()
-		countSQL, countArgs := CountUnifySQL(search)
+		countSQL, countArgs, _ := CountUnifySQL(search)
 		row := queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests := CountUnifyDestTarget(search, "Count")
 		err = ScanRow(row, modelValue, queryFilter, dests...)
@@ -2162,7 +2604,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		search := newSearch()
-		querySQL, queryArgs := Default.QueryUnifySQL(search, "Query")
+		querySQL, queryArgs, _ := Default.QueryUnifySQL(search, "Query")
 		rows, err := queryer.Query(context.Background(), querySQL, queryArgs...)
 		if err != nil {
 			t.Error(err)
@@ -2179,7 +2621,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		search = newSearch()
-		countSQL, countArgs := Default.CountUnifySQL(search)
+		countSQL, countArgs, _ := Default.CountUnifySQL(search)
 		row := queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests := Default.CountUnifyDest(search)
 		err = Default.ScanRow(row, modelValue, queryFilter, dests...)
@@ -2192,7 +2634,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		search = newSearch()
-		countSQL, countArgs = Default.CountUnifySQL(search)
+		countSQL, countArgs, _ = Default.CountUnifySQL(search)
 		row = queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests = Default.CountUnifyDestTarget(search, "Count")
 		err = Default.ScanRow(row, modelValue, queryFilter, dests...)
@@ -2221,7 +2663,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		rows.Close()
-		countSQL, countArgs := CountUnifySQL(search)
+		countSQL, countArgs, _ := CountUnifySQL(search)
 		row := queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests := CountUnifyDest(search)
 		err = ScanRow(row, modelValue, queryFilter, dests...)
@@ -2250,7 +2692,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 			return
 		}
 		rows.Close()
-		countSQL, countArgs := Default.CountUnifySQL(search)
+		countSQL, countArgs, _ := Default.CountUnifySQL(search)
 		row := queryer.QueryRow(context.Background(), countSQL, countArgs...)
 		modelValue, queryFilter, dests := Default.CountUnifyDest(search)
 		err = ScanRow(row, modelValue, queryFilter, dests...)
@@ -2265,7 +2707,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		find := newFind()
-		querySQL, queryArgs := Default.QueryUnifySQL(find, "QueryRow")
+		querySQL, queryArgs, _ := Default.QueryUnifySQL(find, "QueryRow")
 		row := queryer.QueryRow(context.Background(), querySQL, queryArgs...)
 		err = ScanRowUnify(row, find)
 		if err != nil || find.QueryRow.Object == nil || find.QueryRow.UserID < 1 {
@@ -2275,7 +2717,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		find := newFind()
-		querySQL, queryArgs := Default.QueryUnifySQL(find, "QueryRow")
+		querySQL, queryArgs, _ := Default.QueryUnifySQL(find, "QueryRow")
 		row := queryer.QueryRow(context.Background(), querySQL, queryArgs...)
 		err = ScanRowUnifyTarget(row, find, "QueryRow")
 		if err != nil || find.QueryRow.Object == nil || find.QueryRow.UserID < 1 {
@@ -2285,7 +2727,7 @@ func testUnify(t *testing.T, queryer Queryer) {
 	}
 	{
 		find := newFind()
-		querySQL, queryArgs := Default.QueryUnifySQL(find, "QueryRow")
+		querySQL, queryArgs, _ := Default.QueryUnifySQL(find, "QueryRow")
 		row := queryer.QueryRow(context.Background(), querySQL, queryArgs...)
 		modelValue, queryFilter, dests := ScanUnifyDest(find, "QueryRow")
 		err = ScanRow(row, modelValue, queryFilter, dests...)
@@ -2422,3 +2864,1250 @@ func testUnifyError(t *testing.T, queryer Queryer) {
 		t.Error("eror")
 	}()
 }
+
+type testColumnsRows struct {
+	err     error
+	columns []string
+}
+
+func (t *testColumnsRows) Scan(dest ...interface{}) (err error) { return }
+func (t *testColumnsRows) Next() bool                           { return false }
+func (t *testColumnsRows) Close() (err error)                   { return }
+func (t *testColumnsRows) Err() error                           { return t.err }
+func (t *testColumnsRows) Columns() ([]string, error)           { return t.columns, nil }
+
+type testSimpleRows struct {
+}
+
+func (t *testSimpleRows) Scan(dest ...interface{}) (err error) { return }
+func (t *testSimpleRows) Next() bool                           { return false }
+func (t *testSimpleRows) Close() (err error)                   { return }
+
+func TestRowsErrColumns(t *testing.T) {
+	var objects []*CrudObject
+	rows := &testColumnsRows{err: fmt.Errorf("rows error"), columns: []string{"tid", "title"}}
+	if err := Scan(rows, &CrudObject{}, "#all", &objects); err == nil || err.Error() != "rows error" {
+		t.Error(err)
+		return
+	}
+	if err := Scan(&testSimpleRows{}, &CrudObject{}, "#all", &objects); err != nil {
+		t.Error(err)
+		return
+	}
+	if columns, err := Columns(rows); err != nil || len(columns) != 2 {
+		t.Errorf("err is %v, columns is %v", err, columns)
+		return
+	}
+	if _, err := Columns(&testSimpleRows{}); err == nil {
+		t.Error("error")
+		return
+	}
+}
+
+func TestForcePrimary(t *testing.T) {
+	if IsForcePrimary(context.Background()) || !IsForcePrimary(ForcePrimary(context.Background())) {
+		t.Error("error")
+		return
+	}
+}
+
+func TestSprintfArgFormat(t *testing.T) {
+	c := &CRUD{ArgFormat: "?"}
+	if v := c.Sprintf("tid=?", 1); v != "tid=?" {
+		t.Error(v)
+		return
+	}
+	c.ArgFormat = "$%v"
+	if v := c.Sprintf("tid=$%v or tid=$%v", 1); v != "tid=$1 or tid=$1" {
+		t.Error(v)
+		return
+	}
+}
+
+type testRecordRow struct {
+}
+
+func (t *testRecordRow) Scan(dest ...interface{}) (err error) { return }
+
+type testRecordQueryer struct {
+	sqls []string
+}
+
+func (t *testRecordQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	t.sqls = append(t.sqls, query)
+	affected = 1
+	return
+}
+
+func (t *testRecordQueryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
+	t.sqls = append(t.sqls, query)
+	return
+}
+
+func (t *testRecordQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows Rows, err error) {
+	t.sqls = append(t.sqls, query)
+	rows = &testSimpleRows{}
+	return
+}
+
+func (t *testRecordQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row Row) {
+	t.sqls = append(t.sqls, query)
+	row = &testRecordRow{}
+	return
+}
+
+func TestUpdateWhereIn(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.MaxParams = 5
+	ctx := context.Background()
+	object := &CrudObject{Title: "title"}
+	//below limit
+	queryer := &testRecordQueryer{}
+	affected, err := c.UpdateWhereIn(queryer, ctx, object, "title", []string{"user_id=$1"}, "and", []interface{}{100}, "tid", []int64{1, 2, 3})
+	if err != nil || affected != 1 || len(queryer.sqls) != 1 || queryer.sqls[0] != "update crud_object set title=$2 where user_id=$1 and tid in ($3,$4,$5)" {
+		t.Errorf("%v,%v,%v", err, affected, queryer.sqls)
+		return
+	}
+	//above limit
+	queryer = &testRecordQueryer{}
+	affected, err = c.UpdateWhereIn(queryer, ctx, object, "title", []string{"user_id=$1"}, "and", []interface{}{100}, "tid", []int64{1, 2, 3, 4, 5, 6, 7})
+	if err != nil || affected != 3 || len(queryer.sqls) != 3 || queryer.sqls[2] != "update crud_object set title=$2 where user_id=$1 and tid in ($3)" {
+		t.Errorf("%v,%v,%v", err, affected, queryer.sqls)
+		return
+	}
+	//single statement is not split
+	where, args := c.AppendWhereIn(nil, nil, "tid", []int64{1, 2, 3, 4, 5, 6, 7})
+	if _, err = c.Update(queryer, ctx, object, "update crud_object set status=0", where, "and", args); !errors.Is(err, ErrTooManyParams) || !strings.Contains(err.Error(), "7") {
+		t.Error(err)
+		return
+	}
+	//empty
+	queryer = &testRecordQueryer{}
+	if affected, err = UpdateWhereIn(queryer, ctx, object, "title", nil, "", nil, "tid", []int64{}); err != nil || affected != 0 || len(queryer.sqls) != 0 {
+		t.Errorf("%v,%v,%v", err, affected, queryer.sqls)
+		return
+	}
+	//error
+	if _, err = c.UpdateWhereIn(queryer, ctx, object, "title", nil, "", []interface{}{1, 2, 3, 4}, "tid", []int64{1}); !errors.Is(err, ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+	if _, err = c.UpdateWhereIn(queryer, ctx, object, "title", nil, "", nil, "tid", 1); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, err = c.UpdateWhereIn(queryer, ctx, &CrudObject{}, "title", nil, "", nil, "tid", []int64{1}); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err = c.UpdateWhereIn(&testTxQueryer{failOn: "update"}, ctx, object, "title", nil, "", nil, "tid", []int64{1}); err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestWithTablePrefix(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.TablePrefix = "t0_"
+	if table := c.TableContext(context.Background(), &CrudObject{}); table != "t0_crud_object" {
+		t.Error(table)
+		return
+	}
+	if table := c.TableContext(WithTablePrefix(context.Background(), "t1_"), &CrudObject{}); table != "t1_crud_object" {
+		t.Error(table)
+		return
+	}
+	if table := TableContext(WithTablePrefix(context.Background(), "t1_"), &CrudObject{}); table != "t1_crud_object" {
+		t.Error(table)
+		return
+	}
+	runPrefix := func(prefix string) (queryer *testRecordQueryer, err error) {
+		queryer = &testRecordQueryer{}
+		ctx := context.Background()
+		if len(prefix) > 0 {
+			ctx = WithTablePrefix(ctx, prefix)
+		}
+		for i := 0; i < 100; i++ {
+			object := &CrudObject{TID: int64(i), Title: "title"}
+			if _, err = c.InsertFilter(queryer, ctx, object, "title", "", ""); err != nil {
+				break
+			}
+			if _, err = c.UpdateFilter(queryer, ctx, object, "title", []string{"tid=$2"}, "and", []interface{}{object.TID}); err != nil {
+				break
+			}
+			var objects []*CrudObject
+			if err = c.QueryFilter(queryer, ctx, &CrudObject{}, "#all", []string{"tid>$1"}, "and", []interface{}{0}, "", 0, 0, &objects); err != nil {
+				break
+			}
+			var count int64
+			if err = c.CountFilter(queryer, ctx, &CrudObject{}, "count(tid)#all", nil, "", nil, "", &count, "tid"); err != nil {
+				break
+			}
+		}
+		return
+	}
+	prefixes := []string{"t1_", "t2_", ""}
+	queryers := make([]*testRecordQueryer, len(prefixes))
+	errs := make([]error, len(prefixes))
+	waiter := make(chan int, len(prefixes))
+	for i, prefix := range prefixes {
+		go func(i int, prefix string) {
+			queryers[i], errs[i] = runPrefix(prefix)
+			waiter <- 1
+		}(i, prefix)
+	}
+	for range prefixes {
+		<-waiter
+	}
+	for i, prefix := range prefixes {
+		if errs[i] != nil {
+			t.Error(errs[i])
+			return
+		}
+		if len(prefix) < 1 {
+			prefix = c.TablePrefix
+		}
+		if len(queryers[i].sqls) != 400 {
+			t.Error(len(queryers[i].sqls))
+			return
+		}
+		for _, sql := range queryers[i].sqls {
+			if !strings.Contains(sql, " "+prefix+"crud_object") {
+				t.Errorf("%v not having %v", sql, prefix)
+				return
+			}
+		}
+	}
+	if c.TablePrefix != "t0_" {
+		t.Error(c.TablePrefix)
+		return
+	}
+}
+
+type testValueRows struct {
+	rows  int
+	index int
+}
+
+func (t *testValueRows) Scan(dest ...interface{}) (err error) {
+	for _, d := range dest {
+		switch v := d.(type) {
+		case *int64:
+			*v = int64(t.index)
+		case *string:
+			*v = fmt.Sprintf("title%v", t.index)
+		case **string:
+			if t.index%2 == 0 {
+				image := fmt.Sprintf("image%v", t.index)
+				*v = &image
+			}
+		}
+	}
+	return
+}
+
+func (t *testValueRows) Next() bool {
+	t.index++
+	return t.index <= t.rows
+}
+
+func (t *testValueRows) Close() (err error) { return }
+
+func TestScanReuse(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	filter := "tid,title,image#all"
+	for _, alloc := range []bool{false, true} {
+		c.ScanAlloc = alloc
+		var objects []*CrudObject
+		var values []CrudObject
+		var titles []string
+		var tids map[int64]string
+		var images []*string
+		var calls []CrudObject
+		err := c.Scan(&testValueRows{rows: 5}, &CrudObject{}, filter, &objects, &titles, "title", &tids, "tid:title", &images, "image")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = c.Scan(&testValueRows{rows: 5}, CrudObject{}, filter, &values, func(v CrudObject) { calls = append(calls, v) })
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(objects) != 5 || len(values) != 5 || len(calls) != 5 || len(titles) != 5 || len(tids) != 5 || len(images) != 2 {
+			t.Errorf("%v,%v,%v,%v,%v,%v", len(objects), len(values), len(calls), len(titles), len(tids), len(images))
+			return
+		}
+		for i := 0; i < 5; i++ {
+			tid := int64(i + 1)
+			title := fmt.Sprintf("title%v", tid)
+			if objects[i].TID != tid || values[i].TID != tid || calls[i].TID != tid || titles[i] != title || tids[tid] != title {
+				t.Errorf("%v,%v,%v,%v,%v", objects[i].TID, values[i].TID, calls[i].TID, titles[i], tids[tid])
+				return
+			}
+			if (tid%2 == 0) != (values[i].Image != nil) || (values[i].Image != nil && *values[i].Image != fmt.Sprintf("image%v", tid)) {
+				t.Errorf("%v,%v", tid, values[i].Image)
+				return
+			}
+		}
+		if *images[0] != "image2" || *images[1] != "image4" {
+			t.Errorf("%v,%v", *images[0], *images[1])
+			return
+		}
+	}
+	object := &CrudObject{}
+	args := c.ScanArgs(object, "tid,title#all")
+	if index := scanIndex(reflect.ValueOf(object), args); len(index) != 2 {
+		t.Error(index)
+		return
+	}
+	if index := scanIndex(reflect.ValueOf(object), []interface{}{1}); index != nil {
+		t.Error(index)
+		return
+	}
+	valueType := reflect.TypeOf(&CrudObject{})
+	if c.ScanAlloc = false; !c.scanReusable(valueType, true, &[]int64{}, "tid") || !c.scanReusable(valueType, false, func(CrudObject) {}) {
+		t.Error("error")
+		return
+	}
+	if c.scanReusable(valueType, true, &[]*CrudObject{}) || c.scanReusable(valueType, true, func(*CrudObject) {}) || c.scanReusable(valueType, true, &map[int64]*CrudObject{}, "tid") {
+		t.Error("error")
+		return
+	}
+	if c.ScanAlloc = true; c.scanReusable(valueType, false, func(CrudObject) {}) {
+		t.Error("error")
+		return
+	}
+}
+
+func benchmarkScan(b *testing.B, alloc bool, v interface{}, dest ...interface{}) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.ScanAlloc = alloc
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		err := c.Scan(&testValueRows{rows: 1000}, v, "tid,title,image,int_value,int64_value,string_value#all", dest...)
+		if err != nil {
+			b.Error(err)
+			return
+		}
+	}
+}
+
+func BenchmarkScanReuse(b *testing.B) {
+	count := 0
+	benchmarkScan(b, false, CrudObject{}, func(v CrudObject) { count++ })
+}
+
+func BenchmarkScanAlloc(b *testing.B) {
+	count := 0
+	benchmarkScan(b, true, CrudObject{}, func(v CrudObject) { count++ })
+}
+
+func BenchmarkScanAppend(b *testing.B) {
+	var objects []*CrudObject
+	benchmarkScan(b, false, &CrudObject{}, func(v *CrudObject) { objects = append(objects[:0], v) })
+}
+
+func TestBulkInsertFilter(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.MaxParams = 5
+	objects := []*CrudObject{}
+	for i := 0; i < 5; i++ {
+		objects = append(objects, &CrudObject{TID: int64(i + 1), Title: fmt.Sprintf("title%v", i)})
+	}
+	queryer := &testRecordQueryer{}
+	affected, err := c.BulkInsertFilter(queryer, context.Background(), objects, "tid,title#all")
+	if err != nil || affected != 3 || len(queryer.sqls) != 3 {
+		t.Errorf("err:%v,affected:%v,sqls:%v", err, affected, queryer.sqls)
+		return
+	}
+	if sql := queryer.sqls[1]; sql != "insert into crud_object(tid,title) values($1,$2),($3,$4)" {
+		t.Error(sql)
+		return
+	}
+	if _, err = c.BulkInsertFilter(queryer, context.Background(), objects, "tid,title,type,level,int_value,int64_value#all"); !errors.Is(err, ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+	objects[1].Title = ""
+	if _, err = c.BulkInsertFilter(queryer, context.Background(), objects, "tid,title"); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, err = BulkInsertFilter(queryer, context.Background(), "abc", "tid,title"); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, _, err = c.queryerExec(queryer, context.Background(), "select 1", []interface{}{1, 2, 3, 4, 5, 6}); !errors.Is(err, ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+	if err = c.queryerQueryRow(queryer, context.Background(), "select 1", []interface{}{1, 2, 3, 4, 5, 6}).Scan(); !errors.Is(err, ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+}
+
+func TestWheref(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	queryer := &testRecordQueryer{}
+	where := c.NewWheref("type=$%v,status=any($%v),title like $%v", "a", []int{100}, "")
+	var objects []*CrudObject
+	err := where.Query(queryer, context.Background(), &CrudObject{}, "#all", "order by tid", 0, 10, &objects)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var count int64
+	err = where.Count(queryer, context.Background(), &CrudObject{}, "count(tid)#all", &count, "tid")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var object *CrudObject
+	err = where.QueryRow(queryer, context.Background(), &CrudObject{}, "#all", &object)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	sql, args := where.Apply("delete from crud_object", []interface{}{1})
+	if sql != "delete from crud_object where type=$2 and status=any($3)" || len(args) != 3 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	for _, sql := range queryer.sqls {
+		if !strings.Contains(sql, " where type=$1 and status=any($2)") {
+			t.Error(sql)
+			return
+		}
+	}
+	if sql, args := NewWheref("").Apply("select 1", nil); sql != "select 1" || len(args) != 0 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		NewWheref("type=$%v,status=$%v", "a")
+	}()
+}
+
+func TestSQLReturning(t *testing.T) {
+	object := &CrudObject{Title: "title", Status: CrudObjectStatusNormal}
+	sql, args, scanArgs, err := InsertSQLReturning(object, "title,status", "tid,create_time#all")
+	if err != nil || sql != "insert into crud_object(title,status) values($1,$2) returning tid,create_time" || len(args) != 2 || len(scanArgs) != 2 {
+		t.Errorf("%v,%v,%v,%v", err, sql, args, scanArgs)
+		return
+	}
+	if tid, ok := scanArgs[0].(*int64); !ok || tid != &object.TID {
+		t.Error(scanArgs)
+		return
+	}
+	sql, args, scanArgs, err = UpdateSQLReturning(object, "title", []string{"tid=$1"}, "and", []interface{}{1}, "update_time#all")
+	if err != nil || sql != "update crud_object set title=$2 where tid=$1 returning update_time" || len(args) != 2 || len(scanArgs) != 1 {
+		t.Errorf("%v,%v,%v,%v", err, sql, args, scanArgs)
+		return
+	}
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	sql, _, _, _ = c.UpdateSQLReturning(object, "title", nil, "", nil, "tid#all")
+	if sql != "update crud_object set title=$1 returning tid" {
+		t.Error(sql)
+		return
+	}
+	sql, _, _, _ = c.InsertSQLReturning(object, "title", "tid#all")
+	if sql != "insert into crud_object(title) values($1) returning tid" {
+		t.Error(sql)
+		return
+	}
+	//empty scan
+	simple := &struct {
+		T     string `table:"simple_object"`
+		TID   int64  `json:"tid"`
+		Title string `json:"title"`
+	}{Title: "title"}
+	sql, _, scanArgs, _ = c.InsertSQLReturning(simple, "title", "^tid,title#all")
+	if sql != "insert into simple_object(title) values($1)" || len(scanArgs) != 0 {
+		t.Errorf("%v,%v", sql, scanArgs)
+		return
+	}
+	sql, _, scanArgs, _ = c.UpdateSQLReturning(simple, "title", []string{"tid=$1"}, "and", []interface{}{1}, "^tid,title#all")
+	if sql != "update simple_object set title=$2 where tid=$1" || len(scanArgs) != 0 {
+		t.Errorf("%v,%v", sql, scanArgs)
+		return
+	}
+}
+
+func TestNoFields(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = true
+	queryer := &testRecordQueryer{}
+	object := &CrudObject{}
+	ctx := context.Background()
+	if _, err := c.InsertFilter(queryer, ctx, object, "title,image", "", ""); !errors.Is(err, ErrNoFields) || !strings.Contains(err.Error(), "no fields matched filter title,image for struct *crud.CrudObject") {
+		t.Error(err)
+		return
+	}
+	if _, err := c.UpsertFilter(queryer, ctx, object, "title", "tid", "title", "", ""); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err := c.UpdateFilter(queryer, ctx, object, "title", []string{"tid=$1"}, "and", []interface{}{1}); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if err := c.UpdateRowFilter(queryer, ctx, object, "title", []string{"tid=$1"}, "and", []interface{}{1}); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err := c.UpdateWheref(queryer, ctx, object, "title", "tid=$%v", 1); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err := c.UpdateSet(queryer, ctx, object, nil, []string{"tid=$1"}, "and", []interface{}{1}); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err := c.BulkInsertFilter(queryer, ctx, []*CrudObject{object}, "title"); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if sql, _, err := c.InsertSQL(object, "title,image", "returning tid"); !errors.Is(err, ErrNoFields) || len(sql) > 0 {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+	if sql, _, _, err := c.InsertSQLReturning(object, "title,image", "tid#all"); !errors.Is(err, ErrNoFields) || len(sql) > 0 {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+	if sql, _, err := c.UpdateSQL(object, "title,image", nil); !errors.Is(err, ErrNoFields) || len(sql) > 0 {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+	if sql, _, _, err := c.UpdateSQLReturning(object, "title,image", []string{"tid=$1"}, "and", []interface{}{1}, "tid#all"); !errors.Is(err, ErrNoFields) || len(sql) > 0 {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+	if len(queryer.sqls) > 0 {
+		t.Error(queryer.sqls)
+		return
+	}
+	c.InsertDefault = true
+	if _, err := c.InsertFilter(queryer, ctx, object, "title,image", "", ""); err != nil || queryer.sqls[0] != "insert into crud_object default values" {
+		t.Errorf("%v,%v", err, queryer.sqls)
+		return
+	}
+	if sql, _, err := c.InsertSQL(object, "title,image", "returning tid"); err != nil || sql != "insert into crud_object default values returning tid" {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+	if sql, _, _, err := c.InsertSQLReturning(object, "title,image", "tid#all"); err != nil || sql != "insert into crud_object default values returning tid" {
+		t.Errorf("%v,%v", err, sql)
+		return
+	}
+}
+
+func TestScanTag(t *testing.T) {
+	type scanObject struct {
+		T      string `table:"crud_object"`
+		TID    int64  `json:"tid" scan:"id"`
+		Title  string `json:"title" scan:"-"`
+		Status int64  `json:"status"`
+	}
+	object := &scanObject{}
+	args := ScanArgs(object, "tid,title,status#all")
+	if len(args) != 3 || args[0] != &object.TID || args[2] != &object.Status {
+		t.Errorf("%v", args)
+		return
+	}
+	if _, ok := args[1].(*interface{}); !ok {
+		t.Errorf("%v", args)
+		return
+	}
+	var ids []int64
+	var idTitles map[int64]int64
+	var objects []*scanObject
+	err := Scan(&testValueRows{rows: 3}, &scanObject{}, "tid,title,status#all", &objects, &ids, "id", &idTitles, "id:status")
+	if err != nil || len(objects) != 3 || len(ids) != 3 || len(idTitles) != 3 {
+		t.Errorf("%v,%v,%v,%v", err, objects, ids, idTitles)
+		return
+	}
+	for i, object := range objects {
+		if object.TID != int64(i+1) || ids[i] != object.TID || len(object.Title) > 0 {
+			t.Errorf("%v,%v", object, ids[i])
+			return
+		}
+	}
+	ids = nil
+	if err = Scan(&testValueRows{rows: 3}, &scanObject{}, "tid,title,status#all", &ids, "tid"); err != nil || len(ids) != 3 {
+		t.Errorf("%v,%v", err, ids)
+		return
+	}
+	if err = Scan(&testValueRows{rows: 1}, &scanObject{}, "tid,title,status#all", &ids, "-"); err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestCaptureSQL(t *testing.T) {
+	queryer := &testRecordQueryer{}
+	object := &CrudObject{Title: "title"}
+	if allocs := testing.AllocsPerRun(100, func() { capturedSQL(context.Background()) }); allocs != 0 {
+		t.Error(allocs)
+		return
+	}
+	if recorder := capturedSQL(nil); recorder != nil {
+		t.Error(recorder)
+		return
+	}
+	ctx, recorder := CaptureSQL(context.Background())
+	recorder.SetMax(3)
+	if recorder.Slowest() != nil {
+		t.Error("error")
+		return
+	}
+	UpdateFilter(queryer, ctx, object, "title", nil, "", nil)
+	QueryFilter(queryer, ctx, &CrudObject{}, "#all", nil, "", nil, "", 0, 0, &[]*CrudObject{})
+	CountFilter(queryer, ctx, &CrudObject{}, "count(tid)#all", nil, "", nil, "", new(int64), "tid")
+	if _, err := ExecStmts(queryer, ctx, []Stmt{BuildDeleteWheref(object, ""), BuildDeleteWheref(object, "")}, false); err != nil {
+		t.Error(err)
+		return
+	}
+	records := recorder.Records()
+	if len(records) != 3 || recorder.Dropped() != 2 || recorder.Slowest() == nil {
+		t.Errorf("%v,%v", len(records), recorder.Dropped())
+		return
+	}
+	if records[0].Op != "exec" || records[0].SQL != "update crud_object set title=$1" || len(records[0].Args) != 1 || records[1].Op != "query" || records[2].Op != "queryRow" {
+		t.Errorf("%v,%v,%v", jsonString(records[0]), jsonString(records[1]), jsonString(records[2]))
+		return
+	}
+	if len(queryer.sqls) != 5 {
+		t.Error(queryer.sqls)
+		return
+	}
+}
+
+func TestCaptureSQLConcurrent(t *testing.T) {
+	queryer := &testLockedQueryer{}
+	ctx, recorder := CaptureSQL(context.Background())
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				recorder.SetMax(i + j)
+				ExecStmts(queryer, ctx, []Stmt{BuildDeleteWheref(&CrudObject{}, "")}, false)
+				recorder.Dropped()
+				recorder.Records()
+				recorder.Slowest()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(recorder.Records())+recorder.Dropped() != 80 {
+		t.Errorf("%v,%v", len(recorder.Records()), recorder.Dropped())
+		return
+	}
+}
+
+type testLockedQueryer struct {
+	testRecordQueryer
+	lock sync.Mutex
+}
+
+func (t *testLockedQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.testRecordQueryer.Exec(ctx, query, args...)
+}
+
+func TestStrictDest(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	filter := "tid,title,image#all"
+	var titles []string
+	var tid int64
+	var images []*string
+	var imageMap map[int64]*string
+	var objects []*CrudObject
+	//not strict
+	if err := c.Scan(&testValueRows{rows: 1}, &CrudObject{}, filter, &images, "image"); err != nil || len(images) != 0 {
+		t.Errorf("%v,%v", err, images)
+		return
+	}
+	c.StrictDest = true
+	//scalar with pattern
+	if err := c.Scan(&testValueRows{rows: 3}, &CrudObject{}, filter, &objects, &tid, "tid", &titles, "title"); err != nil || tid != 3 || len(titles) != 3 || len(objects) != 3 {
+		t.Errorf("%v,%v,%v", err, tid, titles)
+		return
+	}
+	//slice with pattern, image is only setted on even row
+	images = nil
+	if err := c.Scan(&testValueRows{rows: 3}, &CrudObject{}, filter, &images, "image"); err != nil || len(images) != 1 {
+		t.Errorf("%v,%v", err, images)
+		return
+	}
+	err := c.Scan(&testValueRows{rows: 1}, &CrudObject{}, filter, &titles, "title", &images, "image")
+	if err == nil || !strings.Contains(err.Error(), "dests[2](*[]*string)") || !strings.Contains(err.Error(), "try pattern image#all") {
+		t.Error(err)
+		return
+	}
+	images = nil
+	if err := c.Scan(&testValueRows{rows: 1}, &CrudObject{}, filter, &images, "image#all"); err != nil || len(images) != 1 {
+		t.Errorf("%v,%v", err, images)
+		return
+	}
+	//map with pattern
+	if err := c.Scan(&testValueRows{rows: 2}, &CrudObject{}, filter, &imageMap, "tid:image"); err != nil || len(imageMap) != 2 {
+		t.Errorf("%v,%v", err, imageMap)
+		return
+	}
+	//scalar by row
+	err = c.ScanRow(&testValueRows{index: 1}, &CrudObject{}, filter, &tid, "tid", &tid, "int64_value")
+	if err == nil || !strings.Contains(err.Error(), "dests[2](*int64)") || strings.Contains(err.Error(), "dests[0]") {
+		t.Error(err)
+		return
+	}
+	//no rows is not checked
+	if err := c.Scan(&testValueRows{rows: 0}, &CrudObject{}, filter, &images, "image"); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestMetaField(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	queryer := &testRecordQueryer{}
+	ctx := context.Background()
+	var maxID int64
+	err := c.QueryRow(queryer, ctx, MetaWith("crud_object", Int64Field("max(tid)")), "max(tid)", "select max(tid) from crud_object", nil, &maxID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//name is used when filter is empty
+	table, fields := c.QueryField(MetaWith("crud_object", Int64Field("max(tid)"), StringField("title"), TimeField("max(update_time)")), "#all")
+	if table != "crud_object" || strings.Join(fields, ",") != "max(tid),title,max(update_time)" {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	//filter is used first
+	table, fields = c.QueryField(MetaWith("crud_object", Int64Field("max(tid)")), "o.count(tid)")
+	if table != "crud_object o" || strings.Join(fields, ",") != "count(o.tid)" {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	meta := MetaWith("crud_object", Int64Field("max(tid)"), StringField("title"))
+	var ids []int64
+	var titles map[int64]string
+	err = c.Scan(&testValueRows{rows: 3}, meta, "", &ids, "tid", &titles, "tid:title")
+	if err != nil || len(ids) != 3 || ids[2] != 3 || len(titles) != 3 || titles[3] != "title3" {
+		t.Errorf("%v,%v,%v", err, ids, titles)
+		return
+	}
+	//single meta value to dest without pattern
+	maxID, ids = 0, nil
+	meta = MetaWith("crud_object", Int64Field("max(tid)"))
+	if err = c.ScanRow(&testValueRows{index: 5}, meta, "", &maxID); err != nil || maxID != 5 {
+		t.Errorf("%v,%v", err, maxID)
+		return
+	}
+	if err = c.Scan(&testValueRows{rows: 2}, meta, "", &ids); err != nil || len(ids) != 2 {
+		t.Errorf("%v,%v", err, ids)
+		return
+	}
+	var title string
+	if err = c.ScanRow(&testValueRows{index: 5}, MetaWith("crud_object", StringField("title")), "", &title); err != nil || title != "title5" {
+		t.Errorf("%v,%v", err, title)
+		return
+	}
+	var updateTime xsql.Time
+	if err = c.ScanRow(&testValueRows{index: 5}, MetaWith("crud_object", TimeField("update_time")), "", &updateTime); err != nil {
+		t.Errorf("%v,%v", err, updateTime)
+		return
+	}
+}
+
+func TestLogCaller(t *testing.T) {
+	type logRecord struct {
+		file   string
+		line   int
+		format string
+		ctx    context.Context
+	}
+	type logKey struct{}
+	var records []logRecord
+	oldLog := Default.Log
+	defer func() {
+		Default.Log = oldLog
+		Default.LogCtx = nil
+	}()
+	Default.Log = func(caller int, format string, args ...interface{}) {
+		_, file, line, _ := runtime.Caller(caller + 2)
+		records = append(records, logRecord{file: file, line: line, format: format})
+	}
+	logCtx := func(ctx context.Context, caller int, format string, args ...interface{}) {
+		_, file, line, _ := runtime.Caller(caller + 2)
+		records = append(records, logRecord{file: file, line: line, format: format, ctx: ctx})
+	}
+	queryer := &testRecordQueryer{}
+	ctx := context.WithValue(context.Background(), logKey{}, "marked")
+	object := &CrudObject{TID: 1, Title: "title", Status: CrudObjectStatusNormal}
+	search := &SearchCrudObjectUnifySkip{}
+	search.Where.UserID = 100
+	search.Query.Enabled = true
+	search.Count.Enabled = true
+	calls := map[string]func(){
+		"InsertArgs":         func() { InsertArgs(object, "title", nil) },
+		"InsertSQLReturning": func() { InsertSQLReturning(object, "title", "tid#all") },
+		"UpdateArgs":         func() { UpdateArgs(object, "title", nil) },
+		"UpdateSQL":          func() { UpdateSQL(object, "title", nil) },
+		"UpdateSQLReturning": func() { UpdateSQLReturning(object, "title", nil, "", nil, "tid#all") },
+		"QueryField":         func() { QueryField(object, "#all") },
+		"JoinWhere":          func() { JoinWhere("select 1", []string{"tid=$1"}, "and") },
+		"JoinWheref":         func() { JoinWheref("select 1", nil, "tid=$%v", 1) },
+		"JoinWhereUnify":     func() { JoinWhereUnify("select 1", nil, search) },
+		"JoinPage":           func() { JoinPage("select 1", "order by tid", 0, 10) },
+		"JoinPageUnify":      func() { JoinPageUnify("select 1", search) },
+		"QueryUnifySQL":      func() { QueryUnifySQL(search, "Query") },
+		"CountUnifySQL":      func() { CountUnifySQL(search) },
+		"BuildInsert":        func() { BuildInsert(object, "title") },
+		"BuildUpdateWheref":  func() { BuildUpdateWheref(object, "title", "tid=$%v", 1) },
+		"InsertFilter":       func() { InsertFilter(queryer, ctx, object, "title,status", "returning", "tid#all") },
+		"BulkInsertFilter":   func() { BulkInsertFilter(queryer, ctx, []*CrudObject{object}, "title,status") },
+		"UpsertFilter":       func() { UpsertFilter(queryer, ctx, object, "title,status", "tid", "title", "returning", "tid#all") },
+		"Update":             func() { Update(queryer, ctx, object, "update crud_object set title=$1", nil, "", []interface{}{"a"}) },
+		"UpdateRow": func() {
+			UpdateRow(queryer, ctx, object, "update crud_object set title=$1", nil, "", []interface{}{"a"})
+		},
+		"UpdateSet":       func() { UpdateSet(queryer, ctx, object, []string{"title=$1"}, nil, "", []interface{}{"a"}) },
+		"UpdateRowSet":    func() { UpdateRowSet(queryer, ctx, object, []string{"title=$1"}, nil, "", []interface{}{"a"}) },
+		"UpdateFilter":    func() { UpdateFilter(queryer, ctx, object, "title", nil, "", nil) },
+		"UpdateRowFilter": func() { UpdateRowFilter(queryer, ctx, object, "title", nil, "", nil) },
+		"UpdateWheref":    func() { UpdateWheref(queryer, ctx, object, "title", "tid=$%v", 1) },
+		"UpdateRowWheref": func() { UpdateRowWheref(queryer, ctx, object, "title", "tid=$%v", 1) },
+		"UpdateWhereIn":   func() { UpdateWhereIn(queryer, ctx, object, "title", nil, "", nil, "tid", []int64{1}) },
+		"Query": func() {
+			Query(queryer, ctx, &CrudObject{}, "#all", "select tid from crud_object", nil, &[]*CrudObject{})
+		},
+		"QueryFilter": func() { QueryFilter(queryer, ctx, &CrudObject{}, "#all", nil, "", nil, "", 0, 0, &[]*CrudObject{}) },
+		"QueryWheref": func() {
+			QueryWheref(queryer, ctx, &CrudObject{}, "#all", "tid=$%v", []interface{}{1}, "", 0, 10, &[]*CrudObject{})
+		},
+		"QueryUnify":       func() { QueryUnify(queryer, ctx, search) },
+		"QueryUnifyTarget": func() { QueryUnifyTarget(queryer, ctx, search, "Query") },
+		"QueryRow": func() {
+			QueryRow(queryer, ctx, &CrudObject{}, "#all", "select tid from crud_object", nil, &CrudObject{})
+		},
+		"QueryRowFilter": func() { QueryRowFilter(queryer, ctx, &CrudObject{}, "#all", nil, "", nil, &CrudObject{}) },
+		"QueryRowWheref": func() {
+			QueryRowWheref(queryer, ctx, &CrudObject{}, "#all", "tid=$%v", []interface{}{1}, &CrudObject{})
+		},
+		"Count": func() {
+			Count(queryer, ctx, &CrudObject{}, "count(tid)#all", "select count(tid) from crud_object", nil, new(int64), "tid")
+		},
+		"CountFilter": func() {
+			CountFilter(queryer, ctx, &CrudObject{}, "count(tid)#all", nil, "", nil, "", new(int64), "tid")
+		},
+		"CountWheref": func() {
+			CountWheref(queryer, ctx, &CrudObject{}, "count(tid)#all", "tid=$%v", []interface{}{1}, "", new(int64), "tid")
+		},
+		"CountUnify":       func() { CountUnify(queryer, ctx, search) },
+		"CountUnifyTarget": func() { CountUnifyTarget(queryer, ctx, search, "ApplyCount") },
+		"ApplyUnify":       func() { ApplyUnify(queryer, ctx, search) },
+		"ExecStmts":        func() { ExecStmts(queryer, ctx, []Stmt{BuildDeleteWheref(object, "tid=$%v", 1)}, false) },
+		"FindWheref":       func() { FindWheref[CrudObject](queryer, ctx, "#all", "tid=$%v", 1) },
+		"FindFilter":       func() { FindFilter[CrudObject](queryer, ctx, "#all", nil, "", nil) },
+		"Wheref.Query":     func() { NewWheref("tid=$%v", 1).Query(queryer, ctx, &CrudObject{}, "#all", "", 0, 0, &[]*CrudObject{}) },
+		"Wheref.QueryRow":  func() { NewWheref("tid=$%v", 1).QueryRow(queryer, ctx, &CrudObject{}, "#all", &CrudObject{}) },
+		"Wheref.Count": func() {
+			NewWheref("tid=$%v", 1).Count(queryer, ctx, &CrudObject{}, "count(tid)#all", new(int64), "tid")
+		},
+		"InsertSQL": func() { InsertSQL(object, "title", "") },
+		"QuerySQL":  func() { QuerySQL(&CrudObject{}, "#all") },
+		"CountSQL":  func() { CountSQL(&CrudObject{}, "count(tid)#all") },
+		"Default.QueryFilter": func() {
+			Default.QueryFilter(queryer, ctx, &CrudObject{}, "#all", nil, "", nil, "", 0, 0, &[]*CrudObject{})
+		},
+		"Default.UpdateRowWheref": func() {
+			Default.UpdateRowWheref(queryer, ctx, object, "title", "tid=$%v", 1)
+		},
+	}
+	//the sql builders without ctx is logging with context.Background()
+	builders := map[string]bool{}
+	for _, name := range []string{
+		"InsertArgs", "InsertSQLReturning", "UpdateArgs", "UpdateSQL", "UpdateSQLReturning", "QueryField",
+		"JoinWhere", "JoinWheref", "JoinWhereUnify", "JoinPage", "JoinPageUnify", "QueryUnifySQL", "CountUnifySQL",
+		"BuildInsert", "BuildUpdateWheref", "ExecStmts", "InsertSQL", "QuerySQL", "CountSQL",
+	} {
+		builders[name] = true
+	}
+	for _, withCtx := range []bool{false, true} {
+		if withCtx {
+			Default.LogCtx = logCtx
+		}
+		for name, call := range calls {
+			records = nil
+			call()
+			if len(records) < 1 {
+				t.Errorf("%v not log", name)
+				continue
+			}
+			for _, record := range records {
+				if !strings.HasSuffix(record.file, "/crud_test.go") {
+					t.Errorf("%v log %v by %v:%v", name, record.format, record.file, record.line)
+				}
+				if withCtx && record.ctx == nil {
+					t.Errorf("%v log %v without ctx", name, record.format)
+				}
+			}
+			if !withCtx || builders[name] {
+				continue
+			}
+			for _, record := range records {
+				if record.ctx.Value(logKey{}) != "marked" {
+					t.Errorf("%v log %v without marked ctx", name, record.format)
+				}
+			}
+		}
+	}
+}
+
+func TestJoinWhereUnifySections(t *testing.T) {
+	type searchSections struct {
+		Model CrudObject `json:"model"`
+		Where struct {
+			UserID int64 `json:"user_id"`
+			Type   int   `json:"type"`
+		} `json:"where" join:"and"`
+		Key struct {
+			Title string `json:"title" cmp:"title like $%v"`
+			Data  string `json:"data" cmp:"data like $%v"`
+		} `json:"key" join:"or"`
+	}
+	search := &searchSections{}
+	search.Where.UserID = 100
+	search.Where.Type = 1
+	search.Key.Title = "%a%"
+	search.Key.Data = "%a%"
+	sql, args := JoinWhereUnify("select 1", nil, search)
+	if sql != "select 1 where user_id = $1 and type = $2" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	sql, args = JoinWhereUnify("select 1", nil, search, "Key")
+	if sql != "select 1 where title like $1 or data like $2" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	sql, args = Default.JoinWhereUnify("select 1", nil, search, "Where", "Key")
+	if sql != "select 1 where (user_id = $1 and type = $2) and (title like $3 or data like $4)" || len(args) != 4 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	search.Where.Type = 0
+	search.Key.Data = ""
+	sql, args = JoinWhereUnify("select 1", nil, search, "Where", "Key", "None")
+	if sql != "select 1 where (user_id = $1) and (title like $2)" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	search.Where.UserID = 0
+	sql, args = JoinWhereUnify("select 1", nil, search, "Where", "Key")
+	if sql != "select 1 where title like $1" || len(args) != 1 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	search.Key.Title = ""
+	sql, args = JoinWhereUnify("select 1", nil, search, "Where", "Key")
+	if sql != "select 1" || len(args) != 0 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	type searchOrSections struct {
+		Model CrudObject `json:"model" joinsections:"or"`
+		Where struct {
+			UserID int64 `json:"user_id"`
+			Type   int   `json:"type"`
+		} `json:"where" join:"and"`
+		Key struct {
+			Title string `json:"title" cmp:"title like $%v"`
+		} `json:"key"`
+	}
+	searchOr := &searchOrSections{}
+	searchOr.Where.UserID = 100
+	searchOr.Where.Type = 1
+	searchOr.Key.Title = "%a%"
+	sql, args = JoinWhereUnify("select 1", []interface{}{"x"}, searchOr, "Where", "Key")
+	if sql != "select 1 where (user_id = $2 and type = $3) or (title like $4)" || len(args) != 4 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+}
+
+func TestQueryJSONPath(t *testing.T) {
+	clearPG()
+	queryer := getPG()
+	ctx := context.Background()
+	object := newTestObject()
+	object.MapValue = xsql.M{"city": "shanghai", "addr": xsql.M{"zip": "200000"}}
+	_, err := InsertFilter(queryer, ctx, object, "^tid#all", "returning", "tid#all")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var found *jsonPathObject
+	err = QueryRowWheref(queryer, ctx, &jsonPathObject{}, "tid,city,zip#all", "tid=$%v", []interface{}{object.TID}, &found)
+	if err != nil || found.TID != object.TID || found.City != "shanghai" || found.Zip != "200000" {
+		t.Errorf("%v,%v", err, jsonString(found))
+		return
+	}
+	var city string
+	err = QueryRowWheref(queryer, ctx, MetaWith("crud_object", StringField("map_value->>city")), "#all", "tid=$%v", []interface{}{object.TID}, &city)
+	if err != nil || city != "shanghai" {
+		t.Errorf("%v,%v", err, city)
+		return
+	}
+	var cities []string
+	err = QueryWheref(queryer, ctx, &jsonPathObject{City: "shanghai"}, "city#all", "map_value->>'city'=$%v", []interface{}{"shanghai"}, "", 0, 0, &cities, "city")
+	if err != nil || len(cities) != 1 {
+		t.Errorf("%v,%v", err, cities)
+		return
+	}
+}
+
+func TestFilterWhereGroup(t *testing.T) {
+	type whereGroup struct {
+		_ struct{} `join:"or"`
+		A struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+		} `json:"a" join:"or"`
+		B struct {
+			X int `json:"x2" cmp:"x2>$%v"`
+			C struct {
+				Z int `json:"z"`
+				D struct {
+					W int `json:"w"`
+					V int `json:"v" cmp:"v<$%v"`
+				} `json:"d" join:"or"`
+			} `json:"c" join:"and"`
+		} `json:"b" join:"and"`
+		Status int `json:"status"`
+	}
+	where := &whereGroup{}
+	where.A.X, where.A.Y = 1, 2
+	where.B.X, where.B.C.Z, where.B.C.D.W, where.B.C.D.V = 3, 4, 5, 6
+	where.Status = 7
+	sqlWhere, args := FilterWhere([]interface{}{0}, where, "")
+	if len(sqlWhere) != 1 || sqlWhere[0] != "((x = $2 or y = $3) or (x2>$4 and (z = $5 and (w = $6 or v<$7))) or status = $8)" || len(args) != 8 {
+		t.Errorf("%v,%v", sqlWhere, args)
+		return
+	}
+	for i, arg := range args[1:] {
+		if *(arg.(*int)) != i+1 {
+			t.Errorf("%v,%v", i, arg)
+			return
+		}
+	}
+	//empty group is skipped
+	where = &whereGroup{}
+	where.B.C.D.V = 6
+	sqlWhere, args = FilterWhere(nil, where, "")
+	if len(sqlWhere) != 1 || sqlWhere[0] != "(((v<$1)))" || len(args) != 1 {
+		t.Errorf("%v,%v", sqlWhere, args)
+		return
+	}
+	where = &whereGroup{}
+	sqlWhere, args = FilterWhere(nil, where, "")
+	if len(sqlWhere) != 0 || len(args) != 0 {
+		t.Errorf("%v,%v", sqlWhere, args)
+		return
+	}
+	//group by _ join of nested struct
+	type whereNested struct {
+		Key struct {
+			_     struct{} `join:"or"`
+			Title string   `json:"title" cmp:"title like $%v"`
+			Data  string   `json:"data" cmp:"data like $%v"`
+		} `json:"key"`
+		UserID int64 `json:"user_id"`
+	}
+	nested := &whereNested{UserID: 1}
+	nested.Key.Title, nested.Key.Data = "a", "b"
+	sqlWhere, args = FilterWhere(nil, nested, "")
+	if strings.Join(sqlWhere, ",") != "(title like $1 or data like $2),user_id = $3" || len(args) != 3 {
+		t.Errorf("%v,%v", sqlWhere, args)
+		return
+	}
+}
+
+type testKeysQueryer struct {
+	testRecordQueryer
+	args []interface{}
+	rows int
+}
+
+func (t *testKeysQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows Rows, err error) {
+	t.sqls = append(t.sqls, query)
+	t.args = args
+	rows = &testValueRows{rows: t.rows}
+	return
+}
+
+func TestQueryByKeys(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	queryer := &testKeysQueryer{rows: 3}
+	objects := map[int64]*CrudObject{}
+	missing, err := c.QueryByKeys(queryer, context.Background(), &CrudObject{}, "tid,title#all", "tid", []int{1, 2, 5}, &objects)
+	if err != nil || len(objects) != 3 || objects[2].Title != "title2" || len(missing) != 1 || missing[0] != 5 {
+		t.Errorf("err is %v, objects is %v, missing is %v", err, objects, missing)
+		return
+	}
+	if sql := queryer.sqls[0]; !strings.HasSuffix(sql, "from crud_object where tid in ($1,$2,$3)") || len(queryer.args) != 3 {
+		t.Errorf("%v,%v", sql, queryer.args)
+		return
+	}
+	//split by MaxParams
+	c.MaxParams = 2
+	queryer = &testKeysQueryer{rows: 3}
+	objects = map[int64]*CrudObject{}
+	missing, err = c.QueryByKeys(queryer, context.Background(), &CrudObject{}, "tid,title#all", "tid", []int{1, 2, 3, 5, 6}, &objects)
+	if err != nil || len(objects) != 3 || objects[3].Title != "title3" || len(missing) != 2 || missing[0] != 5 || missing[1] != 6 {
+		t.Errorf("err is %v, objects is %v, missing is %v", err, objects, missing)
+		return
+	}
+	if len(queryer.sqls) != 3 || !strings.HasSuffix(queryer.sqls[0], "where tid in ($1,$2)") || !strings.HasSuffix(queryer.sqls[2], "where tid in ($1)") || len(queryer.args) != 1 {
+		t.Errorf("%v,%v", queryer.sqls, queryer.args)
+		return
+	}
+	c.MaxParams = 0
+	//empty keys
+	queryer = &testKeysQueryer{}
+	var titles map[int64]string
+	missing, err = QueryByKeys(queryer, context.Background(), &CrudObject{}, "tid,title#all", "tid", []int64{}, &titles)
+	if err != nil || len(missing) != 0 || len(queryer.sqls) != 0 {
+		t.Errorf("err is %v, missing is %v, sqls is %v", err, missing, queryer.sqls)
+		return
+	}
+	//all missing
+	missing, err = QueryByKeys(queryer, context.Background(), &CrudObject{}, "tid,title#all", "tid", []int64{7, 8}, &objects)
+	if err != nil || len(missing) != 2 || missing[0] != int64(7) {
+		t.Errorf("err is %v, missing is %v", err, missing)
+		return
+	}
+	//mysql
+	c.ArgFormat = "?"
+	where, args := c.AppendWhereIn([]string{"status=?"}, []interface{}{100}, "tid", []int64{1, 2})
+	if strings.Join(where, " and ") != "status=? and tid in (?,?)" || len(args) != 3 {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	if where, args = AppendWhereIn(nil, nil, "tid", nil); len(where) != 1 || where[0] != "1=0" || len(args) != 0 {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	//error
+	if _, err = QueryByKeys(queryer, context.Background(), &CrudObject{}, "#all", "tid", 1, &objects); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = QueryByKeys(queryer, context.Background(), &CrudObject{}, "#all", "tid", []int64{1}, objects); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = QueryByKeys(queryer, context.Background(), &CrudObject{}, "#all", "tid", []string{"a"}, &objects); err == nil {
+		t.Error("error")
+		return
+	}
+}
+
+func TestSkipField(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.ParmConv = func(on, fieldName, fieldFunc string, field reflect.StructField, value interface{}) interface{} {
+		if fieldName == "title" || strings.HasPrefix(fieldName, "title") {
+			return SkipField
+		}
+		if v, ok := value.(int64); ok && v < 0 {
+			return SkipField
+		}
+		return value
+	}
+	object := &CrudObject{UserID: 100, Title: "title", Status: CrudObjectStatusNormal}
+	if sql, args, err := c.InsertSQL(object, "user_id,title,status"); err != nil || strings.TrimSpace(sql) != "insert into crud_object(user_id,status) values($1,$2)" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	if sql, args, err := c.UpdateSQL(object, "user_id,title,status", []interface{}{1}); err != nil || strings.TrimSpace(sql) != "update crud_object set user_id=$2,status=$3" || len(args) != 3 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	if where, args := c.FilterWhere(nil, object, "user_id,title,status"); strings.Join(where, " and ") != "user_id = $1 and status = $2" || len(args) != 2 {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	fields, param, args := c.AppendInsertf(nil, nil, nil, "user_id=$%v,title=$%v", int64(100), "title")
+	fields, param, args = c.AppendInsert(fields, param, args, true, "title=$%v", "title")
+	if strings.Join(fields, ",") != "user_id" || strings.Join(param, ",") != "$1" || len(args) != 1 {
+		t.Errorf("%v,%v,%v", fields, param, args)
+		return
+	}
+	sets, args := c.AppendSetf(nil, nil, "title=$%v,user_id=$%v", "title", int64(100))
+	sets, args = c.AppendSet(sets, args, true, "title=$%v", "title")
+	if strings.Join(sets, ",") != "user_id=$1" || len(args) != 1 {
+		t.Errorf("%v,%v", sets, args)
+		return
+	}
+	where, args := c.AppendWheref(nil, nil, "title=$%v,user_id=$%v", "title", int64(100))
+	where, args = c.AppendWhere(where, args, true, "title=$%v", "title")
+	where, args = c.AppendWhereIn(where, args, "tid", []int64{1, -1, 2})
+	if strings.Join(where, " and ") != "user_id=$1 and tid in ($2,$3)" || len(args) != 3 {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	if where, _ = c.AppendWhereIn(nil, nil, "tid", []int64{-1}); len(where) != 1 || where[0] != "1=0" {
+		t.Errorf("%v", where)
+		return
+	}
+	//scan
+	scanArgs := c.ScanArgs(object, "tid,title,status#all")
+	if len(scanArgs) != 3 || scanArgs[0] != &object.TID || scanArgs[2] != &object.Status {
+		t.Errorf("%v", scanArgs)
+		return
+	}
+	if _, ok := scanArgs[1].(*interface{}); !ok {
+		t.Errorf("%v", scanArgs)
+		return
+	}
+	var objects []*CrudObject
+	if err := c.Scan(&testValueRows{rows: 2}, &CrudObject{}, "tid,title#all", &objects); err != nil || len(objects) != 2 || objects[1].TID != 2 || len(objects[1].Title) > 0 {
+		t.Errorf("err is %v, objects is %v", err, converter.JSON(objects))
+		return
+	}
+}
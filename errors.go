@@ -0,0 +1,99 @@
+package crud
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrFilter is the kind of FilterError, it is used to check by errors.Is(err, ErrFilter)
+var ErrFilter = fmt.Errorf("filter error")
+
+// ErrDest is the kind of DestError, it is used to check by errors.Is(err, ErrDest)
+var ErrDest = fmt.Errorf("dest error")
+
+// FilterError is the error of filter misuse, it is returned by CheckFilter and Query/Insert/Update/Count entry points, it is panic by FilterFieldCall/ScanUnifyDest
+type FilterError struct {
+	Struct string // the type of v which filter is applied on
+	Filter string // the full filter string
+	Field  string // the fields not found, multi fields is joined by comma
+	Pos    int    // the byte offset of first Field in Filter, it is -1 when unknown
+	Hint   string // the detail of error
+}
+
+func newFilterError(v interface{}, filter, field, hint string) *FilterError {
+	pos := -1
+	if len(field) > 0 {
+		pos = filterFieldPos(filter, strings.SplitN(field, ",", 2)[0])
+	}
+	return &FilterError{Struct: fmt.Sprintf("%v", reflect.TypeOf(v)), Filter: filter, Field: field, Pos: pos, Hint: hint}
+}
+
+func (e *FilterError) Error() string {
+	parts := []string{}
+	if len(e.Filter) > 0 {
+		parts = append(parts, "filter "+e.Filter)
+	}
+	if len(e.Field) > 0 {
+		parts = append(parts, "fields "+e.Field)
+	}
+	parts = append(parts, e.Hint)
+	if len(e.Struct) > 0 {
+		parts = append(parts, "on "+e.Struct)
+	}
+	if e.Pos >= 0 {
+		parts = append(parts, fmt.Sprintf("at %v", e.Pos))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e *FilterError) Is(target error) bool {
+	return target == ErrFilter
+}
+
+// DestError is the error of scan dest misuse, it is returned by Scan/ScanRow when dest can not be setted
+type DestError struct {
+	Index    int    // the index of dest in dests, it is -1 when not related to one dest
+	Pattern  string // the pattern string after dest
+	WantType string // the dest type
+	GotType  string // the value type to set
+	Hint     string // the detail of error
+}
+
+func (e *DestError) Error() string {
+	msg := "dest"
+	if e.Index >= 0 {
+		msg += fmt.Sprintf("[%v]", e.Index)
+	}
+	if len(e.Pattern) > 0 {
+		msg += " pattern " + e.Pattern
+	}
+	msg += " " + e.Hint
+	if len(e.WantType) > 0 || len(e.GotType) > 0 {
+		msg += fmt.Sprintf(" to set %v=>%v", e.GotType, e.WantType)
+	}
+	return msg
+}
+
+func (e *DestError) Is(target error) bool {
+	return target == ErrDest
+}
+
+// filterFieldPos will return the byte offset of field item in filter, the field is matched by delimiter of filter syntax
+func filterFieldPos(filter, field string) int {
+	if len(field) < 1 {
+		return -1
+	}
+	for offset := 0; offset < len(filter); {
+		index := strings.Index(filter[offset:], field)
+		if index < 0 {
+			break
+		}
+		start, end := offset+index, offset+index+len(field)
+		if (start == 0 || strings.ContainsRune(",.^(|* ", rune(filter[start-1]))) && (end == len(filter) || strings.ContainsRune(",)#| ", rune(filter[end]))) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
@@ -0,0 +1,107 @@
+package crud
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFilterError(t *testing.T) {
+	recoverError := func(call func()) (err error) {
+		defer func() {
+			if perr := recover(); perr != nil {
+				err, _ = perr.(error)
+			}
+		}()
+		call()
+		return
+	}
+	var testCases = []struct {
+		Call    func() error
+		Message string
+		Pos     int
+	}{
+		{
+			Call:    func() error { return CheckFilter(&CrudObject{}, "tid,xx#all") },
+			Message: "filter tid,xx#all fields xx is not found on *crud.CrudObject at 4",
+			Pos:     4,
+		},
+		{
+			Call:    func() error { return CheckFilter(&CrudObject{}, "tid#all|o.count(yy),zz") },
+			Message: "filter tid#all|o.count(yy),zz fields yy,zz is not found on *crud.CrudObject at 16",
+			Pos:     16,
+		},
+		{
+			Call:    func() error { return CheckFilter(&CrudObject{}, "o.^tid#only") },
+			Message: "filter o.^tid#only #only is not supported with ^ exclusion on *crud.CrudObject at 2",
+			Pos:     2,
+		},
+		{
+			Call:    func() error { return CheckFilter(&CrudObject{}, "#only") },
+			Message: "filter #only #only fields is empty on *crud.CrudObject",
+			Pos:     -1,
+		},
+		{
+			Call: func() error {
+				return recoverError(func() { FilterFieldCall("test", &CrudObject{}, "tid,xx#only", nil) })
+			},
+			Message: "filter tid,xx#only fields xx is not found on *crud.CrudObject at 4",
+			Pos:     4,
+		},
+		{
+			Call: func() error {
+				return recoverError(func() {
+					QuerySQL([]interface{}{TableName("crud_object"), int64(1), "title"}, "tid")
+				})
+			},
+			Message: "filter tid meta v[1] is not found on []interface {}",
+			Pos:     -1,
+		},
+		{
+			Call: func() error {
+				return recoverError(func() { ScanUnifyDest(&SearchCrudObjectUnify{}, "Xxx") })
+			},
+			Message: "fields Xxx unify target is not exists on *crud.SearchCrudObjectUnify",
+			Pos:     -1,
+		},
+	}
+	for i, testCase := range testCases {
+		err := testCase.Call()
+		var filterErr *FilterError
+		if err == nil || err.Error() != testCase.Message || !errors.Is(err, ErrFilter) || errors.Is(err, ErrDest) || !errors.As(err, &filterErr) || filterErr.Pos != testCase.Pos {
+			t.Errorf("%v: err is %v, expect %v", i, err, testCase.Message)
+			return
+		}
+	}
+}
+
+func TestDestError(t *testing.T) {
+	scan := func(dest ...interface{}) error {
+		return Scan(&testValueRows{rows: 1}, &CrudObject{}, "tid,title#all", dest...)
+	}
+	var objectMap map[int64]*CrudObject
+	var titleMap map[string]*CrudObject
+	var values []map[string]int64
+	var title int64
+	var testCases = []struct {
+		Err     error
+		Message string
+		Index   int
+	}{
+		{Err: scan(), Message: "dest is empty", Index: -1},
+		{Err: scan(&objectMap), Message: "dest[0] pattern is not setted", Index: 0},
+		{Err: scan(&title, "tid", &objectMap, 1), Message: "dest[2] pattern is not string", Index: 2},
+		{Err: scan(&objectMap, ""), Message: "dest[0] pattern is empty", Index: 0},
+		{Err: scan(&objectMap, "xx"), Message: "dest[0] pattern xx field xx is not exists on crud.CrudObject", Index: 0},
+		{Err: scan(&titleMap, "tid"), Message: "dest[0] pattern tid key is not supported to set int64=>string", Index: 0},
+		{Err: scan(&title, "title"), Message: "dest[0] pattern title is not supported to set string=>int64", Index: 0},
+		{Err: scan(&values, "title"), Message: "dest[0] pattern title value title is not supported to set string=>int64", Index: 0},
+	}
+	for i, testCase := range testCases {
+		var destErr *DestError
+		if err := testCase.Err; err == nil || err.Error() != testCase.Message || !errors.Is(err, ErrDest) || !errors.As(fmt.Errorf("wrap %w", err), &destErr) || destErr.Index != testCase.Index {
+			t.Errorf("%v: err is %v, expect %v", i, err, testCase.Message)
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// FindWheref will query one row of T by where formats and return the scanned value, it return ErrNoRows when not found
+func FindWheref[T any](queryer interface{}, ctx context.Context, filter, formats string, args ...interface{}) (value *T, err error) {
+	value, err = findWheref[T](Default, 1, queryer, ctx, filter, formats, args...)
+	return
+}
+
+// FindWherefWith is FindWheref on custom CRUD
+func FindWherefWith[T any](c *CRUD, queryer interface{}, ctx context.Context, filter, formats string, args ...interface{}) (value *T, err error) {
+	value, err = findWheref[T](c, 1, queryer, ctx, filter, formats, args...)
+	return
+}
+
+func findWheref[T any](c *CRUD, caller int, queryer interface{}, ctx context.Context, filter, formats string, args ...interface{}) (value *T, err error) {
+	err = c.queryRowWheref(caller+1, queryer, ctx, new(T), filter, formats, args, &value)
+	if err != nil {
+		value, err = nil, c.normalizeNoRows(err)
+	}
+	return
+}
+
+// FindFilter will query one row of T by where and return the scanned value, it return ErrNoRows when not found
+func FindFilter[T any](queryer interface{}, ctx context.Context, filter string, where []string, sep string, args []interface{}) (value *T, err error) {
+	value, err = findFilter[T](Default, 1, queryer, ctx, filter, where, sep, args)
+	return
+}
+
+// FindFilterWith is FindFilter on custom CRUD
+func FindFilterWith[T any](c *CRUD, queryer interface{}, ctx context.Context, filter string, where []string, sep string, args []interface{}) (value *T, err error) {
+	value, err = findFilter[T](c, 1, queryer, ctx, filter, where, sep, args)
+	return
+}
+
+func findFilter[T any](c *CRUD, caller int, queryer interface{}, ctx context.Context, filter string, where []string, sep string, args []interface{}) (value *T, err error) {
+	err = c.queryRowFilter(caller+1, queryer, ctx, new(T), filter, where, sep, args, &value)
+	if err != nil {
+		value, err = nil, c.normalizeNoRows(err)
+	}
+	return
+}
+
+// normalizeNoRows will convert CRUD.ErrNoRows and driver no rows error in NoRowsErrors to CRUD.ErrNoRows
+func (c *CRUD) normalizeNoRows(err error) error {
+	noRows := c.getErrNoRows()
+	if errors.Is(err, noRows) {
+		return noRows
+	}
+	for _, driverNoRows := range NoRowsErrors {
+		if errors.Is(err, driverNoRows) {
+			return noRows
+		}
+	}
+	return err
+}
+
+var columnIdentRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// QueryColumn will query one column of table by where formats and return the scanned values, the table and column must be identifier like name or schema.name.
+// The NULL value is skipped when T is pointer, it is error by driver when T is not pointer
+func QueryColumn[T any](queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []T, err error) {
+	values, err = queryColumn[T](Default, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
+
+// QueryColumnWith is QueryColumn on custom CRUD
+func QueryColumnWith[T any](c *CRUD, queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []T, err error) {
+	values, err = queryColumn[T](c, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
+
+func queryColumn[T any](c *CRUD, caller int, queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []T, err error) {
+	if !columnIdentRegexp.MatchString(table) || !columnIdentRegexp.MatchString(column) {
+		err = fmt.Errorf("table %v or column %v is not valid identifier", table, column)
+		return
+	}
+	c = c.withContext(ctx)
+	sql := fmt.Sprintf("select %v from %v", c.QuoteIdent(column), c.QuoteIdent(c.TablePrefix+table))
+	sql, sqlArgs := c.joinWheref(caller+1, ctx, sql, nil, formats, args...)
+	rows, err := c.queryerQuery(queryer, ctx, sql, sqlArgs)
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD query column by sql:%v,args:%v result is fail:%v", sql, jsonString(sqlArgs), err)
+		}
+		return
+	}
+	defer rows.Close()
+	isPtr := reflect.TypeOf((*T)(nil)).Elem().Kind() == reflect.Ptr
+	for rows.Next() {
+		var value T
+		if err = rows.Scan(&value); err != nil {
+			break
+		}
+		if isPtr && reflect.ValueOf(&value).Elem().IsNil() {
+			continue
+		}
+		values = append(values, value)
+	}
+	if rowsErr, ok := rows.(RowsErr); ok && err == nil {
+		err = rowsErr.Err()
+	}
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD query column by sql:%v,args:%v result is %v values, err:%v", sql, jsonString(sqlArgs), len(values), err)
+	}
+	return
+}
+
+func QueryInt64Column(queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []int64, err error) {
+	values, err = queryColumn[int64](Default, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
+
+// QueryInt64Column is QueryColumn[int64]
+func (c *CRUD) QueryInt64Column(queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []int64, err error) {
+	values, err = queryColumn[int64](c, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
+
+func QueryStringColumn(queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []string, err error) {
+	values, err = queryColumn[string](Default, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
+
+// QueryStringColumn is QueryColumn[string]
+func (c *CRUD) QueryStringColumn(queryer interface{}, ctx context.Context, table, column, formats string, args ...interface{}) (values []string, err error) {
+	values, err = queryColumn[string](c, 1, queryer, ctx, table, column, formats, args...)
+	return
+}
@@ -0,0 +1,166 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type testFindQueryer struct {
+	testRecordQueryer
+	found  bool
+	noRows error
+}
+
+func (t *testFindQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row Row) {
+	t.sqls = append(t.sqls, query)
+	if t.found {
+		row = &testValueRows{index: 1}
+	} else if t.noRows != nil {
+		row = &errRow{err: t.noRows}
+	} else {
+		row = &errRow{err: ErrNoRows}
+	}
+	return
+}
+
+func TestFind(t *testing.T) {
+	ctx := context.Background()
+	queryer := &testFindQueryer{found: true}
+	object, err := FindWheref[CrudObject](queryer, ctx, "tid,title#all", "tid=$%v", 1)
+	if err != nil || object == nil || object.TID != 1 || object.Title != "title1" {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	if sql := queryer.sqls[len(queryer.sqls)-1]; !strings.HasSuffix(sql, "from crud_object where tid=$1") {
+		t.Error(sql)
+		return
+	}
+	object, err = FindFilter[CrudObject](queryer, ctx, "tid,title#all", []string{"tid=$1"}, "and", []interface{}{1})
+	if err != nil || object == nil || object.TID != 1 {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	queryer.found = false
+	object, err = FindWheref[CrudObject](queryer, ctx, "tid,title#all", "tid=$%v", 1)
+	if err != ErrNoRows || object != nil {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.ErrNoRows = fmt.Errorf("not found")
+	object, err = FindWherefWith[CrudObject](c, queryer, ctx, "tid,title#all", "tid=$%v", 1)
+	if err != c.ErrNoRows || object != nil {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	object, err = FindFilterWith[CrudObject](c, queryer, ctx, "tid,title#all", nil, "", nil)
+	if err != c.ErrNoRows || object != nil {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	//driver no rows
+	driverNoRows := fmt.Errorf("no rows in result set")
+	oldNoRows := NoRowsErrors
+	NoRowsErrors = append(NoRowsErrors, driverNoRows)
+	defer func() {
+		NoRowsErrors = oldNoRows
+	}()
+	queryer.noRows = fmt.Errorf("query fail with %w", driverNoRows)
+	object, err = FindWheref[CrudObject](queryer, ctx, "tid,title#all", "tid=$%v", 1)
+	if err != ErrNoRows || object != nil {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	object, err = FindWherefWith[CrudObject](c, queryer, ctx, "tid,title#all", "tid=$%v", 1)
+	if err != c.ErrNoRows || object != nil {
+		t.Errorf("%v,%v", err, object)
+		return
+	}
+	queryer.noRows = fmt.Errorf("other")
+	if _, err = FindWheref[CrudObject](queryer, ctx, "tid,title#all", "tid=$%v", 1); err != queryer.noRows {
+		t.Error(err)
+		return
+	}
+}
+
+func TestQueryColumn(t *testing.T) {
+	ctx := context.Background()
+	queryer := &testKeysQueryer{rows: 3}
+	ids, err := QueryColumn[int64](queryer, ctx, "crud_object", "tid", "user_id=$%v,type=$%v", int64(100), "")
+	if err != nil || len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("%v,%v", err, ids)
+		return
+	}
+	if sql := queryer.sqls[0]; sql != "select tid from crud_object where user_id=$1" || len(queryer.args) != 1 {
+		t.Errorf("%v,%v", sql, queryer.args)
+		return
+	}
+	images, err := QueryColumn[*string](queryer, ctx, "public.crud_object", "image", "")
+	if err != nil || len(images) != 1 || *images[0] != "image2" {
+		t.Errorf("%v,%v", err, images)
+		return
+	}
+	if sql := queryer.sqls[1]; sql != "select image from public.crud_object" {
+		t.Error(sql)
+		return
+	}
+	titles, err := QueryStringColumn(queryer, ctx, "crud_object", "title", "")
+	if err != nil || len(titles) != 3 || titles[0] != "title1" {
+		t.Errorf("%v,%v", err, titles)
+		return
+	}
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.TablePrefix = "t0_"
+	c.QuoteIdents = true
+	if ids, err = c.QueryInt64Column(queryer, ctx, "crud_object", "user", ""); err != nil || len(ids) != 3 || queryer.sqls[3] != `select "user" from t0_crud_object` {
+		t.Errorf("%v,%v,%v", err, ids, queryer.sqls[3])
+		return
+	}
+	if titles, err = c.QueryStringColumn(queryer, ctx, "crud_object", "title", ""); err != nil || len(titles) != 3 {
+		t.Errorf("%v,%v", err, titles)
+		return
+	}
+	if ids, err = QueryInt64Column(queryer, ctx, "crud_object", "tid", ""); err != nil || len(ids) != 3 {
+		t.Errorf("%v,%v", err, ids)
+		return
+	}
+	if ids, err = QueryColumnWith[int64](c, &testKeysQueryer{}, ctx, "crud_object", "tid", ""); err != nil || len(ids) != 0 {
+		t.Errorf("%v,%v", err, ids)
+		return
+	}
+	//error
+	for _, ident := range [][]string{{"crud_object;drop", "tid"}, {"crud_object", "tid from x"}, {"", "tid"}, {"a.b.c", "tid"}} {
+		if _, err = QueryColumn[int64](queryer, ctx, ident[0], ident[1], ""); err == nil {
+			t.Errorf("%v is not error", ident)
+			return
+		}
+	}
+	if _, err = QueryColumn[int64](&testColumnErrQueryer{queryErr: fmt.Errorf("query error")}, ctx, "crud_object", "tid", ""); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = QueryColumn[int64](&testColumnErrQueryer{}, ctx, "crud_object", "tid", ""); err == nil || err.Error() != "rows error" {
+		t.Error(err)
+		return
+	}
+}
+
+type testColumnErrQueryer struct {
+	testRecordQueryer
+	queryErr error
+}
+
+func (t *testColumnErrQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows Rows, err error) {
+	if t.queryErr != nil {
+		err = t.queryErr
+		return
+	}
+	rows = &testColumnsRows{err: fmt.Errorf("rows error")}
+	return
+}
@@ -0,0 +1,271 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/codingeasygo/util/xsql"
+)
+
+// YAMLUnmarshal is the yaml unmarshal func used by LoadConfig to load .yaml/.yml config,
+// it is not setted by default to keep gen without yaml dependence, it can be setted like gen.YAMLUnmarshal = yaml.Unmarshal
+var YAMLUnmarshal func(data []byte, v interface{}) error
+
+// AutoConfig is the serializable config of AutoGen, it is loaded from json/yaml file by LoadAutoConfig
+type AutoConfig struct {
+	Driver              string                       `json:"driver"`
+	DSN                 string                       `json:"dsn"`
+	TypeField           map[string]map[string]string `json:"type_field"`
+	ValidField          map[string]map[string]string `json:"valid_field"`
+	FieldFilter         map[string]map[string]string `json:"field_filter"`
+	CodeAddInit         map[string]string            `json:"code_add_init"`
+	CodeTestInit        map[string]string            `json:"code_test_init"`
+	CodeSlice           map[string]string            `json:"code_slice"`
+	Comments            map[string]map[string]string `json:"comments"`
+	TableGenAdd         xsql.StringArray             `json:"table_gen_add"`
+	TableRetAdd         map[string]string            `json:"table_ret_add"`
+	TableUpsert         map[string]string            `json:"table_upsert"`
+	TableNotValid       xsql.StringArray             `json:"table_not_valid"`
+	TableViewKey        map[string]string            `json:"table_view_key"`
+	ColumnExclude       map[string]xsql.StringArray  `json:"column_exclude"`
+	ColumnRename        map[string]map[string]string `json:"column_rename"`
+	JSONField           map[string]map[string]string `json:"json_field"`
+	AuditFields         map[string]string            `json:"audit_fields"`
+	TableInclude        xsql.StringArray             `json:"table_include"`
+	TableExclude        xsql.StringArray             `json:"table_exclude"`
+	TableNameType       string                       `json:"table_name_type"`
+	IncludeViews        bool                         `json:"include_views"`
+	TablesFromDDL       xsql.StringArray             `json:"tables_from_ddl"`
+	TableSQL            string                       `json:"table_sql"`
+	ColumnSQL           string                       `json:"column_sql"`
+	ColumnAllSQL        string                       `json:"column_all_sql"`
+	ForeignKeySQL       string                       `json:"foreign_key_sql"`
+	UniqueSQL           string                       `json:"unique_sql"`
+	CheckSQL            string                       `json:"check_sql"`
+	Schema              string                       `json:"schema"`
+	TypeMap             map[string][]string          `json:"type_map"`
+	Acronyms            xsql.StringArray             `json:"acronyms"`
+	SuffixAcronyms      map[string]string            `json:"suffix_acronyms"`
+	Singularize         bool                         `json:"singularize"`
+	Irregulars          map[string]string            `json:"irregulars"`
+	GetQueryer          string                       `json:"get_queryer"`
+	Out                 string                       `json:"out"`
+	OutPackage          string                       `json:"out_package"`
+	OutStructPre        string                       `json:"out_struct_pre"`
+	OutStructFile       string                       `json:"out_struct_file"`
+	OutDefinePre        string                       `json:"out_define_pre"`
+	OutDefineFile       string                       `json:"out_define_file"`
+	OutFuncPre          string                       `json:"out_func_pre"`
+	OutFuncCommon       string                       `json:"out_func_common"`
+	OutFuncFile         string                       `json:"out_func_file"`
+	OutTestPre          string                       `json:"out_test_pre"`
+	OutTestCommon       string                       `json:"out_test_common"`
+	OutTestFile         string                       `json:"out_test_file"`
+	ExtraImports        map[string][]string          `json:"extra_imports"`
+	OutJSONFile         string                       `json:"out_json_file"`
+	EnumHelpers         bool                         `json:"enum_helpers"`
+	DocComments         bool                         `json:"doc_comments"`
+	TestAgainstMock     bool                         `json:"test_against_mock"`
+	ApplyColumnDefaults bool                         `json:"apply_column_defaults"`
+	OutTSFile           string                       `json:"out_ts_file"`
+	TSInt64String       bool                         `json:"ts_int64_string"`
+	OutOpenAPIFile      string                       `json:"out_openapi_file"`
+	SplitPerTable       bool                         `json:"split_per_table"`
+	Force               bool                         `json:"force"`
+	DryRun              bool                         `json:"dry_run"`
+	TemplateDir         string                       `json:"template_dir"`
+	TemplateOverrides   map[string]string            `json:"template_overrides"`
+	Schemas             []SchemaConfig               `json:"schemas"`
+}
+
+// LoadConfig will load AutoGen from json/yaml config file, the Queryer should be setted by caller
+func LoadConfig(path string) (autoGen *AutoGen, err error) {
+	config, err := LoadAutoConfig(path)
+	if err == nil {
+		autoGen, err = config.AutoGen()
+	}
+	return
+}
+
+// LoadAutoConfig will load AutoConfig from json/yaml config file, the unknown key will return error
+func LoadAutoConfig(path string) (config *AutoConfig, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if YAMLUnmarshal == nil {
+			err = fmt.Errorf("load config %v fail with yaml is not supported, gen.YAMLUnmarshal is not setted", path)
+			return
+		}
+		var value interface{}
+		err = YAMLUnmarshal(data, &value)
+		if err != nil {
+			err = fmt.Errorf("load config %v fail with %v", path, err)
+			return
+		}
+		data, err = json.Marshal(yamlValue(value))
+		if err != nil {
+			err = fmt.Errorf("load config %v fail with %v", path, err)
+			return
+		}
+	}
+	config = &AutoConfig{}
+	decoder := json.NewDecoder(bytes.NewBuffer(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(config)
+	if err != nil {
+		err = fmt.Errorf("load config %v fail with %v", path, err)
+		config = nil
+	}
+	return
+}
+
+// yamlValue will convert map[interface{}]interface{} which is unmarshaled by yaml to map[string]interface{}
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, val := range v {
+			result[fmt.Sprintf("%v", key)] = yamlValue(val)
+		}
+		return result
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = yamlValue(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = yamlValue(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// AutoGen will create AutoGen by config and driver preset, the Queryer should be setted by caller
+func (c *AutoConfig) AutoGen() (autoGen *AutoGen, err error) {
+	autoGen = &AutoGen{
+		TypeField:           c.TypeField,
+		ValidField:          c.ValidField,
+		FieldFilter:         c.FieldFilter,
+		CodeAddInit:         c.CodeAddInit,
+		CodeTestInit:        c.CodeTestInit,
+		CodeSlice:           c.CodeSlice,
+		Comments:            c.Comments,
+		TableGenAdd:         c.TableGenAdd,
+		TableRetAdd:         c.TableRetAdd,
+		TableUpsert:         c.TableUpsert,
+		TableNotValid:       c.TableNotValid,
+		TableViewKey:        c.TableViewKey,
+		ColumnExclude:       c.ColumnExclude,
+		ColumnRename:        c.ColumnRename,
+		JSONField:           c.JSONField,
+		AuditFields:         c.AuditFields,
+		TableInclude:        c.TableInclude,
+		TableExclude:        c.TableExclude,
+		TableNameType:       c.TableNameType,
+		IncludeViews:        c.IncludeViews,
+		TablesFromDDL:       c.TablesFromDDL,
+		TableSQL:            c.TableSQL,
+		ColumnSQL:           c.ColumnSQL,
+		ColumnAllSQL:        c.ColumnAllSQL,
+		ForeignKeySQL:       c.ForeignKeySQL,
+		UniqueSQL:           c.UniqueSQL,
+		CheckSQL:            c.CheckSQL,
+		Schema:              c.Schema,
+		TypeMap:             map[string][]string{},
+		Acronyms:            c.Acronyms,
+		SuffixAcronyms:      c.SuffixAcronyms,
+		Singularize:         c.Singularize,
+		Irregulars:          c.Irregulars,
+		GetQueryer:          c.GetQueryer,
+		Out:                 c.Out,
+		OutPackage:          c.OutPackage,
+		OutStructPre:        c.OutStructPre,
+		OutStructFile:       c.OutStructFile,
+		OutDefinePre:        c.OutDefinePre,
+		OutDefineFile:       c.OutDefineFile,
+		OutFuncPre:          c.OutFuncPre,
+		OutFuncCommon:       c.OutFuncCommon,
+		OutFuncFile:         c.OutFuncFile,
+		OutTestPre:          c.OutTestPre,
+		OutTestCommon:       c.OutTestCommon,
+		OutTestFile:         c.OutTestFile,
+		ExtraImports:        c.ExtraImports,
+		OutJSONFile:         c.OutJSONFile,
+		EnumHelpers:         c.EnumHelpers,
+		DocComments:         c.DocComments,
+		TestAgainstMock:     c.TestAgainstMock,
+		ApplyColumnDefaults: c.ApplyColumnDefaults,
+		OutTSFile:           c.OutTSFile,
+		TSInt64String:       c.TSInt64String,
+		OutOpenAPIFile:      c.OutOpenAPIFile,
+		SplitPerTable:       c.SplitPerTable,
+		Force:               c.Force,
+		DryRun:              c.DryRun,
+		TemplateDir:         c.TemplateDir,
+		TemplateOverrides:   c.TemplateOverrides,
+		Schemas:             c.Schemas,
+	}
+	var tableSQL, columnSQL, columnAllSQL, foreignKeySQL, uniqueSQL, checkSQL, schema string
+	var typeMap map[string][]string
+	var codeSlice map[string]string
+	switch c.Driver {
+	case "pgx", "postgres":
+		tableSQL, columnSQL, columnAllSQL, foreignKeySQL, uniqueSQL, checkSQL, schema = TableSQLPG, ColumnSQLPG, ColumnAllSQLPG, ForeignKeySQLPG, UniqueSQLPG, CheckSQLPG, "public"
+		if c.IncludeViews {
+			tableSQL = TableViewSQLPG
+		}
+		typeMap, codeSlice = TypeMapPG, CodeSlicePG
+	case "sqlite3":
+		tableSQL, columnSQL, columnAllSQL, foreignKeySQL, uniqueSQL, checkSQL = TableSQLSQLITE, ColumnSQLSQLITE, ColumnAllSQLSQLITE, ForeignKeySQLSQLITE, UniqueSQLSQLITE, CheckSQLSQLITE
+		if c.IncludeViews {
+			tableSQL = TableViewSQLSQLITE
+		}
+		typeMap, codeSlice = TypeMapSQLITE, CodeSliceSQLITE
+	case "":
+	default:
+		err = fmt.Errorf("driver %v is not supported", c.Driver)
+		autoGen = nil
+		return
+	}
+	if len(autoGen.TableSQL) < 1 {
+		autoGen.TableSQL = tableSQL
+	}
+	if len(autoGen.ColumnSQL) < 1 {
+		autoGen.ColumnSQL = columnSQL
+	}
+	if len(autoGen.ColumnAllSQL) < 1 && len(c.ColumnSQL) < 1 {
+		autoGen.ColumnAllSQL = columnAllSQL
+	}
+	if len(autoGen.ForeignKeySQL) < 1 {
+		autoGen.ForeignKeySQL = foreignKeySQL
+	}
+	if len(autoGen.UniqueSQL) < 1 {
+		autoGen.UniqueSQL = uniqueSQL
+	}
+	if len(autoGen.CheckSQL) < 1 {
+		autoGen.CheckSQL = checkSQL
+	}
+	if len(autoGen.Schema) < 1 {
+		autoGen.Schema = schema
+	}
+	if autoGen.CodeSlice == nil {
+		autoGen.CodeSlice = codeSlice
+	}
+	for key, types := range typeMap {
+		autoGen.TypeMap[key] = types
+	}
+	for key, types := range c.TypeMap {
+		autoGen.TypeMap[key] = types
+	}
+	return
+}
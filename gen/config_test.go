@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "crudgen.json")
+	ioutil.WriteFile(configFile, []byte(`{
+		"driver": "sqlite3",
+		"type_field": {"crud_object": {"int_array": "xsql.IntArray"}},
+		"code_add_init": {"crud_object": "if ARG.Level < 1 {\n ARG.Level = 1\n}"},
+		"table_include": ["crud_object"],
+		"type_map": {"uuid": ["string", "*string"]},
+		"out": "./autogen/",
+		"out_package": "autogen"
+	}`), os.ModePerm)
+	autoGen, err := LoadConfig(configFile)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if autoGen.TableSQL != TableSQLSQLITE || autoGen.ColumnSQL != ColumnSQLSQLITE || autoGen.ForeignKeySQL != ForeignKeySQLSQLITE || len(autoGen.CodeSlice) < 1 {
+		t.Error("preset error")
+		return
+	}
+	if autoGen.TypeMap["integer"][0] != "int64" || autoGen.TypeMap["uuid"][0] != "string" || autoGen.TypeField["crud_object"]["int_array"] != "xsql.IntArray" {
+		t.Error("type error")
+		return
+	}
+	if !strings.Contains(autoGen.CodeAddInit["crud_object"], "ARG.Level = 1") || !autoGen.TableInclude.HavingOne("crud_object") {
+		t.Error("config error")
+		return
+	}
+	autoGen.Queryer = getSQLITE
+	autoGen.Out = dir
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil || !strings.Contains(string(files["auto_func.go"]), "func (crudObject *CrudObject) Insert(") {
+		t.Error(err)
+		return
+	}
+	//pg preset
+	pgFile := filepath.Join(dir, "pg.json")
+	ioutil.WriteFile(pgFile, []byte(`{"driver": "pgx", "include_views": true}`), os.ModePerm)
+	autoGen, err = LoadConfig(pgFile)
+	if err != nil || autoGen.TableSQL != TableViewSQLPG || autoGen.Schema != "public" {
+		t.Error(err)
+		return
+	}
+	//yaml
+	yamlFile := filepath.Join(dir, "crudgen.yaml")
+	ioutil.WriteFile(yamlFile, []byte(`{"driver": "sqlite3", "out": "./autogen/"}`), os.ModePerm)
+	_, err = LoadConfig(yamlFile)
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	YAMLUnmarshal = func(data []byte, v interface{}) error {
+		return json.Unmarshal(data, v)
+	}
+	defer func() {
+		YAMLUnmarshal = nil
+	}()
+	autoGen, err = LoadConfig(yamlFile)
+	if err != nil || autoGen.Out != "./autogen/" {
+		t.Error(err)
+		return
+	}
+	if v := yamlValue(map[interface{}]interface{}{"a": []interface{}{map[interface{}]interface{}{1: "x"}}}); v.(map[string]interface{})["a"].([]interface{})[0].(map[string]interface{})["1"] != "x" {
+		t.Error("yaml value error")
+		return
+	}
+	//error
+	for _, data := range []string{`{"driver": "sqlite3", "tabel_include": []}`, `{"driver": "xxx"}`, `{`} {
+		ioutil.WriteFile(configFile, []byte(data), os.ModePerm)
+		_, err = LoadConfig(configFile)
+		if err == nil {
+			t.Error(data)
+			return
+		}
+	}
+	_, err = LoadConfig(filepath.Join(dir, "none.json"))
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	ioutil.WriteFile(yamlFile, []byte(`{`), os.ModePerm)
+	_, err = LoadConfig(yamlFile)
+	if err == nil {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,624 @@
+package gen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+const ddlName = "(?:\"[^\"]+\"|`[^`]+`|[\\w$]+)(?:\\s*\\.\\s*(?:\"[^\"]+\"|`[^`]+`|[\\w$]+))*"
+
+var (
+	ddlCreateTableRegexp  = regexp.MustCompile(`(?is)^CREATE\s+(?:(?:GLOBAL|LOCAL)\s+)?(?:(?:TEMP|TEMPORARY|UNLOGGED)\s+)?TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + ddlName + `)\s*(\(.*)$`)
+	ddlCreateViewRegexp   = regexp.MustCompile(`(?is)^CREATE\s+(?:OR\s+REPLACE\s+)?(MATERIALIZED\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?(` + ddlName + `)\s+AS\s+\(?\s*SELECT\s+(.*?)\s+FROM\s+(` + ddlName + `)(?:\s.*)?$`)
+	ddlCommentRegexp      = regexp.MustCompile(`(?is)^COMMENT\s+ON\s+(COLUMN|TABLE)\s+(` + ddlName + `)\s+IS\s+'((?:[^']|'')*)'$`)
+	ddlAlterTableRegexp   = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(?:IF\s+EXISTS\s+)?(?:ONLY\s+)?(` + ddlName + `)\s+(.*)$`)
+	ddlAlterAddRegexp     = regexp.MustCompile(`(?is)^ADD\s+(.*)$`)
+	ddlAlterDefaultRegexp = regexp.MustCompile(`(?is)^ALTER\s+(?:COLUMN\s+)?(` + ddlName + `)\s+SET\s+DEFAULT\s+(.*)$`)
+	ddlUniqueIndexRegexp  = regexp.MustCompile(`(?is)^CREATE\s+UNIQUE\s+INDEX\s+(?:CONCURRENTLY\s+)?(?:IF\s+NOT\s+EXISTS\s+)?(` + ddlName + `)\s+ON\s+(?:ONLY\s+)?(` + ddlName + `)\s*(?:USING\s+\w+\s*)?\(([^()]*)\)\s*$`)
+	ddlIgnoreRegexp       = regexp.MustCompile(`(?is)^(DROP|SET|SELECT|BEGIN|COMMIT|START|PRAGMA|CREATE\s+(?:INDEX|SEQUENCE|EXTENSION|SCHEMA)|ALTER\s+SEQUENCE|ALTER\s+TABLE\s+.*\s+(?:DROP\s+DEFAULT|OWNER\s+TO\s+.*))\b`)
+	ddlSelectColumnRegexp = regexp.MustCompile(`(?is)^(?:` + ddlName + `\s*\.\s*)?("[^"]+"|` + "`[^`]+`" + `|[\w$]+|\*)(?:\s+(?:AS\s+)?("[^"]+"|` + "`[^`]+`" + `|[\w$]+))?$`)
+)
+
+// ddlKeywords is the keywords which is end of column type or default value
+var ddlKeywords = map[string]bool{
+	"CONSTRAINT": true, "NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true,
+	"REFERENCES": true, "COMMENT": true, "COLLATE": true, "GENERATED": true, "AUTO_INCREMENT": true, "AUTOINCREMENT": true, "ON": true,
+}
+
+// ParseDDLFiles will parse tables from ddl files, see ParseDDL
+func ParseDDLFiles(files ...string) (tables []*Table, warnings []string, err error) {
+	parser := &ddlParser{}
+	for _, file := range files {
+		var data []byte
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			return
+		}
+		parser.Parse(file, string(data))
+	}
+	tables, warnings = parser.Finish()
+	return
+}
+
+// ParseDDL will parse simple CREATE TABLE/VIEW, COMMENT ON, ALTER TABLE ADD/SET DEFAULT and CREATE UNIQUE INDEX statement of pg/mysql/sqlite to tables,
+// it is not full sql parser, the unsupported statement is skipped and returned by warnings
+func ParseDDL(ddl string) (tables []*Table, warnings []string) {
+	parser := &ddlParser{}
+	parser.Parse("ddl", ddl)
+	tables, warnings = parser.Finish()
+	return
+}
+
+type ddlParser struct {
+	tables   []*Table
+	warnings []string
+}
+
+func (p *ddlParser) warn(source string, line int, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf("%v:%v %v", source, line, fmt.Sprintf(format, args...)))
+}
+
+func (p *ddlParser) findTable(name string) *Table {
+	_, name = ddlTableName(name)
+	for _, table := range p.tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func (p *ddlParser) Parse(source, ddl string) {
+	for _, statement := range splitDDL(ddl) {
+		sql := statement.SQL
+		firstLine := strings.TrimSpace(strings.SplitN(sql, "\n", 2)[0])
+		var err error
+		switch {
+		case ddlCreateTableRegexp.MatchString(sql):
+			match := ddlCreateTableRegexp.FindStringSubmatch(sql)
+			err = p.parseTable(match[1], match[2])
+		case ddlCreateViewRegexp.MatchString(sql):
+			match := ddlCreateViewRegexp.FindStringSubmatch(sql)
+			err = p.parseView(match[2], len(match[1]) > 0, match[3], match[4])
+		case ddlCommentRegexp.MatchString(sql):
+			match := ddlCommentRegexp.FindStringSubmatch(sql)
+			err = p.parseComment(strings.ToUpper(match[1]), match[2], strings.ReplaceAll(match[3], "''", "'"))
+		case ddlUniqueIndexRegexp.MatchString(sql):
+			match := ddlUniqueIndexRegexp.FindStringSubmatch(sql)
+			err = p.parseConstraint(match[2], "CONSTRAINT "+match[1]+" UNIQUE ("+match[3]+")")
+		case ddlIgnoreRegexp.MatchString(sql):
+		case ddlAlterTableRegexp.MatchString(sql):
+			match := ddlAlterTableRegexp.FindStringSubmatch(sql)
+			err = p.parseAlter(match[1], match[2])
+		default:
+			err = fmt.Errorf("unsupported statement")
+		}
+		if err != nil {
+			p.warn(source, statement.Line, "skip %v by %v", firstLine, err)
+		}
+	}
+}
+
+// Finish will fill the ddl type like database and return the parsed tables
+func (p *ddlParser) Finish() (tables []*Table, warnings []string) {
+	for _, table := range p.tables {
+		for _, column := range table.Columns {
+			if len(column.DDLType) > 0 {
+				continue
+			}
+			column.DDLType = column.Type
+			defaultValue := ""
+			if column.DefaultValue != nil {
+				defaultValue = *column.DefaultValue
+			}
+			if strings.HasPrefix(defaultValue, "nextval(") {
+				switch strings.ToLower(column.Type) {
+				case "integer", "int", "int4":
+					column.DDLType = "serial"
+				case "bigint", "int8":
+					column.DDLType = "bigserial"
+				case "smallint", "int2":
+					column.DDLType = "smallserial"
+				}
+			} else if strings.ToLower(column.Type) == "uuid" && len(defaultValue) > 0 {
+				column.DDLType = "autogenuuid"
+			}
+		}
+	}
+	tables, warnings = p.tables, p.warnings
+	return
+}
+
+func (p *ddlParser) parseTable(name, define string) (err error) {
+	tokens := tokenizeDDL(define)
+	if len(tokens) < 1 || !strings.HasPrefix(tokens[0].Text, "(") {
+		err = fmt.Errorf("table body is not found")
+		return
+	}
+	table := &Table{Type: "table"}
+	table.Schema, table.Name = ddlTableName(name)
+	if p.findTable(table.Name) != nil {
+		err = fmt.Errorf("table %v is exists", table.Name)
+		return
+	}
+	body := tokens[0].Text
+	for _, item := range splitDDLTopLevel(body[1:len(body)-1], ',') {
+		item = strings.TrimSpace(item)
+		if len(item) < 1 {
+			continue
+		}
+		first := strings.ToUpper(strings.Fields(item)[0])
+		switch first {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "EXCLUDE":
+			err = parseDDLConstraint(table, item)
+		default:
+			err = parseDDLColumn(table, item)
+		}
+		if err != nil {
+			return
+		}
+	}
+	//mysql table option
+	for i := 1; i < len(tokens); i++ {
+		if strings.ToUpper(tokens[i].Text) != "COMMENT" {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1].Text == "=" {
+			i++
+		}
+		if i+1 < len(tokens) && strings.HasPrefix(tokens[i+1].Text, "'") {
+			table.Comment = ddlUnquote(tokens[i+1].Text)
+		}
+	}
+	p.tables = append(p.tables, table)
+	return
+}
+
+func (p *ddlParser) parseView(name string, materialized bool, selects, from string) (err error) {
+	base := p.findTable(from)
+	if base == nil {
+		err = fmt.Errorf("view table %v is not found", from)
+		return
+	}
+	view := &Table{Type: "view"}
+	if materialized {
+		view.Type = "materialized view"
+	}
+	view.Schema, view.Name = ddlTableName(name)
+	for _, item := range splitDDLTopLevel(selects, ',') {
+		match := ddlSelectColumnRegexp.FindStringSubmatch(strings.TrimSpace(item))
+		if len(match) < 1 {
+			err = fmt.Errorf("view column %v is not supported", strings.TrimSpace(item))
+			return
+		}
+		var columns []*Column
+		if match[1] == "*" {
+			columns = base.Columns
+		} else {
+			column := ddlColumn(base, ddlUnquote(match[1]))
+			if column == nil {
+				err = fmt.Errorf("view column %v is not found on %v", match[1], base.Name)
+				return
+			}
+			columns = append(columns, column)
+		}
+		for _, column := range columns {
+			viewColumn := &Column{
+				Name:    column.Name,
+				Type:    column.Type,
+				Ordinal: len(view.Columns) + 1,
+			}
+			if len(match[2]) > 0 {
+				viewColumn.Name = ddlUnquote(match[2])
+			}
+			view.Columns = append(view.Columns, viewColumn)
+		}
+	}
+	p.tables = append(p.tables, view)
+	return
+}
+
+func (p *ddlParser) parseComment(on, name, comment string) (err error) {
+	if on == "TABLE" {
+		table := p.findTable(name)
+		if table == nil {
+			err = fmt.Errorf("table %v is not found", name)
+			return
+		}
+		table.Comment = comment
+		return
+	}
+	parts := splitDDLName(name)
+	if len(parts) < 2 {
+		err = fmt.Errorf("column %v is invalid", name)
+		return
+	}
+	table := p.findTable(parts[len(parts)-2])
+	if table == nil {
+		err = fmt.Errorf("table %v is not found", parts[len(parts)-2])
+		return
+	}
+	column := ddlColumn(table, parts[len(parts)-1])
+	if column == nil {
+		err = fmt.Errorf("column %v is not found", name)
+		return
+	}
+	column.Comment = comment
+	return
+}
+
+func (p *ddlParser) parseAlter(name, action string) (err error) {
+	table := p.findTable(name)
+	if table == nil {
+		err = fmt.Errorf("table %v is not found", name)
+		return
+	}
+	if match := ddlAlterDefaultRegexp.FindStringSubmatch(action); len(match) > 0 {
+		column := ddlColumn(table, ddlUnquote(match[1]))
+		if column == nil {
+			err = fmt.Errorf("column %v is not found", match[1])
+			return
+		}
+		defaultValue := strings.TrimSpace(match[2])
+		column.DefaultValue = &defaultValue
+		return
+	}
+	if match := ddlAlterAddRegexp.FindStringSubmatch(action); len(match) > 0 {
+		err = p.parseConstraint(name, match[1])
+		return
+	}
+	err = fmt.Errorf("unsupported alter action")
+	return
+}
+
+func (p *ddlParser) parseConstraint(name, item string) (err error) {
+	table := p.findTable(name)
+	if table == nil {
+		err = fmt.Errorf("table %v is not found", name)
+		return
+	}
+	err = parseDDLConstraint(table, item)
+	return
+}
+
+// parseDDLConstraint will parse table constraint like CONSTRAINT xx PRIMARY KEY (a), UNIQUE (a), CHECK (...), FOREIGN KEY (a) REFERENCES t(b)
+func parseDDLConstraint(table *Table, item string) (err error) {
+	tokens := tokenizeDDL(item)
+	name := ""
+	if len(tokens) > 1 && strings.ToUpper(tokens[0].Text) == "CONSTRAINT" {
+		name = ddlUnquote(tokens[1].Text)
+		tokens = tokens[2:]
+	}
+	if len(tokens) < 1 {
+		err = fmt.Errorf("constraint %v is invalid", item)
+		return
+	}
+	group := func() (columns []string) {
+		for _, token := range tokens {
+			if strings.HasPrefix(token.Text, "(") {
+				for _, column := range splitDDLTopLevel(token.Text[1:len(token.Text)-1], ',') {
+					columns = append(columns, ddlUnquote(strings.Fields(column)[0]))
+				}
+				break
+			}
+		}
+		return
+	}
+	switch strings.ToUpper(tokens[0].Text) {
+	case "PRIMARY":
+		for _, key := range group() {
+			column := ddlColumn(table, key)
+			if column == nil {
+				err = fmt.Errorf("primary key %v is not found", key)
+				return
+			}
+			column.IsPK = true
+		}
+	case "UNIQUE":
+		columns := group()
+		for _, token := range tokens[1:] {
+			if strings.HasPrefix(token.Text, "(") {
+				break
+			}
+			if keyword := strings.ToUpper(token.Text); keyword != "KEY" && keyword != "INDEX" {
+				name = ddlUnquote(token.Text) //mysql UNIQUE KEY name (a)
+			}
+		}
+		if len(name) < 1 {
+			name = fmt.Sprintf("%v_%v_key", table.Name, strings.Join(columns, "_"))
+		}
+		table.Uniques = append(table.Uniques, &Unique{Name: name, Columns: columns})
+	case "CHECK":
+		table.Checks = append(table.Checks, &Check{Name: name, Expr: strings.TrimSpace(item[tokens[0].Start:])})
+	case "FOREIGN":
+		columns := group()
+		if len(columns) == 1 {
+			for i, token := range tokens {
+				if strings.ToUpper(token.Text) == "REFERENCES" && i+1 < len(tokens) {
+					table.ForeignKeys = append(table.ForeignKeys, ddlForeignKey(columns[0], tokens[i+1:]))
+					break
+				}
+			}
+		}
+	case "KEY", "INDEX", "FULLTEXT", "SPATIAL", "EXCLUDE":
+	default:
+		err = fmt.Errorf("constraint %v is not supported", item)
+	}
+	return
+}
+
+// parseDDLColumn will parse column define like name type [NOT NULL] [DEFAULT x] [PRIMARY KEY] [UNIQUE] [CHECK (...)] [REFERENCES t(c)] [COMMENT 'x']
+func parseDDLColumn(table *Table, item string) (err error) {
+	tokens := tokenizeDDL(item)
+	if len(tokens) < 2 {
+		err = fmt.Errorf("column %v is invalid", item)
+		return
+	}
+	column := &Column{
+		Name:    ddlUnquote(tokens[0].Text),
+		Ordinal: len(table.Columns) + 1,
+	}
+	isKeyword := func(i int) bool {
+		return ddlKeywords[strings.ToUpper(tokens[i].Text)]
+	}
+	i := 1
+	for i < len(tokens) && !isKeyword(i) {
+		i++
+	}
+	column.Type = strings.Join(strings.Fields(item[tokens[1].Start:tokens[i-1].End]), " ")
+	constraintName := ""
+	for i < len(tokens) {
+		word := strings.ToUpper(tokens[i].Text)
+		i++
+		switch word {
+		case "CONSTRAINT":
+			if i < len(tokens) {
+				constraintName = ddlUnquote(tokens[i].Text)
+				i++
+			}
+			continue
+		case "NOT":
+			if i < len(tokens) && strings.ToUpper(tokens[i].Text) == "NULL" {
+				column.NotNull = true
+				i++
+			}
+		case "PRIMARY":
+			column.IsPK = true
+			if i < len(tokens) && strings.ToUpper(tokens[i].Text) == "KEY" {
+				i++
+			}
+		case "DEFAULT":
+			start := i
+			for i < len(tokens) && (i == start || !isKeyword(i)) {
+				i++
+			}
+			if i > start {
+				defaultValue := item[tokens[start].Start:tokens[i-1].End]
+				column.DefaultValue = &defaultValue
+			}
+		case "UNIQUE":
+			name := constraintName
+			if len(name) < 1 {
+				name = fmt.Sprintf("%v_%v_key", table.Name, column.Name)
+			}
+			table.Uniques = append(table.Uniques, &Unique{Name: name, Columns: []string{column.Name}})
+		case "CHECK":
+			if i < len(tokens) {
+				table.Checks = append(table.Checks, &Check{Name: constraintName, Expr: "CHECK " + tokens[i].Text})
+				i++
+			}
+		case "REFERENCES":
+			table.ForeignKeys = append(table.ForeignKeys, ddlForeignKey(column.Name, tokens[i:]))
+		case "COMMENT":
+			if i < len(tokens) && strings.HasPrefix(tokens[i].Text, "'") {
+				column.Comment = ddlUnquote(tokens[i].Text)
+				i++
+			}
+		}
+		constraintName = ""
+		for i < len(tokens) && !isKeyword(i) {
+			i++
+		}
+	}
+	table.Columns = append(table.Columns, column)
+	return
+}
+
+func ddlForeignKey(column string, tokens []*ddlToken) (key *ForeignKey) {
+	key = &ForeignKey{Column: column}
+	if len(tokens) < 1 {
+		return
+	}
+	_, key.RefTable = ddlTableName(tokens[0].Text)
+	if len(tokens) > 1 && strings.HasPrefix(tokens[1].Text, "(") {
+		key.RefColumn = ddlUnquote(strings.TrimSpace(tokens[1].Text[1 : len(tokens[1].Text)-1]))
+	} else if index := strings.Index(tokens[0].Text, "("); index > 0 {
+		_, key.RefTable = ddlTableName(tokens[0].Text[:index])
+		key.RefColumn = ddlUnquote(strings.Trim(tokens[0].Text[index:], "()"))
+	}
+	return
+}
+
+func ddlColumn(table *Table, name string) *Column {
+	for _, column := range table.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+// ddlTableName will split schema.table name and unquote it
+func ddlTableName(name string) (schema, table string) {
+	parts := splitDDLName(name)
+	table = parts[len(parts)-1]
+	if len(parts) > 1 {
+		schema = parts[len(parts)-2]
+	}
+	return
+}
+
+func splitDDLName(name string) (parts []string) {
+	for _, part := range splitDDLTopLevel(name, '.') {
+		parts = append(parts, ddlUnquote(strings.TrimSpace(part)))
+	}
+	return
+}
+
+func ddlUnquote(value string) string {
+	if len(value) > 1 {
+		switch value[0] {
+		case '"', '`':
+			if value[len(value)-1] == value[0] {
+				return value[1 : len(value)-1]
+			}
+		case '\'':
+			if value[len(value)-1] == '\'' {
+				return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+			}
+		}
+	}
+	return value
+}
+
+type ddlStatement struct {
+	Line int
+	SQL  string
+}
+
+// splitDDL will split ddl to statement by ; and remove the comment
+func splitDDL(ddl string) (statements []*ddlStatement) {
+	line, start := 1, 0
+	buffer := &strings.Builder{}
+	flush := func() {
+		if sql := strings.TrimSpace(buffer.String()); len(sql) > 0 {
+			statements = append(statements, &ddlStatement{Line: start, SQL: sql})
+		}
+		buffer.Reset()
+		start = 0
+	}
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case c == '-' && i+1 < len(ddl) && ddl[i+1] == '-':
+			for i+1 < len(ddl) && ddl[i+1] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(ddl) && ddl[i+1] == '*':
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				end = len(ddl) - i - 2
+			}
+			line += strings.Count(ddl[i:i+2+end], "\n")
+			i += end + 3
+			buffer.WriteByte(' ')
+			continue
+		case c == ';':
+			flush()
+			continue
+		case c == '\n':
+			line++
+		}
+		if start < 1 && c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			start = line
+		}
+		if c == '\'' || c == '"' || c == '`' {
+			end := ddlQuoteEnd(ddl, i)
+			line += strings.Count(ddl[i:end], "\n")
+			buffer.WriteString(ddl[i:end])
+			i = end - 1
+			continue
+		}
+		buffer.WriteByte(c)
+	}
+	flush()
+	return
+}
+
+// ddlQuoteEnd will return the end offset of quoted value which start at i
+func ddlQuoteEnd(text string, i int) int {
+	quote := text[i]
+	for j := i + 1; j < len(text); j++ {
+		if text[j] != quote {
+			continue
+		}
+		if j+1 < len(text) && text[j+1] == quote {
+			j++
+			continue
+		}
+		return j + 1
+	}
+	return len(text)
+}
+
+// splitDDLTopLevel will split text by sep which is not in quote or parentheses
+func splitDDLTopLevel(text string, sep byte) (parts []string) {
+	depth, last := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = ddlQuoteEnd(text, i) - 1
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, text[last:i])
+			last = i + 1
+		}
+	}
+	parts = append(parts, text[last:])
+	return
+}
+
+type ddlToken struct {
+	Text       string
+	Start, End int
+}
+
+// tokenizeDDL will split text to word, quoted value, parenthesized group and symbol token
+func tokenizeDDL(text string) (tokens []*ddlToken) {
+	for i := 0; i < len(text); {
+		c := text[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			i = ddlQuoteEnd(text, i)
+		case c == '(':
+			depth := 0
+			for ; i < len(text); i++ {
+				if text[i] == '\'' || text[i] == '"' || text[i] == '`' {
+					i = ddlQuoteEnd(text, i) - 1
+				} else if text[i] == '(' {
+					depth++
+				} else if text[i] == ')' {
+					depth--
+					if depth == 0 {
+						i++
+						break
+					}
+				}
+			}
+		case isDDLWord(c):
+			for i < len(text) && isDDLWord(text[i]) {
+				i++
+			}
+		case c == ':' && i+1 < len(text) && text[i+1] == ':':
+			i += 2
+		default:
+			i++
+		}
+		tokens = append(tokens, &ddlToken{Text: text[start:i], Start: start, End: i})
+	}
+	return
+}
+
+func isDDLWord(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,178 @@
+package gen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/codingeasygo/crud/testsql"
+)
+
+func findDDLTable(tables []*Table, name string) *Table {
+	for _, table := range tables {
+		if table.Name == name {
+			return table
+		}
+	}
+	return nil
+}
+
+func TestParseDDL(t *testing.T) {
+	tables, warnings := ParseDDL(testsql.PG_LATEST)
+	if len(warnings) > 0 || len(tables) != 3 {
+		t.Errorf("tables is %v, warnings is %v", len(tables), warnings)
+		return
+	}
+	object := findDDLTable(tables, "crud_object")
+	if object == nil || object.Type != "table" || len(object.Columns) != 26 {
+		t.Errorf("object is %v", object)
+		return
+	}
+	tid, typ, status := object.Columns[0], object.Columns[2], object.Columns[25]
+	if tid.Name != "tid" || !tid.IsPK || !tid.NotNull || tid.Type != "bigint" || tid.DDLType != "bigserial" || tid.Ordinal != 1 {
+		t.Errorf("tid is %v", tid)
+		return
+	}
+	if typ.Type != "character varying(255)" || typ.DefaultValue == nil || *typ.DefaultValue != "''::character varying" || !strings.HasPrefix(typ.Comment, "simple type in") {
+		t.Errorf("type is %v", typ)
+		return
+	}
+	if status.Name != "status" || status.Comment != "simple status in, Normal=100, Disabled=200, Removed=-1" {
+		t.Errorf("status is %v", status)
+		return
+	}
+	if image := object.Columns[5]; image.Name != "image" || image.NotNull || image.DefaultValue != nil {
+		t.Errorf("image is %v", image)
+		return
+	}
+	uuidObject := findDDLTable(tables, "crud_uuid_object")
+	if uuidObject == nil || uuidObject.Columns[0].DDLType != "autogenuuid" || !uuidObject.Columns[0].IsPK {
+		t.Errorf("uuid object is %v", uuidObject)
+		return
+	}
+	if len(uuidObject.ForeignKeys) != 1 || uuidObject.ForeignKeys[0].Column != "object_id" || uuidObject.ForeignKeys[0].RefTable != "crud_object" || uuidObject.ForeignKeys[0].RefColumn != "tid" {
+		t.Errorf("foreign keys is %v", uuidObject.ForeignKeys)
+		return
+	}
+	if len(uuidObject.Uniques) != 1 || uuidObject.Uniques[0].Name != "crud_uuid_object_code_key" || uuidObject.Uniques[0].Columns[0] != "code" {
+		t.Errorf("uniques is %v", uuidObject.Uniques)
+		return
+	}
+	if len(uuidObject.Checks) != 1 || uuidObject.Checks[0].Name != "crud_uuid_object_status_check" || uuidObject.Checks[0].Expr != "CHECK (status >= 0 AND status <= 1000)" {
+		t.Errorf("checks is %v", uuidObject.Checks)
+		return
+	}
+	view := findDDLTable(tables, "crud_object_view")
+	if view == nil || !view.IsView() || len(view.Columns) != 6 || view.Columns[1].Name != "user_id" || view.Columns[1].Type != "bigint" || view.Columns[1].NotNull {
+		t.Errorf("view is %v", view)
+		return
+	}
+	fileTables, warnings, err := ParseDDLFiles("../testsql/pg_latest.sql")
+	if err != nil || len(warnings) > 0 || len(fileTables) != len(tables) {
+		t.Errorf("err is %v, warnings is %v", err, warnings)
+		return
+	}
+	_, _, err = ParseDDLFiles("../testsql/none.sql")
+	if err == nil {
+		t.Error("error")
+		return
+	}
+}
+
+func TestParseDDLMySQL(t *testing.T) {
+	tables, warnings := ParseDDL(`
+CREATE TABLE IF NOT EXISTS ` + "`shop`.`crud_user`" + ` (
+  ` + "`tid`" + ` bigint(20) NOT NULL AUTO_INCREMENT COMMENT 'the user id',
+  ` + "`name`" + ` varchar(64) NOT NULL DEFAULT '' COMMENT 'the user''s name',
+  ` + "`role`" + ` int(11) DEFAULT NULL COMMENT 'user role in, Admin=1, Normal=2',
+  ` + "`update_time`" + ` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (` + "`tid`" + `),
+  UNIQUE KEY ` + "`name_idx`" + ` (` + "`name`" + `),
+  KEY ` + "`role_idx`" + ` (` + "`role`" + `)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='the user table';
+CREATE TRIGGER crud_user_trigger BEFORE INSERT ON crud_user FOR EACH ROW SET NEW.role = 2;
+ALTER TABLE crud_user RENAME TO crud_user2;
+`)
+	if len(tables) != 1 || len(warnings) != 2 {
+		t.Errorf("tables is %v, warnings is %v", len(tables), warnings)
+		return
+	}
+	if !strings.HasPrefix(warnings[0], "ddl:11 skip CREATE TRIGGER") || !strings.HasPrefix(warnings[1], "ddl:12 skip ALTER TABLE crud_user RENAME") {
+		t.Errorf("warnings is %v", warnings)
+		return
+	}
+	user := tables[0]
+	if user.Schema != "shop" || user.Name != "crud_user" || user.Comment != "the user table" || len(user.Columns) != 4 {
+		t.Errorf("user is %v", user)
+		return
+	}
+	tid, name, role, updateTime := user.Columns[0], user.Columns[1], user.Columns[2], user.Columns[3]
+	if !tid.IsPK || tid.Type != "bigint(20)" || tid.Comment != "the user id" {
+		t.Errorf("tid is %v", tid)
+		return
+	}
+	if name.Type != "varchar(64)" || !name.NotNull || *name.DefaultValue != "''" || name.Comment != "the user's name" {
+		t.Errorf("name is %v", name)
+		return
+	}
+	if role.NotNull || *role.DefaultValue != "NULL" || role.Comment != "user role in, Admin=1, Normal=2" {
+		t.Errorf("role is %v", role)
+		return
+	}
+	if updateTime.Type != "datetime" || *updateTime.DefaultValue != "CURRENT_TIMESTAMP" {
+		t.Errorf("update time is %v", updateTime)
+		return
+	}
+	if len(user.Uniques) != 1 || user.Uniques[0].Name != "name_idx" || user.Uniques[0].Columns[0] != "name" {
+		t.Errorf("uniques is %v", user.Uniques)
+		return
+	}
+}
+
+func TestPgGenDDL(t *testing.T) {
+	autoGen := PgGen
+	autoGen.Queryer = nil
+	autoGen.TablesFromDDL = []string{"../testsql/pg_latest.sql"}
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(string(files["auto_models.go"]), "type CrudObjectView struct") || !strings.Contains(string(files["auto_models.go"]), `valid:"status,r|i,r:-1~1001;"`) {
+		t.Errorf("models is\n%v", string(files["auto_models.go"]))
+		return
+	}
+}
+
+func TestSqliteGenDDL(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	warnings := []string{}
+	ddlGen := SqliteGen
+	ddlGen.Queryer = nil
+	ddlGen.TablesFromDDL = []string{"../testsql/sqlite_latest.sql"}
+	ddlGen.OnWarning = func(message string) { warnings = append(warnings, message) }
+	ddlGen.DryRun = true
+	ddlFiles, _, err := ddlGen.GenerateFiles()
+	if err != nil || len(warnings) > 0 {
+		t.Errorf("err is %v, warnings is %v", err, warnings)
+		return
+	}
+	if len(files) != len(ddlFiles) {
+		t.Errorf("files is %v/%v", len(files), len(ddlFiles))
+		return
+	}
+	for name, data := range files {
+		if !bytes.Equal(data, ddlFiles[name]) {
+			t.Errorf("%v is not equal\n%v\n%v", name, string(data), string(ddlFiles[name]))
+			return
+		}
+	}
+}
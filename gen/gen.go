@@ -3,13 +3,24 @@ package gen
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -33,6 +44,38 @@ func ConvCamelCase(isTable bool, name string) (result string) {
 	return
 }
 
+// CamelCaseOptions is the options of ConvCamelCaseWith
+type CamelCaseOptions struct {
+	//Acronyms is the name part which will be upper, like api to API
+	Acronyms xsql.StringArray
+	//SuffixAcronyms is the last name part which will be replaced, like ids to IDs
+	SuffixAcronyms map[string]string
+}
+
+// DefaultAcronyms is the default acronyms used by AutoGen when Acronyms is not setted
+var DefaultAcronyms = xsql.StringArray{"api", "id", "ip", "tid", "url", "uuid"}
+
+// DefaultSuffixAcronyms is the default suffix acronyms used by AutoGen when SuffixAcronyms is not setted
+var DefaultSuffixAcronyms = map[string]string{"ids": "IDs"}
+
+// ConvCamelCaseWith will return camel case NameConv by acronyms options
+func ConvCamelCaseWith(opts CamelCaseOptions) NameConv {
+	return func(isTable bool, name string) (result string) {
+		parts := strings.Split(name, "_")
+		for i, part := range parts {
+			lower := strings.ToLower(part)
+			if suffix, ok := opts.SuffixAcronyms[lower]; ok && i == len(parts)-1 {
+				result += suffix
+			} else if opts.Acronyms.HavingOne(lower) {
+				result += strings.ToUpper(part)
+			} else {
+				result += stringTitle(part)
+			}
+		}
+		return
+	}
+}
+
 func ConvSizeTrim(typeMap map[string][]string, s *Struct, column *Column) (result string) {
 	typ := regexp.MustCompile(`\([^\)]*\)`).ReplaceAllString(column.Type, "")
 	types := typeMap[strings.ToLower(typ)]
@@ -90,32 +133,159 @@ type Column struct {
 }
 
 type Table struct {
-	Schema  string    `json:"schema"`
-	Name    string    `json:"name"`
-	Type    string    `json:"type"`
-	Comment string    `json:"comment"`
-	Columns []*Column `json:"columns"`
+	Schema      string        `json:"schema"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Comment     string        `json:"comment"`
+	Columns     []*Column     `json:"columns"`
+	ForeignKeys []*ForeignKey `json:"foreign_keys,omitempty"`
+	Uniques     []*Unique     `json:"uniques,omitempty"`
+	Checks      []*Check      `json:"checks,omitempty"`
+}
+
+// IsView will return true if table is view or materialized view
+func (t *Table) IsView() bool {
+	switch strings.ToLower(t.Type) {
+	case "v", "m", "view", "materialized view":
+		return true
+	default:
+		return false
+	}
+}
+
+type ForeignKey struct {
+	Column    string `json:"column"`
+	RefTable  string `json:"ref_table"`
+	RefColumn string `json:"ref_column"`
+}
+
+// Check is the check constraint of table, the Expr can be constraint define or table ddl which contains CHECK
+type Check struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
 }
 
 func Query(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error) {
-	tableArg := []interface{}{}
+	tables, err = QueryWithProgress(queryer, tableSQL, columnSQL, "", schema, nil)
+	return
+}
+
+// tableColumn is the column with table name which is queried by ColumnAllSQL
+type tableColumn struct {
+	Table  string `json:"table"`
+	Column `filter:"#inline"`
+}
+
+// QueryWithProgress will query tables and columns like Query and call onTable after columns of each table is queried,
+// the columns of all tables is queried by one statement when columnAllSQL is not empty, it must return table name as first column,
+// the table which columns is not found by columnAllSQL will be queried by columnSQL
+func QueryWithProgress(queryer interface{}, tableSQL, columnSQL, columnAllSQL, schema string, onTable func(i, total int, table *Table)) (tables []*Table, err error) {
+	schemaArg := []interface{}{}
 	if len(schema) > 0 {
-		tableArg = append(tableArg, schema)
+		schemaArg = append(schemaArg, schema)
 	}
-	err = crud.Query(queryer, context.Background(), &Table{}, "name,type,comment#all", tableSQL, tableArg, &tables)
+	err = crud.Query(queryer, context.Background(), &Table{}, "name,type,comment#all", tableSQL, schemaArg, &tables)
 	if err != nil {
+		err = fmt.Errorf("query tables fail with %v", err)
 		return
 	}
+	if len(columnAllSQL) > 0 && len(tables) > 0 {
+		var columns []*tableColumn
+		err = crud.Query(queryer, context.Background(), &tableColumn{}, "#all", columnAllSQL, schemaArg, &columns)
+		if err != nil {
+			err = fmt.Errorf("query all columns fail with %v", err)
+			return
+		}
+		tableAll := map[string]*Table{}
+		for _, table := range tables {
+			tableAll[table.Name] = table
+		}
+		for _, column := range columns {
+			if table := tableAll[column.Table]; table != nil {
+				c := column.Column
+				table.Columns = append(table.Columns, &c)
+			}
+		}
+	}
+	for i, table := range tables {
+		if len(table.Columns) < 1 || len(columnAllSQL) < 1 {
+			columnArg := append(append([]interface{}{}, schemaArg...), table.Name)
+			err = crud.Query(queryer, context.Background(), &Column{}, "#all", columnSQL, columnArg, &table.Columns)
+			if err != nil {
+				err = fmt.Errorf("query table %v columns fail with %v", table.Name, err)
+				break
+			}
+		}
+		if onTable != nil {
+			onTable(i, len(tables), table)
+		}
+	}
+	return
+}
+
+func QueryForeignKeys(queryer interface{}, foreignKeySQL, schema string, tables []*Table) (err error) {
+	for _, table := range tables {
+		keyArg := []interface{}{}
+		if len(schema) > 0 {
+			keyArg = append(keyArg, schema)
+		}
+		keyArg = append(keyArg, table.Name)
+		table.ForeignKeys = nil
+		err = crud.Query(queryer, context.Background(), &ForeignKey{}, "#all", foreignKeySQL, keyArg, &table.ForeignKeys)
+		if err != nil {
+			break
+		}
+	}
+	return
+}
+
+func QueryChecks(queryer interface{}, checkSQL, schema string, tables []*Table) (err error) {
+	for _, table := range tables {
+		checkArg := []interface{}{}
+		if len(schema) > 0 {
+			checkArg = append(checkArg, schema)
+		}
+		checkArg = append(checkArg, table.Name)
+		table.Checks = nil
+		err = crud.Query(queryer, context.Background(), &Check{}, "#all", checkSQL, checkArg, &table.Checks)
+		if err != nil {
+			break
+		}
+	}
+	return
+}
+
+type Unique struct {
+	Name    string           `json:"name"`
+	Columns xsql.StringArray `json:"columns"`
+}
+
+type uniqueColumn struct {
+	Name   string `json:"name"`
+	Column string `json:"column"`
+}
+
+func QueryUniques(queryer interface{}, uniqueSQL, schema string, tables []*Table) (err error) {
 	for _, table := range tables {
-		columnArg := []interface{}{}
+		uniqueArg := []interface{}{}
 		if len(schema) > 0 {
-			columnArg = append(columnArg, schema)
+			uniqueArg = append(uniqueArg, schema)
 		}
-		columnArg = append(columnArg, table.Name)
-		err = crud.Query(queryer, context.Background(), &Column{}, "#all", columnSQL, columnArg, &table.Columns)
+		uniqueArg = append(uniqueArg, table.Name)
+		var columns []*uniqueColumn
+		err = crud.Query(queryer, context.Background(), &uniqueColumn{}, "#all", uniqueSQL, uniqueArg, &columns)
 		if err != nil {
 			break
 		}
+		table.Uniques = nil
+		for _, column := range columns {
+			n := len(table.Uniques)
+			if n < 1 || table.Uniques[n-1].Name != column.Name {
+				table.Uniques = append(table.Uniques, &Unique{Name: column.Name})
+				n++
+			}
+			table.Uniques[n-1].Columns = append(table.Uniques[n-1].Columns, column.Column)
+		}
 	}
 	return
 }
@@ -247,15 +417,34 @@ func (g *Gen) Generate(writer io.Writer, call func(buffer io.Writer, data interf
 	return
 }
 
-func (g *Gen) GenerateByTemplate(name, tmpl string, writer io.Writer) (err error) {
+func (g *Gen) GenerateByTemplate(name, tmpl string, writer io.Writer, files ...string) (err error) {
 	structTmpl := template.New(name).Funcs(g.FuncMap)
 	_, err = structTmpl.Parse(tmpl)
-	if err == nil {
-		err = g.Generate(writer, structTmpl.Execute)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		var data []byte
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			err = fmt.Errorf("read template %v from %v fail with %v", name, file, err)
+			return
+		}
+		_, err = structTmpl.Parse(string(data))
+		if err != nil {
+			err = fmt.Errorf("parse template %v from %v fail with %v", name, file, err)
+			return
+		}
+	}
+	err = g.Generate(writer, structTmpl.Execute)
+	if err != nil && len(files) > 0 {
+		err = fmt.Errorf("execute template %v from %v fail with %v", name, strings.Join(files, ","), err)
 	}
 	return
 }
 
+const AutoManifestFile = "auto_manifest.json"
+
 const (
 	FieldsOptional = "optional"
 	FieldsRequired = "required"
@@ -264,43 +453,231 @@ const (
 	FieldsFind     = "find"
 	FieldsScan     = "scan"
 	FieldsNotOmit  = "n_omit"
+	FieldsWhere    = "where"
 )
 
+// TemplateFilter is the crud filter of table used by template
+type TemplateFilter struct {
+	Optional string
+	Required string
+	Insert   string
+	Update   string
+	Order    string
+	Find     string
+	Scan     string
+}
+
+// TemplateArg is the argument info used by template
+type TemplateArg struct {
+	Name string
+}
+
+// TemplateAdd is the insert info used by template
+type TemplateAdd struct {
+	Defaults string
+	Filter   string
+	Return   string
+	Normal   bool
+}
+
+// TemplateTest is the test info used by template
+type TemplateTest struct {
+	Defaults   string
+	OrderField *Field
+	Audit      string
+	Fixture    string
+}
+
+// TemplateUpsert is the upsert info used by template
+type TemplateUpsert struct {
+	Conflict string
+	Fields   []*Field
+}
+
+// TemplateUpdate is the update info used by template
+type TemplateUpdate struct {
+	UpdateTime  bool
+	Fields      []*Field
+	Audit       string
+	AuditFilter string
+}
+
+// TemplateRelation is the foreign key relation used by template to generate loader
+type TemplateRelation struct {
+	Name    string
+	Arg     string
+	Field   *Field
+	Pointer bool
+	Ref     *Struct
+}
+
+// TemplateWhere is the field of typed where filter struct used by template
+type TemplateWhere struct {
+	Field *Field
+	Type  string
+	Cmp   string
+}
+
+// TemplateUnique is the unique index used by template to generate finder
+type TemplateUnique struct {
+	Name     string
+	Fields   []*Field
+	Args     []string
+	Types    []string
+	Pointers []bool
+	Guards   []string // the pointer fields which must be not nil to find by unique in test
+}
+
+// TemplateDefault is the column default value used by template to generate constant
+type TemplateDefault struct {
+	Name  string
+	Value string
+	Field *Field
+}
+
+// TemplateData is the data of one table passed to template by AutoGen.OnPre
+type TemplateData struct {
+	TableNameType   string
+	TableName       string
+	Struct          *Struct
+	Code            map[string]string
+	GetQueryer      string
+	GenValid        bool
+	EnumHelpers     bool
+	DocComments     bool
+	TestAgainstMock bool
+	View            bool
+	Filter          TemplateFilter
+	Arg             TemplateArg
+	Add             TemplateAdd
+	Test            TemplateTest
+	Upsert          TemplateUpsert
+	Update          TemplateUpdate
+	Relations       []*TemplateRelation
+	Uniques         []*TemplateUnique
+	Wheres          []*TemplateWhere
+	Defaults        []*TemplateDefault
+}
+
+// SchemaConfig is the config of one schema when AutoGen generate multi schema in one run,
+// the Out/OutPackage is default to AutoGen.Out/<Name> and <Name>
+type SchemaConfig struct {
+	Name         string           `json:"name"`
+	Out          string           `json:"out"`
+	OutPackage   string           `json:"out_package"`
+	TableInclude xsql.StringArray `json:"table_include"`
+	TableExclude xsql.StringArray `json:"table_exclude"`
+}
+
 type AutoGen struct {
-	TypeField     map[string]map[string]string
-	ValidField    map[string]map[string]string
-	FieldFilter   map[string]map[string]string
-	CodeAddInit   map[string]string
-	CodeTestInit  map[string]string
-	CodeSlice     map[string]string
-	Comments      map[string]map[string]string
-	TableGenAdd   xsql.StringArray
-	TableRetAdd   map[string]string
-	TableNotValid xsql.StringArray
-	TableInclude  xsql.StringArray
-	TableExclude  xsql.StringArray
-	TableNameType string
-	Queryer       interface{}
-	TableQueryer  func(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error)
-	TableSQL      string
-	ColumnSQL     string
-	Schema        string
-	TypeMap       map[string][]string
-	NameConv      NameConv
-	FuncOver      template.FuncMap
-	GetQueryer    string
-	Out           string
-	OutPackage    string
-	OutStructPre  string
-	OutStructFile string
-	OutDefinePre  string
-	OutDefineFile string
-	OutFuncPre    string
-	OutFuncCommon string
-	OutFuncFile   string
-	OutTestPre    string
-	OutTestCommon string
-	OutTestFile   string
+	TypeField           map[string]map[string]string
+	ValidField          map[string]map[string]string
+	FieldFilter         map[string]map[string]string
+	CodeAddInit         map[string]string
+	CodeTestInit        map[string]string
+	CodeSlice           map[string]string
+	Comments            map[string]map[string]string
+	TableGenAdd         xsql.StringArray
+	TableRetAdd         map[string]string
+	TableUpsert         map[string]string
+	TableNotValid       xsql.StringArray
+	TableViewKey        map[string]string
+	ColumnExclude       map[string]xsql.StringArray
+	ColumnRename        map[string]map[string]string
+	JSONField           map[string]map[string]string
+	AuditFields         map[string]string
+	TableInclude        xsql.StringArray
+	TableExclude        xsql.StringArray
+	TableNameType       string
+	IncludeViews        bool
+	Queryer             interface{}
+	TableQueryer        func(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error)
+	TablesFromDDL       xsql.StringArray
+	OnWarning           func(message string)
+	OnTable             func(i, total int, table *Table)
+	TableSQL            string
+	ColumnSQL           string
+	ColumnAllSQL        string
+	ForeignKeySQL       string
+	UniqueSQL           string
+	CheckSQL            string
+	Schema              string
+	TypeMap             map[string][]string
+	NameConv            NameConv
+	Acronyms            xsql.StringArray
+	SuffixAcronyms      map[string]string
+	Singularize         bool
+	Inflector           func(name string) string
+	Irregulars          map[string]string
+	FuncOver            template.FuncMap
+	PreData             func(gen *Gen, table *Table, data *TemplateData) interface{}
+	GetQueryer          string
+	Out                 string
+	OutPackage          string
+	OutStructPre        string
+	OutStructFile       string
+	OutDefinePre        string
+	OutDefineFile       string
+	OutFuncPre          string
+	OutFuncCommon       string
+	OutFuncFile         string
+	OutTestPre          string
+	OutTestCommon       string
+	OutTestFile         string
+	ExtraImports        map[string][]string
+	OutJSONFile         string
+	EnumHelpers         bool
+	DocComments         bool
+	TestAgainstMock     bool
+	ApplyColumnDefaults bool
+	OutTSFile           string
+	TSInt64String       bool
+	OutOpenAPIFile      string
+	SplitPerTable       bool
+	Force               bool
+	DryRun              bool
+	Output              func(name string, content []byte) error
+	TemplateDir         string
+	TemplateOverrides   map[string]string
+	Schemas             []SchemaConfig
+	qualify             string
+	included            []*Table
+	files               map[string][]byte
+	written             []string
+}
+
+func (g *AutoGen) nameConv() NameConv {
+	conv := g.camelCaseConv()
+	if !g.Singularize {
+		return conv
+	}
+	inflector := g.Inflector
+	if inflector == nil {
+		inflector = SingularWith(g.Irregulars)
+	}
+	return func(isTable bool, name string) string {
+		if isTable {
+			name = inflector(name)
+		}
+		return conv(isTable, name)
+	}
+}
+
+func (g *AutoGen) camelCaseConv() NameConv {
+	if g.NameConv != nil {
+		return g.NameConv
+	}
+	opts := CamelCaseOptions{
+		Acronyms:       g.Acronyms,
+		SuffixAcronyms: g.SuffixAcronyms,
+	}
+	if opts.Acronyms == nil {
+		opts.Acronyms = DefaultAcronyms
+	}
+	if opts.SuffixAcronyms == nil {
+		opts.SuffixAcronyms = DefaultSuffixAcronyms
+	}
+	return ConvCamelCaseWith(opts)
 }
 
 func (g *AutoGen) FuncMap() (funcs template.FuncMap) {
@@ -313,6 +690,12 @@ func (g *AutoGen) FuncMap() (funcs template.FuncMap) {
 		"FieldTags":       g.FieldTags,
 		"FieldJson":       g.FieldJson,
 		"FieldDefineType": g.FieldDefineType,
+		"FieldTSName":     g.FieldTSName,
+		"FieldTSType":     g.FieldTSType,
+		"FieldJSONType":   g.FieldJSONType,
+		"FieldMock":       g.FieldMock,
+		"ZeroLiteral":     ZeroLiteral,
+		"DocComment":      DocComment,
 	}
 	for k, v := range g.FuncOver {
 		funcs[k] = v
@@ -352,24 +735,80 @@ func (g *AutoGen) PrimaryField(s *Struct, key string) string {
 	return ""
 }
 
+// FieldInvalid will return the literal which is not in enum options of field, it is used to test EnumValid
 func (g *AutoGen) FieldInvalid(s *Struct, field *Field) (typ string) {
-	switch field.Type {
-	case "string":
+	valueType := g.FieldType(s, field)
+	if len(field.Options) > 0 {
+		valueType = field.Type
+	}
+	switch {
+	case valueType == "string":
 		typ = `"this should invalid"`
-	default:
+	case strings.HasPrefix(valueType, "uint"):
+		typ = `321654`
+	case strings.HasPrefix(valueType, "int") || strings.HasPrefix(valueType, "float"):
 		typ = `-321654`
+	case valueType == "decimal.Decimal":
+		typ = `decimal.NewFromInt(-321654)`
+	default:
+		typ = ZeroLiteral(valueType)
 	}
 	return
 }
 
+// FieldZero will return the compilable zero value literal of field by FieldType, the enum field is zero of base type
 func (g *AutoGen) FieldZero(s *Struct, field *Field) (typ string) {
-	switch field.Type {
+	valueType := g.FieldType(s, field)
+	if len(field.Options) > 0 {
+		valueType = field.Type
+	}
+	typ = ZeroLiteral(valueType)
+	return
+}
+
+// DocComment will return the go line comment by joining not empty parts with space, each line of text is started with //,
+// like DocComment("CrudObject", "is", "object") is // CrudObject is object
+func DocComment(parts ...string) string {
+	values := []string{}
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); len(part) > 0 {
+			values = append(values, part)
+		}
+	}
+	lines := strings.Split(strings.Join(values, " "), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("// "+strings.TrimSpace(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ZeroLiteral will return the compilable zero value literal of go type, like "" for string, 0 for number, nil for pointer/slice/map,
+// decimal.Zero for decimal.Decimal and T{} for other named type like xsql.Time or json field struct
+func ZeroLiteral(typ string) (literal string) {
+	typ = strings.TrimSpace(typ)
+	switch typ {
 	case "string":
-		typ = `""`
+		return `""`
+	case "bool":
+		return "false"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128", "byte", "rune":
+		return "0"
+	case "decimal.Decimal":
+		return "decimal.Zero"
+	case "", "interface{}", "any", "error", "xsql.M":
+		return "nil"
+	}
+	switch {
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["), strings.HasPrefix(typ, "func("), strings.HasPrefix(typ, "chan "):
+		return "nil"
+	case strings.HasPrefix(typ, "["):
+		return typ + "{}"
+	case strings.HasPrefix(typ, "xsql.") && strings.HasSuffix(typ, "Array"):
+		return "nil"
 	default:
-		typ = `0`
+		return typ + "{}"
 	}
-	return
 }
 
 func (g *AutoGen) FieldType(s *Struct, field *Field) (typ string) {
@@ -383,12 +822,49 @@ func (g *AutoGen) FieldType(s *Struct, field *Field) (typ string) {
 	if typeFields, ok := g.TypeField[s.Table.Name]; ok {
 		typ = typeFields[field.Column.Name]
 	}
+	if len(typ) < 1 {
+		typ = g.JSONField[s.Table.Name][field.Column.Name]
+	}
 	if len(typ) < 1 {
 		typ = field.Type
 	}
 	return
 }
 
+// FieldMock will return the mock value code of field which is used by Mock func, it is empty when field should not be mocked
+func (g *AutoGen) FieldMock(s *Struct, field *Field) (value string) {
+	if _, audit := g.AuditFields[field.Column.Name]; audit || field.Column.IsPK || !field.Column.NotNull {
+		return
+	}
+	if len(field.Options) > 0 {
+		value = field.Options[0].Name
+		return
+	}
+	typ := g.FieldType(s, field)
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		value = "1"
+	case "string":
+		value = fmt.Sprintf("%q", "mock-"+field.Column.Name)
+	case "bool":
+		value = "true"
+	case "xsql.Time":
+		value = "xsql.TimeNow()"
+	case "decimal.Decimal":
+		value = "decimal.NewFromInt(1)"
+	case "interface{}":
+	default:
+		if strings.HasPrefix(typ, "xsql.") || g.FieldJSONType(s, field) {
+			value = typ + "{}"
+		}
+	}
+	return
+}
+
+func (g *AutoGen) FieldJSONType(s *Struct, field *Field) bool {
+	return len(g.JSONField[s.Table.Name][field.Column.Name]) > 0
+}
+
 func (g *AutoGen) FieldTags(s *Struct, field *Field) (allTag string) {
 	if g.ValidField == nil {
 		g.ValidField = map[string]map[string]string{}
@@ -425,15 +901,15 @@ func (g *AutoGen) FieldTags(s *Struct, field *Field) (allTag string) {
 		} else {
 			switch field.Type {
 			case "int", "int64", "*int", "*int64":
-				addTag(`valid:"%v,%v|i,r:0;"`, field.Column.Name, required)
+				addTag(`valid:"%v,%v|i,%v;"`, field.Column.Name, required, g.FieldRange(s, field, "r", "0"))
 			case "string", "*string", "xsql.M":
 				if field.Column.Name == "phone" {
 					addTag(`valid:"%v,%v|s,p:^\\d{11}$;"`, field.Column.Name, required)
 				} else {
-					addTag(`valid:"%v,%v|s,l:0;"`, field.Column.Name, required)
+					addTag(`valid:"%v,%v|s,%v;"`, field.Column.Name, required, g.FieldRange(s, field, "l", "0"))
 				}
 			case "decimal.Decimal":
-				addTag(`valid:"%v,%v|f,r:0;"`, field.Column.Name, required)
+				addTag(`valid:"%v,%v|f,%v;"`, field.Column.Name, required, g.FieldRange(s, field, "r", "0"))
 			case "xsql.Time":
 				addTag(`valid:"%v,%v|i,r:1;"`, field.Column.Name, required)
 			}
@@ -445,6 +921,131 @@ func (g *AutoGen) FieldTags(s *Struct, field *Field) (allTag string) {
 	return
 }
 
+var (
+	typeLengthRegexp    = regexp.MustCompile(`(?i)^\s*(character varying|varying character|varchar|nvarchar|character|char)\s*\(\s*(\d+)\s*\)`)
+	typePrecisionRegexp = regexp.MustCompile(`(?i)^\s*(numeric|decimal)\s*\(\s*(\d+)\s*(,\s*(\d+)\s*)?\)`)
+	checkBetweenRegexp  = regexp.MustCompile(`(?i)"?(\w+)"?\s+between\s+(-?[\d.]+)\s+and\s+(-?[\d.]+)`)
+	checkCastRegexp     = regexp.MustCompile(`::\w+(\s+precision|\s+varying)?`)
+	checkTermRegexp     = regexp.MustCompile(`(?i)^(?:(length|char_length|character_length)\s+)?"?(\w+)"?\s*(>=|>|<=|<)\s*(-?\d+(?:\.\d+)?)$`)
+	checkAndRegexp      = regexp.MustCompile(`(?i)\s+and\s+`)
+	checkOrRegexp       = regexp.MustCompile(`(?i)\s+or\s+`)
+)
+
+// FieldRange will return the valid range of field which is derived from column type precision and simple check constraint,
+// the range of attrvalid is exclusive, so numeric(4) will be r:0~10000, the kind is l for length or r for value, the begin is default begin.
+// The length end is not emitted as l of attrvalid is counting bytes, but varchar(n) and length() is counting characters
+func (g *AutoGen) FieldRange(s *Struct, field *Field, kind, begin string) (result string) {
+	end := ""
+	if kind == "r" {
+		if match := typePrecisionRegexp.FindStringSubmatch(field.Column.Type); len(match) > 0 {
+			precision, _ := strconv.Atoi(match[2])
+			scale, _ := strconv.Atoi(match[4])
+			if precision > scale {
+				end = "1" + strings.Repeat("0", precision-scale)
+			}
+		}
+	}
+	integer := kind == "l" || !strings.Contains(field.Type, "decimal") && !strings.Contains(field.Type, "float")
+	for _, check := range s.Table.Checks {
+		checkBegin, checkEnd := checkRange(check.Expr, field.Column.Name, kind == "l", integer)
+		if len(checkBegin) > 0 {
+			begin = checkBegin
+		}
+		if len(checkEnd) > 0 && kind != "l" {
+			checkValue, _ := strconv.ParseFloat(checkEnd, 64)
+			endValue, _ := strconv.ParseFloat(end, 64)
+			if len(end) < 1 || checkValue < endValue {
+				end = checkEnd
+			}
+		}
+	}
+	result = kind + ":" + begin
+	if len(end) > 0 {
+		result += "~" + end
+	}
+	return
+}
+
+// checkRange will parse exclusive begin/end of column from simple check constraint like level >= 0 AND level <= 10 or length(title) <= 64,
+// the constraint having OR is not supported
+func checkRange(expr, column string, length, integer bool) (begin, end string) {
+	for _, body := range checkBodies(expr) {
+		body = checkBetweenRegexp.ReplaceAllString(body, "$1 >= $2 and $1 <= $3")
+		body = checkCastRegexp.ReplaceAllString(body, "")
+		body = strings.NewReplacer("(", " ", ")", " ").Replace(body)
+		if checkOrRegexp.MatchString(body) {
+			continue
+		}
+		for _, term := range checkAndRegexp.Split(strings.TrimSpace(body), -1) {
+			match := checkTermRegexp.FindStringSubmatch(strings.Join(strings.Fields(term), " "))
+			if len(match) < 1 || match[2] != column || (len(match[1]) > 0) != length {
+				continue
+			}
+			value := match[4]
+			inclusive := strings.HasSuffix(match[3], "=")
+			if inclusive && !integer {
+				continue
+			}
+			if inclusive {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					continue
+				}
+				if match[3] == ">=" {
+					n--
+				} else {
+					n++
+				}
+				value = fmt.Sprintf("%v", n)
+			}
+			if strings.HasPrefix(match[3], ">") {
+				begin = value
+			} else {
+				end = value
+			}
+		}
+	}
+	return
+}
+
+// checkBodies will return the body of all CHECK(...) in expr, the expr is returned when CHECK is not found
+func checkBodies(expr string) (bodies []string) {
+	upper := strings.ToUpper(expr)
+	offset := 0
+	for {
+		index := strings.Index(upper[offset:], "CHECK")
+		if index < 0 {
+			break
+		}
+		start := strings.Index(expr[offset+index:], "(")
+		if start < 0 {
+			break
+		}
+		start += offset + index
+		depth, stop := 0, -1
+		for i := start; i < len(expr) && stop < 0; i++ {
+			switch expr[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					stop = i
+				}
+			}
+		}
+		if stop < 0 {
+			break
+		}
+		bodies = append(bodies, expr[start+1:stop])
+		offset = stop
+	}
+	if len(bodies) < 1 && !strings.Contains(upper, "CREATE") {
+		bodies = append(bodies, expr)
+	}
+	return
+}
+
 func (g *AutoGen) FieldJson(s *Struct, field *Field) (tag string) {
 	var fieldNotOmit = xsql.StringArray{}
 	if fieldConfig := g.FieldFilter[s.Table.Name]; len(fieldConfig) > 0 {
@@ -480,7 +1081,60 @@ func (g *AutoGen) FieldDefineType(s *Struct, field *Field) (result string) {
 	return
 }
 
-func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
+func (g *AutoGen) FieldTSName(s *Struct, field *Field) (name string) {
+	parts := strings.SplitN(g.FieldJson(s, field), ",", 2)
+	name = parts[0]
+	if len(parts) > 1 && strings.Contains(parts[1], "omitempty") {
+		name += "?"
+	}
+	return
+}
+
+func (g *AutoGen) FieldTSType(s *Struct, field *Field) (result string) {
+	if len(field.Options) > 0 {
+		values := []string{}
+		for _, option := range field.Options {
+			values = append(values, option.Value)
+		}
+		result = strings.Join(values, " | ")
+		return
+	}
+	if g.FieldJSONType(s, field) {
+		result = "Record<string, unknown>"
+		return
+	}
+	int64Type := "number"
+	if g.TSInt64String {
+		int64Type = "string"
+	}
+	typ := strings.TrimPrefix(g.FieldType(s, field), "*")
+	switch typ {
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "float32", "float64", "xsql.Time":
+		result = "number"
+	case "int64", "uint64":
+		result = int64Type
+	case "string", "decimal.Decimal":
+		result = "string"
+	case "bool":
+		result = "boolean"
+	case "xsql.M":
+		result = "Record<string, unknown>"
+	case "xsql.MArray":
+		result = "Record<string, unknown>[]"
+	case "xsql.IntArray", "xsql.Float64Array":
+		result = "number[]"
+	case "xsql.Int64Array":
+		result = int64Type + "[]"
+	case "xsql.StringArray":
+		result = "string[]"
+	default:
+		result = "unknown"
+	}
+	return
+}
+
+// applyDefaults will set the nil options to default value, it is called before template is executed and options is hashed
+func (g *AutoGen) applyDefaults() {
 	if g.FieldFilter == nil {
 		g.FieldFilter = map[string]map[string]string{}
 	}
@@ -510,25 +1164,28 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 	if len(g.TableNameType) < 1 {
 		g.TableNameType = "string"
 	}
-	for _, column := range table.Columns {
-		comments, ok := g.Comments[table.Name]
-		if !ok {
-			continue
-		}
-		comment, ok := comments[column.Name]
-		if !ok {
-			continue
-		}
-		column.Comment = comment
-	}
-	s := gen.AsStruct(table)
-	result := map[string]interface{}{
-		"TableNameType": g.TableNameType,
-		"Struct":        s,
-		"Code":          g.CodeSlice,
-		"GetQueryer":    g.GetQueryer,
-		"GenValid":      !g.TableNotValid.HavingOne(table.Name),
-	}
+}
+
+func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
+	g.applyDefaults()
+	g.applyComments(table)
+	s := g.asStruct(gen, table)
+	result := &TemplateData{
+		TableNameType:   g.TableNameType,
+		TableName:       table.Name,
+		Struct:          s,
+		Code:            g.CodeSlice,
+		GetQueryer:      g.GetQueryer,
+		GenValid:        !g.TableNotValid.HavingOne(table.Name) && !table.IsView(),
+		EnumHelpers:     g.EnumHelpers,
+		DocComments:     g.DocComments,
+		TestAgainstMock: g.TestAgainstMock,
+		View:            table.IsView(),
+	}
+	if len(g.qualify) > 0 {
+		result.TableName = g.qualify + "." + table.Name
+	}
+	auditInsert, auditUpdate, auditAll := g.auditFields(s)
 	fieldOptional := ""
 	fieldRequired := ""
 	fieldInsert := ""
@@ -546,6 +1203,9 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 		fieldOrder = fieldConfig[FieldsOrder]
 		fieldFind = fieldConfig[FieldsFind]
 		fieldScan = fieldConfig[FieldsScan]
+		fieldOptional = excludeFilter(fieldOptional, auditAll)
+		fieldRequired = excludeFilter(fieldRequired, auditAll)
+		fieldUpdate = excludeFilter(fieldUpdate, auditAll)
 		if len(fieldOptional) > 0 {
 			fieldOptionalValue = xsql.AsStringArray(strings.SplitN(fieldOptional, "#", 2)[0])
 		}
@@ -592,18 +1252,25 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 			fieldUpdateAll = append(fieldUpdateAll, field)
 		}
 	}
-	result["Filter"] = map[string]interface{}{
-		"Optional": fieldOptional,
-		"Required": fieldRequired,
-		"Insert":   fieldInsert,
-		"Update":   strings.TrimSuffix("update_time,"+fieldUpdate, ","),
-		"Order":    fieldOrder,
-		"Find":     fieldFind,
-		"Scan":     fieldScan,
+	for _, field := range s.Fields {
+		if field.Column.Name == "update_time" {
+			fieldUpdate = strings.TrimSuffix("update_time,"+fieldUpdate, ",")
+			break
+		}
+	}
+	result.Filter = TemplateFilter{
+		Optional: fieldOptional,
+		Required: fieldRequired,
+		Insert:   fieldInsert,
+		Update:   fieldUpdate,
+		Order:    fieldOrder,
+		Find:     fieldFind,
+		Scan:     fieldScan,
 	}
+	result.Defaults = g.columnDefaults(s)
 	arg := strings.ToLower(s.Name[0:1]) + s.Name[1:]
-	result["Arg"] = map[string]interface{}{
-		"Name": arg,
+	result.Arg = TemplateArg{
+		Name: arg,
 	}
 	{
 
@@ -613,6 +1280,9 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 			if len(typeFields) > 0 && len(typeFields[field.Column.Name]) > 0 {
 				continue
 			}
+			if g.FieldJSONType(s, field) {
+				continue
+			}
 			switch field.Type {
 			case "xsql.Time":
 				if field.Column.Name == "create_time" || field.Column.Name == "update_time" {
@@ -631,6 +1301,27 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 				`, arg, field.Name, arg, field.Name, typ)
 			}
 		}
+		if g.ApplyColumnDefaults {
+			for _, value := range result.Defaults {
+				field := value.Field
+				typ := g.FieldType(s, field)
+				if strings.HasPrefix(typ, "*") {
+					defaults += fmt.Sprintf(`
+						if %v.%v == nil {
+							%vDefault := %v(%v)
+							%v.%v = &%vDefault
+						}
+					`, arg, field.Name, argName(field.Name), strings.TrimPrefix(typ, "*"), value.Name, arg, field.Name, argName(field.Name))
+				} else if zero := g.defaultZero(typ, field); zero != value.Value {
+					defaults += fmt.Sprintf(`
+						if %v.%v == %v {
+							%v.%v = %v
+						}
+					`, arg, field.Name, zero, arg, field.Name, value.Name)
+				}
+			}
+		}
+		defaults += g.auditCode(s, arg, auditInsert)
 		if code, ok := g.CodeAddInit[s.Table.Name]; ok {
 			defaults += strings.ReplaceAll(code, "ARG.", arg+".")
 		}
@@ -645,11 +1336,11 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 				addReturn = ""
 			}
 		}
-		result["Add"] = map[string]interface{}{
-			"Defaults": defaults,
-			"Filter":   addFilter,
-			"Return":   addReturn,
-			"Normal":   g.TableGenAdd.HavingOne(table.Name),
+		result.Add = TemplateAdd{
+			Defaults: defaults,
+			Filter:   addFilter,
+			Return:   addReturn,
+			Normal:   g.TableGenAdd.HavingOne(table.Name),
 		}
 	}
 	{
@@ -657,226 +1348,1547 @@ func (g *AutoGen) OnPre(gen *Gen, table *Table) (data interface{}) {
 		if code, ok := g.CodeTestInit[s.Table.Name]; ok {
 			defaults += strings.ReplaceAll(code, "ARG.", arg+".")
 		}
-		result["Test"] = map[string]interface{}{
-			"Defaults": defaults,
+		var orderField *Field
+		for _, key := range strings.Split(strings.SplitN(fieldOrder, "#", 2)[0], ",") {
+			for _, field := range s.Fields {
+				if field.Column.Name == key {
+					orderField = field
+					break
+				}
+			}
+			if orderField != nil {
+				break
+			}
+		}
+		auditCheck := []string{}
+		for _, field := range s.Fields {
+			if !auditAll.HavingOne(field.Column.Name) {
+				continue
+			}
+			typ := g.FieldType(s, field)
+			if strings.HasPrefix(typ, "*") {
+				auditCheck = append(auditCheck, fmt.Sprintf("find%v.%v == nil || *find%v.%v != %v", s.Name, field.Name, s.Name, field.Name, auditUser(strings.TrimPrefix(typ, "*"))))
+			} else {
+				auditCheck = append(auditCheck, fmt.Sprintf("find%v.%v != %v", s.Name, field.Name, auditUser(typ)))
+			}
 		}
-	}
-	{
-		havingUpdateTime := false
+		fixture := ""
+		if len(result.Add.Return) > 0 || table.IsView() {
+			for _, field := range s.Fields {
+				if key := fixtureKey(g.FieldType(s, field)); field.Column.IsPK && len(key) > 0 {
+					fixture += fmt.Sprintf("%v.%v = %v\n", arg, field.Name, key)
+				}
+			}
+		}
+		auditFields := []*Field{}
 		for _, field := range s.Fields {
-			if field.Name == "UpdateTime" {
-				havingUpdateTime = true
-				break
+			if auditAll.HavingOne(field.Column.Name) {
+				auditFields = append(auditFields, field)
 			}
 		}
-		result["Update"] = map[string]interface{}{
-			"UpdateTime": havingUpdateTime,
-			"Fields":     fieldUpdateAll,
+		fixture += g.auditCode(s, arg, auditFields)
+		result.Test = TemplateTest{
+			Defaults:   defaults,
+			OrderField: orderField,
+			Audit:      strings.Join(auditCheck, " || "),
+			Fixture:    fixture,
 		}
 	}
+	{
+		conflict := g.TableUpsert[s.Table.Name]
+		conflictFields := []*Field{}
+		for _, key := range strings.Split(conflict, ",") {
+			for _, field := range s.Fields {
+				if field.Column.Name == strings.TrimSpace(key) {
+					conflictFields = append(conflictFields, field)
+				}
+			}
+		}
+		result.Upsert = TemplateUpsert{
+			Conflict: conflict,
+			Fields:   conflictFields,
+		}
+	}
+	{
+		havingUpdateTime := false
+		for _, field := range s.Fields {
+			if field.Name == "UpdateTime" {
+				havingUpdateTime = true
+				break
+			}
+		}
+		auditFilter := []string{}
+		for _, field := range auditUpdate {
+			auditFilter = append(auditFilter, field.Column.Name)
+		}
+		result.Update = TemplateUpdate{
+			UpdateTime:  havingUpdateTime,
+			Fields:      fieldUpdateAll,
+			Audit:       g.auditCode(s, arg, auditUpdate),
+			AuditFilter: strings.Join(auditFilter, ","),
+		}
+	}
+	result.Relations = g.relations(gen, s)
+	result.Uniques = g.uniques(s)
+	result.Wheres = g.wheres(s)
 	data = result
 	return
 }
 
+// auditFields will return the audit fields of struct by AuditFields, all is the column name of all audit fields
+func (g *AutoGen) auditFields(s *Struct) (insert, update []*Field, all xsql.StringArray) {
+	for _, field := range s.Fields {
+		mode, ok := g.AuditFields[field.Column.Name]
+		if !ok || field.Column.IsPK {
+			continue
+		}
+		modes := xsql.AsStringArray(mode)
+		if modes.HavingOne("insert") {
+			insert = append(insert, field)
+		}
+		if modes.HavingOne("update") {
+			update = append(update, field)
+		}
+		all = append(all, field.Column.Name)
+	}
+	return
+}
+
+// auditCode will return the code to set audit fields by GetAuditUser when they are zero
+func (g *AutoGen) auditCode(s *Struct, arg string, fields []*Field) (code string) {
+	for _, field := range fields {
+		typ := g.FieldType(s, field)
+		if strings.HasPrefix(typ, "*") {
+			code += fmt.Sprintf(`
+				if %v.%v == nil {
+					auditUser := %v
+					%v.%v = &auditUser
+				}
+			`, arg, field.Name, auditUser(strings.TrimPrefix(typ, "*")), arg, field.Name)
+		} else {
+			code += fmt.Sprintf(`
+				if %v.%v == %v {
+					%v.%v = %v
+				}
+			`, arg, field.Name, g.defaultZero(typ, field), arg, field.Name, auditUser(typ))
+		}
+	}
+	return
+}
+
+// fixtureKey will return the primary key value of fixture which is returned by mocker queryer, it is empty when type is not supported
+func fixtureKey(typ string) string {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "1"
+	case "string":
+		return `"00000000-0000-0000-0000-000000000001"`
+	}
+	return ""
+}
+
+func auditUser(typ string) string {
+	if typ == "int64" {
+		return "GetAuditUser(ctx)"
+	}
+	return typ + "(GetAuditUser(ctx))"
+}
+
+// excludeFilter will remove columns from include filter or add columns to exclude filter(start with ^)
+func excludeFilter(filter string, columns xsql.StringArray) string {
+	if len(filter) < 1 || len(columns) < 1 {
+		return filter
+	}
+	parts := strings.SplitN(filter, "#", 2)
+	names := []string{}
+	if strings.HasPrefix(parts[0], "^") {
+		names = strings.Split(parts[0], ",")
+		for _, column := range columns {
+			if !xsql.StringArray(names).HavingOne(column) && "^"+column != names[0] {
+				names = append(names, column)
+			}
+		}
+	} else {
+		for _, name := range strings.Split(parts[0], ",") {
+			if !columns.HavingOne(strings.TrimSpace(name)) {
+				names = append(names, name)
+			}
+		}
+	}
+	parts[0] = strings.Join(names, ",")
+	return strings.Join(parts, "#")
+}
+
+// templateData will return the template data by OnPre and PreData
+func (g *AutoGen) templateData(gen *Gen, table *Table) (data interface{}) {
+	data = g.OnPre(gen, table)
+	if g.PreData != nil {
+		data = g.PreData(gen, table, data.(*TemplateData))
+	}
+	return
+}
+
+// Map will return the map of template data, the map can be returned by PreData with other custom key
+func (t *TemplateData) Map() (data map[string]interface{}) {
+	data = map[string]interface{}{}
+	value := reflect.ValueOf(t).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		data[value.Type().Field(i).Name] = value.Field(i).Interface()
+	}
+	return
+}
+
+// AsTemplateData will convert *TemplateData or map[string]interface{} returned by PreData to *TemplateData,
+// the map key which is not TemplateData field is ignored
+func AsTemplateData(data interface{}) (result *TemplateData, err error) {
+	switch v := data.(type) {
+	case *TemplateData:
+		result = v
+	case TemplateData:
+		result = &v
+	case map[string]interface{}:
+		result = &TemplateData{}
+		value := reflect.ValueOf(result).Elem()
+		for key, val := range v {
+			field := value.FieldByName(key)
+			if !field.IsValid() || val == nil {
+				continue
+			}
+			fieldValue := reflect.ValueOf(val)
+			if fieldValue.Type().AssignableTo(field.Type()) {
+				field.Set(fieldValue)
+			} else if fieldValue.Type().ConvertibleTo(field.Type()) {
+				field.Set(fieldValue.Convert(field.Type()))
+			} else {
+				err = fmt.Errorf("template data %v type %v is not assignable to %v", key, fieldValue.Type(), field.Type())
+				result = nil
+				return
+			}
+		}
+		if result.Struct == nil {
+			err = fmt.Errorf("template data Struct is not setted")
+			result = nil
+		}
+	default:
+		err = fmt.Errorf("template data type %T is not supported", data)
+	}
+	return
+}
+
+// applyComments will override the table and column comment by Comments, the empty column name key is the table comment,
+// it is applied before AsStruct so the struct, apidoc and openapi is using same comment
+func (g *AutoGen) applyComments(table *Table) {
+	comments, ok := g.Comments[table.Name]
+	if !ok {
+		return
+	}
+	if comment, ok := comments[""]; ok {
+		table.Comment = comment
+	}
+	for _, column := range table.Columns {
+		if comment, ok := comments[column.Name]; ok {
+			column.Comment = comment
+		}
+	}
+}
+
+func (g *AutoGen) asStruct(gen *Gen, table *Table) (s *Struct) {
+	s = gen.AsStruct(table)
+	renames := g.ColumnRename[table.Name]
+	for _, field := range s.Fields {
+		if name, ok := renames[field.Column.Name]; ok {
+			field.Name = name
+		}
+	}
+	return
+}
+
+func (g *AutoGen) uniques(s *Struct) (uniques []*TemplateUnique) {
+	having := map[string]bool{
+		g.PrimaryField(s, "Name"): true,
+	}
+	for _, index := range s.Table.Uniques {
+		unique := &TemplateUnique{}
+		for _, column := range index.Columns {
+			for _, field := range s.Fields {
+				if field.Column.Name == column {
+					unique.Name += field.Name
+					unique.Fields = append(unique.Fields, field)
+					unique.Args = append(unique.Args, argName(field.Name))
+					typ := g.FieldType(s, field)
+					unique.Types = append(unique.Types, strings.TrimPrefix(typ, "*"))
+					unique.Pointers = append(unique.Pointers, strings.HasPrefix(typ, "*"))
+					if strings.HasPrefix(typ, "*") {
+						unique.Guards = append(unique.Guards, field.Name)
+					}
+					break
+				}
+			}
+		}
+		if len(unique.Fields) != len(index.Columns) || having[unique.Name] {
+			continue
+		}
+		having[unique.Name] = true
+		uniques = append(uniques, unique)
+	}
+	return
+}
+
+// ColumnDefaultLiteral will normalize the column default value to go literal, the expression like now() is not supported,
+// it supports postgres default like 100, '-1'::integer, 'abc'::character varying
+func ColumnDefaultLiteral(value string) (literal string, ok bool) {
+	value = trimDefaultCast(value)
+	for len(value) > 1 && value[0] == '(' && value[len(value)-1] == ')' {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	switch {
+	case regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`).MatchString(value):
+		literal, ok = value, true
+	case strings.EqualFold(value, "true") || strings.EqualFold(value, "false"):
+		literal, ok = strings.ToLower(value), true
+	case len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'':
+		inner := value[1 : len(value)-1]
+		if strings.Contains(strings.ReplaceAll(inner, "''", ""), "'") {
+			break
+		}
+		inner = strings.ReplaceAll(inner, "''", "'")
+		if regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`).MatchString(inner) {
+			literal, ok = inner, true
+		} else {
+			literal, ok = strconv.Quote(inner), true
+		}
+	}
+	return
+}
+
+// trimDefaultCast will remove the postgres type cast like ::character varying on end of default value
+func trimDefaultCast(value string) string {
+	value = strings.TrimSpace(value)
+	for {
+		index := strings.LastIndex(value, "::")
+		if index < 0 || strings.Contains(value[index:], "'") {
+			break
+		}
+		value = strings.TrimSpace(value[:index])
+	}
+	return value
+}
+
+func (g *AutoGen) columnDefaults(s *Struct) (defaults []*TemplateDefault) {
+	for _, field := range s.Fields {
+		if field.Column.IsPK || field.Column.DefaultValue == nil {
+			continue
+		}
+		literal, ok := ColumnDefaultLiteral(*field.Column.DefaultValue)
+		if !ok {
+			continue
+		}
+		typ := strings.TrimPrefix(g.FieldType(s, field), "*")
+		if len(field.Options) > 0 {
+			typ = strings.TrimPrefix(field.Type, "*")
+		}
+		quoted := strings.HasPrefix(literal, `"`)
+		switch typ {
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+			ok = !quoted && literal != "true" && literal != "false"
+		case "string":
+			ok = quoted
+		case "bool":
+			ok = literal == "true" || literal == "false"
+		default:
+			ok = false
+		}
+		if !ok {
+			continue
+		}
+		defaults = append(defaults, &TemplateDefault{
+			Name:  s.Name + field.Name + "Default",
+			Value: literal,
+			Field: field,
+		})
+	}
+	return
+}
+
+func (g *AutoGen) defaultZero(typ string, field *Field) string {
+	if len(field.Options) > 0 {
+		typ = field.Type
+	}
+	switch typ {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	default:
+		return "0"
+	}
+}
+
+// argName will convert field name to argument name, like UUID to uuid, MerchantID to merchantID
+func argName(name string) (arg string) {
+	upper := 0
+	for upper < len(name) && name[upper] >= 'A' && name[upper] <= 'Z' {
+		upper++
+	}
+	switch {
+	case upper == len(name):
+		arg = strings.ToLower(name)
+	case upper > 1:
+		arg = strings.ToLower(name[:upper-1]) + name[upper-1:]
+	default:
+		arg = strings.ToLower(name[:upper]) + name[upper:]
+	}
+	if token.IsKeyword(arg) {
+		arg += "Value"
+	}
+	return
+}
+
+func (g *AutoGen) relations(gen *Gen, s *Struct) (relations []*TemplateRelation) {
+	included := g.included
+	if included == nil {
+		included = gen.Tables
+	}
+	for _, key := range s.Table.ForeignKeys {
+		var refTable *Table
+		for _, table := range included {
+			if table.Name == key.RefTable {
+				refTable = table
+				break
+			}
+		}
+		if refTable == nil {
+			continue
+		}
+		ref := g.asStruct(gen, refTable)
+		refColumn := g.PrimaryField(ref, "Column")
+		if len(refColumn) < 1 || (len(key.RefColumn) > 0 && key.RefColumn != refColumn) {
+			continue
+		}
+		for _, field := range s.Fields {
+			if field.Column.Name != key.Column {
+				continue
+			}
+			name := strings.TrimSuffix(field.Name, "ID")
+			if len(name) < 1 {
+				name = ref.Name
+			}
+			relations = append(relations, &TemplateRelation{
+				Name:    name,
+				Arg:     strings.ToLower(name[0:1]) + name[1:],
+				Field:   field,
+				Pointer: strings.HasPrefix(g.FieldType(s, field), "*"),
+				Ref:     ref,
+			})
+			break
+		}
+	}
+	return
+}
+
+// wheres will return the field of typed where filter struct, it is configured by FieldFilter where
+// or default to primary key, option and foreign key fields, the option field is filtered by array
+func (g *AutoGen) wheres(s *Struct) (wheres []*TemplateWhere) {
+	where := g.FieldFilter[s.Table.Name][FieldsWhere]
+	columns := xsql.StringArray{}
+	if len(where) > 0 {
+		columns = xsql.AsStringArray(strings.SplitN(where, "#", 2)[0])
+	} else {
+		for _, key := range s.Table.ForeignKeys {
+			columns = append(columns, key.Column)
+		}
+	}
+	whereIn := g.CodeSlice["WhereIn"]
+	if len(whereIn) < 1 {
+		whereIn = "COLUMN=any($%v)"
+	}
+	for _, field := range s.Fields {
+		if !columns.HavingOne(field.Column.Name) && (len(where) > 0 || (!field.Column.IsPK && len(field.Options) < 1)) {
+			continue
+		}
+		if len(field.Options) > 0 {
+			wheres = append(wheres, &TemplateWhere{
+				Field: field,
+				Type:  g.FieldType(s, field) + "Array",
+				Cmp:   strings.ReplaceAll(whereIn, "COLUMN", field.Column.Name),
+			})
+		} else {
+			wheres = append(wheres, &TemplateWhere{
+				Field: field,
+				Type:  "*" + strings.TrimPrefix(g.FieldType(s, field), "*"),
+				Cmp:   field.Column.Name + "=$%v",
+			})
+		}
+	}
+	return
+}
+
+func (g *AutoGen) TemplateFiles(section string) (files []string) {
+	if file := g.TemplateOverrides[section]; len(file) > 0 {
+		if len(g.TemplateDir) > 0 && !filepath.IsAbs(file) {
+			file = filepath.Join(g.TemplateDir, file)
+		}
+		files = append(files, file)
+	} else if len(g.TemplateDir) > 0 {
+		file := filepath.Join(g.TemplateDir, section+".tmpl")
+		if _, err := os.Stat(file); err == nil {
+			files = append(files, file)
+		}
+	}
+	return
+}
+
 func (g *AutoGen) Generate() (err error) {
+	_, _, err = g.GenerateFiles()
+	return
+}
+
+// GenerateFiles will generate code and return the written/updated file names,
+// when DryRun is true, the generated files is only returned by files without touching disk
+func (g *AutoGen) GenerateFiles() (files map[string][]byte, written []string, err error) {
+	if len(g.Schemas) > 0 {
+		files, written, err = g.generateSchemas()
+		return
+	}
+	g.files = map[string][]byte{}
+	g.written = nil
+	defer func() {
+		if g.DryRun {
+			files = g.files
+		}
+		written = g.written
+		g.files, g.written = nil, nil
+	}()
+	err = g.generate()
+	return
+}
+
+// generateSchemas will generate each schema to its package, the table not in AutoGen.Schema is qualified by schema name,
+// the returned file name is relative to AutoGen.Out
+func (g *AutoGen) generateSchemas() (files map[string][]byte, written []string, err error) {
+	if g.DryRun {
+		files = map[string][]byte{}
+	}
+	for _, schema := range g.Schemas {
+		schemaGen := *g
+		schemaGen.Schemas = nil
+		schemaGen.Schema = schema.Name
+		schemaGen.Out = schema.Out
+		if len(schemaGen.Out) < 1 {
+			schemaGen.Out = filepath.Join(g.Out, schema.Name)
+		}
+		schemaGen.OutPackage = schema.OutPackage
+		if len(schemaGen.OutPackage) < 1 {
+			schemaGen.OutPackage = schema.Name
+		}
+		schemaGen.TableInclude = schema.TableInclude
+		schemaGen.TableExclude = schema.TableExclude
+		if schema.Name != g.Schema {
+			schemaGen.qualify = schema.Name
+		}
+		prefix, xerr := filepath.Rel(g.Out, schemaGen.Out)
+		if xerr != nil {
+			prefix = schemaGen.Out
+		}
+		if g.Output != nil {
+			schemaGen.Output = func(name string, content []byte) error {
+				return g.Output(filepath.Join(prefix, name), content)
+			}
+		} else if !g.DryRun {
+			err = os.MkdirAll(schemaGen.Out, os.ModePerm)
+			if err != nil {
+				break
+			}
+		}
+		schemaFiles, schemaWritten, xerr := schemaGen.GenerateFiles()
+		if xerr != nil {
+			err = fmt.Errorf("generate schema %v fail with %v", schema.Name, xerr)
+			break
+		}
+		for name, data := range schemaFiles {
+			files[filepath.Join(prefix, name)] = data
+		}
+		for _, name := range schemaWritten {
+			written = append(written, filepath.Join(prefix, name))
+		}
+	}
+	return
+}
+
+const (
+	ImportsStruct = "struct"
+	ImportsDefine = "define"
+	ImportsFunc   = "func"
+	ImportsTest   = "test"
+)
+
+// RenderPre will render the file preamble by comment, package placeholder and import block, it is used as default OutXXXPre
+func RenderPre(comment string, imports ...[]string) string {
+	return fmt.Sprintf("\n//%v\npackage %%v\n%v", comment, RenderImports(imports...))
+}
+
+// RenderImports will render import block, the import is path or "name path", it is de-duplicated and grouped to std and others,
+// the order in group is kept and sorted by go/format when writing source
+func RenderImports(imports ...[]string) string {
+	having := map[string]bool{}
+	groups := [][]string{{}, {}}
+	for _, list := range imports {
+		for _, value := range list {
+			spec, path := importSpec(value)
+			if len(spec) < 1 || having[spec] {
+				continue
+			}
+			having[spec] = true
+			if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+				groups[1] = append(groups[1], spec)
+			} else {
+				groups[0] = append(groups[0], spec)
+			}
+		}
+	}
+	if len(groups[0])+len(groups[1]) < 1 {
+		return ""
+	}
+	buffer := bytes.NewBufferString("import (\n")
+	for i, group := range groups {
+		if i > 0 && len(groups[0]) > 0 && len(group) > 0 {
+			buffer.WriteString("\n")
+		}
+		for _, spec := range group {
+			buffer.WriteString("\t" + spec + "\n")
+		}
+	}
+	buffer.WriteString(")\n")
+	return buffer.String()
+}
+
+// AppendImports will append the import block of imports which is not imported by pre
+func AppendImports(pre string, imports []string) string {
+	if len(imports) < 1 {
+		return pre
+	}
+	existed := map[string]bool{}
+	file, err := parser.ParseFile(token.NewFileSet(), "", fmt.Sprintf(pre, "autogen"), parser.ImportsOnly)
+	if err == nil {
+		for _, spec := range file.Imports {
+			name := ""
+			if spec.Name != nil {
+				name = spec.Name.Name + " "
+			}
+			existed[name+spec.Path.Value] = true
+		}
+	}
+	missing := []string{}
+	for _, value := range imports {
+		if spec, _ := importSpec(value); len(spec) > 0 && !existed[spec] {
+			missing = append(missing, value)
+		}
+	}
+	return pre + "\n" + RenderImports(missing)
+}
+
+// importSpec will normalize import value to "path" or name "path"
+func importSpec(value string) (spec, path string) {
+	parts := strings.Fields(value)
+	switch len(parts) {
+	case 1:
+		path = strings.Trim(parts[0], `"`)
+		spec = strconv.Quote(path)
+	case 2:
+		path = strings.Trim(parts[1], `"`)
+		spec = parts[0] + " " + strconv.Quote(path)
+	}
+	return
+}
+
+// queryTables will query tables by TableQueryer or QueryWithProgress
+func (g *AutoGen) queryTables() (tables []*Table, err error) {
+	if g.TableQueryer != nil {
+		tables, err = g.TableQueryer(g.Queryer, g.TableSQL, g.ColumnSQL, g.Schema)
+	} else {
+		tables, err = QueryWithProgress(g.Queryer, g.TableSQL, g.ColumnSQL, g.ColumnAllSQL, g.Schema, g.OnTable)
+	}
+	return
+}
+
+// ddlTables will parse tables from TablesFromDDL files, the table without schema is belong to default schema
+func (g *AutoGen) ddlTables() (tables []*Table, err error) {
+	allTables, warnings, err := ParseDDLFiles(g.TablesFromDDL...)
+	if err != nil {
+		return
+	}
+	for _, warning := range warnings {
+		if g.OnWarning != nil {
+			g.OnWarning(warning)
+		} else {
+			log.Printf("gen: %v", warning)
+		}
+	}
+	for _, table := range allTables {
+		if len(g.Schema) < 1 || table.Schema == g.Schema || (len(table.Schema) < 1 && len(g.qualify) < 1) {
+			tables = append(tables, table)
+		}
+	}
+	return
+}
+
+func (g *AutoGen) generate() (err error) {
 	if g.TypeMap == nil {
 		g.TypeMap = map[string][]string{}
 	}
-	if g.TableQueryer == nil {
-		g.TableQueryer = Query
+	if len(g.OutPackage) < 1 {
+		g.OutPackage = "autogen"
+	}
+	for kind := range g.ExtraImports {
+		switch kind {
+		case ImportsStruct, ImportsDefine, ImportsFunc, ImportsTest:
+		default:
+			err = fmt.Errorf("extra imports kind %v is not supported", kind)
+			return
+		}
+	}
+	structPre := g.OutStructPre
+	if len(structPre) < 1 {
+		structPre = RenderPre("auto gen models by autogen", []string{
+			"github.com/codingeasygo/util/xsql",
+			"github.com/shopspring/decimal",
+		}, g.ExtraImports[ImportsStruct])
+	} else {
+		structPre = AppendImports(structPre, g.ExtraImports[ImportsStruct])
+	}
+	definePre := g.OutDefinePre
+	if len(definePre) < 1 {
+		definePre = RenderPre("auto gen func by autogen", nil, g.ExtraImports[ImportsDefine])
+	} else {
+		definePre = AppendImports(definePre, g.ExtraImports[ImportsDefine])
+	}
+	funcDefine := ""
+	funcPre := g.OutFuncPre
+	if len(funcPre) > 0 {
+		funcPre = AppendImports(funcPre, g.ExtraImports[ImportsFunc])
+	} else {
+		funcPre = RenderPre("auto gen func by autogen", []string{
+			"reflect",
+			"context",
+			"database/sql/driver",
+			"encoding/json",
+			"fmt",
+			"github.com/codingeasygo/crud",
+			"github.com/codingeasygo/util/attrvalid",
+			"github.com/codingeasygo/util/converter",
+			"github.com/codingeasygo/util/xsql",
+		}, g.ExtraImports[ImportsFunc])
+		if len(g.GetQueryer) > 0 && g.GetQueryer != "GetQueryer" {
+			funcDefine += fmt.Sprintf(`
+				var GetQueryer interface{} = func() crud.Queryer { return %v() }
+			`, g.GetQueryer)
+		} else if len(g.GetQueryer) > 0 && g.GetQueryer == "GetQueryer" {
+			funcDefine += `
+				var GetQueryer interface{} = func() crud.Queryer { panic("get crud queryer is not setted") }
+			`
+		}
+		if len(g.AuditFields) > 0 {
+			funcDefine += `
+				//GetAuditUser will return the current user id from context, which is used to set audit fields
+				var GetAuditUser = func(ctx context.Context) int64 { panic("get audit user is not setted") }
+			`
+		}
+	}
+	funcCommon := g.OutFuncCommon
+	if len(funcCommon) < 1 {
+		funcCommon = `
+			//Validable is interface to valid
+			type Validable interface {
+				Valid() error
+			}
+		`
+	}
+	testImports := g.ExtraImports[ImportsTest]
+	testCommon := g.OutTestCommon
+	if g.TestAgainstMock {
+		testImports = append([]string{"github.com/codingeasygo/crud/mocker"}, testImports...)
+		testCommon += `
+			func init() {
+				//the generated test is running against mocker.FixtureShared, the fixture of each table is registered by Fixture func
+				GetQueryer = func() crud.Queryer { return mocker.FixtureShared }
+			}
+		`
+	}
+	testPre := g.OutTestPre
+	if len(testPre) > 0 {
+		testPre = AppendImports(testPre, testImports)
+	} else {
+		testPre = RenderPre("auto gen func by autogen", []string{
+			"context",
+			"fmt",
+			"reflect",
+			"strings",
+			"testing",
+			"github.com/codingeasygo/crud",
+			"github.com/codingeasygo/util/xsql",
+			"github.com/shopspring/decimal",
+		}, testImports)
+		if len(g.GetQueryer) < 1 {
+			funcDefine += fmt.Sprintf(`
+				var %v interface{} = func() crud.Queryer {
+					panic("get crud queryer is not setted")
+				}
+			`, "GetQueryer")
+		}
+	}
+	var allTables []*Table
+	if len(g.TablesFromDDL) > 0 {
+		allTables, err = g.ddlTables()
+	} else {
+		allTables, err = g.queryTables()
+	}
+	if err != nil {
+		return
+	}
+	if len(allTables) < 1 {
+		err = fmt.Errorf("table is not found")
+		return
+	}
+	tables := []*Table{}
+	for _, table := range allTables {
+		if g.TableExclude.HavingOne(table.Name) {
+			continue
+		}
+		if table.IsView() && !g.IncludeViews {
+			continue
+		}
+		if len(g.TableInclude) < 1 || g.TableInclude.HavingOne(table.Name) {
+			tables = append(tables, table)
+		}
+	}
+	err = g.excludeColumns(tables)
+	if err != nil {
+		return
+	}
+	for _, table := range tables {
+		if !table.IsView() {
+			continue
+		}
+		if key := g.TableViewKey[table.Name]; len(key) > 0 {
+			for _, column := range table.Columns {
+				if column.Name == key {
+					column.IsPK = true
+					column.NotNull = true
+				}
+			}
+		}
+		having := false
+		for _, column := range table.Columns {
+			having = having || column.IsPK
+		}
+		if !having {
+			err = fmt.Errorf("view %v primary key is not found, it can be setted by TableViewKey", table.Name)
+			return
+		}
+	}
+	if len(g.ForeignKeySQL) > 0 && len(g.TablesFromDDL) < 1 {
+		err = QueryForeignKeys(g.Queryer, g.ForeignKeySQL, g.Schema, tables)
+		if err != nil {
+			return
+		}
+	}
+	if len(g.UniqueSQL) > 0 && len(g.TablesFromDDL) < 1 {
+		err = QueryUniques(g.Queryer, g.UniqueSQL, g.Schema, tables)
+		if err != nil {
+			return
+		}
+	}
+	if len(g.CheckSQL) > 0 && len(g.TablesFromDDL) < 1 {
+		err = QueryChecks(g.Queryer, g.CheckSQL, g.Schema, tables)
+		if err != nil {
+			return
+		}
+	}
+	sortTables(tables)
+	g.included = tables
+	defer func() {
+		g.included = nil
+	}()
+	jsonTypes := g.jsonTypes(tables)
+	if len(jsonTypes) > 0 {
+		err = g.writeJSONStubs(jsonTypes)
+		if err != nil {
+			return
+		}
+		for _, name := range jsonTypes {
+			funcCommon += fmt.Sprintf(JSONFuncTmpl, name)
+		}
+	}
+	sections := []*autoSection{
+		{Name: "mod", Tmpl: StructTmpl, Pre: structPre, File: g.OutStructFile, Default: "auto_models.go", Suffix: "_model.go"},
+		{Name: "fields", Tmpl: DefineTmpl, Pre: definePre, Models: true, File: g.OutDefineFile, Default: "auto_define.go", Suffix: "_define.go"},
+		{Name: "func", Tmpl: StructFuncTmpl, Pre: funcPre, Common: funcDefine + funcCommon, File: g.OutFuncFile, Default: "auto_func.go", Suffix: "_func.go"},
+		{Name: "test", Tmpl: StructTestTmpl, Pre: testPre, Common: testCommon, File: g.OutTestFile, Default: "auto_func_test.go", Suffix: "_test.go"},
+	}
+	if len(g.OutTSFile) > 0 {
+		err = g.generateTS(tables)
+		if err != nil {
+			return
+		}
+	}
+	if len(g.OutOpenAPIFile) > 0 {
+		var data []byte
+		data, err = g.OpenAPI(tables)
+		if err == nil {
+			err = g.writeFile(g.OutOpenAPIFile, data)
+		}
+		if err != nil {
+			return
+		}
+	}
+	autoModels, err := g.autoModels(tables)
+	if err != nil {
+		return
 	}
-	if len(g.OutPackage) < 1 {
-		g.OutPackage = "autogen"
+	if !g.SplitPerTable {
+		for _, section := range sections {
+			pre := fmt.Sprintf(section.Pre, g.OutPackage) + section.common(autoModels)
+			err = g.generateFile(tables, section.Name, section.Tmpl, pre, section.OutFile())
+			if err != nil {
+				break
+			}
+		}
+		return
 	}
-	if len(g.OutStructPre) < 1 {
-		g.OutStructPre = `
-			//auto gen models by autogen
-			package %v
-			import (
-				"github.com/codingeasygo/util/xsql"
-				"github.com/shopspring/decimal"
-			)
-		`
+	manifest := g.loadManifest()
+	err = g.removeStale(manifest, tables, sections)
+	if err != nil {
+		return
 	}
-	if len(g.OutDefinePre) < 1 {
-		g.OutDefinePre = `
-			//auto gen func by autogen
-			package %v
-		`
+	g.applyDefaults()
+	newManifest := &AutoManifest{
+		Template: g.templateHash(sections),
+		Tables:   map[string]string{},
 	}
-	if len(g.OutFuncPre) < 1 {
-		g.OutFuncPre = `
-			//auto gen func by autogen
-			package %v
-			import (
-				"reflect"
-				"context"
-				"fmt"
-
-				"github.com/codingeasygo/crud"
-				"github.com/codingeasygo/util/attrvalid"
-				"github.com/codingeasygo/util/converter"
-				"github.com/codingeasygo/util/xsql"
-			)
-		`
-		if len(g.GetQueryer) > 0 && g.GetQueryer != "GetQueryer" {
-			g.OutFuncPre += fmt.Sprintf(`
-				var GetQueryer interface{} = func() crud.Queryer { return %v() }
-			`, g.GetQueryer)
-		} else if len(g.GetQueryer) > 0 && g.GetQueryer == "GetQueryer" {
-			g.OutFuncPre += `
-				var GetQueryer interface{} = func() crud.Queryer { panic("get crud queryer is not setted") }
-			`
+	for _, table := range tables {
+		newManifest.Tables[table.Name] = g.tableHash(table)
+	}
+	for _, section := range sections {
+		if common := section.common(autoModels); len(strings.TrimSpace(common)) > 0 {
+			commonFile := "auto_common.go"
+			switch section.Name {
+			case "fields":
+				commonFile = section.OutFile()
+			case "test":
+				commonFile = "auto_common_test.go"
+			}
+			err = g.writeSource(commonFile, fmt.Sprintf(section.Pre, g.OutPackage)+common)
+			if err != nil {
+				return
+			}
+		}
+		for _, table := range tables {
+			filename := "auto_" + table.Name + section.Suffix
+			if !g.Force && !g.DryRun && manifest.Template == newManifest.Template && manifest.Tables[table.Name] == newManifest.Tables[table.Name] {
+				if _, xerr := os.Stat(filepath.Join(g.Out, filename)); xerr == nil {
+					continue
+				}
+			}
+			pre := fmt.Sprintf(section.Pre, g.OutPackage)
+			err = g.generateFile([]*Table{table}, section.Name, section.Tmpl, pre, filename)
+			if err != nil {
+				return
+			}
 		}
 	}
-	if len(g.OutFuncCommon) < 1 {
-		g.OutFuncCommon = `
-			//Validable is interface to valid
-			type Validable interface {
-				Valid() error
+	err = g.writeManifest(newManifest)
+	return
+}
+
+// sortTables will sort tables by name and table columns/keys/uniques/checks, so the generated code is not changed by query order
+func sortTables(tables []*Table) {
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].Name < tables[j].Name
+	})
+	for _, table := range tables {
+		sort.SliceStable(table.Columns, func(i, j int) bool {
+			return table.Columns[i].Ordinal < table.Columns[j].Ordinal
+		})
+		sort.SliceStable(table.ForeignKeys, func(i, j int) bool {
+			return table.ForeignKeys[i].Column < table.ForeignKeys[j].Column
+		})
+		sort.SliceStable(table.Uniques, func(i, j int) bool {
+			return table.Uniques[i].Name < table.Uniques[j].Name
+		})
+		sort.SliceStable(table.Checks, func(i, j int) bool {
+			return table.Checks[i].Name < table.Checks[j].Name
+		})
+	}
+}
+
+func (g *AutoGen) excludeColumns(tables []*Table) (err error) {
+	for _, table := range tables {
+		excludes := g.ColumnExclude[table.Name]
+		renames := g.ColumnRename[table.Name]
+		having := map[string]bool{}
+		columns := []*Column{}
+		for _, column := range table.Columns {
+			having[column.Name] = true
+			if !excludes.HavingOne(column.Name) {
+				columns = append(columns, column)
+				continue
 			}
-		`
+			if column.IsPK {
+				err = fmt.Errorf("column %v.%v is primary key, it can not be excluded", table.Name, column.Name)
+				return
+			}
+		}
+		for _, name := range excludes {
+			if !having[name] {
+				err = fmt.Errorf("column %v.%v is not exists, it can not be excluded", table.Name, name)
+				return
+			}
+		}
+		for name := range renames {
+			if !having[name] || excludes.HavingOne(name) {
+				err = fmt.Errorf("column %v.%v is not exists, it can not be renamed", table.Name, name)
+				return
+			}
+		}
+		table.Columns = columns
 	}
-	if len(g.OutTestPre) < 1 {
-		g.OutTestPre = `
-			//auto gen func by autogen
-			package %v
-			import (
-				"context"
-				"fmt"
-				"reflect"
-				"strings"
-				"testing"
-
-				"github.com/codingeasygo/crud"
-			)
-		`
-		if len(g.GetQueryer) < 1 {
-			g.OutFuncPre += fmt.Sprintf(`
-				var %v interface{} = func() crud.Queryer {
-					panic("get crud queryer is not setted")
-				}
-			`, "GetQueryer")
+	return
+}
+
+// jsonTypes will return the sorted local type name of json field on tables
+func (g *AutoGen) jsonTypes(tables []*Table) (names []string) {
+	having := map[string]bool{}
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			name := g.JSONField[table.Name][column.Name]
+			if len(name) < 1 || strings.Contains(name, ".") || having[name] {
+				continue
+			}
+			having[name] = true
+			names = append(names, name)
 		}
 	}
-	allTables, err := g.TableQueryer(g.Queryer, g.TableSQL, g.ColumnSQL, g.Schema)
-	if err != nil {
+	sort.Strings(names)
+	return
+}
+
+// writeJSONStubs will append the stub struct of json type which is not declared in out package, so the user edited type is kept
+func (g *AutoGen) writeJSONStubs(names []string) (err error) {
+	filename := g.OutJSONFile
+	if len(filename) < 1 {
+		filename = "json_types.go"
+	}
+	fileSet := token.NewFileSet()
+	packages, err := parser.ParseDir(fileSet, g.Out, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil && !os.IsNotExist(err) {
 		return
 	}
-	if len(allTables) < 1 {
-		err = fmt.Errorf("table is not found")
-		return
+	err = nil
+	declared := map[string]bool{}
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			for name, object := range file.Scope.Objects {
+				if object.Kind == ast.Typ {
+					declared[name] = true
+				}
+			}
+		}
 	}
-	tables := []*Table{}
-	for _, table := range allTables {
-		if g.TableExclude.HavingOne(table.Name) {
+	code, xerr := ioutil.ReadFile(filepath.Join(g.Out, filename))
+	if xerr != nil {
+		code = []byte(fmt.Sprintf("//json type of autogen, it is only generated when type is not exists, so it can be edited\npackage %v\n", g.OutPackage))
+	}
+	having := false
+	for _, name := range names {
+		if declared[name] {
 			continue
 		}
-		if len(g.TableInclude) < 1 || g.TableInclude.HavingOne(table.Name) {
-			tables = append(tables, table)
+		code = append(code, []byte(fmt.Sprintf(JSONStubTmpl, name))...)
+		having = true
+	}
+	if having {
+		err = g.writeSource(filename, string(code))
+	}
+	return
+}
+
+// AutoManifest is the hash of generated table, it is used to skip generating the table which is not changed
+type AutoManifest struct {
+	Template string            `json:"template"`
+	Tables   map[string]string `json:"tables"`
+}
+
+func (g *AutoGen) loadManifest() (manifest *AutoManifest) {
+	manifest = &AutoManifest{Tables: map[string]string{}}
+	data, err := ioutil.ReadFile(filepath.Join(g.Out, AutoManifestFile))
+	if err == nil {
+		json.Unmarshal(data, manifest)
+	}
+	return
+}
+
+func (g *AutoGen) writeManifest(manifest *AutoManifest) (err error) {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		err = g.writeFile(AutoManifestFile, append(data, '\n'))
+	}
+	return
+}
+
+func (g *AutoGen) templateHash(sections []*autoSection) string {
+	config := map[string]interface{}{
+		"options": g.options(),
+	}
+	for _, section := range sections {
+		files := map[string]string{}
+		for _, file := range g.TemplateFiles(section.Name) {
+			data, _ := ioutil.ReadFile(file)
+			files[file] = string(data)
 		}
+		common := section.Common
+		if section.Models {
+			common += AutoModelTmpl
+		}
+		config[section.Name] = []interface{}{section.Tmpl, section.Pre, common, files}
 	}
-	{
-		var source []byte
-		generator := NewGen(g.TypeMap, tables)
-		generator.Funcs(g.FuncMap())
-		generator.NameConv = g.NameConv
-		generator.OnPre = g.OnPre
-		buffer := bytes.NewBuffer(nil)
-		fmt.Fprintf(buffer, g.OutStructPre, g.OutPackage)
-		err = generator.GenerateByTemplate("mod", StructTmpl, buffer)
-		if err != nil {
-			return
+	return hashJSON(config)
+}
+
+// options will return all serializable options of AutoGen, the func/queryer, empty map/slice and run mode like Force/DryRun is skipped
+func (g *AutoGen) options() map[string]interface{} {
+	options := map[string]interface{}{}
+	value := reflect.ValueOf(g).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if len(field.PkgPath) > 0 || field.Name == "Force" || field.Name == "DryRun" {
+			continue
 		}
-		source, err = format.Source(buffer.Bytes())
-		if err != nil {
-			return
+		fieldValue := value.Field(i)
+		switch field.Type.Kind() {
+		case reflect.Func, reflect.Interface:
+			continue
+		case reflect.Map, reflect.Slice:
+			if fieldValue.Len() < 1 || field.Type.Elem().Kind() == reflect.Func || field.Type.Elem().Kind() == reflect.Interface {
+				continue
+			}
 		}
-		structFile := g.OutStructFile
-		if len(structFile) < 1 {
-			structFile = "auto_models.go"
+		options[field.Name] = fieldValue.Interface()
+	}
+	return options
+}
+
+func (g *AutoGen) tableHash(table *Table) string {
+	config := map[string]interface{}{
+		"table":           table,
+		"type_field":      g.TypeField[table.Name],
+		"valid_field":     g.ValidField[table.Name],
+		"field_filter":    g.FieldFilter[table.Name],
+		"code_add_init":   g.CodeAddInit[table.Name],
+		"code_test_init":  g.CodeTestInit[table.Name],
+		"comments":        g.Comments[table.Name],
+		"table_gen_add":   g.TableGenAdd.HavingOne(table.Name),
+		"table_ret_add":   g.TableRetAdd[table.Name],
+		"table_upsert":    g.TableUpsert[table.Name],
+		"table_not_valid": g.TableNotValid.HavingOne(table.Name),
+		"column_rename":   g.ColumnRename[table.Name],
+		"json_field":      g.JSONField[table.Name],
+		"relations":       g.relationTables(table),
+		"qualify":         g.qualify,
+		"audit_fields":    g.AuditFields,
+	}
+	return hashJSON(config)
+}
+
+func (g *AutoGen) relationTables(table *Table) (refTables []*Table) {
+	for _, key := range table.ForeignKeys {
+		for _, included := range g.included {
+			if included.Name == key.RefTable {
+				refTables = append(refTables, included)
+			}
 		}
-		err = ioutil.WriteFile(filepath.Join(g.Out, structFile), source, os.ModePerm)
+	}
+	return
+}
+
+func hashJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type autoSection struct {
+	Name    string
+	Tmpl    string
+	Pre     string
+	Common  string
+	Models  bool // append the static models of all tables rendered by AutoModelTmpl to Common
+	File    string
+	Default string
+	Suffix  string
+}
+
+// common will return the common code of section, the models is appended when Models is true
+func (a *autoSection) common(models string) string {
+	if a.Models {
+		return a.Common + models
+	}
+	return a.Common
+}
+
+func (a *autoSection) OutFile() string {
+	if len(a.File) > 0 {
+		return a.File
+	}
+	return a.Default
+}
+
+func (g *AutoGen) generateFile(tables []*Table, name, tmpl, pre, filename string) (err error) {
+	generator := NewGen(g.TypeMap, tables)
+	generator.Funcs(g.FuncMap())
+	generator.NameConv = g.nameConv()
+	generator.OnPre = g.templateData
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString(pre)
+	err = generator.GenerateByTemplate(name, tmpl, buffer, g.TemplateFiles(name)...)
+	if err == nil {
+		err = g.writeSource(filename, buffer.String())
+	}
+	return
+}
+
+// autoModels will render AutoModelTmpl by all tables to one static models list, so it is not changed by file init order on split mode
+func (g *AutoGen) autoModels(tables []*Table) (code string, err error) {
+	generator := NewGen(g.TypeMap, tables)
+	generator.Funcs(g.FuncMap())
+	generator.NameConv = g.nameConv()
+	modelTmpl := template.New("model").Funcs(generator.FuncMap)
+	_, err = modelTmpl.Parse(AutoModelTmpl)
+	if err != nil {
+		return
+	}
+	models := []*TemplateData{}
+	for _, table := range tables {
+		var model *TemplateData
+		model, err = AsTemplateData(g.templateData(generator, table))
 		if err != nil {
 			return
 		}
+		models = append(models, model)
 	}
-	{
-		var source []byte
-		generator := NewGen(g.TypeMap, tables)
-		generator.Funcs(g.FuncMap())
-		generator.NameConv = g.NameConv
-		generator.OnPre = g.OnPre
-		buffer := bytes.NewBuffer(nil)
-		fmt.Fprintf(buffer, g.OutDefinePre, g.OutPackage)
-		err = generator.GenerateByTemplate("fields", DefineTmpl, buffer)
+	buffer := bytes.NewBuffer(nil)
+	err = modelTmpl.Execute(buffer, models)
+	if err != nil {
+		err = fmt.Errorf("execute template model fail with %v", err)
+		return
+	}
+	code = buffer.String()
+	return
+}
+
+func (g *AutoGen) generateTS(tables []*Table) (err error) {
+	generator := NewGen(g.TypeMap, tables)
+	generator.Funcs(g.FuncMap())
+	generator.NameConv = g.nameConv()
+	tsTmpl := template.New("ts").Funcs(generator.FuncMap)
+	_, err = tsTmpl.Parse(TSTmpl)
+	if err != nil {
+		return
+	}
+	buffer := bytes.NewBuffer(nil)
+	buffer.WriteString("//auto gen models by autogen\n")
+	for _, table := range tables {
+		err = tsTmpl.Execute(buffer, g.templateData(generator, table))
 		if err != nil {
+			err = fmt.Errorf("execute template ts by table %v fail with %v", table.Name, err)
 			return
 		}
-		source, err = format.Source(buffer.Bytes())
+	}
+	err = g.writeFile(g.OutTSFile, buffer.Bytes())
+	return
+}
+
+// OpenAPI will return the openapi components.schemas json of tables
+func (g *AutoGen) OpenAPI(tables []*Table) (data []byte, err error) {
+	generator := NewGen(g.TypeMap, tables)
+	generator.Funcs(g.FuncMap())
+	generator.NameConv = g.nameConv()
+	schemas := map[string]interface{}{}
+	for _, table := range tables {
+		var templateData *TemplateData
+		templateData, err = AsTemplateData(g.templateData(generator, table))
 		if err != nil {
 			return
 		}
-		defineFile := g.OutDefineFile
-		if len(defineFile) < 1 {
-			defineFile = "auto_define.go"
+		schemas[templateData.Struct.Name] = g.OpenAPISchema(templateData)
+	}
+	data, err = json.MarshalIndent(map[string]interface{}{
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}, "", "  ")
+	if err == nil {
+		data = append(data, '\n')
+	}
+	return
+}
+
+// OpenAPISchema will return the openapi schema of struct
+func (g *AutoGen) OpenAPISchema(data *TemplateData) (schema map[string]interface{}) {
+	s := data.Struct
+	properties := map[string]interface{}{}
+	for _, field := range s.Fields {
+		name := strings.SplitN(g.FieldJson(s, field), ",", 2)[0]
+		properties[name] = g.OpenAPIProperty(s, field)
+	}
+	schema = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(s.Comment) > 0 {
+		schema["description"] = s.Comment
+	}
+	required := []string{}
+	if len(data.Filter.Required) > 0 {
+		for _, column := range strings.Split(strings.SplitN(data.Filter.Required, "#", 2)[0], ",") {
+			for _, field := range s.Fields {
+				if field.Column.Name == strings.TrimSpace(column) {
+					required = append(required, strings.SplitN(g.FieldJson(s, field), ",", 2)[0])
+				}
+			}
 		}
-		err = ioutil.WriteFile(filepath.Join(g.Out, defineFile), source, os.ModePerm)
-		if err != nil {
-			return
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return
+}
+
+// OpenAPIProperty will return the openapi property of field
+func (g *AutoGen) OpenAPIProperty(s *Struct, field *Field) (property map[string]interface{}) {
+	property = map[string]interface{}{}
+	typ := g.FieldType(s, field)
+	if len(field.Options) > 0 {
+		typ = field.Type
+	}
+	if strings.HasPrefix(typ, "*") {
+		property["nullable"] = true
+		typ = strings.TrimPrefix(typ, "*")
+	}
+	if g.FieldJSONType(s, field) {
+		typ = "xsql.M"
+	}
+	typeFormat := func(v map[string]interface{}, typ string) {
+		switch typ {
+		case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32":
+			v["type"], v["format"] = "integer", "int32"
+		case "int64", "uint64":
+			v["type"], v["format"] = "integer", "int64"
+		case "float32":
+			v["type"], v["format"] = "number", "float"
+		case "float64":
+			v["type"], v["format"] = "number", "double"
+		case "decimal.Decimal":
+			v["type"], v["format"] = "string", "decimal"
+		case "string":
+			v["type"] = "string"
+		case "bool":
+			v["type"] = "boolean"
+		case "xsql.Time":
+			v["type"], v["format"] = "integer", "int64"
+		case "xsql.M":
+			v["type"] = "object"
 		}
 	}
-	{
-		var source []byte
-		generator := NewGen(g.TypeMap, tables)
-		generator.Funcs(g.FuncMap())
-		generator.NameConv = g.NameConv
-		generator.OnPre = g.OnPre
-		buffer := bytes.NewBuffer(nil)
-		fmt.Fprintf(buffer, g.OutFuncPre, g.OutPackage)
-		fmt.Fprintf(buffer, "%v", g.OutFuncCommon)
-		err = generator.GenerateByTemplate("func", StructFuncTmpl, buffer)
-		if err != nil {
-			return
+	switch typ {
+	case "xsql.IntArray":
+		items := map[string]interface{}{}
+		typeFormat(items, "int")
+		property["type"], property["items"] = "array", items
+	case "xsql.Int64Array":
+		items := map[string]interface{}{}
+		typeFormat(items, "int64")
+		property["type"], property["items"] = "array", items
+	case "xsql.Float64Array":
+		items := map[string]interface{}{}
+		typeFormat(items, "float64")
+		property["type"], property["items"] = "array", items
+	case "xsql.StringArray":
+		items := map[string]interface{}{}
+		typeFormat(items, "string")
+		property["type"], property["items"] = "array", items
+	case "xsql.MArray":
+		items := map[string]interface{}{}
+		typeFormat(items, "xsql.M")
+		property["type"], property["items"] = "array", items
+	default:
+		typeFormat(property, typ)
+	}
+	if len(field.Options) > 0 {
+		enum := []interface{}{}
+		for _, option := range field.Options {
+			var value interface{}
+			if err := json.Unmarshal([]byte(option.Value), &value); err != nil {
+				value = option.Value
+			}
+			enum = append(enum, value)
 		}
-		source, err = format.Source(buffer.Bytes())
-		if err != nil {
+		property["enum"] = enum
+	}
+	if len(field.Comment) > 0 {
+		property["description"] = field.Comment
+	}
+	return
+}
+
+func (g *AutoGen) writeSource(filename, code string) (err error) {
+	source, err := format.Source([]byte(code))
+	if err != nil {
+		return
+	}
+	source, err = CleanImports(source)
+	if err != nil {
+		return
+	}
+	err = g.writeFile(filename, source)
+	return
+}
+
+// writeFile will write data to file when data is changed, so the not changed file's mtime is kept
+func (g *AutoGen) writeFile(filename string, data []byte) (err error) {
+	if g.files != nil && g.DryRun {
+		g.files[filename] = data
+	}
+	if g.Output == nil || g.DryRun {
+		if old, xerr := ioutil.ReadFile(filepath.Join(g.Out, filename)); xerr == nil && bytes.Equal(old, data) {
 			return
 		}
-		funcFile := g.OutFuncFile
-		if len(funcFile) < 1 {
-			funcFile = "auto_func.go"
+	}
+	if !g.DryRun {
+		output := g.Output
+		if output == nil {
+			output = g.outputFile
 		}
-		err = ioutil.WriteFile(filepath.Join(g.Out, funcFile), source, os.ModePerm)
-		if err != nil {
-			return
+		err = output(filename, data)
+	}
+	if err == nil {
+		g.written = append(g.written, filename)
+	}
+	return
+}
+
+func (g *AutoGen) outputFile(name string, content []byte) (err error) {
+	err = ioutil.WriteFile(filepath.Join(g.Out, name), content, os.ModePerm)
+	return
+}
+
+// removeStale will remove the generated file of table which is listed in previous manifest but not existed now and single mode file
+func (g *AutoGen) removeStale(manifest *AutoManifest, tables []*Table, sections []*autoSection) (err error) {
+	if g.DryRun {
+		return
+	}
+	tableNames := map[string]bool{}
+	for _, table := range tables {
+		tableNames[table.Name] = true
+	}
+	for _, section := range sections {
+		os.Remove(filepath.Join(g.Out, section.OutFile()))
+		for tableName := range manifest.Tables {
+			if tableNames[tableName] {
+				continue
+			}
+			err = os.Remove(filepath.Join(g.Out, "auto_"+tableName+section.Suffix))
+			if err != nil && !os.IsNotExist(err) {
+				return
+			}
+			err = nil
 		}
 	}
-	{
-		var source []byte
-		generator := NewGen(g.TypeMap, tables)
-		generator.Funcs(g.FuncMap())
-		generator.NameConv = g.NameConv
-		generator.OnPre = g.OnPre
-		buffer := bytes.NewBuffer(nil)
-		fmt.Fprintf(buffer, g.OutTestPre, g.OutPackage)
-		fmt.Fprintf(buffer, "%v", g.OutTestCommon)
-		err = generator.GenerateByTemplate("test", StructTestTmpl, buffer)
-		if err != nil {
-			return
+	return
+}
+
+var (
+	importMajorRegexp   = regexp.MustCompile(`^v[0-9]+$`)
+	importGopkgInRegexp = regexp.MustCompile(`^(.+)\.v[0-9]+$`)
+)
+
+// importName will return the package name of import spec, it is guessed by import path when spec is not named
+func importName(spec *ast.ImportSpec) (name string) {
+	if spec.Name != nil {
+		name = spec.Name.Name
+		return
+	}
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	name = path.Base(importPath)
+	if importMajorRegexp.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	} else if match := importGopkgInRegexp.FindStringSubmatch(name); len(match) > 0 {
+		name = match[1]
+	}
+	return
+}
+
+// CleanImports will remove the import which is not used in source by parsing it, the name only used in string or comment is not counted
+func CleanImports(source []byte) (result []byte, err error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "", source, parser.ParseComments)
+	if err != nil {
+		return
+	}
+	used := map[string]bool{}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
 		}
-		source, err = format.Source(buffer.Bytes())
-		if err != nil {
-			return
+		ast.Inspect(decl, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+	type span struct{ pos, end token.Pos }
+	removed := []span{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
 		}
-		testFile := g.OutTestFile
-		if len(testFile) < 1 {
-			testFile = "auto_func_test.go"
+		unused := []span{}
+		for _, spec := range gen.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			name := importName(importSpec)
+			if name == "_" || name == "." || !token.IsIdentifier(name) || used[name] {
+				continue
+			}
+			pos, end := importSpec.Pos(), importSpec.End()
+			if importSpec.Doc != nil {
+				pos = importSpec.Doc.Pos()
+			}
+			if importSpec.Comment != nil {
+				end = importSpec.Comment.End()
+			}
+			unused = append(unused, span{pos: pos, end: end})
 		}
-		err = ioutil.WriteFile(filepath.Join(g.Out, testFile), source, os.ModePerm)
-		if err != nil {
-			return
+		if len(unused) > 0 && len(unused) == len(gen.Specs) {
+			pos := gen.Pos()
+			if gen.Doc != nil {
+				pos = gen.Doc.Pos()
+			}
+			unused = []span{{pos: pos, end: gen.End()}}
 		}
+		removed = append(removed, unused...)
+	}
+	code := []byte{}
+	offset := 0
+	for _, r := range removed {
+		code = append(code, source[offset:fileSet.Position(r.pos).Offset]...)
+		offset = fileSet.Position(r.end).Offset
 	}
+	code = append(code, source[offset:]...)
+	result, err = format.Source(code)
 	return
 }
@@ -1,8 +1,11 @@
 package gen
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"flag"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/codingeasygo/crud/sqlx"
 	"github.com/codingeasygo/crud/testsql"
+	"github.com/codingeasygo/util/converter"
 	"github.com/codingeasygo/util/xsql"
 
 	_ "github.com/lib/pq"
@@ -100,6 +104,7 @@ func init() {
 		reflect.ValueOf(GetQueryer).Call(nil)
 	}()
 	GetQueryer = func() crud.Queryer { return sharedPG }
+	GetAuditUser = func(ctx context.Context) int64 { return 100 }
 	crud.Default.Verbose = true
 	crud.Default.NameConv = gen.NameConvPG
 	crud.Default.ParmConv = gen.ParmConvPG
@@ -128,21 +133,41 @@ var PgGen = AutoGen{
 			}
 		`,
 	},
+	CodeTestInit: map[string]string{
+		"crud_uuid_object": `
+			code := "upsert"
+			ARG.Code = &code
+		`,
+	},
 	CodeSlice: CodeSlicePG,
 	TableGenAdd: xsql.StringArray{
 		"crud_object",
 	},
-	TableInclude: xsql.StringArray{},
-	TableExclude: xsql.StringArray{},
-	Queryer:      getPG,
-	TableSQL:     TableSQLPG,
-	ColumnSQL:    ColumnSQLPG,
-	Schema:       "public",
-	TypeMap:      TypeMapPG,
-	NameConv:     nameConv,
-	GetQueryer:   "GetQueryer",
-	Out:          "./autogen/",
-	OutPackage:   "autogen",
+	TableUpsert: map[string]string{
+		"crud_uuid_object": "code",
+	},
+	TableViewKey: map[string]string{
+		"crud_object_view": "tid",
+	},
+	AuditFields: map[string]string{
+		"created_by": "insert",
+		"updated_by": "insert,update",
+	},
+	IncludeViews:  true,
+	TableInclude:  xsql.StringArray{},
+	TableExclude:  xsql.StringArray{},
+	Queryer:       getPG,
+	TableSQL:      TableViewSQLPG,
+	ColumnSQL:     ColumnSQLPG,
+	ForeignKeySQL: ForeignKeySQLPG,
+	UniqueSQL:     UniqueSQLPG,
+	CheckSQL:      CheckSQLPG,
+	Schema:        "public",
+	TypeMap:       TypeMapPG,
+	NameConv:      nameConv,
+	GetQueryer:    "GetQueryer",
+	Out:           "./autogen/",
+	OutPackage:    "autogen",
 }
 
 func TestPgGen(t *testing.T) {
@@ -173,23 +198,33 @@ func TestPgGen(t *testing.T) {
 
 var sharedSQLITE *sqlx.DbQueryer
 
-func getSQLITE() *sqlx.DbQueryer {
-	if sharedSQLITE != nil {
-		return sharedSQLITE
-	}
-	db, err := sql.Open("sqlite3", "file:crud.sqlite?cache=shared")
+// useSQLITE will open the sqlite test database in t.TempDir and init the tables, it is closed when test is done
+func useSQLITE(t testing.TB) *sqlx.DbQueryer {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "crud.sqlite")+"?cache=shared")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 	db.SetMaxOpenConns(1)
-	sharedSQLITE = sqlx.NewDbQueryer(db)
-	_, _, err = sharedSQLITE.Exec(context.Background(), testsql.SQLITE_DROP)
+	queryer := sqlx.NewDbQueryer(db)
+	_, _, err = queryer.Exec(context.Background(), testsql.SQLITE_DROP)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	_, _, err = sharedSQLITE.Exec(context.Background(), testsql.SQLITE_LATEST)
+	_, _, err = queryer.Exec(context.Background(), testsql.SQLITE_LATEST)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	sharedSQLITE = queryer
+	t.Cleanup(func() {
+		sharedSQLITE = nil
+		db.Close()
+	})
+	return queryer
+}
+
+func getSQLITE() *sqlx.DbQueryer {
+	if sharedSQLITE == nil {
+		panic("sqlite is not opened, call useSQLITE(t) first")
 	}
 	return sharedSQLITE
 }
@@ -232,6 +267,7 @@ func init() {
 		reflect.ValueOf(GetQueryer).Call(nil)
 	}()
 	GetQueryer = func() crud.Queryer { return sharedSQLITE }
+	GetAuditUser = func(ctx context.Context) int64 { return 100 }
 	crud.Default.Verbose = true
 	crud.Default.NameConv = gen.NameConvSQLITE
 	crud.Default.ParmConv = gen.ParmConvSQLITE
@@ -255,6 +291,10 @@ var SqliteGen = AutoGen{
 			FieldsOrder:   "type,update_time,create_time",
 			FieldsNotOmit: "tid",
 		},
+		"crud_uuid_object": {
+			FieldsOptional: "code,created_by",
+			FieldsRequired: "title,updated_by",
+		},
 	},
 	CodeAddInit: map[string]string{
 		"crud_object": `
@@ -263,7 +303,15 @@ var SqliteGen = AutoGen{
 			}
 		`,
 	},
-	CodeSlice: CodeSliceSQLITE,
+	CodeTestInit: map[string]string{
+		"crud_uuid_object": `
+			code := "upsert"
+			ARG.Code = &code
+		`,
+	},
+	CodeSlice:           CodeSliceSQLITE,
+	EnumHelpers:         true,
+	ApplyColumnDefaults: true,
 	Comments: map[string]map[string]string{
 		"crud_object": {
 			"type":   `simple type in, A=1:test a, B=2:test b, C=3:test c`,
@@ -273,20 +321,44 @@ var SqliteGen = AutoGen{
 	TableGenAdd: xsql.StringArray{
 		"crud_object",
 	},
-	TableInclude: xsql.StringArray{},
-	TableExclude: xsql.StringArray{},
-	Queryer:      getSQLITE,
-	TableSQL:     TableSQLSQLITE,
-	ColumnSQL:    ColumnSQLSQLITE,
-	Schema:       "",
-	TypeMap:      TypeMapSQLITE,
-	NameConv:     nameConv,
-	GetQueryer:   "GetQueryer",
-	Out:          "./autogen/",
-	OutPackage:   "autogen",
+	TableUpsert: map[string]string{
+		"crud_uuid_object": "code",
+	},
+	TableViewKey: map[string]string{
+		"crud_object_view": "tid",
+	},
+	AuditFields: map[string]string{
+		"created_by": "insert",
+		"updated_by": "insert,update",
+	},
+	ColumnExclude: map[string]xsql.StringArray{
+		"crud_object": {"description"},
+	},
+	ColumnRename: map[string]map[string]string{
+		"crud_object": {"image": "Picture"},
+	},
+	JSONField: map[string]map[string]string{
+		"crud_object": {"data": "CrudObjectData"},
+	},
+	IncludeViews:  true,
+	TableInclude:  xsql.StringArray{},
+	TableExclude:  xsql.StringArray{},
+	Queryer:       getSQLITE,
+	TableSQL:      TableViewSQLSQLITE,
+	ColumnSQL:     ColumnSQLSQLITE,
+	ForeignKeySQL: ForeignKeySQLSQLITE,
+	UniqueSQL:     UniqueSQLSQLITE,
+	CheckSQL:      CheckSQLSQLITE,
+	Schema:        "",
+	TypeMap:       TypeMapSQLITE,
+	NameConv:      nameConv,
+	GetQueryer:    "GetQueryer",
+	Out:           "./autogen/",
+	OutPackage:    "autogen",
 }
 
 func TestSqliteGen(t *testing.T) {
+	useSQLITE(t)
 	var err error
 	defer func() {
 		if err == nil {
@@ -311,3 +383,1400 @@ func TestSqliteGen(t *testing.T) {
 		return
 	}
 }
+
+func TestSqliteGenTemplate(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "func.tmpl"), []byte(`{{define "Add"}}//Add{{.Struct.Name}} is overrided{{end}}`), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(dir, "error.tmpl"), []byte(`{{.Struct.Name`), os.ModePerm)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	autoGen.TemplateDir = dir
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if !strings.Contains(string(funcData), "//AddCrudObject is overrided") || strings.Contains(string(funcData), "func AddCrudObject(") {
+		t.Error("override error")
+		return
+	}
+	if !strings.Contains(string(funcData), "func FindCrudObject(") {
+		t.Error("override error")
+		return
+	}
+	autoGen.TemplateOverrides = map[string]string{"mod": "error.tmpl"}
+	err = autoGen.Generate()
+	if err == nil || !strings.Contains(err.Error(), "error.tmpl") || !strings.Contains(err.Error(), "mod") {
+		t.Error(err)
+		return
+	}
+	autoGen.TemplateOverrides = map[string]string{"mod": "none.tmpl"}
+	err = autoGen.Generate()
+	if err == nil || !strings.Contains(err.Error(), "none.tmpl") {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSqliteGenSplit(t *testing.T) {
+	useSQLITE(t)
+	var err error
+	autoGen := SqliteGen
+	autoGen.Out = "./autogen_split/"
+	autoGen.SplitPerTable = true
+	defer func() {
+		if err == nil {
+			os.RemoveAll(autoGen.Out)
+		}
+	}()
+	os.MkdirAll(autoGen.Out, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, "auto_test.go"), []byte(SqliteInit), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, "auto_func.go"), []byte("package autogen\n"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, "auto_not_exists_func.go"), []byte("package autogen\n"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, "auto_custom_func.go"), []byte("package autogen\n"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, AutoManifestFile), []byte(`{"tables":{"not_exists":""}}`), os.ModePerm)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, name := range []string{"auto_common.go", "auto_define.go", "auto_crud_object_model.go", "auto_crud_object_func.go", "auto_crud_object_test.go", "auto_crud_uuid_object_func.go", "auto_custom_func.go"} {
+		if _, err = os.Stat(filepath.Join(autoGen.Out, name)); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	defineData, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_define.go"))
+	objectDefine, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_crud_object_define.go"))
+	if objectIndex, uuidIndex := bytes.Index(defineData, []byte(`"crud_object"`)), bytes.Index(defineData, []byte(`"crud_uuid_object"`)); objectIndex < 0 || uuidIndex < objectIndex || bytes.Contains(objectDefine, []byte("AutoModel")) {
+		err = fmt.Errorf("auto models error")
+		t.Errorf("%v\n%v", string(defineData), string(objectDefine))
+		return
+	}
+	for _, name := range []string{"auto_func.go", "auto_not_exists_func.go"} {
+		if _, xerr := os.Stat(filepath.Join(autoGen.Out, name)); xerr == nil {
+			err = fmt.Errorf("%v is not removed", name)
+			t.Error(err)
+			return
+		}
+	}
+	//incremental
+	funcFile := filepath.Join(autoGen.Out, "auto_crud_object_func.go")
+	manifest, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, AutoManifestFile))
+	funcData, _ := ioutil.ReadFile(funcFile)
+	ioutil.WriteFile(funcFile, append(funcData, []byte("\n//modified\n")...), os.ModePerm)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newManifest, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, AutoManifestFile))
+	newFuncData, _ := ioutil.ReadFile(funcFile)
+	if len(manifest) < 1 || string(manifest) != string(newManifest) || !strings.Contains(string(newFuncData), "//modified") {
+		err = fmt.Errorf("incremental error")
+		t.Error(err)
+		return
+	}
+	autoGen.Force = true
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newFuncData, _ = ioutil.ReadFile(funcFile)
+	if string(newFuncData) != string(funcData) {
+		err = fmt.Errorf("force error")
+		t.Error(err)
+		return
+	}
+	//options changed
+	autoGen.Force = false
+	ioutil.WriteFile(funcFile, append(funcData, []byte("\n//modified\n")...), os.ModePerm)
+	autoGen.EnumHelpers = !autoGen.EnumHelpers
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	newManifest, _ = ioutil.ReadFile(filepath.Join(autoGen.Out, AutoManifestFile))
+	newFuncData, _ = ioutil.ReadFile(funcFile)
+	if string(manifest) == string(newManifest) || strings.Contains(string(newFuncData), "//modified") {
+		err = fmt.Errorf("options error")
+		t.Error(err)
+		return
+	}
+	autoGen.EnumHelpers = !autoGen.EnumHelpers
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	pwd, _ := os.Getwd()
+	tester := exec.Command("go", "test", "-v")
+	tester.Dir = filepath.Join(pwd, "autogen_split")
+	tester.Stderr = os.Stderr
+	tester.Stdout = os.Stdout
+	err = tester.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSqliteGenImports(t *testing.T) {
+	useSQLITE(t)
+	var err error
+	autoGen := SqliteGen
+	autoGen.Out = "./autogen_imports/"
+	autoGen.TableQueryer = func(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error) {
+		defaultValue := func(v string) *string { return &v }
+		tables = []*Table{
+			{
+				Name: "simple_object",
+				Columns: []*Column{
+					{Name: "tid", Type: "INTEGER", IsPK: true, NotNull: true},
+					{Name: "title", Type: "TEXT", NotNull: true, DefaultValue: defaultValue("'abc'")},
+					{Name: "level", Type: "INT4", DefaultValue: defaultValue("5")},
+					{Name: "status", Type: "INT4", NotNull: true, DefaultValue: defaultValue("100")},
+				},
+			},
+		}
+		return
+	}
+	defer func() {
+		if err == nil {
+			os.RemoveAll(autoGen.Out)
+		}
+	}()
+	os.MkdirAll(autoGen.Out, os.ModePerm)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	modelData, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_models.go"))
+	if strings.Contains(string(modelData), "decimal") || strings.Contains(string(modelData), "xsql") {
+		err = fmt.Errorf("not clean imports")
+		t.Error(err)
+		return
+	}
+	funcData, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_func.go"))
+	if !strings.Contains(string(funcData), "SimpleObjectStatusDefault = 100") || !strings.Contains(string(funcData), "simpleObject.Level = &levelDefault") {
+		err = fmt.Errorf("not column default")
+		t.Error(err)
+		return
+	}
+	pwd, _ := os.Getwd()
+	tester := exec.Command("go", "vet", ".")
+	tester.Dir = filepath.Join(pwd, "autogen_imports")
+	tester.Stderr = os.Stderr
+	tester.Stdout = os.Stdout
+	err = tester.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestCleanImports(t *testing.T) {
+	cases := []struct {
+		source  string
+		imports []string
+		removed []string
+	}{
+		{
+			source: `package x
+
+import "fmt"
+
+func A() string { return "fmt.Println" }
+`,
+			removed: []string{`"fmt"`, "import"},
+		},
+		{
+			source: `package x
+
+import (
+	"fmt"
+	"strings" // strings
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/lib/pq"
+	yaml "gopkg.in/yaml.v3"
+	"gopkg.in/check.v1"
+)
+
+// fmt.Println is only in comment
+func A(s string) string {
+	var x pgx.Conn
+	_ = x
+	_ = check.Commentf
+	return strings.TrimSpace(s) + "fmt.Sprintf"
+}
+`,
+			imports: []string{`"strings"`, `"github.com/jackc/pgx/v5"`, `_ "github.com/lib/pq"`, `"gopkg.in/check.v1"`},
+			removed: []string{`"fmt"`, `"gopkg.in/yaml.v3"`},
+		},
+		{
+			source: `package x
+
+import (
+	"fmt"
+)
+
+func A() {
+	fmt := struct{ Println func() }{}
+	fmt.Println()
+}
+`,
+			removed: []string{`"fmt"`, "import"},
+		},
+	}
+	for i, c := range cases {
+		result, err := CleanImports([]byte(c.source))
+		if err != nil {
+			t.Errorf("%v:%v", i, err)
+			return
+		}
+		for _, spec := range c.imports {
+			if !strings.Contains(string(result), spec) {
+				t.Errorf("%v:%v not found in\n%v", i, spec, string(result))
+				return
+			}
+		}
+		for _, spec := range c.removed {
+			if strings.Contains(string(result), spec) {
+				t.Errorf("%v:%v found in\n%v", i, spec, string(result))
+				return
+			}
+		}
+	}
+	if _, err := CleanImports([]byte("package x\nfunc {")); err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSqliteGenFilterCheck(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	tests := string(files["auto_func_test.go"])
+	if !strings.Contains(tests, "crud.CheckFilter(&CrudObject{}, filter)") || !strings.Contains(tests, "CrudObjectFilterUpdate, CrudObjectFilterFind") {
+		t.Errorf("test is\n%v", tests)
+		return
+	}
+}
+
+func TestSqliteGenExtraImports(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	autoGen.CodeAddInit = map[string]string{
+		"crud_object": `
+			ARG.StringValue = strconv.Itoa(ARG.IntValue)
+		`,
+	}
+	autoGen.OutTestPre = `
+		package %v
+		import (
+			"context"
+			"fmt"
+			"reflect"
+			"strings"
+			"testing"
+
+			"github.com/codingeasygo/crud"
+			"github.com/codingeasygo/util/xsql"
+			"github.com/shopspring/decimal"
+		)
+	`
+	autoGen.ExtraImports = map[string][]string{
+		ImportsFunc: {"strconv", "fmt", "github.com/codingeasygo/crud"},
+		ImportsTest: {"strings", "xstrconv strconv"},
+	}
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcs := string(files["auto_func.go"])
+	if !strings.Contains(funcs, `"strconv"`) || strings.Count(funcs, `"fmt"`) != 1 || strings.Count(funcs, `"github.com/codingeasygo/crud"`) != 1 {
+		t.Errorf("imports error\n%v", funcs)
+		return
+	}
+	autoGen.ExtraImports = map[string][]string{"xxx": {"strconv"}}
+	_, _, err = autoGen.GenerateFiles()
+	if err == nil {
+		t.Error("error")
+		return
+	}
+	pre := AppendImports(autoGen.OutTestPre, []string{"strings", "xstrconv strconv", "os"})
+	if strings.Count(pre, `"strings"`) != 1 || !strings.Contains(pre, "\txstrconv \"strconv\"\n") || !strings.Contains(pre, "\t\"os\"\n") {
+		t.Errorf("append error\n%v", pre)
+		return
+	}
+	if AppendImports(autoGen.OutTestPre, nil) != autoGen.OutTestPre {
+		t.Error("error")
+		return
+	}
+	imports := RenderImports([]string{"fmt", "github.com/a/b"}, []string{"\"fmt\"", "_ github.com/c/d", "context", ""})
+	if imports != "import (\n\t\"fmt\"\n\t\"context\"\n\n\t\"github.com/a/b\"\n\t_ \"github.com/c/d\"\n)\n" || len(RenderImports()) > 0 {
+		t.Errorf("render error\n%v", imports)
+		return
+	}
+}
+
+func TestSqliteGenTS(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	autoGen.OutTSFile = "models.ts"
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	tsData, _ := ioutil.ReadFile(filepath.Join(dir, "models.ts"))
+	tsSource := string(tsData)
+	for _, line := range []string{
+		"export interface CrudObject {",
+		"tid: number;",
+		"title?: string;",
+		`type?: "1" | "2" | "3";`,
+		"status?: 100 | 200 | -1;",
+		"float64_value?: string;",
+		"int64_array?: number[];",
+		"map_array?: Record<string, unknown>[];",
+		"update_time?: number;",
+		"export interface CrudUuidObject {",
+	} {
+		if !strings.Contains(tsSource, line) {
+			t.Errorf("%v not found in \n%v", line, tsSource)
+			return
+		}
+	}
+	autoGen.TSInt64String = true
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	tsData, _ = ioutil.ReadFile(filepath.Join(dir, "models.ts"))
+	if !strings.Contains(string(tsData), "int64_value?: string;") || !strings.Contains(string(tsData), "int64_array?: string[];") {
+		t.Errorf("int64 error by \n%v", string(tsData))
+		return
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	simpleTable := &Table{
+		Name:    "simple_object",
+		Comment: "the simple object",
+		Columns: []*Column{
+			{Name: "tid", Type: "INTEGER", IsPK: true, NotNull: true, Comment: "the primary key"},
+			{Name: "title", Type: "TEXT", NotNull: true},
+			{Name: "image", Type: "TEXT"},
+			{Name: "float64_value", Type: "DOUBLE", NotNull: true},
+			{Name: "update_time", Type: "DATE", NotNull: true},
+		},
+	}
+	enumTable := &Table{
+		Name: "enum_object",
+		Columns: []*Column{
+			{Name: "tid", Type: "INTEGER", IsPK: true, NotNull: true},
+			{Name: "type", Type: "TEXT", NotNull: true},
+			{Name: "status", Type: "INT4", NotNull: true},
+			{Name: "int64_array", Type: "TEXT", NotNull: true},
+			{Name: "map_array", Type: "TEXT", NotNull: true},
+		},
+	}
+	enumGen := &AutoGen{
+		TypeField: map[string]map[string]string{
+			"enum_object": {
+				"int64_array": "xsql.Int64Array",
+				"map_array":   "xsql.MArray",
+			},
+		},
+		FieldFilter: map[string]map[string]string{
+			"enum_object": {
+				FieldsRequired: "type,status",
+			},
+		},
+		Comments: map[string]map[string]string{
+			"enum_object": {
+				"type":   `simple type in, A=1:test a, B=2:test b`,
+				"status": `simple status in, Normal=100, Removed=-1`,
+			},
+		},
+		TypeMap:  TypeMapSQLITE,
+		NameConv: nameConv,
+	}
+	for _, c := range []struct {
+		Name   string
+		Gen    *AutoGen
+		Tables []*Table
+	}{
+		{Name: "simple", Gen: &AutoGen{TypeMap: TypeMapSQLITE, NameConv: nameConv}, Tables: []*Table{simpleTable}},
+		{Name: "enum", Gen: enumGen, Tables: []*Table{enumTable}},
+		{Name: "all", Gen: enumGen, Tables: []*Table{simpleTable, enumTable}},
+	} {
+		data, err := c.Gen.OpenAPI(c.Tables)
+		if err != nil {
+			t.Errorf("%v: %v", c.Name, err)
+			return
+		}
+		golden := filepath.Join("testdata", "openapi_"+c.Name+".json")
+		if *updateGolden {
+			ioutil.WriteFile(golden, data, 0644)
+		}
+		expect, _ := ioutil.ReadFile(golden)
+		if string(data) != string(expect) {
+			t.Errorf("%v: not equal to %v by \n%v", c.Name, golden, string(data))
+			return
+		}
+	}
+}
+
+func TestSqliteGenRelation(t *testing.T) {
+	useSQLITE(t)
+	tables, err := Query(getSQLITE(), TableSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = QueryForeignKeys(getSQLITE(), ForeignKeySQLSQLITE, "", tables)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, table := range tables {
+		if table.Name != "crud_uuid_object" {
+			continue
+		}
+		if len(table.ForeignKeys) != 1 || table.ForeignKeys[0].Column != "object_id" || table.ForeignKeys[0].RefTable != "crud_object" || table.ForeignKeys[0].RefColumn != "tid" {
+			t.Errorf("foreign key error %v", converter.JSON(table.ForeignKeys))
+			return
+		}
+	}
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if !strings.Contains(string(funcData), "func LoadCrudUuidObjectObjectCall(caller interface{}, ctx context.Context, crudUuidObjectList []*CrudUuidObject) (objectMap map[int64]*CrudObject, err error)") {
+		t.Error("relation error")
+		return
+	}
+	autoGen.TableInclude = xsql.StringArray{"crud_uuid_object"}
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ = ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if strings.Contains(string(funcData), "LoadCrudUuidObjectObject") {
+		t.Error("relation error")
+		return
+	}
+}
+
+func TestSqliteGenView(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if !strings.Contains(string(funcData), "func FindCrudObjectView(ctx context.Context, crudObjectViewID int64)") || !strings.Contains(string(funcData), "func ListCrudObjectViewByID(") {
+		t.Error("view error")
+		return
+	}
+	if strings.Contains(string(funcData), "func (crudObjectView *CrudObjectView) Insert(") || strings.Contains(string(funcData), "func UpdateCrudObjectViewFilter(") {
+		t.Error("view error")
+		return
+	}
+	autoGen.TableViewKey = nil
+	err = autoGen.Generate()
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	autoGen.IncludeViews = false
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ = ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	if strings.Contains(string(funcData), "CrudObjectView") {
+		t.Error("view error")
+		return
+	}
+}
+
+func TestSqliteGenColumn(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	modelData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_models.go"))
+	if strings.Contains(string(modelData), `json:"description`) || !strings.Contains(string(modelData), "Picture ") {
+		t.Error("column error")
+		return
+	}
+	for _, c := range []struct {
+		Exclude map[string]xsql.StringArray
+		Rename  map[string]map[string]string
+		Message string
+	}{
+		{Exclude: map[string]xsql.StringArray{"crud_object": {"tid"}}, Message: "primary key"},
+		{Exclude: map[string]xsql.StringArray{"crud_object": {"xxx"}}, Message: "crud_object.xxx is not exists"},
+		{Rename: map[string]map[string]string{"crud_object": {"xxx": "XXX"}}, Message: "crud_object.xxx is not exists"},
+	} {
+		autoGen := SqliteGen
+		autoGen.Out = dir
+		autoGen.ColumnExclude = c.Exclude
+		autoGen.ColumnRename = c.Rename
+		err = autoGen.Generate()
+		if err == nil || !strings.Contains(err.Error(), c.Message) {
+			t.Errorf("err is %v", err)
+			return
+		}
+	}
+}
+
+func TestSqliteGenJSON(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	modelData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_models.go"))
+	funcData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_func.go"))
+	stubData, _ := ioutil.ReadFile(filepath.Join(dir, "json_types.go"))
+	if !strings.Contains(string(modelData), "CrudObjectData") || !strings.Contains(string(funcData), "func (v *CrudObjectData) Scan(") || !strings.Contains(string(stubData), "type CrudObjectData struct") {
+		t.Error("json error")
+		return
+	}
+	edited := strings.Replace(string(stubData), "type CrudObjectData struct {", "type CrudObjectData struct {\n\tName string `json:\"name\"`", 1)
+	ioutil.WriteFile(filepath.Join(dir, "json_types.go"), []byte(edited), os.ModePerm)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stubData, _ = ioutil.ReadFile(filepath.Join(dir, "json_types.go"))
+	if string(stubData) != edited {
+		t.Error("json error")
+		return
+	}
+}
+
+func TestConvCamelCaseWith(t *testing.T) {
+	useSQLITE(t)
+	conv := ConvCamelCaseWith(CamelCaseOptions{
+		Acronyms:       append(DefaultAcronyms, "i18n", "qq"),
+		SuffixAcronyms: DefaultSuffixAcronyms,
+	})
+	for _, name := range []string{"tid", "uuid", "i18n", "qq", "user_id", "user_ids", "int64_array", "update_time"} {
+		if conv(false, name) != nameConv(false, name) {
+			t.Errorf("%v is %v, but %v", name, conv(false, name), nameConv(false, name))
+			return
+		}
+	}
+	for name, expect := range map[string]string{"api_url": "APIURL", "remote_ip": "RemoteIP", "ids_value": "IdsValue", "crud_object": "CrudObject"} {
+		if conv(true, name) != expect {
+			t.Errorf("%v is %v, but %v", name, conv(true, name), expect)
+			return
+		}
+	}
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	autoGen.NameConv = nil
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	modelData, _ := ioutil.ReadFile(filepath.Join(dir, "auto_models.go"))
+	if !strings.Contains(string(modelData), "UserID ") || !strings.Contains(string(modelData), "TID ") {
+		t.Error("name conv error")
+		return
+	}
+}
+
+func TestSqliteGenDryRun(t *testing.T) {
+	useSQLITE(t)
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	autoGen := SqliteGen
+	autoGen.Out = dir
+	autoGen.DryRun = true
+	files, written, err := autoGen.GenerateFiles()
+	if err != nil || len(files) < 1 || len(written) != len(files) {
+		t.Errorf("%v,%v,%v", err, len(files), len(written))
+		return
+	}
+	if !strings.Contains(string(files["auto_func.go"]), "func FindCrudObject(") {
+		t.Error("dry run error")
+		return
+	}
+	if infos, _ := ioutil.ReadDir(dir); len(infos) > 0 {
+		t.Error("dry run error")
+		return
+	}
+	outputs := map[string][]byte{}
+	autoGen.DryRun = false
+	autoGen.Output = func(name string, content []byte) error {
+		outputs[name] = content
+		return nil
+	}
+	files, written, err = autoGen.GenerateFiles()
+	if err != nil || len(files) > 0 || len(written) != len(outputs) || !strings.Contains(string(outputs["auto_func.go"]), "func FindCrudObject(") {
+		t.Errorf("%v,%v,%v", err, len(files), len(written))
+		return
+	}
+	autoGen.Output = nil
+	_, written, err = autoGen.GenerateFiles()
+	if err != nil || len(written) != len(outputs) {
+		t.Errorf("%v,%v", err, len(written))
+		return
+	}
+	_, written, err = autoGen.GenerateFiles()
+	if err != nil || len(written) > 0 {
+		t.Errorf("%v,%v", err, written)
+		return
+	}
+}
+
+func TestSqliteGenUnique(t *testing.T) {
+	useSQLITE(t)
+	tables, err := Query(getSQLITE(), TableSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err = QueryUniques(getSQLITE(), UniqueSQLSQLITE, "", tables)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, table := range tables {
+		if table.Name == "crud_uuid_object" && (len(table.Uniques) != 1 || strings.Join(table.Uniques[0].Columns, ",") != "code") {
+			t.Errorf("unique error %v", converter.JSON(table.Uniques))
+			return
+		}
+	}
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(string(files["auto_func.go"]), "func FindCrudUuidObjectByCodeCall(caller interface{}, ctx context.Context, code string, lock bool) (crudUuidObject *CrudUuidObject, err error)") {
+		t.Error("unique error")
+		return
+	}
+	//unique without pointer field is not guarded
+	ddlFile := filepath.Join(t.TempDir(), "unique.sql")
+	ioutil.WriteFile(ddlFile, []byte(`
+CREATE TABLE crud_unique_object (
+    tid bigint NOT NULL,
+    code character varying(255) NOT NULL,
+    title character varying(255)
+);
+ALTER TABLE ONLY crud_unique_object
+    ADD CONSTRAINT crud_unique_object_pkey PRIMARY KEY (tid);
+ALTER TABLE ONLY crud_unique_object
+    ADD CONSTRAINT crud_unique_object_code_key UNIQUE (code);
+ALTER TABLE ONLY crud_unique_object
+    ADD CONSTRAINT crud_unique_object_code_title_key UNIQUE (code, title);
+`), os.ModePerm)
+	ddlGen := PgGen
+	ddlGen.Queryer = nil
+	ddlGen.TablesFromDDL = []string{ddlFile}
+	ddlGen.TableInclude = nil
+	ddlGen.DryRun = true
+	files, _, err = ddlGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testData := string(files["auto_func_test.go"])
+	if !strings.Contains(testData, "\tfindCrudUniqueObject, err = FindCrudUniqueObjectByCode(context.Background(), crudUniqueObject.Code)") ||
+		!strings.Contains(testData, "if crudUniqueObject.Title != nil {") || strings.Contains(testData, "true {") {
+		t.Errorf("unique error\n%v", testData)
+		return
+	}
+	for name, arg := range map[string]string{"UUID": "uuid", "MerchantID": "merchantID", "OrderNo": "orderNo", "Type": "typeValue", "X": "x"} {
+		if argName(name) != arg {
+			t.Errorf("%v is %v", name, argName(name))
+			return
+		}
+	}
+}
+
+func TestSqliteGenMock(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	test := string(files["auto_func_test.go"])
+	if !strings.Contains(test, "func MockCrudObject(overrides ...func(crudObject *CrudObject)) (crudObject *CrudObject) {") ||
+		!strings.Contains(test, "func MustAddCrudObject(t testing.TB, overrides ...func(crudObject *CrudObject)) (crudObject *CrudObject) {") ||
+		!strings.Contains(test, `crudObject.Title = "mock-title"`) ||
+		!strings.Contains(test, "crudObject.Type = CrudObjectTypeA") {
+		t.Errorf("mock error\n%v", test)
+		return
+	}
+	if strings.Contains(test, "func MustAddCrudObjectView(") {
+		t.Error("mock error")
+		return
+	}
+}
+
+func TestSqliteGenWhere(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	models, funcs := string(files["auto_models.go"]), string(files["auto_func.go"])
+	if !strings.Contains(models, "type CrudObjectFilter struct {") ||
+		!strings.Contains(models, `json:"type" cmp:"instr(','||$%v||',', ','||quote(type)||',')>0"`) ||
+		!strings.Contains(models, `json:"object_id" cmp:"object_id=$%v"`) ||
+		!strings.Contains(funcs, "func (f *CrudObjectFilter) Where(where []string, args []interface{}) (where_ []string, args_ []interface{}) {") ||
+		!strings.Contains(funcs, "func ListCrudObjectByFilter(ctx context.Context, filter *CrudObjectFilter)") {
+		t.Errorf("where error\n%v", models)
+		return
+	}
+	autoGen.CodeSlice = map[string]string{}
+	autoGen.FieldFilter = map[string]map[string]string{
+		"crud_uuid_object": {
+			FieldsWhere: "code,status",
+		},
+	}
+	files, _, err = autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	models = string(files["auto_models.go"])
+	if !strings.Contains(models, `json:"type" cmp:"type=any($%v)"`) ||
+		!strings.Contains(models, `json:"code" cmp:"code=$%v"`) ||
+		strings.Contains(models, `json:"object_id" cmp:"object_id=$%v"`) {
+		t.Errorf("where error\n%v", models)
+		return
+	}
+}
+
+func TestSqliteGenSchemas(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	autoGen.ForeignKeySQL, autoGen.UniqueSQL = "", ""
+	autoGen.TableQueryer = func(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error) {
+		tables, err = Query(queryer, tableSQL, columnSQL, "") //sqlite is not supported schema
+		return
+	}
+	autoGen.Schemas = []SchemaConfig{
+		{Name: "", TableInclude: xsql.StringArray{"crud_object"}},
+		{Name: "report", TableInclude: xsql.StringArray{"crud_uuid_object"}},
+	}
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	publicStruct, reportStruct := string(files["auto_models.go"]), string(files[filepath.Join("report", "auto_models.go")])
+	if !strings.Contains(publicStruct, "package autogen") || !strings.Contains(publicStruct, `table:"crud_object"`) || strings.Contains(publicStruct, "CrudUuidObject") {
+		t.Errorf("schema error\n%v", publicStruct)
+		return
+	}
+	if !strings.Contains(reportStruct, "package report") || !strings.Contains(reportStruct, `table:"report.crud_uuid_object"`) || strings.Contains(reportStruct, "type CrudObject struct") {
+		t.Errorf("schema error\n%v", reportStruct)
+		return
+	}
+	if !strings.Contains(string(files[filepath.Join("report", "auto_func.go")]), `crud.MetaWith(string("report.crud_uuid_object"), fields...)`) {
+		t.Error("schema error")
+		return
+	}
+}
+
+func TestSqliteGenAudit(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData := string(files["auto_func.go"])
+	if !strings.Contains(funcData, `const CrudUuidObjectFilterOptional = "code"`) || !strings.Contains(funcData, `const CrudUuidObjectFilterRequired = "title"`) {
+		t.Errorf("audit filter error\n%v", funcData)
+		return
+	}
+	if !strings.Contains(funcData, "crudUuidObject.CreatedBy = GetAuditUser(ctx)") || !strings.Contains(funcData, `crud.UpdateSQL(crudUuidObject, filter+"|updated_by", nil)`) {
+		t.Errorf("audit code error\n%v", funcData)
+		return
+	}
+	for filter, except := range map[string]string{
+		"":                   "",
+		"title,created_by":   "title",
+		"^tid#all":           "^tid,created_by#all",
+		"^created_by,tid":    "^created_by,tid",
+		"title,updated_by#a": "title,updated_by#a",
+	} {
+		if result := excludeFilter(filter, xsql.StringArray{"created_by"}); result != except {
+			t.Errorf("%v is %v", filter, result)
+			return
+		}
+	}
+}
+
+func TestFieldRange(t *testing.T) {
+	useSQLITE(t)
+	autoGen := &AutoGen{}
+	table := &Table{
+		Name: "test",
+		Checks: []*Check{
+			{Name: "test_level_check", Expr: "CHECK (((level >= 0) AND (level <= 10)))"},
+			{Name: "test_amount_check", Expr: "CHECK ((amount > (0)::numeric))"},
+			{Name: "test_or_check", Expr: "CHECK (((count > 0) OR (count < -10)))"},
+			{Name: "test_code_check", Expr: "CHECK (((length((code)::text) > 2) AND (length((code)::text) <= 8)))"},
+			{Name: "test", Expr: `CREATE TABLE "test" ("name" TEXT NOT NULL CHECK (length("name") <= 32), "age" INT CHECK ("age" BETWEEN 1 AND 150))`},
+		},
+	}
+	for _, c := range []struct {
+		Column *Column
+		Type   string
+		Kind   string
+		Result string
+	}{
+		{Column: &Column{Name: "title", Type: "character varying(64)"}, Type: "string", Kind: "l", Result: "l:0"},
+		{Column: &Column{Name: "title", Type: "VARCHAR(255)"}, Type: "string", Kind: "l", Result: "l:0"},
+		{Column: &Column{Name: "title", Type: "text"}, Type: "string", Kind: "l", Result: "l:0"},
+		{Column: &Column{Name: "name", Type: "TEXT"}, Type: "string", Kind: "l", Result: "l:0"},
+		{Column: &Column{Name: "name", Type: "VARCHAR(64)"}, Type: "string", Kind: "l", Result: "l:0"},
+		{Column: &Column{Name: "code", Type: "VARCHAR(64)"}, Type: "string", Kind: "l", Result: "l:2"},
+		{Column: &Column{Name: "level", Type: "integer"}, Type: "int", Kind: "r", Result: "r:-1~11"},
+		{Column: &Column{Name: "age", Type: "INT"}, Type: "int", Kind: "r", Result: "r:0~151"},
+		{Column: &Column{Name: "count", Type: "integer"}, Type: "int", Kind: "r", Result: "r:0"},
+		{Column: &Column{Name: "price", Type: "numeric(10,2)"}, Type: "decimal.Decimal", Kind: "r", Result: "r:0~100000000"},
+		{Column: &Column{Name: "amount", Type: "numeric(6)"}, Type: "decimal.Decimal", Kind: "r", Result: "r:0~1000000"},
+	} {
+		s := &Struct{Table: table}
+		result := autoGen.FieldRange(s, &Field{Column: c.Column, Type: c.Type}, c.Kind, "0")
+		if result != c.Result {
+			t.Errorf("%v is %v, except %v", c.Column.Name, result, c.Result)
+			return
+		}
+	}
+	sqliteGen := SqliteGen
+	sqliteGen.DryRun = true
+	files, _, err := sqliteGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	structData := string(files["auto_models.go"])
+	if !strings.Contains(structData, `valid:"title,r|s,l:0;"`) || !strings.Contains(structData, `valid:"status,r|i,r:-1~1001;"`) {
+		t.Errorf("range error\n%v", structData)
+		return
+	}
+}
+
+func TestSqliteGenStable(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 0; i < 3; i++ {
+		reverseGen := SqliteGen
+		reverseGen.DryRun = true
+		reverseGen.TableQueryer = func(queryer interface{}, tableSQL, columnSQL, schema string) (tables []*Table, err error) {
+			tables, err = Query(queryer, tableSQL, columnSQL, schema)
+			for i, j := 0, len(tables)-1; i < j; i, j = i+1, j-1 {
+				tables[i], tables[j] = tables[j], tables[i]
+			}
+			for _, table := range tables {
+				for i, j := 0, len(table.Columns)-1; i < j; i, j = i+1, j-1 {
+					table.Columns[i], table.Columns[j] = table.Columns[j], table.Columns[i]
+				}
+			}
+			return
+		}
+		reverseFiles, _, err := reverseGen.GenerateFiles()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(files) != len(reverseFiles) {
+			t.Errorf("files is %v/%v", len(files), len(reverseFiles))
+			return
+		}
+		for name, data := range files {
+			if !bytes.Equal(data, reverseFiles[name]) {
+				t.Errorf("%v is not stable", name)
+				return
+			}
+		}
+	}
+}
+
+func TestQueryWithProgress(t *testing.T) {
+	useSQLITE(t)
+	tables, err := Query(getSQLITE(), TableViewSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	progress := []string{}
+	batchTables, err := QueryWithProgress(getSQLITE(), TableViewSQLSQLITE, ColumnSQLSQLITE, ColumnAllSQLSQLITE, "", func(i, total int, table *Table) {
+		progress = append(progress, fmt.Sprintf("%v/%v:%v", i, total, table.Name))
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if converter.JSON(tables) != converter.JSON(batchTables) {
+		t.Errorf("batch error\n%v\n%v", converter.JSON(tables), converter.JSON(batchTables))
+		return
+	}
+	if strings.Join(progress, ",") != "0/3:crud_object,1/3:crud_object_view,2/3:crud_uuid_object" {
+		t.Errorf("progress is %v", progress)
+		return
+	}
+	_, err = QueryWithProgress(getSQLITE(), TableViewSQLSQLITE, "select xx from none where name=$1", "", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "query table crud_object columns fail with") {
+		t.Error(err)
+		return
+	}
+	_, err = QueryWithProgress(getSQLITE(), TableViewSQLSQLITE, "select xx from none where name=$1", ColumnAllSQLSQLITE, "", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err = QueryWithProgress(getSQLITE(), TableViewSQLSQLITE, ColumnSQLSQLITE, "select xx from none", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "query all columns fail with") {
+		t.Error(err)
+		return
+	}
+	_, err = QueryWithProgress(getSQLITE(), "select xx from none", ColumnSQLSQLITE, "", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "query tables fail with") {
+		t.Error(err)
+		return
+	}
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	total := 0
+	batchGen := SqliteGen
+	batchGen.DryRun = true
+	batchGen.ColumnAllSQL = ColumnAllSQLSQLITE
+	batchGen.OnTable = func(i, n int, table *Table) { total++ }
+	batchFiles, _, err := batchGen.GenerateFiles()
+	if err != nil || total != 3 {
+		t.Errorf("err is %v, total is %v", err, total)
+		return
+	}
+	for name, data := range files {
+		if !bytes.Equal(data, batchFiles[name]) {
+			t.Errorf("%v is not equal", name)
+			return
+		}
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "update golden files of generated code")
+
+func TestSqliteGenGolden(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, name := range []string{"auto_models.go", "auto_define.go", "auto_func.go", "auto_func_test.go"} {
+		golden := filepath.Join("testdata", "golden", name+".golden")
+		if *updateGolden {
+			os.MkdirAll(filepath.Dir(golden), os.ModePerm)
+			ioutil.WriteFile(golden, files[name], os.ModePerm)
+			continue
+		}
+		except, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !bytes.Equal(files[name], except) {
+			t.Errorf("%v is not equal to %v, run go test -run TestSqliteGenGolden -update to update it if changed is expected", name, golden)
+			return
+		}
+	}
+}
+
+func TestSqliteGenDocComments(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	autoGen.DocComments = true
+	autoGen.TableInclude = xsql.StringArray{"crud_object"}
+	autoGen.Comments = map[string]map[string]string{
+		"crud_object": {
+			"":       "the object for crud test\nit is multi line",
+			"title":  "the object title",
+			"type":   SqliteGen.Comments["crud_object"]["type"],
+			"status": SqliteGen.Comments["crud_object"]["status"],
+		},
+	}
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	golden := filepath.Join("testdata", "golden", "auto_models_doc.go.golden")
+	if *updateGolden {
+		ioutil.WriteFile(golden, files["auto_models.go"], os.ModePerm)
+	}
+	except, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !bytes.Equal(files["auto_models.go"], except) {
+		t.Errorf("auto_models.go is not equal to %v, run go test -run TestSqliteGenDocComments -update to update it if changed is expected\n%v", golden, string(files["auto_models.go"]))
+		return
+	}
+	//same comment on openapi
+	tables, err := Query(getSQLITE(), TableSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	data, err := autoGen.OpenAPI(tables)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !strings.Contains(string(data), `"description": "the object for crud test\nit is multi line"`) || !strings.Contains(string(data), `"description": "the object title"`) {
+		t.Errorf("openapi comment error\n%v", string(data))
+		return
+	}
+	//empty table comment
+	autoGen.Comments = nil
+	files, _, err = autoGen.GenerateFiles()
+	if err != nil || !strings.Contains(string(files["auto_models.go"]), "// CrudObject is the model of table crud_object\ntype CrudObject struct {") {
+		t.Errorf("%v\n%v", err, string(files["auto_models.go"]))
+		return
+	}
+	if DocComment("A", " ", "is", "b\n\nc") != "// A is b\n//\n// c" {
+		t.Error(DocComment("A", " ", "is", "b\n\nc"))
+		return
+	}
+}
+
+func TestSqliteGenPreData(t *testing.T) {
+	useSQLITE(t)
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	autoGen.TableInclude = xsql.StringArray{"crud_object"}
+	autoGen.PreData = func(gen *Gen, table *Table, data *TemplateData) interface{} {
+		result := data.Map()
+		result["GenValid"] = false
+		result["Custom"] = "custom"
+		return result
+	}
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if strings.Contains(string(files["auto_func.go"]), "func (crudObject *CrudObject) Valid() (err error)") {
+		t.Error("pre data error")
+		return
+	}
+	data, err := autoGen.OpenAPI([]*Table{{Name: "crud_object", Columns: []*Column{{Name: "tid", Type: "integer", IsPK: true, NotNull: true}}}})
+	if err != nil || !strings.Contains(string(data), "CrudObject") {
+		t.Errorf("%v,%v", err, string(data))
+		return
+	}
+	templateData, err := AsTemplateData(map[string]interface{}{"Struct": &Struct{Name: "A"}, "GenValid": true, "Custom": 1})
+	if err != nil || templateData.Struct.Name != "A" || !templateData.GenValid {
+		t.Error(err)
+		return
+	}
+	_, err = AsTemplateData(map[string]interface{}{"Struct": "A"})
+	if err == nil {
+		t.Error("error")
+		return
+	}
+	_, err = AsTemplateData(map[string]interface{}{})
+	if err == nil {
+		t.Error("error")
+		return
+	}
+	_, err = AsTemplateData("A")
+	if err == nil {
+		t.Error("error")
+		return
+	}
+}
+
+func TestColumnDefaultLiteral(t *testing.T) {
+	useSQLITE(t)
+	for value, expect := range map[string]string{
+		"100":                          "100",
+		"-1":                           "-1",
+		"(-1)":                         "-1",
+		"1.5":                          "1.5",
+		"'-1'::integer":                "-1",
+		"'abc'::text":                  `"abc"`,
+		"'abc'::character varying":     `"abc"`,
+		"'a::b'":                       `"a::b"`,
+		"'it''s'":                      `"it's"`,
+		"''::character varying":        `""`,
+		"true":                         "true",
+		"FALSE":                        "false",
+		"now()":                        "",
+		"nextval('a_seq'::regclass)":   "",
+		"CURRENT_TIMESTAMP":            "",
+		"(lower(hex(randomblob(16))))": "",
+		"'{}'::jsonb":                  `"{}"`,
+		"'a' || 'b'":                   "",
+	} {
+		literal, ok := ColumnDefaultLiteral(value)
+		if literal != expect || ok != (len(expect) > 0) {
+			t.Errorf("%v is %v,%v, but %v", value, literal, ok, expect)
+			return
+		}
+	}
+	autoGen := SqliteGen
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData := string(files["auto_func.go"])
+	if !strings.Contains(funcData, "const CrudObjectLevelDefault = 0") || strings.Contains(funcData, "CrudObjectTitleDefault") || !strings.Contains(funcData, "const CrudObjectTypeDefault = \"\"") {
+		t.Error("default error")
+		return
+	}
+}
+
+func TestFieldZero(t *testing.T) {
+	for typ, expect := range map[string]string{
+		"string":              `""`,
+		"bool":                "false",
+		"int64":               "0",
+		"float64":             "0",
+		"decimal.Decimal":     "decimal.Zero",
+		"xsql.Time":           "xsql.Time{}",
+		"xsql.M":              "nil",
+		"xsql.Int64Array":     "nil",
+		"xsql.Int64NilZero":   "xsql.Int64NilZero{}",
+		"*string":             "nil",
+		"[]int64":             "nil",
+		"map[string]int":      "nil",
+		"[2]int":              "[2]int{}",
+		"interface{}":         "nil",
+		"CrudObjectData":      "CrudObjectData{}",
+		"":                    "nil",
+		"func(a int) (error)": "nil",
+	} {
+		if literal := ZeroLiteral(typ); literal != expect {
+			t.Errorf("%v is %v, but %v", typ, literal, expect)
+			return
+		}
+	}
+	//compile zero literal of all field
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "func.tmpl"), []byte(`
+{{define "Default"}}
+{{- range .Defaults}}
+const {{.Name}} = {{.Value}}
+{{end}}
+//zero{{.Struct.Name}} will assign zero literal to all field
+func zero{{.Struct.Name}}() (v *{{.Struct.Name}}) {
+	v = &{{.Struct.Name}}{}
+	{{- range .Struct.Fields}}
+	v.{{.Name}} = {{FieldZero $.Struct .}}
+	{{- end}}
+	_ = {{ZeroLiteral "decimal.Decimal"}}
+	return
+}
+{{end}}
+`), os.ModePerm)
+	autoGen := PgGen
+	autoGen.Queryer = nil
+	autoGen.TablesFromDDL = []string{"../testsql/pg_latest.sql"}
+	autoGen.TemplateDir = dir
+	autoGen.ExtraImports = map[string][]string{ImportsFunc: {"github.com/shopspring/decimal"}}
+	autoGen.Out = "./autogen_zero/"
+	os.MkdirAll(autoGen.Out, os.ModePerm)
+	defer os.RemoveAll(autoGen.Out)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	funcData, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_func.go"))
+	if !strings.Contains(string(funcData), "v.TimeValue = xsql.Time{}") || !strings.Contains(string(funcData), "v.Float64Value = decimal.Zero") || !strings.Contains(string(funcData), "v.Image = nil") {
+		t.Errorf("zero error\n%v", string(funcData))
+		return
+	}
+	pwd, _ := os.Getwd()
+	vet := exec.Command("go", "vet", ".")
+	vet.Dir = filepath.Join(pwd, "autogen_zero")
+	vet.Stderr = os.Stderr
+	vet.Stdout = os.Stdout
+	err = vet.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+const MockInit = `
+package autogen
+
+import (
+	"context"
+
+	"github.com/codingeasygo/crud"
+	"github.com/codingeasygo/crud/gen"
+)
+
+func init() {
+	GetAuditUser = func(ctx context.Context) int64 { return 100 }
+	crud.Default.NameConv = gen.NameConvPG
+	crud.Default.ParmConv = gen.ParmConvPG
+}
+`
+
+func TestPgGenAgainstMock(t *testing.T) {
+	autoGen := PgGen
+	autoGen.Queryer = nil
+	autoGen.TablesFromDDL = []string{"../testsql/pg_latest.sql"}
+	autoGen.TestAgainstMock = true
+	autoGen.Out = "./autogen_mock/"
+	var err error
+	defer func() {
+		if err == nil {
+			os.RemoveAll(autoGen.Out)
+		}
+	}()
+	os.MkdirAll(autoGen.Out, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(autoGen.Out, "auto_test.go"), []byte(MockInit), os.ModePerm)
+	err = autoGen.Generate()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testData, _ := ioutil.ReadFile(filepath.Join(autoGen.Out, "auto_func_test.go"))
+	if !strings.Contains(string(testData), "func FixtureCrudObject(ctx context.Context)") || !strings.Contains(string(testData), `mocker.FixtureShared.Fixture("crud_object"`) {
+		t.Errorf("fixture error\n%v", string(testData))
+		return
+	}
+	pwd, _ := os.Getwd()
+	tester := exec.Command("go", "test", "-v")
+	tester.Dir = filepath.Join(pwd, "autogen_mock")
+	tester.Stderr = os.Stderr
+	tester.Stdout = os.Stdout
+	err = tester.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,76 @@
+package gen
+
+import (
+	"strings"
+)
+
+// DefaultIrregulars is the irregular plural to singular map used by SingularWith, the uncountable word is mapped to itself
+var DefaultIrregulars = map[string]string{
+	"people":   "person",
+	"men":      "man",
+	"women":    "woman",
+	"children": "child",
+	"feet":     "foot",
+	"teeth":    "tooth",
+	"geese":    "goose",
+	"mice":     "mouse",
+	"indices":  "index",
+	"matrices": "matrix",
+	"vertices": "vertex",
+	"statuses": "status",
+	"buses":    "bus",
+	"aliases":  "alias",
+	"movies":   "movie",
+	"cookies":  "cookie",
+	"news":     "news",
+	"series":   "series",
+	"species":  "species",
+	"data":     "data",
+	"metadata": "metadata",
+}
+
+// Singular will return the singular of name by DefaultIrregulars and english rules, only the last part splitted by _ is converted,
+// like users to user, order_items to order_item
+func Singular(name string) string {
+	return SingularWith(nil)(name)
+}
+
+// SingularWith will return the inflector to singular name, the last part splitted by _ is checked in irregulars first, DefaultIrregulars second,
+// then converted by rules: ies to y, sses/xes/ches/shes to remove es, s to remove s, the word ending with ss/us/is is kept
+func SingularWith(irregulars map[string]string) func(name string) string {
+	return func(name string) string {
+		index := strings.LastIndex(name, "_")
+		prefix, word := name[:index+1], name[index+1:]
+		return prefix + singularWord(irregulars, word)
+	}
+}
+
+func singularWord(irregulars map[string]string, word string) string {
+	lower := strings.ToLower(word)
+	for _, irregular := range []map[string]string{irregulars, DefaultIrregulars} {
+		if singular, ok := irregular[lower]; ok {
+			return keepCase(word, singular)
+		}
+	}
+	switch {
+	case len(lower) < 3:
+		return word
+	case strings.HasSuffix(lower, "ss") || strings.HasSuffix(lower, "us") || strings.HasSuffix(lower, "is"):
+		return word
+	case strings.HasSuffix(lower, "ies") && len(lower) > 4:
+		return word[:len(word)-3] + keepCase(word[len(word)-3:], "y")
+	case strings.HasSuffix(lower, "sses") || strings.HasSuffix(lower, "xes") || strings.HasSuffix(lower, "ches") || strings.HasSuffix(lower, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "s"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// keepCase will return value in upper case when word is upper case, it is used to keep USERS to USER
+func keepCase(word, value string) string {
+	if len(word) > 0 && word == strings.ToUpper(word) {
+		return strings.ToUpper(value)
+	}
+	return value
+}
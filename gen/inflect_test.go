@@ -0,0 +1,124 @@
+package gen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSingular(t *testing.T) {
+	for name, expect := range map[string]string{
+		"users":       "user",
+		"addresses":   "address",
+		"categories":  "category",
+		"order_items": "order_item",
+		"boxes":       "box",
+		"matches":     "match",
+		"wishes":      "wish",
+		"people":      "person",
+		"user_people": "user_person",
+		"statuses":    "status",
+		"status":      "status",
+		"address":     "address",
+		"analysis":    "analysis",
+		"news":        "news",
+		"user":        "user",
+		"USERS":       "USER",
+		"CATEGORIES":  "CATEGORY",
+		"as":          "as",
+	} {
+		if singular := Singular(name); singular != expect {
+			t.Errorf("%v is %v, but %v", name, singular, expect)
+			return
+		}
+	}
+	inflector := SingularWith(map[string]string{"octopi": "octopus", "people": "peoples"})
+	if singular := inflector("sea_octopi"); singular != "sea_octopus" {
+		t.Error(singular)
+		return
+	}
+	if singular := inflector("people"); singular != "peoples" {
+		t.Error(singular)
+		return
+	}
+}
+
+func TestSingularize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gen")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	ddlFile := filepath.Join(dir, "tables.sql")
+	ioutil.WriteFile(ddlFile, []byte(`
+CREATE TABLE users (
+  tid bigint NOT NULL,
+  user_name character varying(64) NOT NULL,
+  PRIMARY KEY (tid)
+);
+CREATE TABLE addresses (
+  tid bigint NOT NULL,
+  addresses text NOT NULL,
+  PRIMARY KEY (tid)
+);
+CREATE TABLE categories (
+  tid bigint NOT NULL,
+  PRIMARY KEY (tid)
+);
+CREATE TABLE order_items (
+  tid bigint NOT NULL,
+  PRIMARY KEY (tid)
+);
+`), os.ModePerm)
+	autoGen := PgGen
+	autoGen.Queryer = nil
+	autoGen.TablesFromDDL = []string{ddlFile}
+	autoGen.NameConv = nil
+	autoGen.Out = dir
+	autoGen.DryRun = true
+	files, _, err := autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if models := string(files["auto_models.go"]); !strings.Contains(models, "type Users struct") {
+		t.Errorf("singularize is enabled\n%v", models)
+		return
+	}
+	autoGen.Singularize = true
+	files, _, err = autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	models := string(files["auto_models.go"])
+	for _, expect := range []string{
+		"type User struct",
+		`table:"users"`,
+		"type Address struct",
+		`table:"addresses"`,
+		"Addresses string",
+		"type Category struct",
+		`table:"categories"`,
+		"type OrderItem struct",
+		`table:"order_items"`,
+	} {
+		if !strings.Contains(models, expect) {
+			t.Errorf("%v is not found\n%v", expect, models)
+			return
+		}
+	}
+	autoGen.Inflector = func(name string) string { return "my_" + name }
+	files, _, err = autoGen.GenerateFiles()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if models := string(files["auto_models.go"]); !strings.Contains(models, "type MyUsers struct") || !strings.Contains(models, `table:"users"`) {
+		t.Errorf("inflector error\n%v", models)
+		return
+	}
+}
@@ -0,0 +1,278 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DDLTypeMapPG is the reverse type map of TypeMapPG, it is used to convert the column type which is not supported by postgres
+var DDLTypeMapPG = map[string]string{
+	"int":             "integer",
+	"int64":           "bigint",
+	"float32":         "real",
+	"float64":         "double precision",
+	"decimal.Decimal": "numeric",
+	"string":          "text",
+	"xsql.Time":       "timestamp with time zone",
+	"bool":            "boolean",
+	"xsql.M":          "jsonb",
+}
+
+// DDLTypeMapSQLITE is the reverse type map of TypeMapSQLITE, it is used to convert the column type which is not supported by sqlite
+var DDLTypeMapSQLITE = map[string]string{
+	"int":             "INT4",
+	"int64":           "INTEGER",
+	"float32":         "REAL",
+	"float64":         "DOUBLE",
+	"decimal.Decimal": "DOUBLE",
+	"string":          "TEXT",
+	"xsql.Time":       "DATE",
+	"bool":            "BOOLEAN",
+	"xsql.M":          "TEXT",
+}
+
+// ddlSerialTypes is the postgres serial type and the base type
+var ddlSerialTypes = map[string]string{
+	"smallserial": "smallint",
+	"serial":      "integer",
+	"bigserial":   "bigint",
+}
+
+// DDL will render CREATE TABLE statement of tables by dialect postgres/sqlite, it is for development convenience not production migration.
+// The column type not supported by dialect is converted by DDLTypeMapPG/DDLTypeMapSQLITE, the view is skipped and table schema is not rendered,
+// so the ddl is applied to current schema
+func DDL(tables []*Table, dialect string) (ddl string, err error) {
+	var typeMap map[string][]string
+	switch dialect {
+	case "postgres":
+		typeMap = TypeMapPG
+	case "sqlite":
+		typeMap = TypeMapSQLITE
+	default:
+		err = fmt.Errorf("dialect %v is not supported", dialect)
+		return
+	}
+	statements := []string{}
+	for _, table := range tables {
+		if table.IsView() {
+			continue
+		}
+		var create string
+		create, err = ddlCreateTable(table, dialect, typeMap)
+		if err != nil {
+			return
+		}
+		statements = append(statements, create)
+		if dialect == "postgres" {
+			statements = append(statements, ddlComments(table)...)
+		}
+	}
+	ddl = strings.Join(statements, "\n")
+	return
+}
+
+// DiffDDL will render ALTER TABLE statement to migrate current tables to desired tables for added/removed/retyped columns,
+// the table only in desired is created and the table only in current is dropped, the column type is rendered as desired without converting.
+// The ALTER COLUMN TYPE statement is postgres only and the view is skipped
+func DiffDDL(current, desired []*Table) (ddl string, err error) {
+	currentAll := map[string]*Table{}
+	for _, table := range current {
+		if !table.IsView() {
+			currentAll[table.Name] = table
+		}
+	}
+	desiredAll := map[string]bool{}
+	statements := []string{}
+	for _, want := range desired {
+		if want.IsView() {
+			continue
+		}
+		desiredAll[want.Name] = true
+		have := currentAll[want.Name]
+		if have == nil {
+			var create string
+			create, err = ddlCreateTable(want, "", nil)
+			if err != nil {
+				return
+			}
+			statements = append(statements, create)
+			continue
+		}
+		tableName := ddlQuote(want.Name)
+		for _, column := range want.Columns {
+			exists := ddlColumn(have, column.Name)
+			if exists == nil {
+				var define string
+				define, err = ddlColumnDefine(column, "", nil)
+				if err != nil {
+					return
+				}
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v;", tableName, define))
+				continue
+			}
+			if !strings.EqualFold(ddlColumnType(exists), ddlColumnType(column)) {
+				typ := ddlColumnType(column)
+				if base, ok := ddlSerialTypes[strings.ToLower(typ)]; ok {
+					typ = base
+				}
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %v ALTER COLUMN %v TYPE %v;", tableName, ddlQuote(column.Name), typ))
+			}
+		}
+		for _, column := range have.Columns {
+			if ddlColumn(want, column.Name) == nil {
+				statements = append(statements, fmt.Sprintf("ALTER TABLE %v DROP COLUMN %v;", tableName, ddlQuote(column.Name)))
+			}
+		}
+	}
+	for _, table := range current {
+		if !table.IsView() && !desiredAll[table.Name] {
+			statements = append(statements, fmt.Sprintf("DROP TABLE %v;", ddlQuote(table.Name)))
+		}
+	}
+	ddl = strings.Join(statements, "\n")
+	return
+}
+
+func ddlCreateTable(table *Table, dialect string, typeMap map[string][]string) (create string, err error) {
+	if len(table.Columns) < 1 {
+		err = fmt.Errorf("table %v is not having columns", table.Name)
+		return
+	}
+	defines := []string{}
+	keys := []string{}
+	for _, column := range table.Columns {
+		var define string
+		define, err = ddlColumnDefine(column, dialect, typeMap)
+		if err != nil {
+			err = fmt.Errorf("table %v %v", table.Name, err)
+			return
+		}
+		defines = append(defines, "  "+define)
+		if column.IsPK {
+			keys = append(keys, ddlQuote(column.Name))
+		}
+	}
+	if len(keys) > 0 {
+		defines = append(defines, fmt.Sprintf("  PRIMARY KEY (%v)", strings.Join(keys, ",")))
+	}
+	create = fmt.Sprintf("CREATE TABLE %v (\n%v\n);", ddlQuote(table.Name), strings.Join(defines, ",\n"))
+	return
+}
+
+// ddlColumnDefine will render column define like "name" type NOT NULL DEFAULT x, the type is converted by typeMap if dialect is not empty
+func ddlColumnDefine(column *Column, dialect string, typeMap map[string][]string) (define string, err error) {
+	typ := ddlColumnType(column)
+	if len(typ) < 1 {
+		err = fmt.Errorf("column %v type is empty", column.Name)
+		return
+	}
+	if len(dialect) > 0 {
+		typ = ddlConvType(typ, dialect, typeMap)
+	}
+	define = ddlQuote(column.Name) + " " + typ
+	if column.NotNull {
+		define += " NOT NULL"
+	}
+	if column.DefaultValue != nil {
+		if defaultValue := ddlDefault(column, dialect); len(defaultValue) > 0 {
+			define += " DEFAULT " + defaultValue
+		}
+	}
+	return
+}
+
+// ddlColumnType will return the type to create column, it is serial type for auto increment column on postgres
+func ddlColumnType(column *Column) (typ string) {
+	ddlType := strings.ToLower(column.DDLType)
+	if _, ok := ddlSerialTypes[ddlType]; ok {
+		return ddlType
+	}
+	if ddlType == "autogenuuid" {
+		return "uuid"
+	}
+	return strings.TrimSpace(column.Type)
+}
+
+// ddlConvType will keep the type which is supported by typeMap, or convert it by reverse type map of dialect
+func ddlConvType(typ, dialect string, typeMap map[string][]string) string {
+	base := strings.ToLower(strings.TrimSpace(strings.SplitN(typ, "(", 2)[0]))
+	if _, ok := ddlSerialTypes[base]; ok {
+		if dialect == "postgres" {
+			return typ
+		}
+		//sqlite INTEGER PRIMARY KEY is alias of rowid which is auto increment
+		return "INTEGER"
+	}
+	if _, ok := typeMap[base]; ok {
+		return typ
+	}
+	reverseMap := DDLTypeMapPG
+	if dialect == "sqlite" {
+		reverseMap = DDLTypeMapSQLITE
+	}
+	for _, sourceMap := range []map[string][]string{TypeMapPG, TypeMapSQLITE} {
+		if types, ok := sourceMap[base]; ok {
+			if target, ok := reverseMap[types[0]]; ok {
+				return target
+			}
+		}
+	}
+	return typ
+}
+
+// ddlSQLITEDefaults is the postgres default function translated for sqlite, the empty value is dropped as sqlite is not supported
+var ddlSQLITEDefaults = map[string]string{
+	"now()":                   "CURRENT_TIMESTAMP",
+	"current_timestamp":       "CURRENT_TIMESTAMP",
+	"localtimestamp":          "CURRENT_TIMESTAMP",
+	"transaction_timestamp()": "CURRENT_TIMESTAMP",
+	"statement_timestamp()":   "CURRENT_TIMESTAMP",
+	"clock_timestamp()":       "CURRENT_TIMESTAMP",
+	"current_date":            "CURRENT_DATE",
+	"current_time":            "CURRENT_TIME",
+	"localtime":               "CURRENT_TIME",
+	"gen_random_uuid()":       "",
+	"uuid_generate_v1()":      "",
+	"uuid_generate_v4()":      "",
+}
+
+// ddlDefault will return the default value of column, the auto increment default is skipped,
+// the postgres type cast is removed, the known postgres function is translated by ddlSQLITEDefaults
+// and the expression is wrapped by parentheses for sqlite
+func ddlDefault(column *Column, dialect string) (value string) {
+	value = strings.TrimSpace(*column.DefaultValue)
+	if _, ok := ddlSerialTypes[strings.ToLower(column.DDLType)]; ok || strings.HasPrefix(value, "nextval(") {
+		return ""
+	}
+	if dialect != "sqlite" {
+		return
+	}
+	value = trimDefaultCast(value)
+	if translated, ok := ddlSQLITEDefaults[strings.ToLower(value)]; ok {
+		return translated
+	}
+	if _, ok := ColumnDefaultLiteral(value); !ok && !strings.HasPrefix(value, "(") && !strings.EqualFold(value, "NULL") {
+		value = "(" + value + ")"
+	}
+	return
+}
+
+func ddlComments(table *Table) (comments []string) {
+	if len(table.Comment) > 0 {
+		comments = append(comments, fmt.Sprintf("COMMENT ON TABLE %v IS %v;", ddlQuote(table.Name), ddlString(table.Comment)))
+	}
+	for _, column := range table.Columns {
+		if len(column.Comment) > 0 {
+			comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %v.%v IS %v;", ddlQuote(table.Name), ddlQuote(column.Name), ddlString(column.Comment)))
+		}
+	}
+	return
+}
+
+func ddlQuote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func ddlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,280 @@
+package gen
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codingeasygo/crud/sqlx"
+	"github.com/codingeasygo/crud/testsql"
+	"github.com/codingeasygo/util/converter"
+)
+
+func TestDDL(t *testing.T) {
+	tables, warnings := ParseDDL(testsql.PG_LATEST)
+	if len(warnings) > 0 {
+		t.Errorf("warnings is %v", warnings)
+		return
+	}
+	ddl, err := DDL(tables, "postgres")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, line := range []string{
+		`CREATE TABLE "crud_object" (`,
+		`  "tid" bigserial NOT NULL,`,
+		`  "type" character varying(255) NOT NULL DEFAULT ''::character varying,`,
+		`  "image" character varying(1024),`,
+		`  PRIMARY KEY ("tid")`,
+		`  "tid" uuid NOT NULL DEFAULT gen_random_uuid(),`,
+		`COMMENT ON COLUMN "crud_object"."status" IS 'simple status in, Normal=100, Disabled=200, Removed=-1';`,
+	} {
+		if !strings.Contains(ddl, line) {
+			t.Errorf("%v is not found on ddl\n%v", line, ddl)
+			return
+		}
+	}
+	if strings.Contains(ddl, "crud_object_view") {
+		t.Errorf("view is rendered\n%v", ddl)
+		return
+	}
+	parsed, warnings := ParseDDL(ddl)
+	if len(warnings) > 0 {
+		t.Errorf("warnings is %v", warnings)
+		return
+	}
+	diff, err := DiffDDL(parsed, tables)
+	if err != nil || len(diff) > 0 {
+		t.Errorf("err is %v, diff is\n%v", err, diff)
+		return
+	}
+	if object := findDDLTable(parsed, "crud_object"); object.Columns[2].Comment != findDDLTable(tables, "crud_object").Columns[2].Comment {
+		t.Errorf("comment is %v", object.Columns[2].Comment)
+		return
+	}
+	//convert to sqlite
+	ddl, err = DDL(tables, "sqlite")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, line := range []string{
+		`  "tid" INTEGER NOT NULL,`,
+		`  "type" TEXT NOT NULL DEFAULT '',`,
+		`  "data" TEXT NOT NULL DEFAULT '[]',`,
+		`  "float64_value" double precision NOT NULL DEFAULT 0,`,
+		`  "time_value" DATE NOT NULL,`,
+		`  "tid" TEXT NOT NULL,`,
+	} {
+		if !strings.Contains(ddl, line) {
+			t.Errorf("%v is not found on ddl\n%v", line, ddl)
+			return
+		}
+	}
+	if strings.Contains(ddl, "COMMENT ON") {
+		t.Errorf("comment is rendered\n%v", ddl)
+		return
+	}
+	scratch := newScratchSQLITE(t)
+	_, _, err = scratch.Exec(context.Background(), ddl)
+	if err != nil {
+		t.Errorf("err is %v by\n%v", err, ddl)
+		return
+	}
+	//error
+	if _, err = DDL(tables, "oracle"); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = DDL([]*Table{{Name: "empty"}}, "postgres"); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = DDL([]*Table{{Name: "none", Columns: []*Column{{Name: "none"}}}}, "postgres"); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = DiffDDL(nil, []*Table{{Name: "empty"}}); err == nil {
+		t.Error("error")
+		return
+	}
+	if _, err = DiffDDL([]*Table{{Name: "none", Columns: []*Column{{Name: "a", Type: "text"}}}}, []*Table{{Name: "none", Columns: []*Column{{Name: "b"}}}}); err == nil {
+		t.Error("error")
+		return
+	}
+}
+
+func TestDiffDDL(t *testing.T) {
+	current := []*Table{
+		{Name: "crud_user", Type: "table", Columns: []*Column{
+			{Name: "tid", Type: "bigint", DDLType: "bigserial", IsPK: true, NotNull: true},
+			{Name: "name", Type: "character varying(64)", NotNull: true},
+			{Name: "role", Type: "integer"},
+		}},
+		{Name: "crud_old", Type: "table", Columns: []*Column{{Name: "tid", Type: "bigint"}}},
+		{Name: "crud_user_view", Type: "view", Columns: []*Column{{Name: "tid", Type: "bigint"}}},
+	}
+	desired := []*Table{
+		{Name: "crud_user", Type: "table", Columns: []*Column{
+			{Name: "tid", Type: "bigint", DDLType: "bigserial", IsPK: true, NotNull: true},
+			{Name: "name", Type: "text", NotNull: true},
+			{Name: "status", Type: "integer", NotNull: true, DefaultValue: converter.StringPtr("100")},
+		}},
+		{Name: "crud_new", Type: "table", Columns: []*Column{{Name: "tid", Type: "bigint", DDLType: "bigserial", IsPK: true, NotNull: true}}},
+		{Name: "crud_new_view", Type: "view", Columns: []*Column{{Name: "tid", Type: "bigint"}}},
+	}
+	diff, err := DiffDDL(current, desired)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	expected := strings.Join([]string{
+		`ALTER TABLE "crud_user" ALTER COLUMN "name" TYPE text;`,
+		`ALTER TABLE "crud_user" ADD COLUMN "status" integer NOT NULL DEFAULT 100;`,
+		`ALTER TABLE "crud_user" DROP COLUMN "role";`,
+		`CREATE TABLE "crud_new" (`,
+		`  "tid" bigserial NOT NULL,`,
+		`  PRIMARY KEY ("tid")`,
+		`);`,
+		`DROP TABLE "crud_old";`,
+	}, "\n")
+	if diff != expected {
+		t.Errorf("diff is\n%v", diff)
+		return
+	}
+	desired[0].Columns[0].DDLType = ""
+	desired[0].Columns[0].Type = "bigserial"
+	if diff, _ = DiffDDL(current, desired[:1]); !strings.HasPrefix(diff, `ALTER TABLE "crud_user" ALTER COLUMN "name"`) {
+		t.Errorf("diff is\n%v", diff)
+		return
+	}
+	current[0].Columns[0].DDLType = ""
+	if diff, _ = DiffDDL(current, desired[:1]); !strings.HasPrefix(diff, `ALTER TABLE "crud_user" ALTER COLUMN "tid" TYPE bigint;`) {
+		t.Errorf("diff is\n%v", diff)
+		return
+	}
+}
+
+func newScratchSQLITE(t *testing.T) *sqlx.DbQueryer {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		panic(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return sqlx.NewDbQueryer(db)
+}
+
+func TestSqliteDDLDefault(t *testing.T) {
+	tables, warnings := ParseDDL(`
+CREATE TABLE crud_default (
+    tid uuid DEFAULT gen_random_uuid() NOT NULL,
+    title character varying(255) DEFAULT 'abc'::character varying NOT NULL,
+    level integer DEFAULT (1 + 2) NOT NULL,
+    day date DEFAULT CURRENT_DATE NOT NULL,
+    update_time timestamp with time zone DEFAULT now() NOT NULL,
+    create_time timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (tid)
+);
+`)
+	if len(warnings) > 0 {
+		t.Errorf("warnings is %v", warnings)
+		return
+	}
+	ddl, err := DDL(tables, "sqlite")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	golden := filepath.Join("testdata", "golden", "ddl_default_sqlite.sql.golden")
+	if *updateGolden {
+		ioutil.WriteFile(golden, []byte(ddl), os.ModePerm)
+	}
+	except, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if ddl != string(except) {
+		t.Errorf("ddl is not equal to %v, run go test -run TestSqliteDDLDefault -update to update it if changed is expected\n%v", golden, ddl)
+		return
+	}
+	scratch := newScratchSQLITE(t)
+	_, _, err = scratch.Exec(context.Background(), ddl+";insert into crud_default(tid) values('a')")
+	if err != nil {
+		t.Errorf("err is %v by\n%v", err, ddl)
+		return
+	}
+}
+
+func TestSqliteDDL(t *testing.T) {
+	useSQLITE(t)
+	tables, err := Query(getSQLITE(), TableSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ddl, err := DDL(tables, "sqlite")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	scratch := newScratchSQLITE(t)
+	_, _, err = scratch.Exec(context.Background(), ddl)
+	if err != nil {
+		t.Errorf("err is %v by\n%v", err, ddl)
+		return
+	}
+	applied, err := Query(scratch, TableSQLSQLITE, ColumnSQLSQLITE, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(applied) != len(tables) {
+		t.Errorf("tables is %v/%v", len(applied), len(tables))
+		return
+	}
+	diff, err := DiffDDL(applied, tables)
+	if err != nil || len(diff) > 0 {
+		t.Errorf("err is %v, diff is\n%v", err, diff)
+		return
+	}
+}
+
+func TestPgDDL(t *testing.T) {
+	tables, err := Query(getPG(), TableSQLPG, ColumnSQLPG, "public")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	ddl, err := DDL(tables, "postgres")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, _, err = getPG().Exec(context.Background(), "DROP SCHEMA IF EXISTS crud_ddl CASCADE;CREATE SCHEMA crud_ddl;SET search_path TO crud_ddl;"+ddl+";SET search_path TO public")
+	if err != nil {
+		t.Errorf("err is %v by\n%v", err, ddl)
+		return
+	}
+	defer getPG().Exec(context.Background(), "DROP SCHEMA IF EXISTS crud_ddl CASCADE")
+	applied, err := Query(getPG(), TableSQLPG, ColumnSQLPG, "crud_ddl")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(applied) != len(tables) {
+		t.Errorf("tables is %v/%v", len(applied), len(tables))
+		return
+	}
+	diff, err := DiffDDL(applied, tables)
+	if err != nil || len(diff) > 0 {
+		t.Errorf("err is %v, diff is\n%v", err, diff)
+		return
+	}
+}
@@ -19,8 +19,20 @@ AND c.relkind = 'r'
 ORDER BY c.relname
 `
 
-const ColumnSQLPG = `
+const TableViewSQLPG = `
 SELECT
+    c.relname AS name,
+	c.relkind::text AS type,
+    coalesce(obj_description(c.oid),'') as comment
+FROM pg_class c
+JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1
+AND c.relkind IN ('r', 'v', 'm')
+ORDER BY c.relname
+`
+
+// columnSelectPG is the shared select fields and joins of ColumnSQLPG/ColumnAllSQLPG
+const columnSelectPG = `
     a.attname AS name,
     format_type(a.atttypid, a.atttypmod) AS type,
 	COALESCE(ct.contype = 'p', false) AS  is_pk,
@@ -54,13 +66,75 @@ JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
 LEFT JOIN pg_constraint ct ON ct.conrelid = c.oid
 AND a.attnum = ANY(ct.conkey) AND ct.contype = 'p'
 LEFT JOIN pg_attrdef ad ON ad.adrelid = c.oid AND ad.adnum = a.attnum
-WHERE a.attisdropped = false
+`
+
+const ColumnSQLPG = `
+SELECT` + columnSelectPG + `WHERE a.attisdropped = false
     AND n.nspname = $1
     AND c.relname = $2
     AND a.attnum > 0
 ORDER BY a.attnum
 `
 
+const ColumnAllSQLPG = `
+SELECT
+    c.relname AS "table",` + columnSelectPG + `WHERE a.attisdropped = false
+    AND n.nspname = $1
+    AND c.relkind IN ('r', 'v', 'm')
+    AND a.attnum > 0
+ORDER BY c.relname, a.attnum
+`
+
+const ForeignKeySQLPG = `
+SELECT
+    a.attname AS column,
+    rc.relname AS ref_table,
+    ra.attname AS ref_column
+FROM pg_constraint ct
+JOIN ONLY pg_class c ON c.oid = ct.conrelid
+JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+JOIN ONLY pg_class rc ON rc.oid = ct.confrelid
+JOIN pg_attribute a ON a.attrelid = ct.conrelid AND a.attnum = ct.conkey[1]
+JOIN pg_attribute ra ON ra.attrelid = ct.confrelid AND ra.attnum = ct.confkey[1]
+WHERE ct.contype = 'f'
+    AND array_length(ct.conkey, 1) = 1
+    AND n.nspname = $1
+    AND c.relname = $2
+ORDER BY a.attnum
+`
+
+const UniqueSQLPG = `
+SELECT
+    i.relname AS name,
+    a.attname AS column
+FROM pg_index x
+JOIN ONLY pg_class c ON c.oid = x.indrelid
+JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+JOIN ONLY pg_class i ON i.oid = x.indexrelid
+JOIN LATERAL unnest(x.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+WHERE x.indisunique
+    AND NOT x.indisprimary
+    AND x.indpred IS NULL
+    AND x.indexprs IS NULL
+    AND n.nspname = $1
+    AND c.relname = $2
+ORDER BY i.relname, k.ord
+`
+
+const CheckSQLPG = `
+SELECT
+    ct.conname AS name,
+    pg_get_constraintdef(ct.oid) AS expr
+FROM pg_constraint ct
+JOIN ONLY pg_class c ON c.oid = ct.conrelid
+JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+WHERE ct.contype = 'c'
+    AND n.nspname = $1
+    AND c.relname = $2
+ORDER BY ct.conname
+`
+
 var TypeMapPG = map[string][]string{
 	//int
 	"smallint":    {"int", "*int"},
@@ -76,6 +150,7 @@ var TypeMapPG = map[string][]string{
 	"character":         {"string", "*string"},
 	"character varying": {"string", "*string"},
 	"text":              {"string", "*string"},
+	"uuid":              {"string", "*string"},
 	//time
 	"time with time zone":         {"xsql.Time", "xsql.Time"},
 	"time without time zone":      {"xsql.Time", "xsql.Time"},
@@ -91,6 +166,7 @@ var TypeMapPG = map[string][]string{
 
 var CodeSlicePG = map[string]string{
 	"RowLock": "for update",
+	"WhereIn": "COLUMN=any($%v)",
 }
 
 func NameConvPG(on, name string, field reflect.StructField) string {
@@ -12,10 +12,37 @@ where type='table' and name <> 'sqlite_sequence'
 order by name asc
 `
 
+const TableViewSQLSQLITE = `
+select name,type,'' from sqlite_master
+where type in ('table','view') and name <> 'sqlite_sequence'
+order by name asc
+`
+
 const ColumnSQLSQLITE = `
 select name,type,pk,"notnull",dflt_value,cid,type,'' from pragma_table_info($1)
 `
 
+const ColumnAllSQLSQLITE = `
+select m.name,p.name,p.type,p.pk,p."notnull",p.dflt_value,p.cid,p.type,'' from sqlite_master m join pragma_table_info(m.name) p
+where m.type in ('table','view') and m.name <> 'sqlite_sequence'
+order by m.name,p.cid
+`
+
+const ForeignKeySQLSQLITE = `
+select "from","table",coalesce("to",'') from pragma_foreign_key_list($1)
+where id not in (select id from pragma_foreign_key_list($1) where seq > 0)
+`
+
+const UniqueSQLSQLITE = `
+select il.name,ii.name from pragma_index_list($1) il join pragma_index_info(il.name) ii
+where il."unique" = 1 and il.origin <> 'pk' and il.partial = 0
+order by il.name,ii.seqno
+`
+
+const CheckSQLSQLITE = `
+select name,coalesce(sql,'') from sqlite_master where type='table' and name=$1
+`
+
 var TypeMapSQLITE = map[string][]string{
 	//int
 	"integer": {"int64", "*int64"},
@@ -44,6 +71,7 @@ var TypeMapSQLITE = map[string][]string{
 
 var CodeSliceSQLITE = map[string]string{
 	"RowLock": "",
+	"WhereIn": "instr(','||$%v||',', ','||quote(COLUMN)||',')>0",
 }
 
 func NameConvSQLITE(on, name string, field reflect.StructField) string {
@@ -23,17 +23,42 @@ var {{$.Struct.Name}}{{$field.Name}}Show={{$.Struct.Name}}{{$field.Name}}Array{{
 const {{.Struct.Name}}OrderbyAll = "{{.Filter.Order}}"
 {{- end }}
 
+{{- if .DocComments}}
+
+{{if .Struct.Comment}}{{DocComment .Struct.Name "is" .Struct.Comment (printf "(table: %%v)" .Struct.Table.Name)}}{{else}}{{DocComment .Struct.Name "is the model of table" .Struct.Table.Name}}{{end}}
+type {{ .Struct.Name }} struct {
+	// T is the table name tag
+	T {{.TableNameType}} %vjson:"-" table:"{{.TableName}}"%v
+{{- range $field := .Struct.Fields }}
+{{- with .Column.Comment}}
+	{{DocComment $field.Name "is" .}}
+{{- end}}
+	{{ .Name }} {{FieldType $.Struct . }} %vjson:"{{FieldJson $.Struct . }}"{{FieldTags $.Struct . }}%v
+{{- end }}
+}
+{{- else}}
+
 /*
  * {{.Struct.Name}} {{ .Struct.Comment}} represents {{ .Struct.Table.Name }}
  * {{.Struct.Name}} Fields:{{- range .Struct.Fields }}{{.Column.Name}},{{- end }}
  */
 type {{ .Struct.Name }} struct {
-	T {{.TableNameType}}  %vjson:"-" table:"{{.Struct.Table.Name}}"%v /* the table name tag */
+	T {{.TableNameType}}  %vjson:"-" table:"{{.TableName}}"%v /* the table name tag */
 {{- range .Struct.Fields }}
 	{{ .Name }} {{FieldType $.Struct . }}  %vjson:"{{FieldJson $.Struct . }}"{{FieldTags $.Struct . }}%v /* {{ .Column.Comment }} */
 {{- end }}
 }
-`, "`", "`", "`", "`")
+{{- end}}
+{{- if .Wheres}}
+
+//{{.Struct.Name}}Filter is the typed where filter of {{.Struct.Table.Name}}, the nil or zero field is ignored
+type {{.Struct.Name}}Filter struct {
+{{- range .Wheres}}
+	{{.Field.Name}} {{.Type}} %vjson:"{{.Field.Column.Name}}" cmp:"{{.Cmp}}"%v
+{{- end}}
+}
+{{- end}}
+`, "`", "`", "`", "`", "`", "`", "`", "`", "`", "`")
 
 var DefineTmpl = `
 /**
@@ -64,6 +89,7 @@ var DefineTmpl = `
 `
 
 var StructFuncTmpl = `
+{{block "Filter" .}}
 //{{.Struct.Name}}FilterOptional is crud filter
 const {{.Struct.Name}}FilterOptional = "{{.Filter.Optional}}"
 
@@ -81,7 +107,9 @@ const {{.Struct.Name}}FilterFind = "{{.Filter.Find}}"
 
 //{{.Struct.Name}}FilterScan is crud filter
 const {{.Struct.Name}}FilterScan = "{{.Filter.Scan}}"
+{{end}}
 
+{{block "Enum" .}}
 {{- range $i,$field := .Struct.Fields }}
 {{- if $field.Options}}
 //EnumValid will valid value by {{$.Struct.Name}}{{$field.Name}}
@@ -116,33 +144,79 @@ func (o *{{$.Struct.Name}}{{$field.Name}}Array)EnumValid(v interface{}) (err err
 	return fmt.Errorf("must be in %v", {{$.Struct.Name}}{{$field.Name}}All)
 }
 
+//values will return the {{$field.Type}} value of {{$.Struct.Name}}{{$field.Name}}Array
+func (o {{$.Struct.Name}}{{$field.Name}}Array) values() (values []{{$field.Type}}) {
+	for _, v := range o {
+		values = append(values, {{$field.Type}}(v))
+	}
+	return
+}
+
 //DbArray will join value to database array
 func (o {{$.Struct.Name}}{{$field.Name}}Array) DbArray() (res string) {
-	res = "{" + converter.JoinSafe(o, ",", converter.JoinPolicyDefault) + "}"
+	res = "{" + converter.JoinSafe(o.values(), ",", converter.JoinPolicyDefault) + "}"
 	return
 }
 
 //InArray will join value to database array
 func (o {{$.Struct.Name}}{{$field.Name}}Array) InArray() (res string) {
 	{{- if eq $field.Type "string"}}
-	res = "'" + converter.JoinSafe(o, "','", converter.JoinPolicyDefault) + "'"
+	res = "'" + converter.JoinSafe(o.values(), "','", converter.JoinPolicyDefault) + "'"
 	{{- else}}
-	res = "" + converter.JoinSafe(o, ",", converter.JoinPolicyDefault) + ""
+	res = "" + converter.JoinSafe(o.values(), ",", converter.JoinPolicyDefault) + ""
 	{{- end}}
 	return
 }
+{{- if $.EnumHelpers}}
+
+//String will return the option name of {{$.Struct.Name}}{{$field.Name}}
+func (o {{$.Struct.Name}}{{$field.Name}}) String() string {
+	switch o {
+	{{- range $field.Options}}
+	case {{.Name}}:
+		return "{{.Name}}"
+	{{- end}}
+	}
+	return fmt.Sprintf("{{$.Struct.Name}}{{$field.Name}}(%v)", {{$field.Type}}(o))
+}
+
+//Label will return the option comment of {{$.Struct.Name}}{{$field.Name}}, it is String when comment is empty
+func (o {{$.Struct.Name}}{{$field.Name}}) Label() string {
+	switch o {
+	{{- range $field.Options}}
+	case {{.Name}}:
+		return {{if .Comment}}{{printf "%q" .Comment}}{{else}}"{{.Name}}"{{end}}
+	{{- end}}
+	}
+	return o.String()
+}
+
+//MarshalJSON will marshal {{$.Struct.Name}}{{$field.Name}} as {{$field.Type}} value
+func (o {{$.Struct.Name}}{{$field.Name}}) MarshalJSON() ([]byte, error) {
+	return json.Marshal({{$field.Type}}(o))
+}
+{{- end}}
 {{- end }}
 {{- end }}
+{{end}}
+
+{{block "Default" .}}
+{{- range .Defaults}}
+//{{.Name}} is the default value of {{$.Struct.Table.Name}}.{{.Field.Column.Name}}
+const {{.Name}} = {{.Value}}
+{{end}}
+{{end}}
 
+{{block "Meta" .}}
 //MetaWith{{.Struct.Name}} will return {{.Struct.Table.Name}} meta data
 func MetaWith{{.Struct.Name}}(fields ...interface{}) (v []interface{}) {
-	v = crud.MetaWith({{.TableNameType}}("{{.Struct.Table.Name}}"), fields...)
+	v = crud.MetaWith({{.TableNameType}}("{{.TableName}}"), fields...)
 	return
 }
 
 //MetaWith will return {{.Struct.Table.Name}} meta data
 func ({{.Arg.Name}} *{{.Struct.Name}}) MetaWith(fields ...interface{}) (v []interface{}) {
-	v = crud.MetaWith({{.TableNameType}}("{{.Struct.Table.Name}}"), fields...)
+	v = crud.MetaWith({{.TableNameType}}("{{.TableName}}"), fields...)
 	return
 }
 
@@ -151,7 +225,9 @@ func ({{.Arg.Name}} *{{.Struct.Name}}) Meta() (table string, fileds []string) {
 	table, fileds = crud.QueryField({{.Arg.Name}}, "#all")
 	return
 }
+{{end}}
 
+{{block "Valid" .}}
 {{- if .GenValid}}
 //Valid will valid by filter
 func ({{.Arg.Name}} *{{.Struct.Name}}) Valid() (err error) {
@@ -163,7 +239,10 @@ func ({{.Arg.Name}} *{{.Struct.Name}}) Valid() (err error) {
 	return
 }
 {{- end}}
+{{end}}
 
+{{if not .View}}
+{{block "Insert" .}}
 //Insert will add {{.Struct.Table.Name}} to database
 func ({{.Arg.Name}} *{{.Struct.Name}}) Insert(caller interface{}, ctx context.Context) (err error) {
 	{{.Add.Defaults}}
@@ -175,6 +254,22 @@ func ({{.Arg.Name}} *{{.Struct.Name}}) Insert(caller interface{}, ctx context.Co
 	return
 }
 
+{{- if .Upsert.Conflict}}
+
+//Upsert will add {{.Struct.Table.Name}} to database or update it when {{.Upsert.Conflict}} is conflict
+func ({{.Arg.Name}} *{{.Struct.Name}}) Upsert(caller interface{}, ctx context.Context) (err error) {
+	{{.Add.Defaults}}
+	{{- if .Add.Return}}
+	_, err = crud.UpsertFilter(caller, ctx, {{.Arg.Name}}, "{{.Add.Filter}}", "{{.Upsert.Conflict}}", {{.Struct.Name}}FilterUpdate{{with .Update.AuditFilter}}+"|{{.}}"{{end}}, "returning", "{{.Add.Return}}")
+	{{- else}}
+	_, err = crud.UpsertFilter(caller, ctx, {{.Arg.Name}}, "{{.Add.Filter}}", "{{.Upsert.Conflict}}", {{.Struct.Name}}FilterUpdate{{with .Update.AuditFilter}}+"|{{.}}"{{end}}, "", "")
+	{{- end}}
+	return
+}
+{{- end}}
+{{end}}
+
+{{block "Update" .}}
 //UpdateFilter will update {{.Struct.Table.Name}} to database
 func ({{.Arg.Name}} *{{.Struct.Name}}) UpdateFilter(caller interface{}, ctx context.Context, filter string) (err error) {
 	err = {{.Arg.Name}}.UpdateFilterWheref(caller, ctx, filter, "")
@@ -192,7 +287,13 @@ func ({{.Arg.Name}} *{{.Struct.Name}}) UpdateFilterWheref(caller interface{}, ct
 	{{- if .Update.UpdateTime}}
 	{{.Arg.Name}}.UpdateTime = xsql.TimeNow()
 	{{- end}}
-	sql, args := crud.UpdateSQL({{.Arg.Name}}, filter, nil)
+	{{- with .Update.Audit}}
+	{{.}}
+	{{- end}}
+	sql, args, err := crud.UpdateSQL({{.Arg.Name}}, filter{{with .Update.AuditFilter}}+"|{{.}}"{{end}}, nil)
+	if err != nil {
+		return
+	}
 	where, args := crud.AppendWheref(nil, args, "{{PrimaryField .Struct "Column"}}=$%v", {{.Arg.Name}}.{{PrimaryField .Struct "Name"}})
 	if len(formats) > 0 {
 		where, args = crud.AppendWheref(where, args, formats, formatArgs...)
@@ -200,7 +301,9 @@ func ({{.Arg.Name}} *{{.Struct.Name}}) UpdateFilterWheref(caller interface{}, ct
 	err = crud.UpdateRow(caller, ctx, {{.Arg.Name}}, sql, where, "and", args)
 	return
 }
+{{end}}
 
+{{block "Add" .}}
 {{if .Add.Normal}}
 //Add{{.Struct.Name}} will add {{.Struct.Table.Name}} to database
 func Add{{.Struct.Name}}(ctx context.Context, {{.Arg.Name}} *{{.Struct.Name}}) (err error) {
@@ -214,7 +317,25 @@ func Add{{.Struct.Name}}Call(caller interface{}, ctx context.Context, {{.Arg.Nam
 	return
 }
 {{end}}
+{{end}}
+
+{{block "Upsert" .}}
+{{- if .Upsert.Conflict}}
+//Upsert{{.Struct.Name}} will add {{.Struct.Table.Name}} to database or update it when {{.Upsert.Conflict}} is conflict
+func Upsert{{.Struct.Name}}(ctx context.Context, {{.Arg.Name}} *{{.Struct.Name}}) (err error) {
+	err = Upsert{{.Struct.Name}}Call(GetQueryer, ctx, {{.Arg.Name}})
+	return
+}
 
+//Upsert{{.Struct.Name}}Call will add {{.Struct.Table.Name}} to database or update it when {{.Upsert.Conflict}} is conflict
+func Upsert{{.Struct.Name}}Call(caller interface{}, ctx context.Context, {{.Arg.Name}} *{{.Struct.Name}}) (err error) {
+	err = {{.Arg.Name}}.Upsert(caller, ctx)
+	return
+}
+{{end}}
+{{end}}
+
+{{block "UpdateCall" .}}
 //Update{{.Struct.Name}}Filter will update {{.Struct.Table.Name}} to database
 func Update{{.Struct.Name}}Filter(ctx context.Context, {{.Arg.Name}} *{{.Struct.Name}}, filter string) (err error) {
 	err = Update{{.Struct.Name}}FilterCall(GetQueryer, ctx, {{.Arg.Name}}, filter)
@@ -250,7 +371,11 @@ func Update{{.Struct.Name}}FilterWherefCall(caller interface{}, ctx context.Cont
 	err = {{.Arg.Name}}.UpdateFilterWheref(caller, ctx, filter, formats, formatArgs...)
 	return
 }
+{{end}}
 
+{{end}}
+
+{{block "Find" .}}
 //Find{{.Struct.Name}}Call will find {{.Struct.Table.Name}} by id from database
 func Find{{.Struct.Name}}(ctx context.Context, {{.Arg.Name}}ID {{PrimaryField .Struct "Type"}}) ({{.Arg.Name}} *{{.Struct.Name}}, err error) {
 	{{.Arg.Name}}, err = Find{{.Struct.Name}}Call(GetQueryer, ctx, {{.Arg.Name}}ID, false)
@@ -304,7 +429,30 @@ func Find{{.Struct.Name}}FilterWherefCall(caller interface{}, ctx context.Contex
 	err = crud.QueryRow(caller, ctx, &{{.Struct.Name}}{}, filter, querySQL, queryArgs, &{{.Arg.Name}})
 	return
 }
+{{end}}
+
+{{block "Unique" .}}
+{{- range $unique := .Uniques}}
+//Find{{$.Struct.Name}}By{{$unique.Name}} will find {{$.Struct.Table.Name}} by unique {{range $i, $field := $unique.Fields}}{{if $i}},{{end}}{{$field.Column.Name}}{{end}} from database
+func Find{{$.Struct.Name}}By{{$unique.Name}}(ctx context.Context{{range $i, $arg := $unique.Args}}, {{$arg}} {{index $unique.Types $i}}{{end}}) ({{$.Arg.Name}} *{{$.Struct.Name}}, err error) {
+	{{$.Arg.Name}}, err = Find{{$.Struct.Name}}By{{$unique.Name}}Call(GetQueryer, ctx{{range $unique.Args}}, {{.}}{{end}}, false)
+	return
+}
 
+//Find{{$.Struct.Name}}By{{$unique.Name}}Call will find {{$.Struct.Table.Name}} by unique {{range $i, $field := $unique.Fields}}{{if $i}},{{end}}{{$field.Column.Name}}{{end}} from database
+func Find{{$.Struct.Name}}By{{$unique.Name}}Call(caller interface{}, ctx context.Context{{range $i, $arg := $unique.Args}}, {{$arg}} {{index $unique.Types $i}}{{end}}, lock bool) ({{$.Arg.Name}} *{{$.Struct.Name}}, err error) {
+	var where []string
+	var args []interface{}
+	{{- range $i, $field := $unique.Fields}}
+	where, args = crud.AppendWhere(where, args, true, "{{$field.Column.Name}}=$%v", {{index $unique.Args $i}})
+	{{- end}}
+	{{$.Arg.Name}}, err = Find{{$.Struct.Name}}WhereCall(caller, ctx, lock, "and", where, args)
+	return
+}
+{{end}}
+{{end}}
+
+{{block "List" .}}
 //List{{.Struct.Name}}ByID will list {{.Struct.Table.Name}} by id from database
 func List{{.Struct.Name}}ByID(ctx context.Context, {{.Arg.Name}}IDs ...{{PrimaryField .Struct "Type"}}) ({{.Arg.Name}}List []*{{.Struct.Name}}, {{.Arg.Name}}Map map[{{PrimaryField .Struct "Type"}}]*{{.Struct.Name}}, err error) {
 	{{.Arg.Name}}List, {{.Arg.Name}}Map, err = List{{.Struct.Name}}ByIDCall(GetQueryer, ctx, {{.Arg.Name}}IDs...)
@@ -349,6 +497,62 @@ func List{{.Struct.Name}}WherefCall(caller interface{}, ctx context.Context, for
 	return
 }
 
+//List{{.Struct.Name}}PageWheref will list {{.Struct.Table.Name}} by page and count total from database
+func List{{.Struct.Name}}PageWheref(ctx context.Context, format string, args []interface{}, order string, offset, limit int) ({{.Arg.Name}}List []*{{.Struct.Name}}, total int64, err error) {
+	{{.Arg.Name}}List, total, err = List{{.Struct.Name}}PageWherefCall(GetQueryer, ctx, format, args, order, offset, limit)
+	return
+}
+
+//List{{.Struct.Name}}PageWherefCall will list {{.Struct.Table.Name}} by page and count total from database
+func List{{.Struct.Name}}PageWherefCall(caller interface{}, ctx context.Context, format string, args []interface{}, order string, offset, limit int) ({{.Arg.Name}}List []*{{.Struct.Name}}, total int64, err error) {
+	querySQL := crud.QuerySQL(&{{.Struct.Name}}{}, "{{.Filter.Scan}}")
+	querySQL, queryArgs := crud.JoinWheref(querySQL, nil, format, args...)
+	{{- if .Filter.Order}}
+	querySQL = crud.JoinPage(querySQL, crud.BuildOrderby({{.Struct.Name}}OrderbyAll, order), offset, limit)
+	{{- else}}
+	querySQL = crud.JoinPage(querySQL, "", offset, limit)
+	{{- end}}
+	err = crud.Query(caller, ctx, &{{.Struct.Name}}{}, "{{.Filter.Scan}}", querySQL, queryArgs, &{{.Arg.Name}}List)
+	if err != nil {
+		return
+	}
+	err = crud.CountWheref(caller, ctx, crud.MetaWith(&{{.Struct.Name}}{}, int64(0)), "count({{PrimaryField .Struct "Column"}})#all", format, args, "", &total, "{{PrimaryField .Struct "Column"}}")
+	return
+}
+{{end}}
+
+{{block "Where" .}}
+{{- if .Wheres}}
+//Where will append the where of not nil and not zero field in {{.Struct.Name}}Filter
+func (f *{{.Struct.Name}}Filter) Where(where []string, args []interface{}) (where_ []string, args_ []interface{}) {
+	where_, args_ = where, args
+	if f == nil {
+		return
+	}
+	var filterWhere []string
+	filterWhere, args_ = crud.FilterWhere(args_, f, "")
+	where_ = append(where_, filterWhere...)
+	return
+}
+
+//List{{.Struct.Name}}ByFilter will list {{.Struct.Table.Name}} by typed filter from database
+func List{{.Struct.Name}}ByFilter(ctx context.Context, filter *{{.Struct.Name}}Filter) ({{.Arg.Name}}List []*{{.Struct.Name}}, {{.Arg.Name}}Map map[{{PrimaryField .Struct "Type"}}]*{{.Struct.Name}}, err error) {
+	{{.Arg.Name}}List, {{.Arg.Name}}Map, err = List{{.Struct.Name}}ByFilterCall(GetQueryer, ctx, filter)
+	return
+}
+
+//List{{.Struct.Name}}ByFilterCall will list {{.Struct.Table.Name}} by typed filter from database
+func List{{.Struct.Name}}ByFilterCall(caller interface{}, ctx context.Context, filter *{{.Struct.Name}}Filter) ({{.Arg.Name}}List []*{{.Struct.Name}}, {{.Arg.Name}}Map map[{{PrimaryField .Struct "Type"}}]*{{.Struct.Name}}, err error) {
+	querySQL := crud.QuerySQL(&{{.Struct.Name}}{}, "{{.Filter.Scan}}")
+	where, args := filter.Where(nil, nil)
+	querySQL = crud.JoinWhere(querySQL, where, " and ")
+	err = crud.Query(caller, ctx, &{{.Struct.Name}}{}, "{{.Filter.Scan}}", querySQL, args, &{{.Arg.Name}}List, &{{.Arg.Name}}Map, "{{PrimaryField .Struct "Column"}}")
+	return
+}
+{{- end}}
+{{end}}
+
+{{block "Scan" .}}
 //Scan{{.Struct.Name}}ByID will list {{.Struct.Table.Name}} by id from database
 func Scan{{.Struct.Name}}ByID(ctx context.Context, {{.Arg.Name}}IDs []{{PrimaryField .Struct "Type"}}, dest ...interface{}) (err error) {
 	err = Scan{{.Struct.Name}}ByIDCall(GetQueryer, ctx, {{.Arg.Name}}IDs, dest...)
@@ -370,9 +574,24 @@ func Scan{{.Struct.Name}}FilterByID(ctx context.Context, filter string, {{.Arg.N
 //Scan{{.Struct.Name}}FilterByIDCall will list {{.Struct.Table.Name}} by id from database
 func Scan{{.Struct.Name}}FilterByIDCall(caller interface{}, ctx context.Context, filter string, {{.Arg.Name}}IDs []{{PrimaryField .Struct "Type"}}, dest ...interface{}) (err error) {
 	querySQL := crud.QuerySQL(&{{.Struct.Name}}{}, filter)
+	{{- if eq (PrimaryField .Struct "Type") "string"}}
+	var queryArgs []interface{}
+	inParam := ""
+	for i, {{.Arg.Name}}ID := range {{.Arg.Name}}IDs {
+		if i > 0 {
+			inParam += ","
+		}
+		queryArgs = append(queryArgs, {{.Arg.Name}}ID)
+		inParam += fmt.Sprintf("$%v", len(queryArgs))
+	}
+	where := append([]string{}, fmt.Sprintf("{{PrimaryField .Struct "Column"}} in (%v)", inParam))
+	querySQL = crud.JoinWhere(querySQL, where, " and ")
+	err = crud.Query(caller, ctx, &{{.Struct.Name}}{}, filter, querySQL, queryArgs, dest...)
+	{{- else}}
 	where := append([]string{}, fmt.Sprintf("{{PrimaryField .Struct "Column"}} in (%v)", {{PrimaryField .Struct "TypeArray"}}({{.Arg.Name}}IDs).InArray()))
 	querySQL = crud.JoinWhere(querySQL, where, " and ")
 	err = crud.Query(caller, ctx, &{{.Struct.Name}}{}, filter, querySQL, nil, dest...)
+	{{- end}}
 	return
 }
 
@@ -405,12 +624,97 @@ func Scan{{.Struct.Name}}FilterWherefCall(caller interface{}, ctx context.Contex
 	err = crud.Query(caller, ctx, &{{.Struct.Name}}{}, filter, querySQL, args, dest...)
 	return
 }
+{{end}}
+
+{{block "Relation" .}}
+{{- range .Relations}}
+//Load{{$.Struct.Name}}{{.Name}} will load {{.Ref.Table.Name}} referenced by {{$.Struct.Table.Name}}.{{.Field.Column.Name}} from database
+func Load{{$.Struct.Name}}{{.Name}}(ctx context.Context, {{$.Arg.Name}}List []*{{$.Struct.Name}}) ({{.Arg}}Map map[{{PrimaryField .Ref "Type"}}]*{{.Ref.Name}}, err error) {
+	{{.Arg}}Map, err = Load{{$.Struct.Name}}{{.Name}}Call(GetQueryer, ctx, {{$.Arg.Name}}List)
+	return
+}
+
+//Load{{$.Struct.Name}}{{.Name}}Call will load {{.Ref.Table.Name}} referenced by {{$.Struct.Table.Name}}.{{.Field.Column.Name}} from database
+func Load{{$.Struct.Name}}{{.Name}}Call(caller interface{}, ctx context.Context, {{$.Arg.Name}}List []*{{$.Struct.Name}}) ({{.Arg}}Map map[{{PrimaryField .Ref "Type"}}]*{{.Ref.Name}}, err error) {
+	{{.Arg}}IDs := []{{PrimaryField .Ref "Type"}}{}
+	{{.Arg}}Having := map[{{PrimaryField .Ref "Type"}}]bool{}
+	for _, {{$.Arg.Name}} := range {{$.Arg.Name}}List {
+		if {{$.Arg.Name}} == nil{{if .Pointer}} || {{$.Arg.Name}}.{{.Field.Name}} == nil{{end}} {
+			continue
+		}
+		{{.Arg}}ID := {{PrimaryField .Ref "Type"}}({{if .Pointer}}*{{end}}{{$.Arg.Name}}.{{.Field.Name}})
+		if {{.Arg}}Having[{{.Arg}}ID] {
+			continue
+		}
+		{{.Arg}}Having[{{.Arg}}ID] = true
+		{{.Arg}}IDs = append({{.Arg}}IDs, {{.Arg}}ID)
+	}
+	_, {{.Arg}}Map, err = List{{.Ref.Name}}ByIDCall(caller, ctx, {{.Arg}}IDs...)
+	return
+}
+{{end}}
+{{end}}
 
 `
 
 var StructTestTmpl = `
+{{block "Mock" .}}
+//Mock{{.Struct.Name}} will return mocked {{.Struct.Name}} with required fields filled, the overrides will be applied in order
+func Mock{{.Struct.Name}}(overrides ...func({{.Arg.Name}} *{{.Struct.Name}})) ({{.Arg.Name}} *{{.Struct.Name}}) {
+	{{.Arg.Name}} = &{{.Struct.Name}}{}
+	{{- range $field := .Struct.Fields}}
+	{{- with FieldMock $.Struct $field}}
+	{{$.Arg.Name}}.{{$field.Name}} = {{.}}
+	{{- end}}
+	{{- end}}
+	for _, override := range overrides {
+		override({{.Arg.Name}})
+	}
+	return
+}
+{{- if not .View}}
+
+//MustAdd{{.Struct.Name}} will add mocked {{.Struct.Name}} to database and fail test when error
+func MustAdd{{.Struct.Name}}(t testing.TB, overrides ...func({{.Arg.Name}} *{{.Struct.Name}})) ({{.Arg.Name}} *{{.Struct.Name}}) {
+	{{.Arg.Name}} = Mock{{.Struct.Name}}(overrides...)
+	{{- if .Add.Normal}}
+	err := Add{{.Struct.Name}}(context.Background(), {{.Arg.Name}})
+	{{- else}}
+	err := {{.Arg.Name}}.Insert(GetQueryer, context.Background())
+	{{- end}}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+{{- end}}
+{{end}}
+{{- if .TestAgainstMock}}
+{{block "Fixture" .}}
+//Fixture{{.Struct.Name}} will return the fixture {{.Struct.Name}} which is returned by mocker.FixtureShared on query {{.TableName}}
+func Fixture{{.Struct.Name}}(ctx context.Context) ({{.Arg.Name}} *{{.Struct.Name}}) {
+	{{.Arg.Name}} = Mock{{.Struct.Name}}()
+	{{.Test.Defaults}}
+	{{.Test.Fixture}}
+	return
+}
+
+func init() {
+	mocker.FixtureShared.Fixture("{{.TableName}}", func(ctx context.Context) []interface{} {
+		return []interface{}{Fixture{{.Struct.Name}}(ctx)}
+	})
+}
+{{end}}
+{{- end}}
+
 func TestAuto{{.Struct.Name}}(t *testing.T) {
 	var err error
+	for _, filter := range []string{ {{.Struct.Name}}FilterOptional, {{.Struct.Name}}FilterRequired, {{.Struct.Name}}FilterInsert, {{.Struct.Name}}FilterUpdate, {{.Struct.Name}}FilterFind, {{.Struct.Name}}FilterScan} {
+		if err = crud.CheckFilter(&{{.Struct.Name}}{}, filter); err != nil {
+			t.Error(err)
+			return
+		}
+	}
 	{{- range $i,$field := .Struct.Fields }}
 	{{- if $field.Options}}
 	for _, value := range {{$.Struct.Name}}{{$field.Name}}All {
@@ -439,6 +743,22 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("not array")
 		return
 	}
+	{{- if $.EnumHelpers}}
+	{{- range $field.Options}}
+	if {{.Name}}.String() != "{{.Name}}" || {{.Name}}.Label() != {{if .Comment}}{{printf "%q" .Comment}}{{else}}"{{.Name}}"{{end}} {
+		t.Error("enum helper error")
+		return
+	}
+	if data, err := {{.Name}}.MarshalJSON(); err != nil || string(data) != {{printf "%q" .Value}} {
+		t.Error("enum helper error")
+		return
+	}
+	{{- end }}
+	if len({{$.Struct.Name}}{{$field.Name}}({{FieldInvalid $.Struct $field}}).String()) < 1 {
+		t.Error("enum helper error")
+		return
+	}
+	{{- end }}
 	{{- end }}
 	{{- end }}
 	metav := MetaWith{{.Struct.Name}}()
@@ -446,7 +766,7 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("not meta")
 		return
 	}
-	{{.Arg.Name}} := &{{.Struct.Name}}{}
+	{{.Arg.Name}} := Mock{{.Struct.Name}}()
 	{{- if .GenValid}}
 	{{.Arg.Name}}.Valid()
 	{{- end}}
@@ -461,6 +781,25 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("not table")
 		return
 	}
+	{{- if .View}}
+	{{.Arg.Name}}List, {{.Arg.Name}}Map, err := List{{.Struct.Name}}ByID(context.Background())
+	if err != nil || len({{.Arg.Name}}List) > 0 || {{.Arg.Name}}Map == nil || len({{.Arg.Name}}Map) > 0 {
+		t.Error(err)
+		return
+	}
+	{{.Arg.Name}}List, total, err := List{{.Struct.Name}}PageWheref(context.Background(), "", nil, "", 0, 10)
+	if err != nil || int64(len({{.Arg.Name}}List)) > total {
+		t.Errorf("list page error:%v,%v,%v", err, len({{.Arg.Name}}List), total)
+		return
+	}
+	if len({{.Arg.Name}}List) > 0 {
+		find{{.Struct.Name}}, err := Find{{.Struct.Name}}(context.Background(), {{.Arg.Name}}List[0].{{PrimaryField .Struct "Name"}})
+		if err != nil || find{{.Struct.Name}}.{{PrimaryField .Struct "Name"}} != {{.Arg.Name}}List[0].{{PrimaryField .Struct "Name"}} {
+			t.Error(err)
+			return
+		}
+	}
+	{{- else}}
 	{{- if .Add.Normal}}
 	err = Add{{.Struct.Name}}(context.Background(), {{.Arg.Name}})
 	{{- else}}
@@ -474,6 +813,10 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("not id")
 		return
 	}
+	if mocked := MustAdd{{.Struct.Name}}(t); reflect.ValueOf(mocked.{{PrimaryField .Struct "Name"}}).IsZero() {
+		t.Error("not id")
+		return
+	}
 	{{- if .GenValid}}
 	{{.Arg.Name}}.Valid()
 	{{- end}}
@@ -501,6 +844,12 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("find id error")
 		return
 	}
+	{{- with .Test.Audit}}
+	if ctx := context.Background(); {{.}} {
+		t.Error("audit error")
+		return
+	}
+	{{- end}}
 	find{{.Struct.Name}}, err = Find{{.Struct.Name}}Wheref(context.Background(), "{{PrimaryField .Struct "Column"}}=$%v", {{.Arg.Name}}.{{PrimaryField .Struct "Name"}})
 	if err != nil {
 		t.Error(err)
@@ -537,6 +886,34 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("find id error")
 		return
 	}
+	{{- if .Wheres}}
+	{{.Arg.Name}}Filter := &{{.Struct.Name}}Filter{}
+	{{- range .Wheres}}
+	{{- if .Field.Options}}
+	{{$.Arg.Name}}Filter.{{.Field.Name}} = {{$.Struct.Name}}{{.Field.Name}}All
+	{{- else if .Field.Column.IsPK}}
+	{{$.Arg.Name}}Filter.{{.Field.Name}} = &{{$.Arg.Name}}.{{.Field.Name}}
+	{{- end}}
+	{{- end}}
+	{{.Arg.Name}}FilterList, _, err := List{{.Struct.Name}}ByFilter(context.Background(), {{.Arg.Name}}Filter)
+	if err != nil || len({{.Arg.Name}}FilterList) < 1 {
+		t.Errorf("list by filter error:%v,%v", err, len({{.Arg.Name}}FilterList))
+		return
+	}
+	{{- end}}
+	{{- range $unique := .Uniques}}
+	{{- if $unique.Guards}}
+	if {{range $i, $name := $unique.Guards}}{{if $i}} && {{end}}{{$.Arg.Name}}.{{$name}} != nil{{end}} {
+	{{- end}}
+		find{{$.Struct.Name}}, err = Find{{$.Struct.Name}}By{{$unique.Name}}(context.Background(){{range $i, $field := $unique.Fields}}, {{if index $unique.Pointers $i}}*{{end}}{{$.Arg.Name}}.{{$field.Name}}{{end}})
+		if err != nil || find{{$.Struct.Name}}.{{PrimaryField $.Struct "Name"}} != {{$.Arg.Name}}.{{PrimaryField $.Struct "Name"}} {
+			t.Errorf("find by {{$unique.Name}} error:%v", err)
+			return
+		}
+	{{- if $unique.Guards}}
+	}
+	{{- end}}
+	{{- end}}
 	{{.Arg.Name}}List, {{.Arg.Name}}Map, err := List{{.Struct.Name}}ByID(context.Background())
 	if err != nil || len({{.Arg.Name}}List) > 0 || {{.Arg.Name}}Map == nil || len({{.Arg.Name}}Map) > 0 {
 		t.Error(err)
@@ -565,7 +942,7 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("list id error")
 		return
 	}
-	{{.Arg.Name}}List, {{.Arg.Name}}Map, err = List{{.Struct.Name}}Wheref(context.Background(), "tid=$%v", {{.Arg.Name}}.{{PrimaryField .Struct "Name"}})
+	{{.Arg.Name}}List, {{.Arg.Name}}Map, err = List{{.Struct.Name}}Wheref(context.Background(), "{{PrimaryField .Struct "Column"}}=$%v", {{.Arg.Name}}.{{PrimaryField .Struct "Name"}})
 	if err != nil {
 		t.Error(err)
 		return
@@ -618,6 +995,158 @@ func TestAuto{{.Struct.Name}}(t *testing.T) {
 		t.Error("list id error")
 		return
 	}
+	{{- range .Relations}}
+	{{.Arg}}Map, err := Load{{$.Struct.Name}}{{.Name}}(context.Background(), []*{{$.Struct.Name}}{{"{"}}{{$.Arg.Name}}, nil{{"}"}})
+	if err != nil || {{.Arg}}Map == nil || len({{.Arg}}Map) > 1 {
+		t.Error(err)
+		return
+	}
+	{{- end}}
+	{{- if not .TestAgainstMock}}
+	for i := 0; i < 2; i++ {
+		page{{.Struct.Name}} := *{{.Arg.Name}}
+		page{{.Struct.Name}}.{{PrimaryField .Struct "Name"}} = {{.Struct.Name}}{}.{{PrimaryField .Struct "Name"}}
+		{{- range .Upsert.Fields}}
+		page{{$.Struct.Name}}.{{.Name}} = {{$.Struct.Name}}{}.{{.Name}}
+		{{- end}}
+		err = page{{.Struct.Name}}.Insert(GetQueryer, context.Background())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	{{.Arg.Name}}List, total, err := List{{.Struct.Name}}PageWheref(context.Background(), "", nil, "", 0, 2)
+	if err != nil || len({{.Arg.Name}}List) != 2 || total < 3 {
+		t.Errorf("list page error:%v,%v,%v", err, len({{.Arg.Name}}List), total)
+		return
+	}
+	{{.Arg.Name}}List, total, err = List{{.Struct.Name}}PageWheref(context.Background(), "", nil, "", 2, int(total))
+	if err != nil || int64(len({{.Arg.Name}}List)) != total-2 {
+		t.Errorf("list page error:%v,%v,%v", err, len({{.Arg.Name}}List), total)
+		return
+	}
+	{{.Arg.Name}}List, total, err = List{{.Struct.Name}}PageWheref(context.Background(), "{{PrimaryField .Struct "Column"}}=$%v", []interface{}{{"{"}}{{.Arg.Name}}.{{PrimaryField .Struct "Name"}}{{"}"}}, "", 0, 10)
+	{{- else}}
+	{{.Arg.Name}}List, total, err := List{{.Struct.Name}}PageWheref(context.Background(), "{{PrimaryField .Struct "Column"}}=$%v", []interface{}{{"{"}}{{.Arg.Name}}.{{PrimaryField .Struct "Name"}}{{"}"}}, "", 0, 10)
+	{{- end}}
+	if err != nil || len({{.Arg.Name}}List) != 1 || total != 1 || {{.Arg.Name}}List[0].{{PrimaryField .Struct "Name"}} != {{.Arg.Name}}.{{PrimaryField .Struct "Name"}} {
+		t.Errorf("list page error:%v,%v,%v", err, len({{.Arg.Name}}List), total)
+		return
+	}
+	{{- with .Test.OrderField}}
+	ascList, total, err := List{{$.Struct.Name}}PageWheref(context.Background(), "", nil, "+{{.Column.Name}}", 0, 0)
+	if err != nil || int64(len(ascList)) != total {
+		t.Errorf("list page error:%v,%v,%v", err, len(ascList), total)
+		return
+	}
+	descList, total, err := List{{$.Struct.Name}}PageWheref(context.Background(), "", nil, "-{{.Column.Name}}", 0, 0)
+	if err != nil || int64(len(descList)) != total {
+		t.Errorf("list page error:%v,%v,%v", err, len(descList), total)
+		return
+	}
+	if fmt.Sprintf("%v", ascList[0].{{.Name}}) != fmt.Sprintf("%v", descList[len(descList)-1].{{.Name}}) || fmt.Sprintf("%v", descList[0].{{.Name}}) != fmt.Sprintf("%v", ascList[len(ascList)-1].{{.Name}}) {
+		t.Error("list page order error")
+		return
+	}
+	{{- end}}
+	{{- if .Upsert.Conflict}}
+	upsert{{.Struct.Name}} := *{{.Arg.Name}}
+	upsert{{.Struct.Name}}.{{PrimaryField .Struct "Name"}} = {{.Struct.Name}}{}.{{PrimaryField .Struct "Name"}}
+	err = Upsert{{.Struct.Name}}(context.Background(), &upsert{{.Struct.Name}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	{{- if .Add.Return}}
+	if upsert{{.Struct.Name}}.{{PrimaryField .Struct "Name"}} != {{.Arg.Name}}.{{PrimaryField .Struct "Name"}} {
+		t.Error("upsert id error")
+		return
+	}
+	{{- end}}
+	_, total, err = List{{.Struct.Name}}PageWheref(context.Background(), "{{range $i, $field := .Upsert.Fields}}{{if $i}},{{end}}{{$field.Column.Name}}=$%v{{end}}", []interface{}{{"{"}}{{range $i, $field := .Upsert.Fields}}{{if $i}}, {{end}}upsert{{$.Struct.Name}}.{{$field.Name}}{{end}}{{"}"}}, "", 0, 0)
+	if err != nil || total != 1 {
+		t.Errorf("upsert total error:%v,%v", err, total)
+		return
+	}
+	{{- end}}
+	{{- end}}
+}
+
+`
+
+var JSONStubTmpl = `
+//%[1]v is the json type, it can be edited and will not be regenerated
+type %[1]v struct {
 }
+`
 
+var AutoModelTmpl = `
+//AutoModel is the generated model info, it is used to iterate all models at runtime without reflection over package
+type AutoModel struct {
+	Table        string
+	New          func() interface{}
+	FilterAll    string
+	FilterInsert string
+	FilterUpdate string
+	Orderby      string
+}
+
+//AutoModels is all generated models in table name order, the view is not having insert/update filter
+var AutoModels = []*AutoModel{
+	{{- range .}}
+	{
+		Table:        "{{.TableName}}",
+		New:          func() interface{} { return &{{.Struct.Name}}{} },
+		FilterAll:    "{{.Filter.Find}}",
+		{{- if not .View}}
+		FilterInsert: "{{.Filter.Insert}}",
+		FilterUpdate: "{{.Filter.Update}}",
+		{{- end}}
+		Orderby:      "{{.Filter.Order}}",
+	},
+	{{- end}}
+}
+
+//AutoModelMap is all generated models by table name
+var AutoModelMap = map[string]*AutoModel{}
+
+func init() {
+	for _, model := range AutoModels {
+		AutoModelMap[model.Table] = model
+	}
+}
+`
+
+var JSONFuncTmpl = `
+//Value will marshal %[1]v to json value
+func (v %[1]v) Value() (driver.Value, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+//Scan will unmarshal %[1]v from json value
+func (v *%[1]v) Scan(src interface{}) (err error) {
+	switch data := src.(type) {
+	case nil:
+		*v = %[1]v{}
+	case string:
+		err = json.Unmarshal([]byte(data), v)
+	case []byte:
+		err = json.Unmarshal(data, v)
+	default:
+		err = fmt.Errorf("the %%v,%%v is not string", reflect.TypeOf(src), src)
+	}
+	return
+}
+`
+
+var TSTmpl = `
+/**
+ * {{.Struct.Name}} {{.Struct.Comment}} represents {{.Struct.Table.Name}}
+ */
+export interface {{.Struct.Name}} {
+{{- range .Struct.Fields}}
+  {{FieldTSName $.Struct .}}: {{FieldTSType $.Struct .}};{{if .Comment}} /* {{.Comment}} */{{end}}
+{{- end}}
+}
 `
@@ -0,0 +1,228 @@
+package crud
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codingeasygo/util/converter"
+	"github.com/codingeasygo/util/xsql"
+	"github.com/shopspring/decimal"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/golden")
+
+type goldenCase struct {
+	Name  string
+	Build func() (sql string, args []interface{})
+}
+
+func newGoldenObject() (object *CrudObject) {
+	goldenTime := xsql.Time(time.Date(2023, 6, 1, 8, 30, 0, 0, time.UTC))
+	object = &CrudObject{
+		TID:          1000,
+		UserID:       100,
+		Type:         CrudObjectTypeA,
+		Level:        1,
+		Title:        "title",
+		Image:        converter.StringPtr("image"),
+		Data:         xsql.M{"a": 1},
+		IntValue:     1,
+		IntArray:     xsql.IntArray{1, 2},
+		Int64Value:   2,
+		Int64Array:   xsql.Int64Array{3, 4},
+		Float64Value: decimal.NewFromFloat(1.5),
+		StringValue:  "value",
+		StringArray:  xsql.StringArray{"a", "b"},
+		TimeValue:    goldenTime,
+		UpdateTime:   goldenTime,
+		CreateTime:   goldenTime,
+		Status:       CrudObjectStatusNormal,
+	}
+	return
+}
+
+// mustSQL will return sql and args of builder, it will panic when builder is fail
+func mustSQL(sql string, args []interface{}, err error) (string, []interface{}) {
+	if err != nil {
+		panic(err)
+	}
+	return sql, args
+}
+
+func goldenCases() map[string][]goldenCase {
+	return map[string][]goldenCase{
+		"insert": {
+			{"all", func() (string, []interface{}) { return mustSQL(InsertSQL(newGoldenObject(), "")) }},
+			{"skip_tid_all", func() (string, []interface{}) {
+				return mustSQL(InsertSQL(newGoldenObject(), "^tid#all", "returning", "tid"))
+			}},
+			{"fields", func() (string, []interface{}) {
+				return mustSQL(InsertSQL(newGoldenObject(), "user_id,type,title,status"))
+			}},
+			{"multi_filter", func() (string, []interface{}) {
+				return mustSQL(InsertSQL(newGoldenObject(), "title,status|status,type|user_id"))
+			}},
+			{"meta", func() (string, []interface{}) {
+				return mustSQL(InsertSQL([]interface{}{TableName("crud_object"), int64(100), "title", CrudObjectStatusNormal}, "user_id,title,status"))
+			}},
+		},
+		"update": {
+			{"all", func() (string, []interface{}) { return mustSQL(UpdateSQL(newGoldenObject(), "", nil)) }},
+			{"skip_tid_all", func() (string, []interface{}) { return mustSQL(UpdateSQL(newGoldenObject(), "^tid#all", nil)) }},
+			{"fields_where", func() (string, []interface{}) {
+				sql, args := mustSQL(UpdateSQL(newGoldenObject(), "title,update_time,status", nil))
+				return JoinWheref(sql, args, "tid=$%v,user_id=$%v", int64(1000), int64(100))
+			}},
+			{"zero_skipped", func() (string, []interface{}) {
+				object := newGoldenObject()
+				object.Level = 0
+				object.Image = nil
+				return mustSQL(UpdateSQL(object, "level,image,title", nil))
+			}},
+			{"zero_all", func() (string, []interface{}) {
+				object := newGoldenObject()
+				object.Level = 0
+				object.Image = nil
+				return mustSQL(UpdateSQL(object, "level,image,title#all", nil))
+			}},
+		},
+		"query": {
+			{"all", func() (string, []interface{}) { return QuerySQL(&CrudObject{}, "#all"), nil }},
+			{"fields", func() (string, []interface{}) { return QuerySQL(&CrudObject{}, "tid,user_id,title#all"), nil }},
+			{"alias_func", func() (string, []interface{}) {
+				return QuerySQL(&CrudObject{}, "o.tid,count(user_id),max(level)#all"), nil
+			}},
+			{"where_page", func() (string, []interface{}) {
+				sql := QuerySQL(&CrudObject{}, "tid,title#all")
+				sql, args := JoinWheref(sql, nil, "user_id=$%v,type=$%v,status=any($%v)", int64(100), CrudObjectTypeA, CrudObjectStatusShow)
+				return JoinPage(sql, "order by tid desc", 10, 20), args
+			}},
+			{"where_skip_zero", func() (string, []interface{}) {
+				sql := QuerySQL(&CrudObject{}, "tid#all")
+				return JoinWheref(sql, nil, "user_id=$%v,title like $%v,level=$%v#keepzero", int64(0), "%abc%", 0)
+			}},
+		},
+		"count": {
+			{"all", func() (string, []interface{}) { return CountSQL(&CrudObject{}, "count(tid)#all"), nil }},
+			{"multi", func() (string, []interface{}) { return CountSQL(&CrudObject{}, "count(tid),max(user_id)#all"), nil }},
+			{"where", func() (string, []interface{}) {
+				sql := CountSQL(&CrudObject{}, "count(tid)#all")
+				return JoinWheref(sql, nil, "user_id=$%v,status=any($%v)", int64(100), CrudObjectStatusShow)
+			}},
+		},
+		"stmt": {
+			{"insert", func() (string, []interface{}) {
+				stmt, _ := BuildInsert(newGoldenObject(), "^tid#all", "returning", "tid")
+				return stmt.SQL, stmt.Args
+			}},
+			{"update", func() (string, []interface{}) {
+				stmt, _ := BuildUpdateWheref(newGoldenObject(), "title,status", "tid=$%v", int64(1000))
+				return stmt.SQL, stmt.Args
+			}},
+			{"delete", func() (string, []interface{}) {
+				stmt := BuildDeleteWheref(&CrudObject{}, "tid=$%v,user_id=$%v", int64(1000), int64(100))
+				return stmt.SQL, stmt.Args
+			}},
+		},
+		"where": {
+			{"filter_where", func() (string, []interface{}) {
+				where, args := FilterWhere(nil, newGoldenObject(), "user_id,type,title,status")
+				return fmt.Sprintf("%v", where), args
+			}},
+			{"filter_where_cmp", func() (string, []interface{}) {
+				where, args := FilterWhere([]interface{}{int64(1)}, newGoldenObject(), "user_id,level#all")
+				return fmt.Sprintf("%v", where), args
+			}},
+		},
+		"unify": {
+			{"search_query", func() (string, []interface{}) {
+				search := &SearchCrudObjectUnify{}
+				search.Where.UserID = 100
+				search.Where.Type = CrudObjectTypeA
+				search.Where.Key.Title = "%abc%"
+				search.Where.Key.Data = "%abc%"
+				search.Where.Status = CrudObjectStatusShow
+				search.Page.Offset = 10
+				search.Page.Limit = 20
+				return mustSQL(QueryUnifySQL(search, "Query"))
+			}},
+			{"search_count", func() (string, []interface{}) {
+				search := &SearchCrudObjectUnify{}
+				search.Where.UserID = 100
+				search.Where.Status = CrudObjectStatusShow
+				return mustSQL(CountUnifySQL(search))
+			}},
+			{"search_empty", func() (string, []interface{}) {
+				return mustSQL(QueryUnifySQL(&SearchCrudObjectUnify{}, "Query"))
+			}},
+			{"find_query_row", func() (string, []interface{}) {
+				find := &FindCrudObjectUnify{}
+				find.Where.UserID = 100
+				find.Where.Key = "%abc%"
+				find.Where.Status = CrudObjectStatusShow
+				return mustSQL(QueryUnifySQL(find, "QueryRow"))
+			}},
+			{"find_apply_query_row", func() (string, []interface{}) {
+				find := &FindCrudObjectUnify{}
+				find.Where.UserID = 100
+				return mustSQL(QueryUnifySQL(find, "ApplyQueryRow"))
+			}},
+			{"join_where", func() (string, []interface{}) {
+				find := &FindCrudObjectUnify{}
+				find.Where.UserID = 100
+				find.Where.Key = "%abc%"
+				find.Where.Status = CrudObjectStatusShow
+				return JoinWhereUnify("select tid from crud_object", []interface{}{int64(1)}, find, "Where")
+			}},
+		},
+	}
+}
+
+func renderGolden(cases []goldenCase) []byte {
+	buffer := bytes.NewBuffer(nil)
+	for _, c := range cases {
+		sql, args := c.Build()
+		argsData, err := json.Marshal(args)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(buffer, "-- %v\n%v\n-- args: %v\n\n", c.Name, sql, string(argsData))
+	}
+	return buffer.Bytes()
+}
+
+func TestGoldenSQL(t *testing.T) {
+	for name, cases := range goldenCases() {
+		rendered := renderGolden(cases)
+		for i := 0; i < 20; i++ {
+			if again := renderGolden(cases); !bytes.Equal(rendered, again) {
+				t.Errorf("%v is not deterministic\n%s\n---\n%s", name, rendered, again)
+				break
+			}
+		}
+		goldenFile := filepath.Join("testdata", "golden", name+".sql")
+		if *updateGolden {
+			if err := os.MkdirAll(filepath.Dir(goldenFile), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(goldenFile, rendered, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		expected, err := os.ReadFile(goldenFile)
+		if err != nil {
+			t.Errorf("read golden %v fail with %v, run go test -run TestGoldenSQL -update to create", goldenFile, err)
+			continue
+		}
+		if !bytes.Equal(expected, rendered) {
+			t.Errorf("%v is not matched with golden %v, run go test -run TestGoldenSQL -update if change is expected\nexpected:\n%s\nrendered:\n%s", name, goldenFile, expected, rendered)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package mocker
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/codingeasygo/util/converter"
+)
+
+// Assign will assign the mock value to dest by converting, the value is passed as json to sql.Scanner dest when it is not driver value
+func Assign(dest, value interface{}) (err error) {
+	if scanner, ok := dest.(sql.Scanner); ok {
+		switch value.(type) {
+		case nil, string, []byte, int64, float64, bool, time.Time:
+		default:
+			value = converter.JSON(value)
+		}
+		err = scanner.Scan(value)
+		return
+	}
+	target := reflect.Indirect(reflect.ValueOf(dest))
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return
+	}
+	source := reflect.ValueOf(value)
+	if target.Kind() == reflect.Ptr && source.Type() != target.Type() {
+		ptr := reflect.New(target.Type().Elem())
+		if err = Assign(ptr.Interface(), value); err == nil {
+			target.Set(ptr)
+		}
+		return
+	}
+	if !source.Type().ConvertibleTo(target.Type()) || (target.Kind() == reflect.String && source.Kind() != reflect.String) {
+		err = fmt.Errorf("%v is not convertible to %v", source.Type(), target.Type())
+		return
+	}
+	target.Set(source.Convert(target.Type()))
+	return
+}
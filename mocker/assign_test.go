@@ -0,0 +1,37 @@
+package mocker
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	var i int
+	var s string
+	var p *string
+	var n sql.NullString
+	if err := Assign(&i, int64(1)); err != nil || i != 1 {
+		t.Error(err)
+		return
+	}
+	if err := Assign(&s, "a"); err != nil || s != "a" {
+		t.Error(err)
+		return
+	}
+	if err := Assign(&p, "b"); err != nil || *p != "b" {
+		t.Error(err)
+		return
+	}
+	if err := Assign(&p, nil); err != nil || p != nil {
+		t.Error(err)
+		return
+	}
+	if err := Assign(&n, "c"); err != nil || n.String != "c" {
+		t.Error(err)
+		return
+	}
+	if err := Assign(&s, 1); err == nil {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,48 @@
+package mocker_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/codingeasygo/crud/mocker"
+	"github.com/codingeasygo/crud/pgx"
+	"github.com/codingeasygo/crud/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestDriverShared(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer db.Close()
+	sqlxQueryer := sqlx.NewDbQueryer(db)
+	pgxQueryer := pgx.NewPgQueryer(nil)
+	mocker.Start()
+	defer mocker.Stop()
+	mocker.Set("Pool.Exec", -1)
+	if _, _, err := sqlxQueryer.Exec(context.Background(), "select 1"); err != mocker.ErrMock || err != sqlx.ErrMock {
+		t.Error(err)
+		return
+	}
+	if _, _, err := pgxQueryer.Exec(context.Background(), "select 1"); err != mocker.ErrMock || err != pgx.ErrMock {
+		t.Error(err)
+		return
+	}
+	if mocker.Runned("Pool.Exec") != 2 {
+		t.Error("not shared")
+		return
+	}
+	pgx.MockerClear()
+	if _, _, err := sqlxQueryer.Exec(context.Background(), "select 1"); err != nil {
+		t.Error(err)
+		return
+	}
+	pgx.MockerSet("Pool.Exec", 2)
+	if _, _, err := sqlxQueryer.Exec(context.Background(), "select 1"); err != sqlx.ErrMock {
+		t.Error(err)
+		return
+	}
+}
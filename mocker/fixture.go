@@ -0,0 +1,695 @@
+package mocker
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/codingeasygo/crud"
+)
+
+// FixtureQueryer is the crud.Queryer without database, it is used to run the generated test without database.
+// The Query/QueryRow will return fixture rows of table by select fields(or returning fields), the where is applied when it is
+// the and joined conditions like a=$1,a=any($1),a in (1,2),a like $1,a is null, other condition is always matched.
+// The count(x) is resolved as the matched rows count, other function is resolved as nil, the limit/offset is applied and order is not.
+// The Exec will affect the matched fixture rows on update/delete, and one row on other. The Pool.Exec/Pool.Query/Rows.Scan is checked like driver queryer
+type FixtureQueryer struct {
+	ErrNoRows error
+	fixtures  map[string]func(ctx context.Context) []interface{}
+	insertID  int64
+	lck       sync.RWMutex
+}
+
+// NewFixtureQueryer will return new FixtureQueryer without fixture
+func NewFixtureQueryer() (queryer *FixtureQueryer) {
+	queryer = &FixtureQueryer{ErrNoRows: crud.ErrNoRows, fixtures: map[string]func(ctx context.Context) []interface{}{}}
+	return
+}
+
+// FixtureShared is the shared FixtureQueryer, it is used by the generated test when gen.AutoGen.TestAgainstMock is enabled
+var FixtureShared = NewFixtureQueryer()
+
+// Fixture will set the fixture rows of table, the rows is called on each query and the row is struct which is supported by crud
+func (m *FixtureQueryer) Fixture(table string, rows func(ctx context.Context) []interface{}) {
+	m.lck.Lock()
+	m.fixtures[table] = rows
+	m.lck.Unlock()
+}
+
+func (m *FixtureQueryer) getErrNoRows() (err error) {
+	if m.ErrNoRows == nil {
+		err = crud.ErrNoRows
+	} else {
+		err = m.ErrNoRows
+	}
+	return
+}
+
+func (m *FixtureQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	if err = CheckArgs("Pool.Exec", query, args); err != nil {
+		return
+	}
+	stmt := parseFixtureStmt(query)
+	if stmt.Action == "update" || stmt.Action == "delete" {
+		if values, ok := m.values(ctx, stmt.Table); ok {
+			affected = int64(len(stmt.filter(values, args)))
+			return
+		}
+	}
+	m.lck.Lock()
+	m.insertID++
+	insertId, affected = m.insertID, 1
+	m.lck.Unlock()
+	return
+}
+
+func (m *FixtureQueryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
+	insertId, _, err = m.Exec(ctx, query, args...)
+	return
+}
+
+func (m *FixtureQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows crud.Rows, err error) {
+	if err = CheckArgs("Pool.Query", query, args); err != nil {
+		return
+	}
+	rows = m.queryRows(ctx, query, args)
+	return
+}
+
+func (m *FixtureQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row crud.Row) {
+	row = &fixtureRow{rows: m.queryRows(ctx, query, args), errNoRows: m.getErrNoRows()}
+	return
+}
+
+// values will return the fixture rows of table by column name, the schema of table is tried to trim when table is not found
+func (m *FixtureQueryer) values(ctx context.Context, table string) (values []map[string]interface{}, ok bool) {
+	m.lck.RLock()
+	fixture := m.fixtures[table]
+	if fixture == nil {
+		if index := strings.LastIndex(table, "."); index >= 0 {
+			fixture = m.fixtures[table[index+1:]]
+		}
+	}
+	m.lck.RUnlock()
+	if fixture == nil {
+		return
+	}
+	ok = true
+	for _, v := range fixture(ctx) {
+		value := map[string]interface{}{}
+		crud.FilterFieldCall("fixture", v, "#all", func(fieldName, fieldFunc string, field reflect.StructField, fieldValue interface{}) {
+			value[fixtureColumnName(fieldName)] = fixtureDriverValue(reflect.ValueOf(fieldValue).Elem().Interface())
+		})
+		values = append(values, value)
+	}
+	return
+}
+
+func (m *FixtureQueryer) queryRows(ctx context.Context, query string, args []interface{}) (rows *fixtureRows) {
+	rows = &fixtureRows{sql: query, args: args}
+	stmt := parseFixtureStmt(query)
+	if len(stmt.Table) < 1 || len(stmt.Fields) < 1 {
+		return
+	}
+	values, _ := m.values(ctx, stmt.Table)
+	if stmt.Action == "insert" {
+		//insert returning is resolved by the first fixture row
+		if len(values) > 1 {
+			values = values[:1]
+		}
+	} else {
+		values = stmt.filter(values, args)
+	}
+	columns := splitFixtureFields(stmt.Fields)
+	rows.columns = columns
+	if len(columns) > 0 && strings.HasPrefix(strings.ToLower(columns[0]), "count(") {
+		//count query is always having one row
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			if strings.HasPrefix(strings.ToLower(column), "count(") {
+				row[i] = int64(len(values))
+			}
+		}
+		rows.rows = [][]interface{}{row}
+		return
+	}
+	total := int64(len(values))
+	values = stmt.page(values)
+	for _, value := range values {
+		row := make([]interface{}, len(columns))
+		for i, column := range columns {
+			if strings.HasPrefix(strings.ToLower(column), "count(") {
+				row[i] = total
+			} else if !strings.Contains(column, "(") {
+				row[i] = value[fixtureColumnName(column)]
+			}
+		}
+		rows.rows = append(rows.rows, row)
+	}
+	return
+}
+
+// fixtureStmt is the parsed statement of sql which is used by FixtureQueryer
+type fixtureStmt struct {
+	Action string // the select/insert/update/delete
+	Table  string // the table name without quote
+	Fields string // the select fields or returning fields
+	Where  string // the where condition
+	Limit  string // the limit count
+	Offset string // the offset count
+	Placed int    // the number of ? placeholder before where, it is used to resolve the ? arg index
+}
+
+var fixtureKeywords = map[string]bool{
+	"select": true, "insert": true, "update": true, "delete": true, "into": true, "from": true, "set": true, "values": true,
+	"where": true, "group": true, "having": true, "order": true, "limit": true, "offset": true, "on": true, "returning": true,
+}
+
+// parseFixtureStmt will split query by the keywords which is not in parentheses or quote, only the first clause of each keyword is used
+func parseFixtureStmt(query string) (stmt fixtureStmt) {
+	clauses := map[string]string{}
+	clauseAt := map[string]int{}
+	key, start := "", 0
+	for _, word := range fixtureWords(query) {
+		if !fixtureKeywords[word.Text] {
+			continue
+		}
+		if _, having := clauses[key]; len(key) > 0 && !having {
+			clauses[key] = strings.TrimSpace(query[start:word.Start])
+		}
+		key, start = word.Text, word.End
+		if _, having := clauseAt[key]; !having {
+			clauseAt[key] = word.Start
+		}
+	}
+	if _, having := clauses[key]; len(key) > 0 && !having {
+		clauses[key] = strings.TrimSpace(query[start:])
+	}
+	tableOf := func(clause string) string {
+		end := strings.IndexFunc(clause, func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ',' || r == ';' })
+		if end >= 0 {
+			clause = clause[:end]
+		}
+		return strings.ReplaceAll(strings.ReplaceAll(clause, `"`, ""), "`", "")
+	}
+	action := ""
+	if words := fixtureWords(query); len(words) > 0 {
+		action = words[0].Text
+	}
+	switch action {
+	case "select":
+		stmt.Table, stmt.Fields = tableOf(clauses["from"]), clauses["select"]
+	case "insert":
+		stmt.Table, stmt.Fields = tableOf(clauses["into"]), clauses["returning"]
+	case "update":
+		stmt.Table, stmt.Fields = tableOf(clauses["update"]), clauses["returning"]
+	case "delete":
+		stmt.Table, stmt.Fields = tableOf(clauses["from"]), clauses["returning"]
+	default:
+		return
+	}
+	stmt.Action = action
+	stmt.Where, stmt.Limit, stmt.Offset = clauses["where"], clauses["limit"], clauses["offset"]
+	if at, having := clauseAt["where"]; having {
+		stmt.Placed = strings.Count(query[:at], "?")
+	}
+	return
+}
+
+type fixtureWord struct {
+	Text       string // the lower word
+	Start, End int
+}
+
+// fixtureWords will return the words of query which is not in parentheses or quote
+func fixtureWords(query string) (words []fixtureWord) {
+	depth, quote, wordStart := 0, rune(0), -1
+	for i, c := range query + " " {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		isWord := unicode.IsLetter(c) || unicode.IsDigit(c) || c == '_'
+		if isWord && wordStart < 0 {
+			wordStart = i
+		}
+		if !isWord && wordStart >= 0 {
+			if depth == 0 && (wordStart == 0 || !strings.ContainsRune(`."`+"`", rune(query[wordStart-1]))) {
+				words = append(words, fixtureWord{Text: strings.ToLower(query[wordStart:i]), Start: wordStart, End: i})
+			}
+			wordStart = -1
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return
+}
+
+// splitFixtureTop will split s by sep which is not in parentheses or quote, the sep is matched by word when it is letter
+func splitFixtureTop(s, sep string) (parts []string) {
+	start := 0
+	for _, word := range fixtureWords(s) {
+		if word.Text == sep {
+			parts = append(parts, strings.TrimSpace(s[start:word.Start]))
+			start = word.End
+		}
+	}
+	parts = append(parts, strings.TrimSpace(s[start:]))
+	return
+}
+
+// filter will return the values which is matched by where of stmt
+func (s fixtureStmt) filter(values []map[string]interface{}, args []interface{}) (matched []map[string]interface{}) {
+	if len(s.Where) < 1 || len(splitFixtureTop(s.Where, "or")) > 1 {
+		matched = values
+		return
+	}
+	placed := s.Placed
+	conds := []fixtureCond{}
+	for _, part := range splitFixtureTop(s.Where, "and") {
+		conds = append(conds, parseFixtureCond(part, args, &placed))
+	}
+	for _, value := range values {
+		ok := true
+		for _, cond := range conds {
+			if ok = cond.match(value); !ok {
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, value)
+		}
+	}
+	return
+}
+
+// page will apply limit/offset of stmt to values
+func (s fixtureStmt) page(values []map[string]interface{}) []map[string]interface{} {
+	if offset, err := strconv.Atoi(s.Offset); err == nil && offset > 0 {
+		if offset > len(values) {
+			offset = len(values)
+		}
+		values = values[offset:]
+	}
+	if limit, err := strconv.Atoi(s.Limit); err == nil && limit >= 0 && limit < len(values) {
+		values = values[:limit]
+	}
+	return values
+}
+
+// fixtureCond is one condition of where, the Op is empty when condition is not supported and it is always matched
+type fixtureCond struct {
+	Column string
+	Op     string
+	Values []interface{}
+}
+
+var fixtureOps = []string{">=", "<=", "<>", "!=", "=", ">", "<"}
+
+// splitFixtureCond will split condition to column, op and right operand, the op is empty when condition is not supported
+func splitFixtureCond(cond string) (column, op, right string) {
+	depth, quote := 0, rune(0)
+	for i, c := range cond {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth != 0 || !strings.ContainsRune("<>!=", c) {
+			continue
+		}
+		for _, o := range fixtureOps {
+			if strings.HasPrefix(cond[i:], o) {
+				column, op, right = strings.TrimSpace(cond[:i]), o, strings.TrimSpace(cond[i+len(o):])
+				return
+			}
+		}
+	}
+	words := fixtureWords(cond)
+	for i := 1; i < len(words); i++ {
+		texts := []string{}
+		for _, word := range words[i:] {
+			texts = append(texts, word.Text)
+		}
+		phrase, at, end := strings.Join(texts, " "), words[i].Start, words[i].End
+		if words[i].Text == "not" && i+1 < len(words) {
+			end = words[i+1].End
+		}
+		switch {
+		case phrase == "is null" || phrase == "is not null":
+			op = phrase
+		case words[i].Text == "in" || words[i].Text == "like":
+			op = words[i].Text
+		case strings.HasPrefix(phrase, "not in") || strings.HasPrefix(phrase, "not like"):
+			op = "not " + words[i+1].Text
+		default:
+			continue
+		}
+		column, right = strings.TrimSpace(cond[:at]), strings.TrimSpace(cond[end:])
+		return
+	}
+	return
+}
+
+func parseFixtureCond(cond string, args []interface{}, placed *int) (c fixtureCond) {
+	column, op, right := splitFixtureCond(strings.TrimSpace(cond))
+	if len(op) < 1 || len(column) < 1 || strings.ContainsAny(column, "() ") {
+		return
+	}
+	c.Column, c.Op = fixtureColumnName(column), op
+	switch {
+	case c.Op == "is null" || c.Op == "is not null":
+	case c.Op == "in" || c.Op == "not in":
+		if !strings.HasPrefix(right, "(") || !strings.HasSuffix(right, ")") {
+			c.Op = ""
+			return
+		}
+		for _, item := range splitFixtureFields(right[1 : len(right)-1]) {
+			value, ok := fixtureOperand(item, args, placed)
+			if !ok {
+				c.Op = ""
+				return
+			}
+			c.Values = append(c.Values, value)
+		}
+	case c.Op == "=" && strings.HasPrefix(strings.ToLower(right), "any(") && strings.HasSuffix(right, ")"):
+		value, ok := fixtureOperand(right[4:len(right)-1], args, placed)
+		if !ok {
+			c.Op = ""
+			return
+		}
+		c.Op, c.Values = "in", fixtureArray(value)
+	default:
+		value, ok := fixtureOperand(right, args, placed)
+		if !ok {
+			c.Op = ""
+			return
+		}
+		c.Values = []interface{}{value}
+	}
+	return
+}
+
+// fixtureOperand will resolve operand of condition by placeholder or literal, the ok is false when operand is not supported
+func fixtureOperand(operand string, args []interface{}, placed *int) (value interface{}, ok bool) {
+	operand = strings.TrimSpace(strings.SplitN(operand, "::", 2)[0])
+	index := -1
+	switch {
+	case operand == "?":
+		index = *placed
+		*placed++
+	case strings.HasPrefix(operand, "$"):
+		n, err := strconv.Atoi(operand[1:])
+		if err != nil {
+			return
+		}
+		index = n - 1
+	case strings.HasPrefix(operand, "'") && strings.HasSuffix(operand, "'") && len(operand) > 1:
+		value, ok = strings.ReplaceAll(operand[1:len(operand)-1], "''", "'"), true
+		return
+	case strings.EqualFold(operand, "true") || strings.EqualFold(operand, "false"):
+		value, ok = strings.EqualFold(operand, "true"), true
+		return
+	default:
+		if _, err := strconv.ParseFloat(operand, 64); err == nil {
+			value, ok = operand, true
+		}
+		return
+	}
+	if index < 0 || index >= len(args) {
+		return
+	}
+	value, ok = args[index], true
+	return
+}
+
+// fixtureArray will return the items of array value, the driver value like {1,2} or [1,2] is splited
+func fixtureArray(value interface{}) (items []interface{}) {
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+	if reflectValue.IsValid() && (reflectValue.Kind() == reflect.Slice || reflectValue.Kind() == reflect.Array) && reflectValue.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < reflectValue.Len(); i++ {
+			items = append(items, fixtureDriverValue(reflectValue.Index(i).Interface()))
+		}
+		return
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		value, _ = valuer.Value()
+	}
+	if data, ok := value.([]byte); ok {
+		value = string(data)
+	}
+	text, ok := value.(string)
+	if !ok {
+		items = append(items, value)
+		return
+	}
+	text = strings.Trim(strings.TrimSpace(text), "{}[]")
+	if len(text) < 1 {
+		return
+	}
+	for _, item := range strings.Split(text, ",") {
+		items = append(items, strings.Trim(strings.TrimSpace(item), `"`))
+	}
+	return
+}
+
+func (c fixtureCond) match(value map[string]interface{}) bool {
+	current, having := value[c.Column]
+	if len(c.Op) < 1 || !having {
+		return true
+	}
+	current = fixtureIndirect(current)
+	switch c.Op {
+	case "is null":
+		return current == nil
+	case "is not null":
+		return current != nil
+	case "in", "not in":
+		found := false
+		for _, item := range c.Values {
+			if fixtureCompare(current, item) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (c.Op == "in")
+	case "like", "not like":
+		pattern := regexp.QuoteMeta(fmt.Sprintf("%v", c.Values[0]))
+		pattern = strings.ReplaceAll(strings.ReplaceAll(pattern, "%", ".*"), "_", ".")
+		matched, _ := regexp.MatchString("(?s)^"+pattern+"$", fmt.Sprintf("%v", current))
+		return matched == (c.Op == "like")
+	}
+	if current == nil || fixtureIndirect(c.Values[0]) == nil {
+		return false
+	}
+	compared := fixtureCompare(current, c.Values[0])
+	switch c.Op {
+	case "=":
+		return compared == 0
+	case "<>", "!=":
+		return compared != 0
+	case ">":
+		return compared > 0
+	case ">=":
+		return compared >= 0
+	case "<":
+		return compared < 0
+	default: //<=
+		return compared <= 0
+	}
+}
+
+// fixtureIndirect will return the value of pointer, the nil pointer is returned as nil
+func fixtureIndirect(value interface{}) interface{} {
+	reflectValue := reflect.ValueOf(value)
+	for reflectValue.IsValid() && reflectValue.Kind() == reflect.Ptr {
+		if reflectValue.IsNil() {
+			return nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	if !reflectValue.IsValid() {
+		return nil
+	}
+	return fixtureDriverValue(reflectValue.Interface())
+}
+
+// fixtureCompare will compare a and b by number, time or string
+func fixtureCompare(a, b interface{}) int {
+	a, b = fixtureIndirect(a), fixtureIndirect(b)
+	if at, ok := a.(time.Time); ok {
+		if bt, ok := b.(time.Time); ok {
+			switch {
+			case at.Before(bt):
+				return -1
+			case at.After(bt):
+				return 1
+			}
+			return 0
+		}
+	}
+	as, bs := fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)
+	if af, err := strconv.ParseFloat(as, 64); err == nil {
+		if bf, err := strconv.ParseFloat(bs, 64); err == nil {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(as, bs)
+}
+
+// splitFixtureFields will split fields by comma which is not in parentheses or quote
+func splitFixtureFields(fields string) (columns []string) {
+	depth, quote, start := 0, rune(0), 0
+	for i, c := range fields {
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				columns = append(columns, strings.TrimSpace(fields[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(fields[start:]); len(last) > 0 {
+		columns = append(columns, last)
+	}
+	return
+}
+
+// fixtureColumnName will return the column name of field by removing table alias, type cast and quote
+func fixtureColumnName(field string) (name string) {
+	name = strings.TrimSpace(strings.SplitN(field, "::", 2)[0])
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+	name = strings.Trim(name, `"`+"`")
+	return
+}
+
+// fixtureDriverValue will convert value to driver value when it is driver.Valuer, the pointer value is kept to assign directly
+func fixtureDriverValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Ptr {
+		return value
+	}
+	ptr := reflect.New(reflectValue.Type())
+	ptr.Elem().Set(reflectValue)
+	if valuer, ok := ptr.Interface().(driver.Valuer); ok {
+		if driverValue, err := valuer.Value(); err == nil {
+			return driverValue
+		}
+	}
+	return value
+}
+
+type fixtureRows struct {
+	sql     string
+	args    []interface{}
+	columns []string
+	rows    [][]interface{}
+	index   int
+	closed  bool
+}
+
+func (m *fixtureRows) Next() bool {
+	if m.closed || m.index >= len(m.rows) {
+		m.closed = true
+		return false
+	}
+	m.index++
+	return true
+}
+
+func (m *fixtureRows) Scan(dest ...interface{}) (err error) {
+	if err = CheckArgs("Rows.Scan", m.sql, m.args); err != nil {
+		return
+	}
+	if m.index < 1 || m.index > len(m.rows) {
+		err = fmt.Errorf("fixture rows is not having current row")
+		return
+	}
+	row := m.rows[m.index-1]
+	if len(dest) != len(row) {
+		err = fmt.Errorf("fixture rows dest count %v is not equal to row values %v", len(dest), len(row))
+		return
+	}
+	for i, value := range row {
+		if err = Assign(dest[i], value); err != nil {
+			err = fmt.Errorf("fixture rows scan %v fail with %v", i, err)
+			break
+		}
+	}
+	return
+}
+
+func (m *fixtureRows) Err() error {
+	return CheckArgs("Rows.Err", m.sql, m.args)
+}
+
+func (m *fixtureRows) Columns() ([]string, error) {
+	return m.columns, nil
+}
+
+func (m *fixtureRows) Close() error {
+	m.closed = true
+	return nil
+}
+
+type fixtureRow struct {
+	rows      *fixtureRows
+	errNoRows error
+}
+
+func (m *fixtureRow) Scan(dest ...interface{}) (err error) {
+	defer m.rows.Close()
+	if !m.rows.Next() {
+		err = m.errNoRows
+		return
+	}
+	err = m.rows.Scan(dest...)
+	return
+}
@@ -0,0 +1,148 @@
+package mocker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codingeasygo/crud"
+	"github.com/codingeasygo/util/converter"
+	"github.com/codingeasygo/util/xsql"
+	"github.com/shopspring/decimal"
+)
+
+type fixtureObject struct {
+	T          string          `json:"-" table:"mock_object"`
+	TID        int64           `json:"tid"`
+	Title      string          `json:"title"`
+	Image      *string         `json:"image"`
+	Data       xsql.M          `json:"data"`
+	Price      decimal.Decimal `json:"price"`
+	Status     int             `json:"status"`
+	UpdateTime xsql.Time       `json:"update_time"`
+}
+
+func TestFixtureQueryer(t *testing.T) {
+	ctx := context.Background()
+	queryer := NewFixtureQueryer()
+	image := "image"
+	updateTime := xsql.TimeNow()
+	queryer.Fixture("mock_object", func(ctx context.Context) []interface{} {
+		return []interface{}{
+			&fixtureObject{TID: 100, Title: "a", Image: &image, Data: xsql.M{"a": 1}, Price: decimal.NewFromFloat(1.5), Status: 100, UpdateTime: updateTime},
+			&fixtureObject{TID: 101, Title: "b", Status: -1},
+		}
+	})
+	object := &fixtureObject{Title: "abc"}
+	if _, err := crud.InsertFilter(queryer, ctx, object, "^tid#all", "returning", "tid#all"); err != nil || object.TID != 100 {
+		t.Errorf("%v,%v", err, object.TID)
+		return
+	}
+	var objects []*fixtureObject
+	if err := crud.QueryWheref(queryer, ctx, &fixtureObject{}, "#all", "", nil, "", 0, 0, &objects); err != nil || len(objects) != 2 {
+		t.Errorf("%v,%v", err, len(objects))
+		return
+	}
+	if found := objects[0]; found.TID != 100 || found.Title != "a" || found.Image == nil || *found.Image != image || found.Data.AsMap().Int64("a") != 1 ||
+		!found.Price.Equal(decimal.NewFromFloat(1.5)) || found.UpdateTime.Timestamp() != updateTime.Timestamp() || objects[1].Image != nil {
+		t.Errorf("%v", converter.JSON(objects))
+		return
+	}
+	var tid, maxTID int64
+	var title string
+	if err := queryer.QueryRow(ctx, "select o.tid,o.title::text,max(o.tid) from mock_object o where tid=$1", 100).Scan(&tid, &title, &maxTID); err != nil || tid != 100 || title != "a" || maxTID != 0 {
+		t.Errorf("%v,%v,%v", err, tid, title)
+		return
+	}
+	var total int64
+	if err := crud.CountWheref(queryer, ctx, &fixtureObject{}, "count(tid)#all", "", nil, "", &total, "tid"); err != nil || total != 2 {
+		t.Errorf("%v,%v", err, total)
+		return
+	}
+	if err := queryer.QueryRow(ctx, `select count(tid) from "public"."none_object"`).Scan(&total); err != nil || total != 0 {
+		t.Errorf("%v,%v", err, total)
+		return
+	}
+	if err := queryer.QueryRow(ctx, "select tid from none_object").Scan(&total); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+	if err := queryer.QueryRow(ctx, "vacuum").Scan(&total); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+	if affected, err := crud.UpdateWheref(queryer, ctx, object, "title", "tid=$%v", object.TID); err != nil || affected != 1 {
+		t.Errorf("%v,%v", err, affected)
+		return
+	}
+	if affected, err := crud.UpdateWheref(queryer, ctx, object, "title", "tid=$%v", 1); err != nil || affected != 0 {
+		t.Errorf("%v,%v", err, affected)
+		return
+	}
+	if _, affected, err := queryer.Exec(ctx, "delete from mock_object where status<$1", 0); err != nil || affected != 1 {
+		t.Errorf("%v,%v", err, affected)
+		return
+	}
+	if insertID, err := queryer.ExecRow(ctx, "insert into mock_object(title) values($1)", "a"); err != nil || insertID < 1 {
+		t.Errorf("%v,%v", err, insertID)
+		return
+	}
+	//where
+	for where, args := range map[string][]interface{}{
+		"tid=$1":                                {101},
+		"tid=any($1)":                           {[]int64{101, 102}},
+		"o.tid in (101,102)":                    {},
+		"title like $1":                         {"b%"},
+		"image is null":                         {},
+		"tid<>$1 and status=$2":                 {100, -1},
+		"status<? and title=?":                  {0, "b"},
+		`"title"='b' and price>=0`:              {},
+		"update_time<$1 and tid>$2":             {updateTime.AsTime(), 100},
+		"status=any($1) and title not in ('a')": {xsql.IntArray{-1}},
+	} {
+		objects = nil
+		sql := "select tid from mock_object o where " + where + " order by tid desc limit 10 offset 0"
+		if err := crud.Query(queryer, ctx, &fixtureObject{}, "tid#all", sql, args, &objects); err != nil || len(objects) != 1 || objects[0].TID != 101 {
+			t.Errorf("%v,%v,%v", where, err, converter.JSON(objects))
+			return
+		}
+	}
+	//where not supported is always matched
+	objects = nil
+	if err := crud.Query(queryer, ctx, &fixtureObject{}, "tid#all", "select tid from mock_object where tid=$1 or tid=$2", []interface{}{1, 2}, &objects); err != nil || len(objects) != 2 {
+		t.Errorf("%v,%v", err, len(objects))
+		return
+	}
+	//page
+	objects = nil
+	if err := crud.Query(queryer, ctx, &fixtureObject{}, "tid#all", "select tid from mock_object order by tid limit 1 offset 1", nil, &objects); err != nil || len(objects) != 1 || objects[0].TID != 101 {
+		t.Errorf("%v,%v", err, converter.JSON(objects))
+		return
+	}
+	objects = nil
+	if err := crud.Query(queryer, ctx, &fixtureObject{}, "tid#all", "select tid from mock_object limit 10 offset 3", nil, &objects); err != nil || len(objects) != 0 {
+		t.Errorf("%v,%v", err, converter.JSON(objects))
+		return
+	}
+	//mocker
+	Test(t)
+	Set("Pool.Exec", 1)
+	if _, err := queryer.ExecRow(ctx, "delete from mock_object"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	Set("Pool.Query", 1)
+	if _, err := queryer.Query(ctx, "select tid from mock_object"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	Set("Rows.Scan", 1)
+	if err := queryer.QueryRow(ctx, "select tid from mock_object").Scan(&total); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	queryer.ErrNoRows = nil
+	if err := queryer.QueryRow(ctx, "select tid from none_object").Scan(&total); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+}
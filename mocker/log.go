@@ -0,0 +1,49 @@
+package mocker
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var logPrefix = "github.com/codingeasygo/crud"
+var logTo func(format string, args ...interface{})
+var logLck = sync.RWMutex{}
+
+// LogTo will set the verbose log func like t.Logf, the log is printed to stdout when logf is nil
+func LogTo(logf func(format string, args ...interface{})) {
+	logLck.Lock()
+	logTo = logf
+	logLck.Unlock()
+}
+
+func logf(format string, args ...interface{}) {
+	logLck.RLock()
+	to := logTo
+	logLck.RUnlock()
+	if to != nil {
+		to(format, args...)
+	} else {
+		fmt.Printf(format+"\n", args...)
+	}
+}
+
+// caller will return the file:line of the first frame out of crud/mocker/driver package, the test file in package is not skipped
+func caller() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(2, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		wrapper := strings.HasPrefix(name, logPrefix+".") || strings.HasPrefix(name, logPrefix+"/")
+		if !(wrapper && !strings.HasSuffix(frame.File, "_test.go")) && !strings.HasPrefix(name, "runtime.") {
+			return fmt.Sprintf("%v:%v", frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,27 @@
+package mocker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLogTo(t *testing.T) {
+	Test(t)
+	logs := []string{}
+	LogTo(func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+	defer LogTo(nil)
+	Set("a", 1)
+	CheckVerbose("a", "select 1", true)
+	Check("a", "select 2")
+	if len(logs) != 1 || !strings.Contains(logs[0], "log_test.go:") || !strings.Contains(logs[0], "err:mock error") || !strings.Contains(logs[0], "select 1") {
+		t.Error(logs)
+		return
+	}
+	LogTo(t.Logf)
+	CheckVerbose("a", "select 3", true)
+	LogTo(nil)
+	CheckVerbose("a", "select 4", true)
+}
@@ -0,0 +1,582 @@
+// Package mocker is the shared sql mocker used by pgx/sqlx queryer, it makes queryer return error by trigger times or sql match.
+//
+// The check key is named by <Scope>.<Action>, the Scope is Pool/Tx/Primary/Replica in driver or the prefix of custom queryer,
+// the Action is Exec(Exec/ExecRow), Query(Query/QueryRow), Begin, Commit, Rollback, Ping, and Rows.Scan/Rows.Err is used on rows.
+// Custom queryer can call Check by own key or wrap by WrapQueryer to participate in the mocker.
+package mocker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/codingeasygo/util/converter"
+	"github.com/codingeasygo/util/xhttp"
+	"github.com/codingeasygo/util/xmap"
+)
+
+const mockerPackage = "github.com/codingeasygo/crud/mocker"
+
+var ErrMock = fmt.Errorf("mock error")
+var Verbose = false
+var Client = xhttp.Shared
+
+var mocking = false
+var mockPanic = false
+var mockTrigger = map[string][]int{}
+var mockMatch = map[string]*regexp.Regexp{}
+var mockRunned = map[string]int{}
+var mockError = map[string]error{}
+var mockArgs = map[string]func(sql string, args []interface{}) bool{}
+var mockClear = []func(){}
+var mockTests = []*testing.T{}
+var mockRunnedLck = sync.RWMutex{}
+
+// Check will return ErrMock or the error set by SetError/MatchError when key is triggered by runned times or sql is matched, it is the public check for custom queryer
+func Check(key, sql string) (err error) {
+	err = CheckVerbose(key, sql, false)
+	return
+}
+
+// CheckArgs will check like Check and the args is passed to predicate set by MatchArgs
+func CheckArgs(key, sql string, args []interface{}) (err error) {
+	err = CheckVerbose(key, sql, false, args...)
+	return
+}
+
+// CheckVerbose will check like CheckArgs and print the check result when verbose or Verbose is true
+func CheckVerbose(key, sql string, verbose bool, args ...interface{}) (err error) {
+	record(key, sql)
+	if mocking {
+		mockRunnedLck.Lock()
+		mockRunned[key]++
+		trigger := mockTrigger[key]
+		runned := mockRunned[key]
+		if trigger != nil && (trigger[0] < 0 || (trigger[0] <= runned && (trigger[1] < 0 || runned <= trigger[1]))) {
+			err = ErrMock
+		}
+		match := mockMatch[key]
+		if match != nil && match.MatchString(sql) {
+			err = ErrMock
+		}
+		predicate := mockArgs[key]
+		if predicate != nil && predicate(sql, args) {
+			err = ErrMock
+		}
+		if err != nil && mockError[key] != nil {
+			err = mockError[key]
+		}
+		outcome, sequenced := nextOutcome(key)
+		if sequenced {
+			err = outcome.Err
+		}
+		message := ""
+		if verbose || Verbose {
+			message = fmt.Sprintf("Mocking %v trigger:%v,runned:%v,sequenced:%v,err:%v,caller:%v,sql:\n%v", key, mockTrigger[key], mockRunned[key], sequenced, err, caller(), sql)
+		}
+		mockRunnedLck.Unlock()
+		if len(message) > 0 {
+			logf("%v", message)
+		}
+		if (mockPanic || outcome.Panic) && err != nil {
+			panic(err)
+		}
+	}
+	return
+}
+
+// Running will return if mocker is started
+func Running() bool {
+	return mocking
+}
+
+// Runned will return the check times of key after last clear
+func Runned(key string) int {
+	mockRunnedLck.RLock()
+	defer mockRunnedLck.RUnlock()
+	return mockRunned[key]
+}
+
+func Start() {
+	mocking = true
+}
+
+func Stop() {
+	Clear()
+	mocking = false
+}
+
+// Test will start mocker for t and restore the mocker state when t is done by t.Cleanup.
+// It is not supported in parallel tests, t is failed when other test is using mocker except t's parent
+func Test(t *testing.T) {
+	t.Helper()
+	mockRunnedLck.Lock()
+	if n := len(mockTests); n > 0 && mockTests[n-1] != t && !strings.HasPrefix(t.Name(), mockTests[n-1].Name()+"/") {
+		owner := mockTests[n-1].Name()
+		mockRunnedLck.Unlock()
+		t.Fatalf("mocker is used by %v, mocker.Test is not supported in parallel tests", owner)
+		return
+	}
+	mockTests = append(mockTests, t)
+	started, isPanic := mocking, mockPanic
+	trigger, match, runned, errs := map[string][]int{}, map[string]*regexp.Regexp{}, map[string]int{}, map[string]error{}
+	predicates := map[string]func(sql string, args []interface{}) bool{}
+	sequences := map[string][]Outcome{}
+	for k, v := range mockTrigger {
+		trigger[k] = v
+	}
+	for k, v := range mockMatch {
+		match[k] = v
+	}
+	for k, v := range mockRunned {
+		runned[k] = v
+	}
+	for k, v := range mockError {
+		errs[k] = v
+	}
+	for k, v := range mockArgs {
+		predicates[k] = v
+	}
+	for k, v := range mockSequence {
+		sequences[k] = v
+	}
+	mocking = true
+	mockRunnedLck.Unlock()
+	t.Cleanup(func() {
+		Clear()
+		mockRunnedLck.Lock()
+		defer mockRunnedLck.Unlock()
+		mockTrigger, mockMatch, mockRunned, mockError, mockArgs = trigger, match, runned, errs, predicates
+		mockSequence = sequences
+		mocking, mockPanic = started, isPanic
+		for i := len(mockTests) - 1; i >= 0; i-- {
+			if mockTests[i] == t {
+				mockTests = append(mockTests[:i], mockTests[i+1:]...)
+				break
+			}
+		}
+	})
+}
+
+func Clear() {
+	mockRunnedLck.Lock()
+	mockTrigger = map[string][]int{}
+	mockMatch = map[string]*regexp.Regexp{}
+	mockRunned = map[string]int{}
+	mockError = map[string]error{}
+	mockArgs = map[string]func(sql string, args []interface{}) bool{}
+	mockSequence = map[string][]Outcome{}
+	mockPanic = false
+	clears := mockClear
+	mockRunnedLck.Unlock()
+	clearRecord()
+	for _, clear := range clears {
+		clear()
+	}
+}
+
+// OnClear will register the clear func which is called on every Clear, it is used to clear the driver mock data
+func OnClear(clear func()) {
+	mockRunnedLck.Lock()
+	mockClear = append(mockClear, clear)
+	mockRunnedLck.Unlock()
+}
+
+// SetTriggers will set the key triggered by match or triggers range
+func SetTriggers(key, match string, isPanice bool, triggers ...int) {
+	setTriggers(key, match, isPanice, nil, triggers...)
+}
+
+func setTriggers(key, match string, isPanice bool, err error, triggers ...int) {
+	mockRunnedLck.Lock()
+	defer mockRunnedLck.Unlock()
+	if !mocking {
+		panic(fmt.Sprintf("set %v trigger on mocker is not started, call Start or Test first", key))
+	}
+	if len(match) > 0 {
+		mockMatch[key] = regexp.MustCompile(match)
+	} else {
+		if len(triggers) == 1 {
+			mockTrigger[key] = []int{triggers[0], triggers[0]}
+		} else if len(triggers) > 1 {
+			mockTrigger[key] = triggers
+		} else {
+			panic("trigger is required")
+		}
+	}
+	if err != nil {
+		mockError[key] = err
+	} else {
+		delete(mockError, key)
+	}
+	mockPanic = isPanice
+}
+
+// Set will make key return ErrMock when runned times is in triggers range, the range is [from, to] and to < 0 means from the from-th onward.
+// The single trigger -1 means always, and the runned times is reset by Clear
+func Set(key string, triggers ...int) {
+	SetTriggers(key, "", false, triggers...)
+}
+
+// Panic will make key panic like Set
+func Panic(key string, triggers ...int) {
+	SetTriggers(key, "", true, triggers...)
+}
+
+func MatchSet(key, match string) {
+	SetTriggers(key, match, false)
+}
+
+func MatchPanic(key, match string) {
+	SetTriggers(key, match, true)
+}
+
+// MatchArgs will make key return ErrMock when predicate return true by sql and args
+func MatchArgs(key string, predicate func(sql string, args []interface{}) bool) {
+	mockRunnedLck.Lock()
+	defer mockRunnedLck.Unlock()
+	if !mocking {
+		panic(fmt.Sprintf("set %v trigger on mocker is not started, call Start or Test first", key))
+	}
+	mockArgs[key] = predicate
+	mockPanic = false
+}
+
+// SetError will make key return err instead of ErrMock when runned times is trigger
+func SetError(key string, trigger int, err error) {
+	setTriggers(key, "", false, err, trigger)
+}
+
+// MatchError will make key return err instead of ErrMock when sql is matched
+func MatchError(key, match string, err error) {
+	setTriggers(key, match, false, err)
+}
+
+type Caller struct {
+	Call        func(func(trigger int) (res xmap.M, err error)) xmap.M
+	calld       func(func(trigger int) (res xmap.M, err error)) xmap.M
+	Client      *xhttp.Client
+	Shoulder    xmap.Shoulder
+	tester      *testing.T
+	onlyLog     bool
+	errIs       error
+	errContains string
+}
+
+func NewCaller() (caller *Caller) {
+	caller = &Caller{Client: Client}
+	caller.Call = func(c func(trigger int) (xmap.M, error)) xmap.M { return caller.calld(c) }
+	return
+}
+
+// valid will valid the call result by Shoulder and error assert, the fail is reported on the first frame out of mocker package
+func (m *Caller) valid(res xmap.M, err error) bool {
+	skip := callerSkip()
+	if !m.Shoulder.Valid(skip+2, res, err) {
+		return false
+	}
+	if m.errIs != nil && !errors.Is(err, m.errIs) {
+		m.fail(skip+1, fmt.Errorf("err %v is not %v, res is %v", err, m.errIs, converter.JSON(res)))
+		return false
+	}
+	if len(m.errContains) > 0 && (err == nil || !strings.Contains(err.Error(), m.errContains)) {
+		m.fail(skip+1, fmt.Errorf("err %v is not contains %v, res is %v", err, m.errContains, converter.JSON(res)))
+		return false
+	}
+	return true
+}
+
+func (m *Caller) fail(depth int, err error) {
+	if m.tester == nil {
+		panic(err)
+	}
+	if m.Shoulder.Log == nil {
+		m.Shoulder.Log = log.New(os.Stderr, "    ", log.Llongfile)
+	}
+	m.Shoulder.Log.Output(depth+1, err.Error())
+	if !m.onlyLog {
+		m.tester.Fail()
+		m.tester.SkipNow()
+	}
+}
+
+// callerSkip will return the number of frames between valid and the first frame out of mocker package
+func callerSkip() int {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	skip := 0
+	for {
+		frame, more := frames.Next()
+		skip++
+		if !strings.HasPrefix(frame.Function, mockerPackage+".") || strings.HasSuffix(frame.File, "_test.go") || !more {
+			break
+		}
+	}
+	return skip
+}
+
+func (m *Caller) Should(t *testing.T, args ...interface{}) *Caller {
+	m.Shoulder.Should(t, args...)
+	return m
+}
+
+func (m *Caller) ShouldError(t *testing.T) *Caller {
+	m.Shoulder.ShouldError(t)
+	return m
+}
+
+// ShouldErrorIs will make the call should fail with error which is target by errors.Is
+func (m *Caller) ShouldErrorIs(t *testing.T, target error) *Caller {
+	m.Shoulder.ShouldError(t)
+	m.tester, m.errIs = t, target
+	return m
+}
+
+// ShouldErrorContains will make the call should fail with error message contains substr
+func (m *Caller) ShouldErrorContains(t *testing.T, substr string) *Caller {
+	m.Shoulder.ShouldError(t)
+	m.tester, m.errContains = t, substr
+	return m
+}
+
+func (m *Caller) OnlyLog(only bool) *Caller {
+	m.Shoulder.OnlyLog(only)
+	m.onlyLog = only
+	return m
+}
+
+// GetMap will get map from remote
+func (m *Caller) GetMap(format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.GetMap(format, args...)
+		return data, err
+	})
+	return
+}
+
+// GetHeaderMap will get map from remote
+func (m *Caller) GetHeaderMap(header xmap.M, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, res, err = m.Client.GetHeaderMap(header, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostMap will get map from remote
+func (m *Caller) PostMap(body io.Reader, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.PostMap(body, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostTypeMap will get map from remote
+func (m *Caller) PostTypeMap(contentType string, body io.Reader, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.PostTypeMap(contentType, body, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostHeaderMap will get map from remote
+func (m *Caller) PostHeaderMap(header xmap.M, body io.Reader, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, res, err = m.Client.PostHeaderMap(header, body, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostJSONMap will get map from remote
+func (m *Caller) PostJSONMap(body interface{}, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.PostJSONMap(body, format, args...)
+		return data, err
+	})
+	return
+}
+
+// MethodBytes will do http request, read reponse and parse to map
+func (m *Caller) MethodMap(method string, header xmap.M, body io.Reader, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, res, err = m.Client.MethodMap(method, header, body, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostFormMap will get map from remote
+func (m *Caller) PostFormMap(form xmap.M, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.PostFormMap(form, format, args...)
+		return data, err
+	})
+	return
+}
+
+// PostMultipartMap will get map from remote
+func (m *Caller) PostMultipartMap(header, fields xmap.M, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.PostMultipartMap(header, fields, format, args...)
+		return data, err
+	})
+	return
+}
+
+// UploadMap will get map from remote
+func (m *Caller) UploadMap(fields xmap.M, filekey, filename, format string, args ...interface{}) (data xmap.M, err error) {
+	m.calld(func(trigger int) (xmap.M, error) {
+		data, err = m.Client.UploadMap(fields, filekey, filename, format, args...)
+		return data, err
+	})
+	return
+}
+
+func Should(t *testing.T, args ...interface{}) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		res, err := call(0)
+		caller.valid(res, err)
+		return res
+	}
+	return caller.Should(t, args...)
+}
+
+func ShouldError(t *testing.T) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		res, err := call(0)
+		caller.valid(res, err)
+		return res
+	}
+	return caller.ShouldError(t)
+}
+
+// rangeArgs will call by key and triggers in args, the args is key and int trigger or []int triggers range like "Pool.Exec", 1, []int{3, -1}
+func rangeArgs(args []interface{}, call func(key string, triggers []int)) {
+	triggerAll := map[string][][]int{}
+	triggerKeys := []string{}
+	triggerAdd := false
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			if triggerAdd {
+				triggerKeys = []string{}
+			}
+			triggerAdd = false
+			triggerKeys = append(triggerKeys, arg)
+		case int:
+			triggerAdd = true
+			for _, key := range triggerKeys {
+				triggerAll[key] = append(triggerAll[key], []int{arg})
+			}
+		case []int:
+			triggerAdd = true
+			for _, key := range triggerKeys {
+				triggerAll[key] = append(triggerAll[key], arg)
+			}
+		default:
+			panic(fmt.Sprintf("args[%v] is %v and not supported", i, reflect.TypeOf(arg)))
+		}
+	}
+	for key, triggers := range triggerAll {
+		for _, trigger := range triggers {
+			call(key, trigger)
+		}
+	}
+}
+
+func SetCall(args ...interface{}) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		rangeArgs(args, func(key string, triggers []int) {
+			Set(key, triggers...)
+			res, err := call(triggers[0])
+			Clear()
+			caller.valid(res, err)
+		})
+		return nil
+	}
+	return
+}
+
+func PanicCall(args ...interface{}) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		rangeArgs(args, func(key string, triggers []int) {
+			Panic(key, triggers...)
+			res, err := call(triggers[0])
+			Clear()
+			caller.valid(res, err)
+		})
+		return nil
+	}
+	return
+}
+
+func MatchSetCall(key, match string) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		MatchSet(key, match)
+		res, err := call(0)
+		Clear()
+		caller.valid(res, err)
+		return res
+	}
+	return
+}
+
+func MatchPanicCall(key, match string) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		MatchPanic(key, match)
+		res, err := call(0)
+		Clear()
+		caller.valid(res, err)
+		return res
+	}
+	return
+}
+
+// SetRangeCall will call with key triggered by i in [start, end), the optional to is the end of trigger range like Set, -1 means from i-th onward
+func SetRangeCall(key string, start, end int, to ...int) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		for i := start; i < end; i++ {
+			Set(key, append([]int{i}, to...)...)
+			res, err := call(0)
+			Clear()
+			caller.valid(res, err)
+		}
+		return nil
+	}
+	return
+}
+
+// PanicRangeCall will call like SetRangeCall and panic when key is triggered
+func PanicRangeCall(key string, start, end int, to ...int) (caller *Caller) {
+	caller = NewCaller()
+	caller.calld = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
+		for i := start; i < end; i++ {
+			Panic(key, append([]int{i}, to...)...)
+			res, err := call(0)
+			Clear()
+			caller.valid(res, err)
+		}
+		return nil
+	}
+	return
+}
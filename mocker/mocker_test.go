@@ -0,0 +1,325 @@
+package mocker
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/codingeasygo/util/xmap"
+)
+
+func TestRangeArgs(t *testing.T) {
+	rangeArgs([]interface{}{"a", 1, 2}, func(key string, triggers []int) {
+		trigger := triggers[0]
+		if key != "a" {
+			panic(key)
+		}
+		if trigger != 1 && trigger != 2 {
+			panic(trigger)
+		}
+	})
+	rangeArgs([]interface{}{"a", 1, 2, "b", 3}, func(key string, triggers []int) {
+		trigger := triggers[0]
+		if key != "a" && key != "b" {
+			panic(key)
+		}
+		if key == "a" && trigger != 1 && trigger != 2 {
+			panic(trigger)
+		}
+		if key == "b" && trigger != 3 {
+			panic(trigger)
+		}
+	})
+	rangeArgs([]interface{}{"a", []int{3, -1}}, func(key string, triggers []int) {
+		if key != "a" || len(triggers) != 2 || triggers[0] != 3 || triggers[1] != -1 {
+			panic(triggers)
+		}
+	})
+}
+
+var ErrNotMock = fmt.Errorf("not mock error")
+
+func TestCheck(t *testing.T) {
+	if err := Check("a", "sql"); err != nil {
+		t.Error(err)
+		return
+	}
+	Start()
+	defer Stop()
+	Set("a", 2)
+	if err := Check("a", "sql"); err != nil || Runned("a") != 1 {
+		t.Error(err)
+		return
+	}
+	if err := Check("a", "sql"); err != ErrMock || Runned("a") != 2 {
+		t.Error(err)
+		return
+	}
+	MatchSet("b", "^select")
+	if err := CheckVerbose("b", "update", true); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := CheckVerbose("b", "select", true); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	SetError("c", 1, ErrNotMock)
+	if err := Check("c", "sql"); err != ErrNotMock {
+		t.Error(err)
+		return
+	}
+	MatchError("c", "^select", ErrNotMock)
+	if err := Check("c", "select"); err != ErrNotMock {
+		t.Error(err)
+		return
+	}
+	Set("c", -1)
+	if err := Check("c", "sql"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	cleared := 0
+	OnClear(func() { cleared++ })
+	Clear()
+	if err := Check("a", "sql"); err != nil || Runned("a") != 1 || cleared != 1 {
+		t.Error(err)
+		return
+	}
+	Panic("a", -1)
+	func() {
+		defer func() {
+			if perr := recover(); perr != ErrMock {
+				t.Error(perr)
+			}
+		}()
+		Check("a", "sql")
+	}()
+	Clear()
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		SetTriggers("a", "", false)
+	}()
+}
+
+func TestCaller(t *testing.T) {
+	okCall := func(trigger int) (res xmap.M, err error) {
+		res = xmap.M{"ok": 1}
+		return
+	}
+	errCall := func(trigger int) (res xmap.M, err error) {
+		err = Check("a", "select")
+		return
+	}
+	Start()
+	defer Stop()
+	Should(t, "ok", 1).Call(okCall)
+	ShouldError(t).OnlyLog(true).Call(okCall)
+	SetCall("a", 1).ShouldError(t).Call(errCall)
+	PanicCall("a", 1).OnlyLog(true).Should(t, "ok", 1).Call(okCall)
+	MatchSetCall("a", "^select").ShouldError(t).Call(errCall)
+	MatchPanicCall("a", "^select").OnlyLog(true).Should(t, "ok", 1).Call(okCall)
+	SetRangeCall("a", 1, 2).ShouldError(t).Call(errCall)
+	PanicRangeCall("a", 1, 2).OnlyLog(true).Should(t, "ok", 1).Call(okCall)
+	Should(t).OnlyLog(true).GetMap("http://127.0.0.1:234")
+}
+
+func TestTest(t *testing.T) {
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		Set("a", 1)
+	}()
+	t.Run("outer", func(t *testing.T) {
+		Test(t)
+		Set("a", 2)
+		Check("a", "sql")
+		t.Run("inner", func(t *testing.T) {
+			Test(t)
+			Set("b", -1)
+			if err := Check("a", "sql"); err != ErrMock || Runned("a") != 2 {
+				t.Error(err)
+				return
+			}
+		})
+		if Runned("a") != 1 || Check("b", "sql") != nil || !Running() {
+			t.Error("not restored")
+			return
+		}
+		other := &testing.T{}
+		done := make(chan int)
+		go func() {
+			defer close(done)
+			Test(other)
+		}()
+		<-done
+		if !other.Failed() {
+			t.Error("parallel is not failed")
+			return
+		}
+	})
+	if Running() || Runned("a") != 0 || len(mockTests) != 0 {
+		t.Error("not restored")
+		return
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	Test(t)
+	MatchArgs("Pool.Exec", func(sql string, args []interface{}) bool {
+		return len(args) > 0 && args[0] == 42
+	})
+	if err := CheckArgs("Pool.Exec", "update crud_object set status=$1 where tid=$2", []interface{}{41, 1}); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := CheckArgs("Pool.Exec", "update crud_object set status=$1 where tid=$2", []interface{}{42, 1}); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if err := Check("Pool.Exec", "update crud_object set status=$1 where tid=$2"); err != nil {
+		t.Error(err)
+		return
+	}
+	Clear()
+	if err := CheckArgs("Pool.Exec", "update crud_object set status=$1 where tid=$2", []interface{}{42, 1}); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSetOpenEnded(t *testing.T) {
+	Test(t)
+	Set("Pool.Exec", 3, -1)
+	for i := 1; i < 6; i++ {
+		if err := Check("Pool.Exec", "update"); (i >= 3) != (err == ErrMock) {
+			t.Errorf("%v,%v", i, err)
+			return
+		}
+	}
+	//the runned times is reset by clear, so the trigger is count from new
+	Clear()
+	Set("Pool.Exec", 2, -1)
+	if err := Check("Pool.Exec", "update"); err != nil || Runned("Pool.Exec") != 1 {
+		t.Error(err)
+		return
+	}
+	if err := Check("Pool.Exec", "update"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	Set("Pool.Exec", 2, 3)
+	if err := Check("Pool.Exec", "update"); err != ErrMock || Runned("Pool.Exec") != 3 {
+		t.Error(err)
+		return
+	}
+	if err := Check("Pool.Exec", "update"); err != nil {
+		t.Error(err)
+		return
+	}
+	lost := func(trigger int) (res xmap.M, err error) {
+		for i := 0; i < 5; i++ {
+			if xerr := Check("Pool.Exec", "update"); xerr != nil {
+				res = xmap.M{"lost": i}
+				break
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if Check("Pool.Exec", "update") == nil {
+				err = fmt.Errorf("connection is not lost")
+			}
+		}
+		return
+	}
+	Clear()
+	SetCall("Pool.Exec", []int{3, -1}).Should(t, "lost", 2).Call(lost)
+	SetRangeCall("Pool.Exec", 1, 4, -1).Should(t).Call(lost)
+	PanicCall("Pool.Exec", []int{1, -1}).Should(t).Call(func(trigger int) (res xmap.M, err error) {
+		defer func() {
+			if perr := recover(); perr == ErrMock {
+				res = xmap.M{"panic": 1}
+			}
+		}()
+		Check("Pool.Exec", "update")
+		return
+	})
+}
+
+func TestCallerErrorAssert(t *testing.T) {
+	Test(t)
+	errCall := func(trigger int) (res xmap.M, err error) {
+		err = Check("a", "select")
+		return
+	}
+	wrapCall := func(trigger int) (res xmap.M, err error) {
+		if err = Check("a", "select"); err != nil {
+			err = fmt.Errorf("wrap duplicate key: %w", err)
+		}
+		return
+	}
+	okCall := func(trigger int) (res xmap.M, err error) {
+		res = xmap.M{"ok": 1}
+		return
+	}
+	SetCall("a", 1).ShouldErrorIs(t, ErrMock).Call(errCall)
+	SetCall("a", 1).ShouldErrorIs(t, ErrMock).Call(wrapCall)
+	SetRangeCall("a", 1, 2).ShouldErrorContains(t, "duplicate").Call(wrapCall)
+	MatchSetCall("a", "^select").ShouldErrorContains(t, "mock error").Call(errCall)
+	//fail report line
+	buffer := &bytes.Buffer{}
+	line := func() int {
+		_, _, line, _ := runtime.Caller(1)
+		return line
+	}
+	cases := map[int]*Caller{}
+	caller := SetCall("a", 1).ShouldErrorIs(t, ErrNotMock).OnlyLog(true)
+	caller.Shoulder.Log = log.New(buffer, "", log.Lshortfile)
+	caller.Call(errCall)
+	cases[line()-1] = caller
+	caller = SetRangeCall("a", 1, 2).ShouldErrorContains(t, "duplicate").OnlyLog(true)
+	caller.Shoulder.Log = log.New(buffer, "", log.Lshortfile)
+	caller.Call(errCall)
+	cases[line()-1] = caller
+	caller = MatchSetCall("a", "^select").Should(t, "ok", 2).OnlyLog(true)
+	caller.Shoulder.Log = log.New(buffer, "", log.Lshortfile)
+	caller.Call(okCall)
+	cases[line()-1] = caller
+	caller = PanicCall("a", 1).ShouldErrorIs(t, ErrMock).OnlyLog(true)
+	caller.Shoulder.Log = log.New(buffer, "", log.Lshortfile)
+	caller.Call(okCall)
+	cases[line()-1] = caller
+	caller = Should(t, "ok", 2).OnlyLog(true)
+	caller.Shoulder.Log = log.New(buffer, "", log.Lshortfile)
+	caller.Call(okCall)
+	cases[line()-1] = caller
+	output := buffer.String()
+	for l := range cases {
+		if !strings.Contains(output, fmt.Sprintf("mocker_test.go:%v:", l)) {
+			t.Errorf("%v not in %v", l, output)
+		}
+	}
+	if strings.Count(output, "\n") != len(cases) {
+		t.Error(output)
+		return
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		caller := SetCall("a", 1)
+		caller.errIs = ErrNotMock
+		caller.Call(errCall)
+	}()
+}
@@ -0,0 +1,67 @@
+package mocker
+
+import (
+	"context"
+
+	"github.com/codingeasygo/crud"
+)
+
+// Queryer is the crud.Queryer wrapper which check mocker by Prefix.Exec on Exec/ExecRow and Prefix.Query on Query/QueryRow
+type Queryer struct {
+	crud.Queryer
+	Prefix string
+}
+
+// WrapQueryer will wrap q to mockable queryer by prefix key, it is used to make custom queryer like cache/router gain mockability
+func WrapQueryer(q crud.Queryer, prefix string) crud.Queryer {
+	return &Queryer{Queryer: q, Prefix: prefix}
+}
+
+func (q *Queryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	if err = CheckArgs(q.Prefix+".Exec", query, args); err != nil {
+		return
+	}
+	insertId, affected, err = q.Queryer.Exec(ctx, query, args...)
+	return
+}
+
+func (q *Queryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
+	if err = CheckArgs(q.Prefix+".Exec", query, args); err != nil {
+		return
+	}
+	insertId, err = q.Queryer.ExecRow(ctx, query, args...)
+	return
+}
+
+func (q *Queryer) Query(ctx context.Context, query string, args ...interface{}) (rows crud.Rows, err error) {
+	if err = CheckArgs(q.Prefix+".Query", query, args); err != nil {
+		return
+	}
+	rows, err = q.Queryer.Query(ctx, query, args...)
+	return
+}
+
+func (q *Queryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row crud.Row) {
+	row = &queryerRow{
+		Row:  q.Queryer.QueryRow(ctx, query, args...),
+		key:  q.Prefix + ".Query",
+		sql:  query,
+		args: args,
+	}
+	return
+}
+
+type queryerRow struct {
+	crud.Row
+	key  string
+	sql  string
+	args []interface{}
+}
+
+func (r *queryerRow) Scan(dest ...interface{}) (err error) {
+	err = CheckArgs(r.key, r.sql, r.args)
+	if xerr := r.Row.Scan(dest...); err == nil {
+		err = xerr
+	}
+	return
+}
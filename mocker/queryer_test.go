@@ -0,0 +1,84 @@
+package mocker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codingeasygo/crud"
+)
+
+type cacheQueryer struct {
+	values map[string]int64
+}
+
+func (c *cacheQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	c.values[query] = int64(len(args))
+	affected = 1
+	return
+}
+
+func (c *cacheQueryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
+	_, _, err = c.Exec(ctx, query, args...)
+	return
+}
+
+func (c *cacheQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows crud.Rows, err error) {
+	err = crud.ErrNoRows
+	return
+}
+
+func (c *cacheQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row crud.Row) {
+	row = cacheRow{value: c.values[query]}
+	return
+}
+
+type cacheRow struct {
+	value int64
+}
+
+func (c cacheRow) Scan(dest ...interface{}) (err error) {
+	*(dest[0].(*int64)) = c.value
+	return
+}
+
+func TestWrapQueryer(t *testing.T) {
+	Test(t)
+	ctx := context.Background()
+	queryer := WrapQueryer(&cacheQueryer{values: map[string]int64{}}, "Cache")
+	if _, _, err := queryer.Exec(ctx, "set", 1, 2); err != nil {
+		t.Error(err)
+		return
+	}
+	var value int64
+	if err := queryer.QueryRow(ctx, "set").Scan(&value); err != nil || value != 2 {
+		t.Errorf("%v,%v", err, value)
+		return
+	}
+	Set("Cache.Exec", 1, -1)
+	if _, _, err := queryer.Exec(ctx, "set"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if _, err := queryer.ExecRow(ctx, "set"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	Set("Cache.Query", 1, -1)
+	if err := queryer.QueryRow(ctx, "set").Scan(&value); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if _, err := queryer.Query(ctx, "set"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	Clear()
+	if _, err := queryer.ExecRow(ctx, "set"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := queryer.Query(ctx, "set"); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,94 @@
+package mocker
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RecordMax is the max number of calls kept by recorder, the oldest call is dropped when it is full
+var RecordMax = 10000
+
+// Call is the call record of Check
+type Call struct {
+	Key  string
+	SQL  string
+	Time time.Time
+}
+
+var recording = false
+var recordCalls = []*Call{}
+var recordLck = sync.RWMutex{}
+
+// Record will enable/disable recording every key and sql seen by Check
+func Record(on bool) {
+	recordLck.Lock()
+	recording = on
+	recordLck.Unlock()
+}
+
+func record(key, sql string) {
+	recordLck.Lock()
+	defer recordLck.Unlock()
+	if !recording {
+		return
+	}
+	recordCalls = append(recordCalls, &Call{Key: key, SQL: sql, Time: time.Now()})
+	if over := len(recordCalls) - RecordMax; RecordMax > 0 && over > 0 {
+		recordCalls = append([]*Call{}, recordCalls[over:]...)
+	}
+}
+
+func clearRecord() {
+	recordLck.Lock()
+	recordCalls = []*Call{}
+	recordLck.Unlock()
+}
+
+// Records will return all recorded calls after last clear
+func Records() (calls []Call) {
+	recordLck.RLock()
+	defer recordLck.RUnlock()
+	for _, call := range recordCalls {
+		calls = append(calls, *call)
+	}
+	return
+}
+
+// Calls will return the recorded sql of key by called order
+func Calls(key string) (sqls []string) {
+	recordLck.RLock()
+	defer recordLck.RUnlock()
+	for _, call := range recordCalls {
+		if call.Key == key {
+			sqls = append(sqls, call.SQL)
+		}
+	}
+	return
+}
+
+// CallCount will return the number of recorded calls which key and sql is matched, empty regexp is matched all
+func CallCount(keyRegexp, sqlRegexp string) (count int) {
+	keyMatch := regexp.MustCompile(keyRegexp)
+	sqlMatch := regexp.MustCompile(sqlRegexp)
+	recordLck.RLock()
+	defer recordLck.RUnlock()
+	for _, call := range recordCalls {
+		if keyMatch.MatchString(call.Key) && sqlMatch.MatchString(call.SQL) {
+			count++
+		}
+	}
+	return
+}
+
+// AssertCalled will fail t when key with sql matched is not called by times
+func AssertCalled(t *testing.T, key, sqlRegexp string, times int) bool {
+	t.Helper()
+	count := CallCount("^"+regexp.QuoteMeta(key)+"$", sqlRegexp)
+	if count != times {
+		t.Errorf("%v with sql %v is called %v times, expect %v times, calls is %v", key, sqlRegexp, count, times, Calls(key))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package mocker
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	defer Stop()
+	Check("Pool.Exec", "update crud_object set status=1")
+	if len(Records()) != 0 {
+		t.Error("recorded")
+		return
+	}
+	Record(true)
+	defer Record(false)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Check("Pool.Exec", "update crud_object set status=1")
+			Check("Tx.Exec", "delete from crud_object")
+		}()
+	}
+	wg.Wait()
+	Check("Pool.Query", "select * from crud_object")
+	if len(Records()) != 21 || len(Calls("Pool.Exec")) != 10 || len(Calls("Pool.Query")) != 1 {
+		t.Error("error")
+		return
+	}
+	if CallCount("Exec$", "^update crud_object") != 10 || CallCount("Exec$", "") != 20 || CallCount("", "") != 21 {
+		t.Error("error")
+		return
+	}
+	if !AssertCalled(t, "Pool.Exec", "^update", 10) || !AssertCalled(t, "Pool.Exec", "^delete", 0) {
+		return
+	}
+	mt := &testing.T{}
+	if AssertCalled(mt, "Pool.Exec", "^update", 1) || !mt.Failed() {
+		t.Error("error")
+		return
+	}
+	Clear()
+	if len(Records()) != 0 {
+		t.Error("not cleared")
+		return
+	}
+	//bounded
+	recordMax := RecordMax
+	RecordMax = 3
+	defer func() { RecordMax = recordMax }()
+	for _, sql := range []string{"a", "b", "c", "d"} {
+		Check("Pool.Exec", sql)
+	}
+	if calls := Calls("Pool.Exec"); len(calls) != 3 || calls[0] != "b" || calls[2] != "d" {
+		t.Error(calls)
+		return
+	}
+}
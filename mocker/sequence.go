@@ -0,0 +1,65 @@
+package mocker
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Outcome is the result of one check in sequence set by Sequence
+type Outcome struct {
+	Err   error
+	Panic bool
+}
+
+// OutcomeOk will make the check return nil
+var OutcomeOk = Outcome{}
+
+// OutcomePanic will make the check panic with ErrMock
+var OutcomePanic = Outcome{Err: ErrMock, Panic: true}
+
+// OutcomeErr will make the check return err, ErrMock is used when err is nil
+func OutcomeErr(err error) Outcome {
+	if err == nil {
+		err = ErrMock
+	}
+	return Outcome{Err: err}
+}
+
+var mockSequence = map[string][]Outcome{}
+
+// Sequence will make the next checks of key return outcomes in order, the trigger/match is used after all outcomes are consumed.
+// The runned times is still counted on sequenced check, and the outcomes is dropped by Clear, so it should be set in call func when using SetCall/SetRangeCall
+func Sequence(key string, outcomes ...Outcome) {
+	mockRunnedLck.Lock()
+	defer mockRunnedLck.Unlock()
+	if !mocking {
+		panic(fmt.Sprintf("set %v trigger on mocker is not started, call Start or Test first", key))
+	}
+	mockSequence[key] = append(mockSequence[key], outcomes...)
+}
+
+// nextOutcome must be called with mockRunnedLck locked
+func nextOutcome(key string) (outcome Outcome, ok bool) {
+	sequence := mockSequence[key]
+	if len(sequence) < 1 {
+		return
+	}
+	outcome, ok = sequence[0], true
+	mockSequence[key] = sequence[1:]
+	return
+}
+
+// SequenceDone will fail t when any outcome set by Sequence is not consumed
+func SequenceDone(t *testing.T) bool {
+	t.Helper()
+	mockRunnedLck.RLock()
+	defer mockRunnedLck.RUnlock()
+	done := true
+	for key, sequence := range mockSequence {
+		if len(sequence) > 0 {
+			t.Errorf("%v sequence is not done, %v outcomes remain", key, len(sequence))
+			done = false
+		}
+	}
+	return done
+}
@@ -0,0 +1,75 @@
+package mocker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/codingeasygo/util/xmap"
+)
+
+func TestSequence(t *testing.T) {
+	Test(t)
+	Sequence("Pool.Query", OutcomeOk, OutcomeErr(ErrNotMock), OutcomeErr(nil), OutcomeOk)
+	if err := Check("Pool.Query", "select 1"); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := Check("Pool.Query", "select 1"); err != ErrNotMock {
+		t.Error(err)
+		return
+	}
+	if err := Check("Pool.Query", "select 1"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	mt := &testing.T{}
+	if SequenceDone(mt) || !mt.Failed() {
+		t.Error("done")
+		return
+	}
+	if err := Check("Pool.Query", "select 1"); err != nil || Runned("Pool.Query") != 4 {
+		t.Error(err)
+		return
+	}
+	if !SequenceDone(t) {
+		return
+	}
+	//ok is higher than trigger
+	Set("Pool.Exec", -1)
+	Sequence("Pool.Exec", OutcomeOk, OutcomePanic)
+	if err := Check("Pool.Exec", "update"); err != nil {
+		t.Error(err)
+		return
+	}
+	func() {
+		defer func() {
+			if perr := recover(); perr != ErrMock {
+				t.Error(perr)
+			}
+		}()
+		Check("Pool.Exec", "update")
+	}()
+	if err := Check("Pool.Exec", "update"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	//cleared
+	Sequence("Pool.Exec", OutcomeErr(nil))
+	Clear()
+	if err := Check("Pool.Exec", "update"); err != nil || !SequenceDone(t) {
+		t.Error(err)
+		return
+	}
+	//with call
+	SetCall("Pool.Begin", 1).Should(t, "ok", 1).Call(func(trigger int) (res xmap.M, err error) {
+		Sequence("Pool.Exec", OutcomeOk, OutcomeErr(nil))
+		begin := Check("Pool.Begin", "begin")
+		first, second := Check("Pool.Exec", "update"), Check("Pool.Exec", "update")
+		if begin != ErrMock || first != nil || second != ErrMock {
+			err = fmt.Errorf("begin:%v,first:%v,second:%v", begin, first, second)
+			return
+		}
+		res = xmap.M{"ok": 1}
+		return
+	})
+}
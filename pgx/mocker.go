@@ -2,345 +2,361 @@ package pgx
 
 import (
 	"fmt"
-	"io"
-	"net/http"
-	"reflect"
 	"regexp"
+	"strings"
 	"sync"
 	"testing"
 
+	"github.com/codingeasygo/crud/mocker"
 	"github.com/codingeasygo/util/xhttp"
-	"github.com/codingeasygo/util/xmap"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgx/v4"
 )
 
-var ErrMock = fmt.Errorf("mock error")
+var ErrMock = mocker.ErrMock
 var Verbose = false
 var Client = xhttp.Shared
 
-var mocking = false
-var mockPanic = false
-var mockTrigger = map[string][]int{}
-var mockMatch = map[string]*regexp.Regexp{}
-var mockRunned = map[string]int{}
-var mockRunnedLck = sync.RWMutex{}
-
-func mockerCheck(key, sql string) (err error) {
-	if mocking {
-		mockRunnedLck.Lock()
-		mockRunned[key]++
-		trigger := mockTrigger[key]
-		runned := mockRunned[key]
-		if trigger != nil && (trigger[0] < 0 || (trigger[0] <= runned && runned <= trigger[1])) {
-			err = ErrMock
-		}
-		match := mockMatch[key]
-		if match != nil && match.MatchString(sql) {
-			err = ErrMock
-		}
-		if Verbose {
-			fmt.Printf("Mocking %v trigger:%v,runned:%v,err:%v,sql:\n%v\n", key, mockTrigger[key], mockRunned[key], err, sql)
-		}
-		mockRunnedLck.Unlock()
-		if mockPanic && err != nil {
-			panic(err)
-		}
-	}
+var mockData = map[string][]*mockerData{}
+var mockDataLck = sync.RWMutex{}
+
+func init() {
+	mocker.OnClear(func() {
+		mockDataLck.Lock()
+		mockData = map[string][]*mockerData{}
+		mockDataLck.Unlock()
+	})
+}
+
+// MockerCaller is the alias of mocker.Caller
+type MockerCaller = mocker.Caller
+
+// Outcome is the alias of mocker.Outcome, it is used by MockerSequence
+type Outcome = mocker.Outcome
+
+var OutcomeOk = mocker.OutcomeOk
+var OutcomePanic = mocker.OutcomePanic
+
+func OutcomeErr(err error) Outcome {
+	return mocker.OutcomeErr(err)
+}
+
+func mockerCheck(key, sql string, args ...interface{}) (err error) {
+	err = mocker.CheckVerbose(key, sql, Verbose, args...)
 	return
 }
 
+// MockerCheck will check key like driver queryer, it is used by custom queryer to participate in the mocker
+func MockerCheck(key, sql string) error {
+	return mockerCheck(key, sql)
+}
+
+// MockerLogTo will set the verbose log func like t.Logf, the log is printed to stdout when logf is nil
+func MockerLogTo(logf func(format string, args ...interface{})) {
+	mocker.LogTo(logf)
+}
+
 func MockerStart() {
-	mocking = true
+	mocker.Start()
 }
 
 func MockerStop() {
-	MockerClear()
-	mocking = false
+	mocker.Stop()
+}
+
+// MockerTest will start mocker for t and restore the mocker state when t is done, it is not supported in parallel tests
+func MockerTest(t *testing.T) {
+	t.Helper()
+	mocker.Test(t)
 }
 
 func MockerClear() {
-	mockRunnedLck.Lock()
-	mockTrigger = map[string][]int{}
-	mockMatch = map[string]*regexp.Regexp{}
-	mockRunned = map[string]int{}
-	mockPanic = false
-	mockRunnedLck.Unlock()
+	mocker.Clear()
 }
 
 func mockerSet(key, match string, isPanice bool, triggers ...int) {
-	mockRunnedLck.Lock()
-	defer mockRunnedLck.Unlock()
-	if len(match) > 0 {
-		mockMatch[key] = regexp.MustCompile(match)
-	} else {
-		if len(triggers) == 1 {
-			mockTrigger[key] = []int{triggers[0], triggers[0]}
-		} else if len(triggers) > 1 {
-			mockTrigger[key] = triggers
-		} else {
-			panic("trigger is required")
-		}
-	}
-	mockPanic = isPanice
+	mocker.SetTriggers(key, match, isPanice, triggers...)
 }
 
-func MockerSet(key string, trigger int) {
-	mockerSet(key, "", false, trigger)
+// MockerSet will make key return ErrMock when runned times is in triggers range, the range is [from, to] and to < 0 means from the from-th onward
+func MockerSet(key string, triggers ...int) {
+	mocker.Set(key, triggers...)
 }
 
-func MockerPanic(key string, trigger int) {
-	mockerSet(key, "", true, trigger)
+func MockerPanic(key string, triggers ...int) {
+	mocker.Panic(key, triggers...)
 }
 
 func MockerMatchSet(key, match string) {
-	mockerSet(key, match, false)
+	mocker.MatchSet(key, match)
 }
 
 func MockerMatchPanic(key, match string) {
-	mockerSet(key, match, true)
+	mocker.MatchPanic(key, match)
 }
 
-type MockerCaller struct {
-	Call     func(func(trigger int) (res xmap.M, err error)) xmap.M
-	calld    func(int, func(trigger int) (res xmap.M, err error)) xmap.M
-	Client   *xhttp.Client
-	Shoulder xmap.Shoulder
+// MockerMatchArgs will make key return ErrMock when predicate return true by sql and args
+func MockerMatchArgs(key string, predicate func(sql string, args []interface{}) bool) {
+	mocker.MatchArgs(key, predicate)
 }
 
-func NewMockerCaller() (caller *MockerCaller) {
-	caller = &MockerCaller{Client: Client}
-	caller.Call = func(c func(trigger int) (xmap.M, error)) xmap.M { return caller.calld(1, c) }
-	return
+// MockerSequence will make the next checks of key return outcomes in order, the outcomes is cleared by MockerClear
+func MockerSequence(key string, outcomes ...Outcome) {
+	mocker.Sequence(key, outcomes...)
 }
 
-func (m *MockerCaller) Should(t *testing.T, args ...interface{}) *MockerCaller {
-	m.Shoulder.Should(t, args...)
-	return m
+// MockerSequenceDone will fail t when any outcome set by MockerSequence is not consumed
+func MockerSequenceDone(t *testing.T) bool {
+	t.Helper()
+	return mocker.SequenceDone(t)
 }
 
-func (m *MockerCaller) ShouldError(t *testing.T) *MockerCaller {
-	m.Shoulder.ShouldError(t)
-	return m
+// MockerRecord will enable/disable recording every key and sql seen by mocker, the records is cleared by MockerClear
+func MockerRecord(on bool) {
+	mocker.Record(on)
 }
 
-func (m *MockerCaller) OnlyLog(only bool) *MockerCaller {
-	m.Shoulder.OnlyLog(only)
-	return m
+// MockerCalls will return the recorded sql of key by called order
+func MockerCalls(key string) []string {
+	return mocker.Calls(key)
 }
 
-//GetMap will get map from remote
-func (m *MockerCaller) GetMap(format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.GetMap(format, args...)
-		return data, err
-	})
-	return
+// MockerCallCount will return the number of recorded calls which key and sql is matched
+func MockerCallCount(keyRegexp, sqlRegexp string) int {
+	return mocker.CallCount(keyRegexp, sqlRegexp)
 }
 
-//GetHeaderMap will get map from remote
-func (m *MockerCaller) GetHeaderMap(header xmap.M, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, res, err = m.Client.GetHeaderMap(header, format, args...)
-		return data, err
-	})
+// MockerAssertCalled will fail t when key with sql matched is not called by times
+func MockerAssertCalled(t *testing.T, key, sqlRegexp string, times int) bool {
+	t.Helper()
+	return mocker.AssertCalled(t, key, sqlRegexp, times)
+}
+
+// MockerSetError will make key return err instead of ErrMock when runned times is trigger
+func MockerSetError(key string, trigger int, err error) {
+	mocker.SetError(key, trigger, err)
+}
+
+// MockerMatchError will make key return err instead of ErrMock when sql is matched
+func MockerMatchError(key, match string, err error) {
+	mocker.MatchError(key, match, err)
+}
+
+func NewMockerCaller() (caller *MockerCaller) {
+	caller = mocker.NewCaller()
+	caller.Client = Client
 	return
 }
 
-//PostMap will get map from remote
-func (m *MockerCaller) PostMap(body io.Reader, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.PostMap(body, format, args...)
-		return data, err
-	})
+func Should(t *testing.T, args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.Should(t, args...)
+	caller.Client = Client
 	return
 }
 
-//PostTypeMap will get map from remote
-func (m *MockerCaller) PostTypeMap(contentType string, body io.Reader, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.PostTypeMap(contentType, body, format, args...)
-		return data, err
-	})
+func ShouldError(t *testing.T) (caller *MockerCaller) {
+	caller = mocker.ShouldError(t)
+	caller.Client = Client
 	return
 }
 
-//PostHeaderMap will get map from remote
-func (m *MockerCaller) PostHeaderMap(header xmap.M, body io.Reader, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, res, err = m.Client.PostHeaderMap(header, body, format, args...)
-		return data, err
-	})
+func MockerSetCall(args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.SetCall(args...)
+	caller.Client = Client
 	return
 }
 
-//PostJSONMap will get map from remote
-func (m *MockerCaller) PostJSONMap(body interface{}, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.PostJSONMap(body, format, args...)
-		return data, err
-	})
+func MockerPanicCall(args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.PanicCall(args...)
+	caller.Client = Client
 	return
 }
 
-//MethodBytes will do http request, read reponse and parse to map
-func (m *MockerCaller) MethodMap(method string, header xmap.M, body io.Reader, format string, args ...interface{}) (data xmap.M, res *http.Response, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, res, err = m.Client.MethodMap(method, header, body, format, args...)
-		return data, err
-	})
+func MockerMatchSetCall(key, match string) (caller *MockerCaller) {
+	caller = mocker.MatchSetCall(key, match)
+	caller.Client = Client
 	return
 }
 
-//PostFormMap will get map from remote
-func (m *MockerCaller) PostFormMap(form xmap.M, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.PostFormMap(form, format, args...)
-		return data, err
-	})
+func MockerMatchPanicCall(key, match string) (caller *MockerCaller) {
+	caller = mocker.MatchPanicCall(key, match)
+	caller.Client = Client
 	return
 }
 
-//PostMultipartMap will get map from remote
-func (m *MockerCaller) PostMultipartMap(header, fields xmap.M, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.PostMultipartMap(header, fields, format, args...)
-		return data, err
-	})
+func MockerSetRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.SetRangeCall(key, start, end, to...)
+	caller.Client = Client
 	return
 }
 
-//UploadMap will get map from remote
-func (m *MockerCaller) UploadMap(fields xmap.M, filekey, filename, format string, args ...interface{}) (data xmap.M, err error) {
-	m.calld(1, func(trigger int) (xmap.M, error) {
-		data, err = m.Client.UploadMap(fields, filekey, filename, format, args...)
-		return data, err
-	})
+func MockerPanicRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.PanicRangeCall(key, start, end, to...)
+	caller.Client = Client
 	return
 }
 
-func Should(t *testing.T, args ...interface{}) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		res, err := call(0)
-		caller.Shoulder.Valid(depth+3, res, err)
-		return res
-	}
-	return caller.Should(t, args...)
+type mockerData struct {
+	match   *regexp.Regexp
+	columns []string
+	rows    []map[string]interface{}
 }
 
-func ShouldError(t *testing.T) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		res, err := call(0)
-		caller.Shoulder.Valid(depth+3, res, err)
-		return res
+// MockerData will make Query/QueryRow on key(Pool.Query/Tx.Query) return rows when sql is matched,
+// the columns is parsed from select list of query sql if not set, the Scan will fail when it can not be parsed
+func MockerData(key, match string, rows []map[string]interface{}, columns ...string) {
+	mockDataLck.Lock()
+	mockData[key] = append(mockData[key], &mockerData{match: regexp.MustCompile(match), columns: columns, rows: rows})
+	mockDataLck.Unlock()
+}
+
+func mockerDataRows(key, sql string) (rows *mockerRows) {
+	if !mocker.Running() {
+		return
 	}
-	return caller.ShouldError(t)
-}
-
-func rangeArgs(args []interface{}, call func(key string, trigger int)) {
-	triggerAll := map[string][]int{}
-	triggerKeys := []string{}
-	triggerAdd := false
-	for i, arg := range args {
-		switch arg := arg.(type) {
-		case string:
-			if triggerAdd {
-				triggerKeys = []string{}
+	mockDataLck.RLock()
+	defer mockDataLck.RUnlock()
+	for _, data := range mockData[key] {
+		if data.match.MatchString(sql) {
+			columns := data.columns
+			if len(columns) < 1 && len(data.rows) > 0 {
+				columns = mockerColumns(sql, data.rows[0])
 			}
-			triggerAdd = false
-			triggerKeys = append(triggerKeys, arg)
-		case int:
-			triggerAdd = true
-			for _, key := range triggerKeys {
-				triggerAll[key] = append(triggerAll[key], arg)
+			rows = &mockerRows{columns: columns, rows: data.rows}
+			break
+		}
+	}
+	return
+}
+
+var mockerSelectRegexp = regexp.MustCompile(`(?is)^\s*select\s+(.+?)\s+from\s`)
+var mockerColumnRegexp = regexp.MustCompile(`(\w+)"?$`)
+
+// mockerColumns will return the keys of row in order of select list of sql, it is nil when any select item is not found in row
+func mockerColumns(sql string, row map[string]interface{}) (columns []string) {
+	if len(row) == 1 {
+		for column := range row {
+			columns = append(columns, column)
+		}
+		return
+	}
+	match := mockerSelectRegexp.FindStringSubmatch(sql)
+	if len(match) < 2 {
+		return
+	}
+	var items []string
+	depth, start := 0, 0
+	for i, c := range match[1] {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, match[1][start:i])
+				start = i + 1
 			}
-		default:
-			panic(fmt.Sprintf("args[%v] is %v and not supported", i, reflect.TypeOf(arg)))
 		}
 	}
-	for key, triggers := range triggerAll {
-		for _, trigger := range triggers {
-			call(key, trigger)
+	items = append(items, match[1][start:])
+	for _, item := range items {
+		name := mockerColumnRegexp.FindStringSubmatch(strings.TrimSpace(item))
+		if len(name) < 2 {
+			return nil
+		}
+		if _, ok := row[name[1]]; !ok {
+			return nil
 		}
+		columns = append(columns, name[1])
 	}
+	return
 }
 
-func MockerSetCall(args ...interface{}) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		rangeArgs(args, func(key string, i int) {
-			MockerSet(key, i)
-			res, err := call(i)
-			MockerClear()
-			caller.Shoulder.Valid(depth+5, res, err)
-		})
-		return nil
-	}
-	return
+type mockerRows struct {
+	columns []string
+	rows    []map[string]interface{}
+	index   int
+	closed  bool
 }
 
-func MockerPanicCall(args ...interface{}) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		rangeArgs(args, func(key string, i int) {
-			MockerPanic(key, i)
-			res, err := call(i)
-			MockerClear()
-			caller.Shoulder.Valid(depth+5, res, err)
-		})
-		return nil
+func (m *mockerRows) Close() {
+	m.closed = true
+}
+
+func (m *mockerRows) Err() error {
+	return nil
+}
+
+func (m *mockerRows) CommandTag() pgconn.CommandTag {
+	return pgconn.CommandTag(fmt.Sprintf("SELECT %v", len(m.rows)))
+}
+
+func (m *mockerRows) FieldDescriptions() (fields []pgproto3.FieldDescription) {
+	for _, column := range m.columns {
+		fields = append(fields, pgproto3.FieldDescription{Name: []byte(column)})
 	}
 	return
 }
 
-func MockerMatchSetCall(key, match string) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		MockerMatchSet(key, match)
-		res, err := call(0)
-		MockerClear()
-		caller.Shoulder.Valid(depth+3, res, err)
-		return res
+func (m *mockerRows) Next() bool {
+	if m.closed || m.index >= len(m.rows) {
+		m.closed = true
+		return false
 	}
-	return
+	m.index++
+	return true
 }
 
-func MockerMatchPanicCall(key, match string) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		MockerMatchPanic(key, match)
-		res, err := call(0)
-		MockerClear()
-		caller.Shoulder.Valid(depth+3, res, err)
-		return res
+func (m *mockerRows) Scan(dest ...interface{}) (err error) {
+	if m.index < 1 || m.index > len(m.rows) {
+		err = fmt.Errorf("mocker rows is not having current row")
+		return
+	}
+	if len(m.columns) < 1 {
+		err = fmt.Errorf("mocker rows columns is not setted and can not be parsed from select list")
+		return
+	}
+	if len(dest) != len(m.columns) {
+		err = fmt.Errorf("mocker rows dest count %v is not equal to columns %v", len(dest), len(m.columns))
+		return
+	}
+	row := m.rows[m.index-1]
+	for i, column := range m.columns {
+		if err = mocker.Assign(dest[i], row[column]); err != nil {
+			err = fmt.Errorf("mocker rows scan %v fail with %v", column, err)
+			break
+		}
 	}
 	return
 }
 
-func MockerSetRangeCall(key string, start, end int) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		for i := start; i < end; i++ {
-			MockerSet(key, i)
-			res, err := call(0)
-			MockerClear()
-			caller.Shoulder.Valid(depth+3, res, err)
-		}
-		return nil
+func (m *mockerRows) Values() (values []interface{}, err error) {
+	if m.index < 1 || m.index > len(m.rows) {
+		err = fmt.Errorf("mocker rows is not having current row")
+		return
+	}
+	for _, column := range m.columns {
+		values = append(values, m.rows[m.index-1][column])
 	}
 	return
 }
 
-func MockerPanicRangeCall(key string, start, end int) (caller *MockerCaller) {
-	caller = NewMockerCaller()
-	caller.calld = func(depth int, call func(trigger int) (res xmap.M, err error)) xmap.M {
-		for i := start; i < end; i++ {
-			MockerPanic(key, i)
-			res, err := call(0)
-			MockerClear()
-			caller.Shoulder.Valid(depth+3, res, err)
-		}
-		return nil
+func (m *mockerRows) RawValues() [][]byte {
+	return nil
+}
+
+type mockerRow struct {
+	rows *mockerRows
+}
+
+func (m mockerRow) Scan(dest ...interface{}) (err error) {
+	defer m.rows.Close()
+	if !m.rows.Next() {
+		err = pgx.ErrNoRows
+		return
 	}
+	err = m.rows.Scan(dest...)
 	return
 }
@@ -2,11 +2,23 @@ package pgx
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/codingeasygo/crud"
+	"github.com/codingeasygo/util/xsql"
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	shopspring "github.com/jackc/pgtype/ext/shopspring-numeric"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 var Shared *PgQueryer
@@ -16,20 +28,131 @@ var Pool = func() *PgQueryer {
 }
 
 func Bootstrap(connString string) (pool *pgxpool.Pool, err error) {
-	pool, err = pgxpool.Connect(context.Background(), connString)
+	pool, err = BootstrapConfig(connString)
+	return
+}
+
+// RegisterTypes will register shopspring decimal to numeric and xsql json types to jsonb on conn, it is used as AfterConnect hook by Bootstrap
+func RegisterTypes(conn *pgx.Conn) (err error) {
+	info := conn.ConnInfo()
+	info.RegisterDataType(pgtype.DataType{Value: &shopspring.Numeric{}, Name: "numeric", OID: pgtype.NumericOID})
+	info.RegisterDefaultPgType(decimal.Decimal{}, "numeric")
+	info.RegisterDefaultPgType(&decimal.Decimal{}, "numeric")
+	jsonValues := []interface{}{
+		xsql.M{}, xsql.MArray{},
+		xsql.IntArray{}, xsql.IntPtrArray{},
+		xsql.Int64Array{}, xsql.Int64PtrArray{},
+		xsql.Float64Array{}, xsql.Float64PtrArray{},
+		xsql.StringArray{}, xsql.StringPtrArray{},
+	}
+	for _, value := range jsonValues {
+		info.RegisterDefaultPgType(value, "jsonb")
+	}
+	return
+}
+
+// Option is the pool config option for BootstrapConfig
+type Option func(config *pgxpool.Config)
+
+// WithMaxConns will set pool max connections
+func WithMaxConns(n int32) Option {
+	return func(config *pgxpool.Config) { config.MaxConns = n }
+}
+
+// WithMinConns will set pool min connections
+func WithMinConns(n int32) Option {
+	return func(config *pgxpool.Config) { config.MinConns = n }
+}
+
+// WithMaxConnLifetime will set pool max connection lifetime
+func WithMaxConnLifetime(lifetime time.Duration) Option {
+	return func(config *pgxpool.Config) { config.MaxConnLifetime = lifetime }
+}
+
+// WithLogger will set pgx logger and log level to connection config
+func WithLogger(logger pgx.Logger, level pgx.LogLevel) Option {
+	return func(config *pgxpool.Config) {
+		config.ConnConfig.Logger = logger
+		config.ConnConfig.LogLevel = level
+	}
+}
+
+// BootstrapConfig will bootstrap Shared by conn string and options
+func BootstrapConfig(connString string, opts ...Option) (pool *pgxpool.Pool, err error) {
+	pool, err = bootstrap(connString, false, opts...)
+	return
+}
+
+// BootstrapLazy will bootstrap Shared without connecting to database, all query will return ErrNotReady until WaitReady success
+func BootstrapLazy(connString string, opts ...Option) (pool *pgxpool.Pool, err error) {
+	pool, err = bootstrap(connString, true, opts...)
+	return
+}
+
+func bootstrap(connString string, lazy bool, opts ...Option) (pool *pgxpool.Pool, err error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return
+	}
+	config.LazyConnect = lazy
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		return RegisterTypes(conn)
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	pool, err = pgxpool.ConnectConfig(context.Background(), config)
 	if err == nil {
 		Shared = NewPgQueryer(pool)
+		if lazy {
+			Shared.ready = new(int32)
+		}
 	}
 	return
 }
 
 var ErrNoRows = pgx.ErrNoRows
+
+func init() {
+	crud.NoRowsErrors = append(crud.NoRowsErrors, ErrNoRows)
+}
+var ErrNotReady = fmt.Errorf("pool is not ready")
 var ErrTxClosed = pgx.ErrTxClosed
 var ErrTxCommitRollback = pgx.ErrTxCommitRollback
 
+var returningRegexp = regexp.MustCompile(`(?is)^\s*insert\s.*\sreturning\s+[\w."]+\s*;?\s*$`)
+
+// execReturning will read all returning id to insertId and count affected, it is used to exec insert sql with returning single column
+func execReturning(rows pgx.Rows, err error) (insertId, affected int64, err_ error) {
+	if err != nil {
+		err_ = err
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id interface{}
+		if err_ = rows.Scan(&id); err_ != nil {
+			return
+		}
+		affected++
+		switch id := id.(type) {
+		case int64:
+			insertId = id
+		case int32:
+			insertId = int64(id)
+		case int16:
+			insertId = int64(id)
+		}
+	}
+	err_ = rows.Err()
+	return
+}
+
 type Row struct {
-	SQL string
+	SQL  string
+	args []interface{}
 	pgx.Row
+	release func()
 }
 
 func (r Row) Scan(dest ...interface{}) (err error) {
@@ -38,40 +161,65 @@ func (r Row) Scan(dest ...interface{}) (err error) {
 		if err == nil {
 			err = xerr
 		}
+		if r.release != nil {
+			r.release()
+		}
 	}()
-	err = mockerCheck("Rows.Scan", r.SQL)
+	err = mockerCheck("Rows.Scan", r.SQL, r.args...)
 	return
 }
 
 type Rows struct {
-	SQL string
+	SQL  string
+	args []interface{}
 	pgx.Rows
+	release func()
 }
 
 func (r *Rows) Scan(dest ...interface{}) error {
-	if err := mockerCheck("Rows.Scan", r.SQL); err != nil {
+	if err := mockerCheck("Rows.Scan", r.SQL, r.args...); err != nil {
 		return err
 	}
 	return r.Rows.Scan(dest...)
 }
 
 func (r *Rows) Values() ([]interface{}, error) {
-	if err := mockerCheck("Rows.Values", r.SQL); err != nil {
+	if err := mockerCheck("Rows.Values", r.SQL, r.args...); err != nil {
 		return nil, err
 	}
 	return r.Rows.Values()
 }
 
+func (r *Rows) Err() error {
+	if err := mockerCheck("Rows.Err", r.SQL, r.args...); err != nil {
+		return err
+	}
+	return r.Rows.Err()
+}
+
+func (r *Rows) Columns() (columns []string, err error) {
+	for _, field := range r.Rows.FieldDescriptions() {
+		columns = append(columns, string(field.Name))
+	}
+	return
+}
+
 func (r *Rows) Close() (err error) {
 	r.Rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
 	return
 }
 
 type BatchResults struct {
 	pgx.BatchResults
+	index int
 }
 
 func (b *BatchResults) Exec() (pgconn.CommandTag, error) {
+	b.index++
 	if err := mockerCheck("BatchResult.Exec", ""); err != nil {
 		return nil, err
 	}
@@ -79,6 +227,7 @@ func (b *BatchResults) Exec() (pgconn.CommandTag, error) {
 }
 
 func (b *BatchResults) Query() (rows *Rows, err error) {
+	b.index++
 	if err := mockerCheck("BatchResult.Query", ""); err != nil {
 		return nil, err
 	}
@@ -90,9 +239,34 @@ func (b *BatchResults) Query() (rows *Rows, err error) {
 }
 
 func (b *BatchResults) QueryRow() *Row {
+	b.index++
 	return &Row{Row: b.BatchResults.QueryRow()}
 }
 
+// ScanNext will read next query result in batch and scan all rows by crud.Scan
+func (b *BatchResults) ScanNext(v interface{}, filter string, dest ...interface{}) (err error) {
+	index := b.index
+	rows, err := b.Query()
+	if err == nil {
+		err = crud.Scan(rows, v, filter, dest...)
+		rows.Close()
+	}
+	if err != nil {
+		err = fmt.Errorf("batch result[%v] fail with %w", index, err)
+	}
+	return
+}
+
+// ScanRowNext will read next query result in batch and scan single row by crud.ScanRow
+func (b *BatchResults) ScanRowNext(v interface{}, filter string, dest ...interface{}) (err error) {
+	index := b.index
+	err = crud.ScanRow(b.QueryRow(), v, filter, dest...)
+	if err != nil {
+		err = fmt.Errorf("batch result[%v] fail with %w", index, err)
+	}
+	return
+}
+
 func (b *BatchResults) Close() error {
 	if err := mockerCheck("BatchResult.Close", ""); err != nil {
 		return err
@@ -102,34 +276,74 @@ func (b *BatchResults) Close() error {
 
 type Tx struct {
 	pgx.Tx
+	savepoint string
+	sequence  *int
+	closed    bool
 }
 
-// Begin starts a pseudo nested transaction.
+// Begin starts a nested transaction by savepoint, Commit/Rollback on nested transaction will release/rollback to the savepoint
 func (t *Tx) Begin(ctx context.Context) (tx *Tx, err error) {
 	if err := mockerCheck("Tx.Begin", ""); err != nil {
 		return nil, err
 	}
-	raw, err := t.Tx.Begin(ctx)
+	if t.sequence == nil {
+		t.sequence = new(int)
+	}
+	*t.sequence++
+	savepoint := fmt.Sprintf("crud_sp_%v", *t.sequence)
+	_, err = t.Tx.Exec(ctx, "savepoint "+savepoint)
 	if err == nil {
-		tx = &Tx{Tx: raw}
+		tx = &Tx{Tx: t.Tx, savepoint: savepoint, sequence: t.sequence}
+	}
+	return
+}
+
+// BeginTx starts a nested transaction, txOptions is not supported on nested transaction
+func (t *Tx) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (tx *Tx, err error) {
+	if txOptions != (pgx.TxOptions{}) {
+		err = fmt.Errorf("nested transaction options is not supported")
+		return
 	}
+	tx, err = t.Begin(ctx)
 	return
 }
 
 func (t *Tx) Commit(ctx context.Context) error {
 	if err := mockerCheck("Tx.Commit", ""); err != nil {
-		t.Tx.Rollback(ctx)
+		t.rollback(ctx)
 		return err
 	}
-	return t.Tx.Commit(ctx)
+	if len(t.savepoint) < 1 {
+		return t.Tx.Commit(ctx)
+	}
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+	_, err := t.Tx.Exec(ctx, "release savepoint "+t.savepoint)
+	return err
 }
 
 func (t *Tx) Rollback(ctx context.Context) error {
 	if err := mockerCheck("Tx.Rollback", ""); err != nil {
-		t.Tx.Rollback(ctx)
+		t.rollback(ctx)
 		return err
 	}
-	return t.Tx.Rollback(ctx)
+	return t.rollback(ctx)
+}
+
+func (t *Tx) rollback(ctx context.Context) (err error) {
+	if len(t.savepoint) < 1 {
+		err = t.Tx.Rollback(ctx)
+		return
+	}
+	if t.closed {
+		err = pgx.ErrTxClosed
+		return
+	}
+	t.closed = true
+	_, err = t.Tx.Exec(ctx, "rollback to savepoint "+t.savepoint)
+	return
 }
 
 func (t *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
@@ -153,9 +367,13 @@ func (t *Tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDe
 }
 
 func (t *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Tx.Exec", sql); err != nil {
+	if err := mockerCheck("Tx.Exec", sql, args...); err != nil {
 		return 0, 0, err
 	}
+	if returningRegexp.MatchString(sql) {
+		insertId, affected, err = execReturning(t.Tx.Query(ctx, sql, args...))
+		return
+	}
 	res, err := t.Tx.Exec(ctx, sql, args...)
 	if err == nil {
 		affected = res.RowsAffected()
@@ -164,7 +382,7 @@ func (t *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (insertI
 }
 
 func (t *Tx) ExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Tx.Exec", sql); err != nil {
+	if err := mockerCheck("Tx.Exec", sql, args...); err != nil {
 		return 0, err
 	}
 	insertId, affected, err := t.Exec(ctx, sql, args...)
@@ -175,25 +393,33 @@ func (t *Tx) ExecRow(ctx context.Context, sql string, args ...interface{}) (inse
 }
 
 func (t *Tx) Query(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Tx.Query", sql); err != nil {
+	if err := mockerCheck("Tx.Query", sql, args...); err != nil {
 		return nil, err
 	}
+	if data := mockerDataRows("Tx.Query", sql); data != nil {
+		rows = &Rows{SQL: sql, args: args, Rows: data}
+		return
+	}
 	raw, err := t.Tx.Query(ctx, sql, args...)
 	if err == nil {
-		rows = &Rows{SQL: sql, Rows: raw}
+		rows = &Rows{SQL: sql, args: args, Rows: raw}
 	}
 	return
 }
 
 func (t *Tx) QueryRow(ctx context.Context, sql string, args ...interface{}) crud.Row {
+	if data := mockerDataRows("Tx.Query", sql); data != nil {
+		return &Row{SQL: sql, args: args, Row: mockerRow{rows: data}}
+	}
 	return &Row{
-		SQL: sql,
-		Row: t.Tx.QueryRow(ctx, sql, args...),
+		SQL:  sql,
+		args: args,
+		Row:  t.Tx.QueryRow(ctx, sql, args...),
 	}
 }
 
 func (t *Tx) CrudExec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Tx.Exec", sql); err != nil {
+	if err := mockerCheck("Tx.Exec", sql, args...); err != nil {
 		return 0, 0, err
 	}
 	insertId, affected, err = t.Exec(ctx, sql, args...)
@@ -201,7 +427,7 @@ func (t *Tx) CrudExec(ctx context.Context, sql string, args ...interface{}) (ins
 }
 
 func (t *Tx) CrudExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Tx.Exec", sql); err != nil {
+	if err := mockerCheck("Tx.Exec", sql, args...); err != nil {
 		return 0, err
 	}
 	insertId, err = t.ExecRow(ctx, sql, args...)
@@ -209,7 +435,7 @@ func (t *Tx) CrudExecRow(ctx context.Context, sql string, args ...interface{}) (
 }
 
 func (t *Tx) CrudQuery(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Tx.Query", sql); err != nil {
+	if err := mockerCheck("Tx.Query", sql, args...); err != nil {
 		return nil, err
 	}
 	rows, err = t.Query(ctx, sql, args...)
@@ -221,8 +447,25 @@ func (t *Tx) CrudQueryRow(ctx context.Context, sql string, args ...interface{})
 	return
 }
 
+type errRow struct {
+	err error
+}
+
+func (e errRow) Scan(dest ...interface{}) error {
+	return e.err
+}
+
+type pgxRunner interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 type PgQueryer struct {
 	*pgxpool.Pool
+	Timeout    time.Duration
+	ServerSide bool
+	ready      *int32
 }
 
 func NewPgQueryer(pool *pgxpool.Pool) (queryer *PgQueryer) {
@@ -230,11 +473,102 @@ func NewPgQueryer(pool *pgxpool.Pool) (queryer *PgQueryer) {
 	return
 }
 
+// WithTimeout will return shallow copied queryer which run Exec/Query/QueryRow with timeout context
+func (p *PgQueryer) WithTimeout(timeout time.Duration) *PgQueryer {
+	queryer := *p
+	queryer.Timeout = timeout
+	queryer.ServerSide = false
+	return &queryer
+}
+
+// WithServerTimeout will return shallow copied queryer which run Exec/Query/QueryRow with statement_timeout on server side
+func (p *PgQueryer) WithServerTimeout(timeout time.Duration) *PgQueryer {
+	queryer := *p
+	queryer.Timeout = timeout
+	queryer.ServerSide = true
+	return &queryer
+}
+
+// ReadyDelayMin and ReadyDelayMax is the backoff range of ping in WaitReady
+var ReadyDelayMin = 100 * time.Millisecond
+var ReadyDelayMax = 5 * time.Second
+
+// IsReady will return if pool is ready, the pool created by BootstrapLazy is ready after WaitReady success
+func (p *PgQueryer) IsReady() bool {
+	return p.ready == nil || atomic.LoadInt32(p.ready) == 1
+}
+
+// WaitReady will ping database with backoff until it is ready or ctx done
+func (p *PgQueryer) WaitReady(ctx context.Context) (err error) {
+	delay := ReadyDelayMin
+	for {
+		if err = p.Ping(ctx); err == nil {
+			if p.ready != nil {
+				atomic.StoreInt32(p.ready, 1)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > ReadyDelayMax {
+			delay = ReadyDelayMax
+		}
+	}
+}
+
+// prepare will return context and runner to run sql with timeout, release must be called after sql done
+func (p *PgQueryer) prepare(ctx context.Context) (runCtx context.Context, runner pgxRunner, release func(), err error) {
+	runCtx, runner, release = ctx, p.Pool, func() {}
+	if !p.IsReady() {
+		err = ErrNotReady
+		return
+	}
+	if p.Timeout <= 0 {
+		return
+	}
+	if !p.ServerSide {
+		runCtx, release = context.WithTimeout(ctx, p.Timeout)
+		return
+	}
+	//client deadline is longer than server to receive the statement timeout error
+	runCtx, cancel := context.WithTimeout(ctx, 2*p.Timeout)
+	conn, err := p.Pool.Acquire(runCtx)
+	if err != nil {
+		cancel()
+		return
+	}
+	_, err = conn.Exec(runCtx, "select set_config('statement_timeout',$1,false)", fmt.Sprintf("%d", p.Timeout.Milliseconds()))
+	if err != nil {
+		conn.Release()
+		cancel()
+		return
+	}
+	runner = conn
+	release = func() {
+		conn.Exec(context.Background(), "reset statement_timeout")
+		conn.Release()
+		cancel()
+	}
+	return
+}
+
 func (p *PgQueryer) Exec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Pool.Exec", sql); err != nil {
+	if err := mockerCheck("Pool.Exec", sql, args...); err != nil {
 		return 0, 0, err
 	}
-	res, err := p.Pool.Exec(ctx, sql, args...)
+	ctx, runner, release, err := p.prepare(ctx)
+	if err != nil {
+		return
+	}
+	defer release()
+	if returningRegexp.MatchString(sql) {
+		insertId, affected, err = execReturning(runner.Query(ctx, sql, args...))
+		return
+	}
+	res, err := runner.Exec(ctx, sql, args...)
 	if err == nil {
 		affected = res.RowsAffected()
 	}
@@ -242,7 +576,7 @@ func (p *PgQueryer) Exec(ctx context.Context, sql string, args ...interface{}) (
 }
 
 func (p *PgQueryer) ExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Pool.Exec", sql); err != nil {
+	if err := mockerCheck("Pool.Exec", sql, args...); err != nil {
 		return 0, err
 	}
 	insertId, affected, err := p.Exec(ctx, sql, args...)
@@ -253,25 +587,44 @@ func (p *PgQueryer) ExecRow(ctx context.Context, sql string, args ...interface{}
 }
 
 func (p *PgQueryer) Query(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Pool.Query", sql); err != nil {
+	if err := mockerCheck("Pool.Query", sql, args...); err != nil {
 		return nil, err
 	}
-	raw, err := p.Pool.Query(ctx, sql, args...)
+	if data := mockerDataRows("Pool.Query", sql); data != nil {
+		rows = &Rows{SQL: sql, args: args, Rows: data}
+		return
+	}
+	ctx, runner, release, err := p.prepare(ctx)
+	if err != nil {
+		return
+	}
+	raw, err := runner.Query(ctx, sql, args...)
 	if err == nil {
-		rows = &Rows{SQL: sql, Rows: raw}
+		rows = &Rows{SQL: sql, args: args, Rows: raw, release: release}
+	} else {
+		release()
 	}
 	return
 }
 
 func (p *PgQueryer) QueryRow(ctx context.Context, sql string, args ...interface{}) crud.Row {
+	if data := mockerDataRows("Pool.Query", sql); data != nil {
+		return &Row{SQL: sql, args: args, Row: mockerRow{rows: data}}
+	}
+	ctx, runner, release, err := p.prepare(ctx)
+	if err != nil {
+		return &Row{SQL: sql, args: args, Row: errRow{err: err}}
+	}
 	return &Row{
-		SQL: sql,
-		Row: p.Pool.QueryRow(ctx, sql, args...),
+		SQL:     sql,
+		args:    args,
+		Row:     runner.QueryRow(ctx, sql, args...),
+		release: release,
 	}
 }
 
 func (p *PgQueryer) CrudExec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Pool.Exec", sql); err != nil {
+	if err := mockerCheck("Pool.Exec", sql, args...); err != nil {
 		return 0, 0, err
 	}
 	insertId, affected, err = p.Exec(ctx, sql, args...)
@@ -279,7 +632,7 @@ func (p *PgQueryer) CrudExec(ctx context.Context, sql string, args ...interface{
 }
 
 func (p *PgQueryer) CrudExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Pool.Exec", sql); err != nil {
+	if err := mockerCheck("Pool.Exec", sql, args...); err != nil {
 		return 0, err
 	}
 	insertId, err = p.ExecRow(ctx, sql, args...)
@@ -287,7 +640,7 @@ func (p *PgQueryer) CrudExecRow(ctx context.Context, sql string, args ...interfa
 }
 
 func (p *PgQueryer) CrudQuery(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Pool.Query", sql); err != nil {
+	if err := mockerCheck("Pool.Query", sql, args...); err != nil {
 		return nil, err
 	}
 	rows, err = p.Query(ctx, sql, args...)
@@ -313,16 +666,215 @@ func (p *PgQueryer) SendBatch(ctx context.Context, b *pgx.Batch) *BatchResults {
 }
 
 func (p *PgQueryer) Begin(ctx context.Context) (tx *Tx, err error) {
+	tx, err = p.BeginTx(ctx, pgx.TxOptions{})
+	return
+}
+
+// BeginTx starts a transaction with txOptions, like isolation level or read only
+func (p *PgQueryer) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (tx *Tx, err error) {
 	if err := mockerCheck("Pool.Begin", ""); err != nil {
 		return nil, err
 	}
-	raw, err := p.Pool.Begin(ctx)
+	if !p.IsReady() {
+		return nil, ErrNotReady
+	}
+	raw, err := p.Pool.BeginTx(ctx, txOptions)
 	if err == nil {
 		tx = &Tx{Tx: raw}
 	}
 	return
 }
 
+// PoolStat is the statistics of pool
+type PoolStat struct {
+	AcquireCount         int64         `json:"acquire_count"`
+	AcquireDuration      time.Duration `json:"acquire_duration"`
+	AcquiredConns        int32         `json:"acquired_conns"`
+	CanceledAcquireCount int64         `json:"canceled_acquire_count"`
+	ConstructingConns    int32         `json:"constructing_conns"`
+	EmptyAcquireCount    int64         `json:"empty_acquire_count"`
+	IdleConns            int32         `json:"idle_conns"`
+	MaxConns             int32         `json:"max_conns"`
+	TotalConns           int32         `json:"total_conns"`
+}
+
+// Stat will return the statistics of pool
+func (p *PgQueryer) Stat() (stat PoolStat) {
+	raw := p.Pool.Stat()
+	stat = PoolStat{
+		AcquireCount:         raw.AcquireCount(),
+		AcquireDuration:      raw.AcquireDuration(),
+		AcquiredConns:        raw.AcquiredConns(),
+		CanceledAcquireCount: raw.CanceledAcquireCount(),
+		ConstructingConns:    raw.ConstructingConns(),
+		EmptyAcquireCount:    raw.EmptyAcquireCount(),
+		IdleConns:            raw.IdleConns(),
+		MaxConns:             raw.MaxConns(),
+		TotalConns:           raw.TotalConns(),
+	}
+	return
+}
+
+// Ping will acquire a connection and ping the database
+func (p *PgQueryer) Ping(ctx context.Context) error {
+	if err := mockerCheck("Pool.Ping", ""); err != nil {
+		return err
+	}
+	return p.Pool.Ping(ctx)
+}
+
+// StartStatReporter will report pool statistics on every interval until stop called, the stop is safe to call more than once
+func (p *PgQueryer) StartStatReporter(interval time.Duration, report func(stat PoolStat)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan int)
+	stopped := make(chan int)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				report(p.Stat())
+			}
+		}
+	}()
+	stopOnce := sync.Once{}
+	stop = func() {
+		stopOnce.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+		<-stopped
+	}
+	return
+}
+
+// TxRetryMax is the max attempts of RunInTx, TxRetryDelayMin and TxRetryDelayMax is the backoff range between attempts
+var TxRetryMax = 5
+var TxRetryDelayMin = 10 * time.Millisecond
+var TxRetryDelayMax = time.Second
+
+// TxRetryable will check if the transaction error can be retried, default is serialization failure(40001) or deadlock detected(40P01)
+var TxRetryable = func(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// RunInTx will run fn in transaction with txOptions and commit it, the whole transaction will be retried when TxRetryable return true
+func RunInTx(ctx context.Context, queryer *PgQueryer, txOptions pgx.TxOptions, fn func(tx *Tx) error) (err error) {
+	delay := TxRetryDelayMin
+	for i := 1; ; i++ {
+		err = runInTx(ctx, queryer, txOptions, fn)
+		if err == nil || i >= TxRetryMax || !TxRetryable(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > TxRetryDelayMax {
+			delay = TxRetryDelayMax
+		}
+	}
+	return
+}
+
+func runInTx(ctx context.Context, queryer *PgQueryer, txOptions pgx.TxOptions, fn func(tx *Tx) error) (err error) {
+	tx, err := queryer.BeginTx(ctx, txOptions)
+	if err != nil {
+		return
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback(ctx)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return
+	}
+	err = tx.Commit(ctx)
+	committed = err == nil
+	return
+}
+
+// ListenDelayMin and ListenDelayMax is the backoff range of reconnecting when listen connection is lost
+var ListenDelayMin = 100 * time.Millisecond
+var ListenDelayMax = 10 * time.Second
+
+// Listen will listen channel on dedicated connection and call handler by notification payload, it will reconnect when connection is lost until ctx done or stop called
+func (p *PgQueryer) Listen(ctx context.Context, channel string, handler func(payload string)) (stop func(), err error) {
+	if err = mockerCheck("Pool.Listen", channel); err != nil {
+		return
+	}
+	conn, err := p.listenConn(ctx, channel)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan int)
+	go p.runListen(ctx, conn, channel, handler, done)
+	stop = func() {
+		cancel()
+		<-done
+	}
+	return
+}
+
+func (p *PgQueryer) listenConn(ctx context.Context, channel string) (conn *pgxpool.Conn, err error) {
+	conn, err = p.Pool.Acquire(ctx)
+	if err != nil {
+		return
+	}
+	_, err = conn.Exec(ctx, "listen "+pgx.Identifier{channel}.Sanitize())
+	if err != nil {
+		conn.Release()
+		conn = nil
+	}
+	return
+}
+
+func (p *PgQueryer) runListen(ctx context.Context, conn *pgxpool.Conn, channel string, handler func(payload string), done chan int) {
+	defer close(done)
+	delay := ListenDelayMin
+	for {
+		if conn == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if conn, _ = p.listenConn(ctx, channel); conn == nil {
+				if delay *= 2; delay > ListenDelayMax {
+					delay = ListenDelayMax
+				}
+				continue
+			}
+			delay = ListenDelayMin
+		}
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err == nil {
+			handler(notification.Payload)
+			continue
+		}
+		//the connection is listening, so close it before release to pool
+		conn.Conn().Close(context.Background())
+		conn.Release()
+		conn = nil
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// Notify will send notification with payload to channel
+func (p *PgQueryer) Notify(ctx context.Context, channel, payload string) (err error) {
+	_, _, err = p.Exec(ctx, "select pg_notify($1,$2)", channel, payload)
+	return
+}
+
 func Exec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
 	return Shared.Exec(ctx, sql, args...)
 }
@@ -342,3 +894,109 @@ func Query(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows
 func Begin(ctx context.Context) (tx *Tx, err error) {
 	return Shared.Begin(ctx)
 }
+
+func Ping(ctx context.Context) error {
+	return Shared.Ping(ctx)
+}
+
+func WaitReady(ctx context.Context) error {
+	return Shared.WaitReady(ctx)
+}
+
+func BeginTx(ctx context.Context, txOptions pgx.TxOptions) (tx *Tx, err error) {
+	return Shared.BeginTx(ctx, txOptions)
+}
+
+func Listen(ctx context.Context, channel string, handler func(payload string)) (stop func(), err error) {
+	return Shared.Listen(ctx, channel, handler)
+}
+
+func Notify(ctx context.Context, channel, payload string) (err error) {
+	return Shared.Notify(ctx, channel, payload)
+}
+
+type CopyFromQueryer interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// CopyFromStructs will copy struct slice to table by crud filter, all fields in filter is copied whatever it is nil or zero
+func CopyFromStructs(ctx context.Context, queryer CopyFromQueryer, slice interface{}, filter string) (n int64, err error) {
+	sliceValue := reflect.Indirect(reflect.ValueOf(slice))
+	if sliceValue.Kind() != reflect.Slice {
+		err = fmt.Errorf("slice %v is not supported", reflect.TypeOf(slice))
+		return
+	}
+	if sliceValue.Len() < 1 {
+		return
+	}
+	filter = copyFilter(filter)
+	table, columns, _, _ := crud.InsertArgs(sliceValue.Index(0).Interface(), filter, nil)
+	if len(table) < 1 || len(columns) < 1 {
+		err = fmt.Errorf("table or columns is not found on %v by %v", sliceValue.Index(0).Type(), filter)
+		return
+	}
+	source := pgx.CopyFromSlice(sliceValue.Len(), func(i int) (row []interface{}, err error) {
+		_, _, _, row = crud.InsertArgs(sliceValue.Index(i).Interface(), filter, nil)
+		if len(row) != len(columns) {
+			err = fmt.Errorf("slice[%v] fields count %v is not equal to columns %v", i, len(row), len(columns))
+		}
+		return
+	})
+	n, err = queryer.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, source)
+	return
+}
+
+func copyFilter(filter string) string {
+	parts := strings.Split(filter, "|")
+	for i, part := range parts {
+		parts[i] = strings.SplitN(part, "#", 2)[0] + "#all"
+	}
+	return strings.Join(parts, "|")
+}
+
+type BatchQueryer interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) *BatchResults
+}
+
+// RunBatch will send all stmts in one batch and check exec result of each stmt
+func RunBatch(ctx context.Context, queryer BatchQueryer, stmts []crud.Stmt) (err error) {
+	if len(stmts) < 1 {
+		return
+	}
+	batch := &pgx.Batch{}
+	for _, stmt := range stmts {
+		batch.Queue(stmt.SQL, stmt.Args...)
+	}
+	results := queryer.SendBatch(ctx, batch)
+	for i := range stmts {
+		if _, xerr := results.Exec(); xerr != nil {
+			err = fmt.Errorf("batch stmt[%v] fail with %w", i, xerr)
+			break
+		}
+	}
+	if xerr := results.Close(); err == nil {
+		err = xerr
+	}
+	return
+}
+
+// BatchInsertFilter will insert struct slice in one batch by crud filter
+func BatchInsertFilter(ctx context.Context, queryer BatchQueryer, slice interface{}, filter string) (err error) {
+	sliceValue := reflect.Indirect(reflect.ValueOf(slice))
+	if sliceValue.Kind() != reflect.Slice {
+		err = fmt.Errorf("slice %v is not supported", reflect.TypeOf(slice))
+		return
+	}
+	stmts := []crud.Stmt{}
+	for i := 0; i < sliceValue.Len(); i++ {
+		var sql string
+		var args []interface{}
+		sql, args, err = crud.InsertSQL(sliceValue.Index(i).Interface(), filter)
+		if err != nil {
+			return
+		}
+		stmts = append(stmts, crud.Stmt{SQL: sql, Args: args})
+	}
+	err = RunBatch(ctx, queryer, stmts)
+	return
+}
@@ -0,0 +1,150 @@
+package pgx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/codingeasygo/crud"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ReplicaQueryer is the queryer to run Query/QueryRow on replica and Exec/Begin on primary
+type ReplicaQueryer struct {
+	Primary   *PgQueryer
+	Replica   *PgQueryer
+	Cooldown  time.Duration
+	downUntil int64
+}
+
+// NewReplicaQueryer will return the read replica aware queryer, the query is run on primary when ctx is marked by crud.ForcePrimary or replica is down
+func NewReplicaQueryer(primary, replica *pgxpool.Pool) (queryer *ReplicaQueryer) {
+	queryer = &ReplicaQueryer{
+		Primary:  NewPgQueryer(primary),
+		Replica:  NewPgQueryer(replica),
+		Cooldown: 10 * time.Second,
+	}
+	return
+}
+
+func (r *ReplicaQueryer) useReplica(ctx context.Context) bool {
+	return !crud.IsForcePrimary(ctx) && time.Now().UnixNano() >= atomic.LoadInt64(&r.downUntil)
+}
+
+// checkReplica will ping replica after query fail, the replica is marked down in Cooldown if ping fail
+func (r *ReplicaQueryer) checkReplica(ctx context.Context) (down bool) {
+	err := mockerCheck("Replica.Ping", "")
+	if err == nil {
+		err = r.Replica.Pool.Ping(ctx)
+	}
+	if err != nil {
+		atomic.StoreInt64(&r.downUntil, time.Now().Add(r.Cooldown).UnixNano())
+		down = true
+	}
+	return
+}
+
+func (r *ReplicaQueryer) Exec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
+	if err := mockerCheck("Primary.Exec", sql, args...); err != nil {
+		return 0, 0, err
+	}
+	insertId, affected, err = r.Primary.Exec(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) ExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
+	if err := mockerCheck("Primary.Exec", sql, args...); err != nil {
+		return 0, err
+	}
+	insertId, err = r.Primary.ExecRow(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) queryPrimary(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
+	if err := mockerCheck("Primary.Query", sql, args...); err != nil {
+		return nil, err
+	}
+	rows, err = r.Primary.Query(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) Query(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
+	if !r.useReplica(ctx) {
+		rows, err = r.queryPrimary(ctx, sql, args...)
+		return
+	}
+	if err = mockerCheck("Replica.Query", sql, args...); err == nil {
+		rows, err = r.Replica.Query(ctx, sql, args...)
+	}
+	if err != nil && r.checkReplica(ctx) {
+		rows, err = r.queryPrimary(ctx, sql, args...)
+	}
+	return
+}
+
+func (r *ReplicaQueryer) QueryRow(ctx context.Context, sql string, args ...interface{}) crud.Row {
+	return &replicaRow{
+		ctx:     ctx,
+		sql:     sql,
+		args:    args,
+		queryer: r,
+		primary: !r.useReplica(ctx),
+	}
+}
+
+func (r *ReplicaQueryer) CrudExec(ctx context.Context, sql string, args ...interface{}) (insertId, affected int64, err error) {
+	insertId, affected, err = r.Exec(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) CrudExecRow(ctx context.Context, sql string, args ...interface{}) (insertId int64, err error) {
+	insertId, err = r.ExecRow(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) CrudQuery(ctx context.Context, sql string, args ...interface{}) (rows crud.Rows, err error) {
+	rows, err = r.Query(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) CrudQueryRow(ctx context.Context, sql string, args ...interface{}) (row crud.Row) {
+	row = r.QueryRow(ctx, sql, args...)
+	return
+}
+
+func (r *ReplicaQueryer) Begin(ctx context.Context) (tx *Tx, err error) {
+	tx, err = r.BeginTx(ctx, pgx.TxOptions{})
+	return
+}
+
+func (r *ReplicaQueryer) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (tx *Tx, err error) {
+	if err := mockerCheck("Primary.Begin", ""); err != nil {
+		return nil, err
+	}
+	tx, err = r.Primary.BeginTx(ctx, txOptions)
+	return
+}
+
+type replicaRow struct {
+	ctx     context.Context
+	sql     string
+	args    []interface{}
+	queryer *ReplicaQueryer
+	primary bool
+}
+
+func (r *replicaRow) Scan(dest ...interface{}) (err error) {
+	if !r.primary {
+		if err = mockerCheck("Replica.Query", r.sql, r.args...); err == nil {
+			err = r.queryer.Replica.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+		}
+		if err == nil || err == pgx.ErrNoRows || !r.queryer.checkReplica(r.ctx) {
+			return
+		}
+	}
+	if err = mockerCheck("Primary.Query", r.sql, r.args...); err == nil {
+		err = r.queryer.Primary.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...)
+	}
+	return
+}
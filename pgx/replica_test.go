@@ -0,0 +1,123 @@
+package pgx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/codingeasygo/crud"
+	"github.com/codingeasygo/crud/mocker"
+)
+
+func TestReplicaQueryer(t *testing.T) {
+	var _ crud.Queryer = &ReplicaQueryer{}
+	var _ crud.CrudQueryer = &ReplicaQueryer{}
+	queryer := NewReplicaQueryer(Pool().Pool, Pool().Pool)
+	MockerStart()
+	defer MockerStop()
+	runned := mocker.Runned
+	var value int64
+	//route
+	if _, _, err := queryer.Exec(context.Background(), "select 1"); err != nil || runned("Primary.Exec") != 1 {
+		t.Error(err)
+		return
+	}
+	if _, err := queryer.CrudExecRow(context.Background(), "update crud_object set status=0 where 1=0"); err != ErrNoRows || runned("Primary.Exec") != 2 {
+		t.Error(err)
+		return
+	}
+	rows, err := queryer.CrudQuery(context.Background(), "select 1")
+	if err != nil || runned("Replica.Query") != 1 || runned("Primary.Query") != 0 {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	if err = queryer.CrudQueryRow(context.Background(), "select 1").Scan(&value); err != nil || value != 1 || runned("Replica.Query") != 2 {
+		t.Error(err)
+		return
+	}
+	if err = queryer.QueryRow(context.Background(), "select 1 where 1=0").Scan(&value); err != ErrNoRows || runned("Primary.Query") != 0 {
+		t.Error(err)
+		return
+	}
+	tx, err := queryer.Begin(context.Background())
+	if err != nil || runned("Primary.Begin") != 1 {
+		t.Error(err)
+		return
+	}
+	tx.Rollback(context.Background())
+	//force primary
+	primaryCtx := crud.ForcePrimary(context.Background())
+	rows, err = queryer.Query(primaryCtx, "select 1")
+	if err != nil || runned("Primary.Query") != 1 {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	if err = queryer.QueryRow(primaryCtx, "select 1").Scan(&value); err != nil || runned("Primary.Query") != 2 {
+		t.Error(err)
+		return
+	}
+	//replica fail but alive
+	MockerSet("Replica.Query", -1)
+	if _, err = queryer.Query(context.Background(), "select 1"); err != ErrMock || runned("Primary.Query") != 2 {
+		t.Error(err)
+		return
+	}
+	if err = queryer.QueryRow(context.Background(), "select 1").Scan(&value); err != ErrMock || runned("Primary.Query") != 2 {
+		t.Error(err)
+		return
+	}
+	//replica down
+	MockerSet("Replica.Ping", -1)
+	rows, err = queryer.Query(context.Background(), "select 1")
+	if err != nil || runned("Primary.Query") != 3 {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	replicaRunned := runned("Replica.Query")
+	if err = queryer.QueryRow(context.Background(), "select 1").Scan(&value); err != nil || runned("Primary.Query") != 4 || runned("Replica.Query") != replicaRunned {
+		t.Error(err)
+		return
+	}
+	//cooldown
+	MockerClear()
+	queryer.Cooldown = 0
+	queryer.downUntil = 0
+	MockerSet("Replica.Query", 1)
+	MockerSet("Replica.Ping", 1)
+	if err = queryer.QueryRow(context.Background(), "select 1").Scan(&value); err != nil || runned("Primary.Query") != 1 {
+		t.Error(err)
+		return
+	}
+	time.Sleep(time.Millisecond)
+	if err = queryer.QueryRow(context.Background(), "select 1").Scan(&value); err != nil || runned("Replica.Query") != 2 || runned("Primary.Query") != 1 {
+		t.Error(err)
+		return
+	}
+	//mock error
+	MockerSet("Primary.Exec", -1)
+	MockerSet("Primary.Begin", -1)
+	if _, _, err = queryer.CrudExec(context.Background(), "select 1"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if _, err = queryer.ExecRow(context.Background(), "select 1"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if _, err = queryer.Begin(context.Background()); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	MockerSet("Primary.Query", -1)
+	if _, err = queryer.Query(primaryCtx, "select 1"); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	if err = queryer.QueryRow(primaryCtx, "select 1").Scan(&value); err != ErrMock {
+		t.Error(err)
+		return
+	}
+}
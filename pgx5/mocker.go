@@ -0,0 +1,176 @@
+package pgx5
+
+import (
+	"testing"
+
+	"github.com/codingeasygo/crud/mocker"
+	"github.com/codingeasygo/util/xhttp"
+)
+
+var ErrMock = mocker.ErrMock
+var Verbose = false
+var Client = xhttp.Shared
+
+// MockerCaller is the alias of mocker.Caller
+type MockerCaller = mocker.Caller
+
+// Outcome is the alias of mocker.Outcome, it is used by MockerSequence
+type Outcome = mocker.Outcome
+
+var OutcomeOk = mocker.OutcomeOk
+var OutcomePanic = mocker.OutcomePanic
+
+func OutcomeErr(err error) Outcome {
+	return mocker.OutcomeErr(err)
+}
+
+func mockerCheck(key, sql string, args ...interface{}) (err error) {
+	err = mocker.CheckVerbose(key, sql, Verbose, args...)
+	return
+}
+
+// MockerCheck will check key like driver queryer, it is used by custom queryer to participate in the mocker
+func MockerCheck(key, sql string) error {
+	return mockerCheck(key, sql)
+}
+
+// MockerLogTo will set the verbose log func like t.Logf, the log is printed to stdout when logf is nil
+func MockerLogTo(logf func(format string, args ...interface{})) {
+	mocker.LogTo(logf)
+}
+
+func MockerStart() {
+	mocker.Start()
+}
+
+func MockerStop() {
+	mocker.Stop()
+}
+
+// MockerTest will start mocker for t and restore the mocker state when t is done, it is not supported in parallel tests
+func MockerTest(t *testing.T) {
+	t.Helper()
+	mocker.Test(t)
+}
+
+func MockerClear() {
+	mocker.Clear()
+}
+
+// MockerSet will make key return ErrMock when runned times is in triggers range, the range is [from, to] and to < 0 means from the from-th onward
+func MockerSet(key string, triggers ...int) {
+	mocker.Set(key, triggers...)
+}
+
+func MockerPanic(key string, triggers ...int) {
+	mocker.Panic(key, triggers...)
+}
+
+func MockerMatchSet(key, match string) {
+	mocker.MatchSet(key, match)
+}
+
+func MockerMatchPanic(key, match string) {
+	mocker.MatchPanic(key, match)
+}
+
+// MockerMatchArgs will make key return ErrMock when predicate return true by sql and args
+func MockerMatchArgs(key string, predicate func(sql string, args []interface{}) bool) {
+	mocker.MatchArgs(key, predicate)
+}
+
+// MockerSequence will make the next checks of key return outcomes in order, the outcomes is cleared by MockerClear
+func MockerSequence(key string, outcomes ...Outcome) {
+	mocker.Sequence(key, outcomes...)
+}
+
+// MockerSequenceDone will fail t when any outcome set by MockerSequence is not consumed
+func MockerSequenceDone(t *testing.T) bool {
+	t.Helper()
+	return mocker.SequenceDone(t)
+}
+
+// MockerRecord will enable/disable recording every key and sql seen by mocker, the records is cleared by MockerClear
+func MockerRecord(on bool) {
+	mocker.Record(on)
+}
+
+// MockerCalls will return the recorded sql of key by called order
+func MockerCalls(key string) []string {
+	return mocker.Calls(key)
+}
+
+// MockerCallCount will return the number of recorded calls which key and sql is matched
+func MockerCallCount(keyRegexp, sqlRegexp string) int {
+	return mocker.CallCount(keyRegexp, sqlRegexp)
+}
+
+// MockerAssertCalled will fail t when key with sql matched is not called by times
+func MockerAssertCalled(t *testing.T, key, sqlRegexp string, times int) bool {
+	t.Helper()
+	return mocker.AssertCalled(t, key, sqlRegexp, times)
+}
+
+// MockerSetError will make key return err instead of ErrMock when runned times is trigger
+func MockerSetError(key string, trigger int, err error) {
+	mocker.SetError(key, trigger, err)
+}
+
+// MockerMatchError will make key return err instead of ErrMock when sql is matched
+func MockerMatchError(key, match string, err error) {
+	mocker.MatchError(key, match, err)
+}
+
+func NewMockerCaller() (caller *MockerCaller) {
+	caller = mocker.NewCaller()
+	caller.Client = Client
+	return
+}
+
+func Should(t *testing.T, args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.Should(t, args...)
+	caller.Client = Client
+	return
+}
+
+func ShouldError(t *testing.T) (caller *MockerCaller) {
+	caller = mocker.ShouldError(t)
+	caller.Client = Client
+	return
+}
+
+func MockerSetCall(args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.SetCall(args...)
+	caller.Client = Client
+	return
+}
+
+func MockerPanicCall(args ...interface{}) (caller *MockerCaller) {
+	caller = mocker.PanicCall(args...)
+	caller.Client = Client
+	return
+}
+
+func MockerMatchSetCall(key, match string) (caller *MockerCaller) {
+	caller = mocker.MatchSetCall(key, match)
+	caller.Client = Client
+	return
+}
+
+func MockerMatchPanicCall(key, match string) (caller *MockerCaller) {
+	caller = mocker.MatchPanicCall(key, match)
+	caller.Client = Client
+	return
+}
+
+func MockerSetRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.SetRangeCall(key, start, end, to...)
+	caller.Client = Client
+	return
+}
+
+func MockerPanicRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.PanicRangeCall(key, start, end, to...)
+	caller.Client = Client
+	return
+}
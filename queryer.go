@@ -3,10 +3,24 @@ package crud
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
 )
 
 var ErrNoRows = sql.ErrNoRows
 
+// NoRowsErrors is the driver no rows errors which is converted to CRUD.ErrNoRows by FindWheref/FindFilter,
+// the driver package which is not wrapping sql.ErrNoRows should append its error on init
+var NoRowsErrors = []error{sql.ErrNoRows}
+
+// ErrTooManyParams is the error when the params count of one statement is over CRUD.MaxParams
+var ErrTooManyParams = fmt.Errorf("too many params")
+
+// ErrNoFields is the error when no fields is matched by filter to insert or update
+var ErrNoFields = fmt.Errorf("no fields")
+
 type Scanner interface {
 	Scan(v interface{})
 }
@@ -17,6 +31,26 @@ type Rows interface {
 	Close() error
 }
 
+// RowsErr is the optional interface of Rows to return the error encountered during iteration
+type RowsErr interface {
+	Err() error
+}
+
+// RowsColumns is the optional interface of Rows to return the column names
+type RowsColumns interface {
+	Columns() ([]string, error)
+}
+
+// Columns will return the column names of rows, it return error if rows is not implement RowsColumns
+func Columns(rows Rows) (columns []string, err error) {
+	if c, ok := rows.(RowsColumns); ok {
+		columns, err = c.Columns()
+	} else {
+		err = fmt.Errorf("rows %v is not supported columns", reflect.TypeOf(rows))
+	}
+	return
+}
+
 type Row interface {
 	Scan(dest ...interface{}) (err error)
 }
@@ -28,9 +62,114 @@ type Queryer interface {
 	QueryRow(ctx context.Context, query string, args ...interface{}) (row Row)
 }
 
+// errRow is the Row to return err on Scan
+type errRow struct {
+	err error
+}
+
+func (e *errRow) Scan(dest ...interface{}) (err error) {
+	err = e.err
+	return
+}
+
 type CrudQueryer interface {
 	CrudExec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error)
 	CrudExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error)
 	CrudQuery(ctx context.Context, query string, args ...interface{}) (rows Rows, err error)
 	CrudQueryRow(ctx context.Context, query string, args ...interface{}) (row Row)
 }
+
+type forcePrimaryKey struct{}
+
+// ForcePrimary will mark ctx to run query on primary database, it is used by replica aware queryer
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+// IsForcePrimary will return if ctx is marked by ForcePrimary
+func IsForcePrimary(ctx context.Context) bool {
+	force, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return force
+}
+
+// DefaultCaptureMax is the default max records count of SQLRecorder
+var DefaultCaptureMax = 64
+
+// CapturedSQL is the one sql record captured by SQLRecorder
+type CapturedSQL struct {
+	Op   string // the exec/query/queryRow
+	SQL  string
+	Args []interface{}
+	Took time.Duration // the time of queryer call, it is not including rows iteration on query
+	Err  error
+}
+
+// SQLRecorder is the recorder to capture sql executed on ctx which is returned by CaptureSQL
+type SQLRecorder struct {
+	max     int // the max records count, the records after max is dropped but Slowest is still updated, zero is not limited
+	dropped int
+	records []*CapturedSQL
+	slowest *CapturedSQL
+	lock    sync.RWMutex
+}
+
+func (s *SQLRecorder) record(op, sql string, args []interface{}, took time.Duration, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	captured := &CapturedSQL{Op: op, SQL: sql, Args: args, Took: took, Err: err}
+	if s.slowest == nil || took > s.slowest.Took {
+		s.slowest = captured
+	}
+	if s.max > 0 && len(s.records) >= s.max {
+		s.dropped++
+		return
+	}
+	s.records = append(s.records, captured)
+}
+
+// SetMax will set the max records count, the records after max is dropped but Slowest is still updated, zero is not limited
+func (s *SQLRecorder) SetMax(max int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.max = max
+}
+
+// Dropped will return the count of records dropped by max
+func (s *SQLRecorder) Dropped() (dropped int) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	dropped = s.dropped
+	return
+}
+
+// Records will return the captured records in executed order
+func (s *SQLRecorder) Records() (records []*CapturedSQL) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	records = append(records, s.records...)
+	return
+}
+
+// Slowest will return the slowest captured record, it return nil if nothing is captured
+func (s *SQLRecorder) Slowest() (slowest *CapturedSQL) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	slowest = s.slowest
+	return
+}
+
+type captureSQLKey struct{}
+
+// CaptureSQL will return ctx with SQLRecorder, all sql executed by CRUD on ctx is recorded to it
+func CaptureSQL(ctx context.Context) (context.Context, *SQLRecorder) {
+	recorder := &SQLRecorder{max: DefaultCaptureMax}
+	return context.WithValue(ctx, captureSQLKey{}, recorder), recorder
+}
+
+// capturedSQL will return the SQLRecorder on ctx, it return nil if ctx is not captured
+func capturedSQL(ctx context.Context) (recorder *SQLRecorder) {
+	if ctx != nil {
+		recorder, _ = ctx.Value(captureSQLKey{}).(*SQLRecorder)
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package crud
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+func reservedWords(words string) (reserved map[string]bool) {
+	reserved = map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		reserved[word] = true
+	}
+	return
+}
+
+// ReservedPG is the reserved words of postgres which must be quoted when used as identifier
+var ReservedPG = reservedWords(`
+	all analyse analyze and any array as asc asymmetric authorization binary both case cast check collate collation
+	column concurrently constraint create cross current_catalog current_date current_role current_schema current_time
+	current_timestamp current_user default deferrable desc distinct do else end except false fetch for foreign freeze
+	from full grant group having ilike in initially inner intersect into is isnull join lateral leading left like limit
+	localtime localtimestamp natural not notnull null offset on only or order outer overlaps placing primary references
+	returning right select session_user similar some symmetric table tablesample then to trailing true union unique user
+	using variadic verbose when where window with
+`)
+
+// ReservedMySQL is the reserved words of mysql which must be quoted when used as identifier
+var ReservedMySQL = reservedWords(`
+	accessible add all alter analyze and as asc asensitive before between bigint binary blob both by call cascade case
+	change char character check collate column condition constraint continue convert create cross cube cume_dist
+	current_date current_time current_timestamp current_user cursor database databases day_hour day_microsecond
+	day_minute day_second dec decimal declare default delayed delete dense_rank desc describe deterministic distinct
+	distinctrow div double drop dual each else elseif empty enclosed escaped except exists exit explain false fetch
+	first_value float for force foreign from fulltext function generated get grant group grouping groups having
+	high_priority hour_microsecond hour_minute hour_second if ignore in index infile inner inout insensitive insert int
+	integer intersect interval into is iterate join json_table key keys kill lag last_value lateral lead leading leave
+	left like limit linear lines load localtime localtimestamp lock long loop low_priority match maxvalue mediumint
+	minute_microsecond minute_second mod modifies natural not no_write_to_binlog nth_value ntile null numeric of on
+	optimize option optionally or order out outer outfile over partition percent_rank precision primary procedure purge
+	range rank read reads real recursive references regexp release rename repeat replace require resignal restrict
+	return revoke right rlike row row_number rows schema schemas select sensitive separator set show signal smallint
+	spatial specific sql sqlexception sqlstate sqlwarning starting stored straight_join system table terminated then
+	to trailing trigger true undo union unique unlock unsigned update usage use using utc_date utc_time utc_timestamp
+	values varbinary varchar varying virtual when where while window with write xor year_month zerofill
+`)
+
+// ReservedSQLite is the reserved words of sqlite which must be quoted when used as identifier
+var ReservedSQLite = reservedWords(`
+	abort action add after all alter always analyze and as asc attach autoincrement before begin between by cascade
+	case cast check collate column commit conflict constraint create cross current current_date current_time
+	current_timestamp database default deferrable deferred delete desc detach distinct do drop each else end escape
+	except exclude exclusive exists explain fail filter first following for foreign from full generated glob group
+	groups having if ignore immediate in index indexed initially inner insert instead intersect into is isnull join key
+	last left like limit match materialized natural no not nothing notnull null nulls of offset on or order others outer
+	over partition plan pragma preceding primary query raise range recursive references regexp reindex release rename
+	replace restrict returning right rollback row rows savepoint select set table temp temporary then ties to
+	transaction trigger unbounded union unique update using vacuum values view virtual when where window with without
+`)
+
+// identExprChars is the chars which is not in bare identifier, the name having them is expression like max(tid) or table with alias
+const identExprChars = " \t\r\n()*+-/%<>=!~^&|@#?,;:'[]"
+
+// QuoteIdent will quote identifier name when CRUD.QuoteIdents is true and name is reserved word or having upper case/special char,
+// the name like alias.name is quoted by each part and the quoted part is returned directly,
+// the name having whitespace, parentheses, * or operators like crud_object o, count(*) is not identifier and returned directly
+func (c *CRUD) QuoteIdent(name string) string {
+	if !c.QuoteIdents || len(name) < 1 || strings.ContainsAny(name, identExprChars) {
+		return name
+	}
+	quote := c.IdentQuote
+	if len(quote) < 1 {
+		quote = `"`
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if c.identNeedQuote(part, quote) {
+			parts[i] = quote + strings.ReplaceAll(part, quote, quote+quote) + quote
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (c *CRUD) identNeedQuote(part, quote string) bool {
+	if len(part) < 1 || strings.HasPrefix(part, quote) {
+		return false
+	}
+	reserved := c.ReservedWords
+	if reserved == nil {
+		reserved = ReservedPG
+	}
+	if reserved[part] {
+		return true
+	}
+	for i, r := range part {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9' && i > 0) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+var jsonPathRegexp = regexp.MustCompile(`->>?`)
+var jsonKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// fieldIdent will return the field identifier used in sql, the field having jsonpath tag like attrs->>city or
+// the field name like attrs->>city is rendered to postgres json path attrs->>'city', it will panic if dialect is not postgres
+func (c *CRUD) fieldIdent(fieldName string, field reflect.StructField) string {
+	if path := field.Tag.Get("jsonpath"); len(path) > 0 {
+		fieldName = fieldName[:strings.LastIndex(fieldName, ".")+1] + path
+	}
+	if !strings.Contains(fieldName, "->") {
+		return c.QuoteIdent(fieldName)
+	}
+	if len(c.Dialect) > 0 && c.Dialect != "postgres" {
+		panic(fmt.Sprintf("json path %v is not supported on %v", fieldName, c.Dialect))
+	}
+	keys := jsonPathRegexp.Split(fieldName, -1)
+	arrows := jsonPathRegexp.FindAllString(fieldName, -1)
+	ident := c.QuoteIdent(keys[0])
+	for i, key := range keys[1:] {
+		if !jsonKeyRegexp.MatchString(key) {
+			panic(fmt.Sprintf("json path %v key %v is invalid", fieldName, key))
+		}
+		ident += arrows[i] + "'" + key + "'"
+	}
+	return ident
+}
+
+// jsonPathKey will return the last key of json path field name like attrs->>city, it return empty if not json path
+func jsonPathKey(fieldName string) string {
+	keys := jsonPathRegexp.Split(fieldName, -1)
+	if len(keys) < 2 {
+		return ""
+	}
+	return keys[len(keys)-1]
+}
@@ -0,0 +1,183 @@
+package crud
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codingeasygo/util/xsql"
+)
+
+type quoteOrder struct {
+	T        string `table:"order"`
+	TID      int64  `json:"tid"`
+	Group    string `json:"group"`
+	UserName string `json:"userName"`
+}
+
+func TestQuoteIdent(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	order := &quoteOrder{TID: 1, Group: "a", UserName: "b"}
+	//not quote by default
+	if table := c.Table(order); table != "order" {
+		t.Error(table)
+		return
+	}
+	c.QuoteIdents = true
+	for name, quoted := range map[string]string{
+		"order":         `"order"`,
+		"o.group":       `o."group"`,
+		"userName":      `"userName"`,
+		"user_name":     "user_name",
+		"1x":            `"1x"`,
+		"a-b":           "a-b",
+		`"order"`:       `"order"`,
+		"o.*":           "o.*",
+		`we"ird`:        `"we""ird"`,
+		"crud_object":   "crud_object",
+		"public.select": `public."select"`,
+		"crud_object o": "crud_object o",
+		"Order o":       "Order o",
+		"max(tid)":      "max(tid)",
+		"count(*)":      "count(*)",
+		"tid::text":     "tid::text",
+	} {
+		if v := c.QuoteIdent(name); v != quoted {
+			t.Errorf("%v=>%v", name, v)
+			return
+		}
+	}
+	if table := c.Table(order); table != `"order"` {
+		t.Error(table)
+		return
+	}
+	c.TablePrefix = "t_"
+	if table := c.Table(order); table != "t_order" {
+		t.Error(table)
+		return
+	}
+	c.TablePrefix = ""
+	stmt, _ := c.BuildInsert(order, "tid,group,userName")
+	if stmt.SQL != `insert into "order"(tid,"group","userName") values($1,$2,$3)` {
+		t.Error(stmt.SQL)
+		return
+	}
+	stmt, _ = c.BuildUpdateWheref(order, "group,userName", "tid=$%v", order.TID)
+	if stmt.SQL != `update "order" set "group"=$1,"userName"=$2 where tid=$3` {
+		t.Error(stmt.SQL)
+		return
+	}
+	queryer := &testRecordQueryer{}
+	var groups []string
+	err := c.QueryFilter(queryer, context.Background(), &quoteOrder{}, "o.tid,group,userName#all", []string{"o.group=$1"}, "and", []interface{}{"a"}, "", 0, 0, &groups, "group")
+	if err != nil || queryer.sqls[0] != `select o.tid,o."group",o."userName" from "order" o where o.group=$1` {
+		t.Errorf("%v,%v", err, queryer.sqls)
+		return
+	}
+	where, _ := c.FilterWhere(nil, &quoteOrder{Group: "a"}, "group")
+	if len(where) != 1 || where[0] != `"group" = $1` {
+		t.Error(where)
+		return
+	}
+	//alias table and function field
+	meta := MetaWith("crud_object o", Int64Field("max(tid)"), Int64Field("count(*)"), StringField("Title"))
+	if table := c.Table(meta); table != "crud_object o" {
+		t.Error(table)
+		return
+	}
+	table, fields := c.QueryField(meta, "#all")
+	if table != "crud_object o" || strings.Join(fields, ",") != `max(tid),count(*),"Title"` {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	c.IdentQuote = "`"
+	c.ReservedWords = ReservedMySQL
+	if v := c.QuoteIdent("key"); v != "`key`" {
+		t.Error(v)
+		return
+	}
+	if v := c.QuoteIdent("`key`"); v != "`key`" {
+		t.Error(v)
+		return
+	}
+	c.ReservedWords = ReservedSQLite
+	if v := c.QuoteIdent("pragma"); v != "`pragma`" {
+		t.Error(v)
+		return
+	}
+}
+
+type jsonPathObject struct {
+	T        string `table:"crud_object"`
+	TID      int64  `json:"tid"`
+	MapValue xsql.M `json:"map_value"`
+	City     string `json:"city" jsonpath:"map_value->>city"`
+	Zip      string `json:"zip" jsonpath:"map_value->addr->>zip"`
+}
+
+func TestJSONPath(t *testing.T) {
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	table, fields := c.QueryField(&jsonPathObject{}, "o.tid,city,zip#all")
+	if table != "crud_object o" || strings.Join(fields, ",") != "o.tid,o.map_value->>'city',o.map_value->'addr'->>'zip'" {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	table, fields = c.QueryField(MetaWith("crud_object", StringField("map_value->>city")), "#all")
+	if table != "crud_object" || strings.Join(fields, ",") != "map_value->>'city'" {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	table, fields = c.QueryField(MetaWith("crud_object", ""), "max(map_value->>city)")
+	if strings.Join(fields, ",") != "max(map_value->>'city')" {
+		t.Errorf("%v,%v", table, fields)
+		return
+	}
+	where, args := c.FilterWhere(nil, &jsonPathObject{City: "x"}, "city")
+	if len(where) != 1 || where[0] != "map_value->>'city' = $1" || len(args) != 1 {
+		t.Errorf("%v,%v", where, args)
+		return
+	}
+	stmt, _ := c.BuildInsert(&jsonPathObject{TID: 1, City: "x"}, "tid,city")
+	if stmt.SQL != "insert into crud_object(tid) values($1)" {
+		t.Error(stmt.SQL)
+		return
+	}
+	stmt, _ = c.BuildUpdateWheref(&jsonPathObject{TID: 1, City: "x"}, "tid,city", "")
+	if stmt.SQL != "update crud_object set tid=$1" {
+		t.Error(stmt.SQL)
+		return
+	}
+	var cities []string
+	meta := MetaWith("crud_object", StringField("map_value->>city"), Int64Field("tid"))
+	if err := c.Scan(&testValueRows{rows: 2}, meta, "", &cities, "city"); err != nil || len(cities) != 2 {
+		t.Errorf("%v,%v", err, cities)
+		return
+	}
+	for _, name := range []string{"map_value->>'city'", "map_value->>ci ty", "map_value->>"} {
+		func() {
+			defer func() {
+				if perr := recover(); perr == nil {
+					t.Errorf("%v not panic", name)
+				}
+			}()
+			c.QueryField(MetaWith("crud_object", StringField(name)), "")
+		}()
+	}
+	c.Dialect = "mysql"
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil {
+				t.Error("not panic")
+			}
+		}()
+		c.QueryField(&jsonPathObject{}, "city#all")
+	}()
+	if _, fields = c.QueryField(&jsonPathObject{}, "tid#all"); strings.Join(fields, ",") != "tid" {
+		t.Error(fields)
+		return
+	}
+}
@@ -0,0 +1,102 @@
+package sqlx
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+type sqlStater interface {
+	SQLState() string
+}
+
+type fieldGetter interface {
+	Get(k byte) string
+}
+
+type codeGetter interface {
+	Code() int
+}
+
+var mysqlKeyRegexp = regexp.MustCompile(`for key '([^']+)'`)
+var sqliteKeyRegexp = regexp.MustCompile(`(?:UNIQUE|PRIMARY KEY) constraint failed: (.+)$`)
+
+// errorField will find the exported field value by name on err and all wrapped errors
+func errorField(err error, name string) (value reflect.Value) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		errValue := reflect.Indirect(reflect.ValueOf(err))
+		if errValue.Kind() != reflect.Struct {
+			continue
+		}
+		if value = errValue.FieldByName(name); value.IsValid() {
+			return
+		}
+	}
+	return
+}
+
+func errorFieldInt(err error, name string) int64 {
+	value := errorField(err, name)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(value.Uint())
+	}
+	return 0
+}
+
+// IsDuplicate will check if err is unique violation error, it supports lib/pq and pgx(23505), mysql(1062) and sqlite(constraint unique/primary key)
+func IsDuplicate(err error) bool {
+	if err == nil {
+		return false
+	}
+	var stater sqlStater
+	if errors.As(err, &stater) && stater.SQLState() == "23505" {
+		return true
+	}
+	var getter fieldGetter
+	if errors.As(err, &getter) && getter.Get('C') == "23505" {
+		return true
+	}
+	var coder codeGetter
+	if errors.As(err, &coder) && (coder.Code() == 2067 || coder.Code() == 1555) {
+		return true
+	}
+	if errorFieldInt(err, "Number") == 1062 {
+		return true
+	}
+	if code := errorFieldInt(err, "ExtendedCode"); code == 2067 || code == 1555 {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "SQLSTATE 23505") ||
+		strings.Contains(msg, "duplicate key value violates unique constraint") ||
+		strings.HasPrefix(msg, "Error 1062") ||
+		sqliteKeyRegexp.MatchString(msg)
+}
+
+// ConstraintName will return the constraint name of duplicate error if it is available, it is the key name on mysql and columns on sqlite
+func ConstraintName(err error) (name string) {
+	if !IsDuplicate(err) {
+		return
+	}
+	var getter fieldGetter
+	if errors.As(err, &getter) {
+		if name = getter.Get('n'); len(name) > 0 {
+			return
+		}
+	}
+	if value := errorField(err, "ConstraintName"); value.Kind() == reflect.String && value.Len() > 0 {
+		name = value.String()
+		return
+	}
+	msg := err.Error()
+	if match := mysqlKeyRegexp.FindStringSubmatch(msg); len(match) > 1 {
+		name = match[1]
+	} else if match := sqliteKeyRegexp.FindStringSubmatch(msg); len(match) > 1 {
+		name = match[1]
+	}
+	return
+}
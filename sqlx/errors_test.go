@@ -0,0 +1,78 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgconn"
+	"github.com/lib/pq"
+)
+
+type codeError int
+
+func (c codeError) Error() string {
+	return fmt.Sprintf("code %v", int(c))
+}
+
+func (c codeError) Code() int {
+	return int(c)
+}
+
+func TestIsDuplicate(t *testing.T) {
+	useSQLITE(t)
+	//pq
+	err := fmt.Errorf("wrap %w", &pq.Error{Code: "23505", Constraint: "crud_object_code_key"})
+	if !IsDuplicate(err) || ConstraintName(err) != "crud_object_code_key" {
+		t.Error(err)
+		return
+	}
+	//pgx
+	err = fmt.Errorf("wrap %w", &pgconn.PgError{Code: "23505", ConstraintName: "crud_object_code_key"})
+	if !IsDuplicate(err) || ConstraintName(err) != "crud_object_code_key" {
+		t.Error(err)
+		return
+	}
+	//mysql
+	err = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'abc' for key 'crud_object.code'"}
+	if !IsDuplicate(err) || ConstraintName(err) != "crud_object.code" {
+		t.Error(err)
+		return
+	}
+	//sqlite
+	_, _, err = getSQLITE().Exec(context.Background(), `insert into crud_uuid_object(title,code,update_time,create_time,status) values($1,$2,$3,$4,$5)`, "dup", "dup", time.Now(), time.Now(), 100)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, _, err = getSQLITE().Exec(context.Background(), `insert into crud_uuid_object(title,code,update_time,create_time,status) values($1,$2,$3,$4,$5)`, "dup", "dup", time.Now(), time.Now(), 100)
+	if !IsDuplicate(err) || ConstraintName(err) != "crud_uuid_object.code" {
+		t.Errorf("err:%v,name:%v", err, ConstraintName(err))
+		return
+	}
+	if !IsDuplicate(codeError(2067)) || ConstraintName(codeError(2067)) != "" {
+		t.Error("error")
+		return
+	}
+	//string fallback
+	for _, msg := range []string{
+		`ERROR: duplicate key value violates unique constraint "x" (SQLSTATE 23505)`,
+		"Error 1062: Duplicate entry 'abc' for key 'code'",
+		"UNIQUE constraint failed: crud_object.code",
+		"PRIMARY KEY constraint failed: crud_object.tid",
+	} {
+		if !IsDuplicate(fmt.Errorf("%v", msg)) {
+			t.Error(msg)
+			return
+		}
+	}
+	//not duplicate
+	for _, err := range []error{nil, fmt.Errorf("xxx"), &pq.Error{Code: "23503"}, &pgconn.PgError{Code: "23503"}, &mysql.MySQLError{Number: 1064}, codeError(1)} {
+		if IsDuplicate(err) || ConstraintName(err) != "" {
+			t.Error(err)
+			return
+		}
+	}
+}
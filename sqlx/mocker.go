@@ -1,234 +1,279 @@
 package sqlx
 
 import (
+	"database/sql"
 	"fmt"
-	"reflect"
 	"regexp"
 	"sync"
 	"testing"
 
-	"github.com/codingeasygo/util/xmap"
+	"github.com/codingeasygo/crud/mocker"
 )
 
-var ErrMock = fmt.Errorf("mock error")
+var ErrMock = mocker.ErrMock
 var Verbose = false
 
-var mocking = false
-var mockPanic = false
-var mockTrigger = map[string][]int{}
-var mockMatch = map[string]*regexp.Regexp{}
-var mockRunned = map[string]int{}
-var mockRunnedLck = sync.RWMutex{}
-
-func mockerCheck(key, sql string) (err error) {
-	if mocking {
-		mockRunnedLck.Lock()
-		mockRunned[key]++
-		trigger := mockTrigger[key]
-		runned := mockRunned[key]
-		if trigger != nil && (trigger[0] < 0 || (trigger[0] <= runned && runned <= trigger[1])) {
-			err = ErrMock
-		}
-		match := mockMatch[key]
-		if match != nil && match.MatchString(sql) {
-			err = ErrMock
-		}
-		if Verbose {
-			fmt.Printf("Mocking %v trigger:%v,runned:%v,err:%v,sql:\n%v\n", key, mockTrigger[key], mockRunned[key], err, sql)
-		}
-		mockRunnedLck.Unlock()
-		if mockPanic && err != nil {
-			panic(err)
-		}
-	}
+// MockerCaller is the alias of mocker.Caller
+type MockerCaller = mocker.Caller
+
+// Outcome is the alias of mocker.Outcome, it is used by MockerSequence
+type Outcome = mocker.Outcome
+
+var OutcomeOk = mocker.OutcomeOk
+var OutcomePanic = mocker.OutcomePanic
+
+func OutcomeErr(err error) Outcome {
+	return mocker.OutcomeErr(err)
+}
+
+func mockerCheck(key, sql string, args ...interface{}) (err error) {
+	err = mocker.CheckVerbose(key, sql, Verbose, args...)
 	return
 }
 
+// MockerCheck will check key like driver queryer, it is used by custom queryer to participate in the mocker
+func MockerCheck(key, sql string) error {
+	return mockerCheck(key, sql)
+}
+
+// MockerLogTo will set the verbose log func like t.Logf, the log is printed to stdout when logf is nil
+func MockerLogTo(logf func(format string, args ...interface{})) {
+	mocker.LogTo(logf)
+}
+
 func MockerStart() {
-	mocking = true
+	mocker.Start()
 }
 
 func MockerStop() {
-	MockerClear()
-	mocking = false
+	mocker.Stop()
+}
+
+// MockerTest will start mocker for t and restore the mocker state when t is done, it is not supported in parallel tests
+func MockerTest(t *testing.T) {
+	t.Helper()
+	mocker.Test(t)
 }
 
 func MockerClear() {
-	mockRunnedLck.Lock()
-	mockTrigger = map[string][]int{}
-	mockMatch = map[string]*regexp.Regexp{}
-	mockRunned = map[string]int{}
-	mockPanic = false
-	mockRunnedLck.Unlock()
+	mocker.Clear()
 }
 
 func mockerSet(key, match string, isPanice bool, triggers ...int) {
-	mockRunnedLck.Lock()
-	defer mockRunnedLck.Unlock()
-	if len(match) > 0 {
-		mockMatch[key] = regexp.MustCompile(match)
-	} else {
-		if len(triggers) == 1 {
-			mockTrigger[key] = []int{triggers[0], triggers[0]}
-		} else if len(triggers) > 1 {
-			mockTrigger[key] = triggers
-		} else {
-			panic("trigger is required")
-		}
-	}
-	mockPanic = isPanice
+	mocker.SetTriggers(key, match, isPanice, triggers...)
 }
 
-type MockerCaller struct {
-	Call     func(func(trigger int) (res xmap.M, err error)) xmap.M
-	Shoulder xmap.Shoulder
+// MockerSet will make key return ErrMock when runned times is in triggers range, the range is [from, to] and to < 0 means from the from-th onward
+func MockerSet(key string, triggers ...int) {
+	mocker.Set(key, triggers...)
 }
 
-func (m *MockerCaller) Should(t *testing.T, args ...interface{}) *MockerCaller {
-	m.Shoulder.Should(t, args...)
-	return m
+func MockerPanic(key string, triggers ...int) {
+	mocker.Panic(key, triggers...)
 }
 
-func (m *MockerCaller) ShouldError(t *testing.T) *MockerCaller {
-	m.Shoulder.ShouldError(t)
-	return m
+func MockerMatchSet(key, match string) {
+	mocker.MatchSet(key, match)
 }
 
-func (m *MockerCaller) OnlyLog(only bool) *MockerCaller {
-	m.Shoulder.OnlyLog(only)
-	return m
+func MockerMatchPanic(key, match string) {
+	mocker.MatchPanic(key, match)
 }
 
-func Should(t *testing.T, key string, v interface{}) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Should(t, key, v).Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		res, err := call(0)
-		caller.Shoulder.Valid(4, res, err)
-		return res
-	}
-	return
+// MockerMatchArgs will make key return ErrMock when predicate return true by sql and args
+func MockerMatchArgs(key string, predicate func(sql string, args []interface{}) bool) {
+	mocker.MatchArgs(key, predicate)
 }
 
-func rangeArgs(args []interface{}, call func(key string, trigger int)) {
-	triggerAll := map[string][]int{}
-	triggerKeys := []string{}
-	triggerAdd := false
-	for i, arg := range args {
-		switch arg := arg.(type) {
-		case string:
-			if triggerAdd {
-				triggerKeys = []string{}
-			}
-			triggerAdd = false
-			triggerKeys = append(triggerKeys, arg)
-		case int:
-			triggerAdd = true
-			for _, key := range triggerKeys {
-				triggerAll[key] = append(triggerAll[key], arg)
-			}
-		default:
-			panic(fmt.Sprintf("args[%v] is %v and not supported", i, reflect.TypeOf(arg)))
-		}
-	}
-	for key, triggers := range triggerAll {
-		for _, trigger := range triggers {
-			call(key, trigger)
-		}
-	}
+// MockerSequence will make the next checks of key return outcomes in order, the outcomes is cleared by MockerClear
+func MockerSequence(key string, outcomes ...Outcome) {
+	mocker.Sequence(key, outcomes...)
 }
 
-func MockerSet(key string, trigger int) {
-	mockerSet(key, "", false, trigger)
+// MockerSequenceDone will fail t when any outcome set by MockerSequence is not consumed
+func MockerSequenceDone(t *testing.T) bool {
+	t.Helper()
+	return mocker.SequenceDone(t)
 }
 
-func MockerPanic(key string, trigger int) {
-	mockerSet(key, "", true, trigger)
+// MockerRecord will enable/disable recording every key and sql seen by mocker, the records is cleared by MockerClear
+func MockerRecord(on bool) {
+	mocker.Record(on)
 }
 
-func MockerMatchSet(key, match string) {
-	mockerSet(key, match, false)
+// MockerCalls will return the recorded sql of key by called order
+func MockerCalls(key string) []string {
+	return mocker.Calls(key)
 }
 
-func MockerMatchPanic(key, match string) {
-	mockerSet(key, match, true)
+// MockerCallCount will return the number of recorded calls which key and sql is matched
+func MockerCallCount(keyRegexp, sqlRegexp string) int {
+	return mocker.CallCount(keyRegexp, sqlRegexp)
+}
+
+// MockerAssertCalled will fail t when key with sql matched is not called by times
+func MockerAssertCalled(t *testing.T, key, sqlRegexp string, times int) bool {
+	t.Helper()
+	return mocker.AssertCalled(t, key, sqlRegexp, times)
+}
+
+// MockerSetError will make key return err instead of ErrMock when runned times is trigger
+func MockerSetError(key string, trigger int, err error) {
+	mocker.SetError(key, trigger, err)
+}
+
+// MockerMatchError will make key return err instead of ErrMock when sql is matched
+func MockerMatchError(key, match string, err error) {
+	mocker.MatchError(key, match, err)
+}
+
+func Should(t *testing.T, key string, v interface{}) (caller *MockerCaller) {
+	caller = mocker.Should(t, key, v)
+	return
+}
+
+func ShouldError(t *testing.T) (caller *MockerCaller) {
+	caller = mocker.ShouldError(t)
+	return
 }
 
 func MockerSetCall(args ...interface{}) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		rangeArgs(args, func(key string, i int) {
-			MockerSet(key, i)
-			res, err := call(i)
-			MockerClear()
-			caller.Shoulder.Valid(6, res, err)
-		})
-		return nil
-	}
+	caller = mocker.SetCall(args...)
 	return
 }
 
 func MockerPanicCall(args ...interface{}) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		rangeArgs(args, func(key string, i int) {
-			MockerPanic(key, i)
-			res, err := call(i)
-			MockerClear()
-			caller.Shoulder.Valid(6, res, err)
-		})
-		return nil
-	}
+	caller = mocker.PanicCall(args...)
 	return
 }
 
 func MockerMatchSetCall(key, match string) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		MockerMatchSet(key, match)
-		res, err := call(0)
-		MockerClear()
-		caller.Shoulder.Valid(4, res, err)
-		return res
-	}
+	caller = mocker.MatchSetCall(key, match)
 	return
 }
 
 func MockerMatchPanicCall(key, match string) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		MockerMatchPanic(key, match)
-		res, err := call(0)
-		MockerClear()
-		caller.Shoulder.Valid(4, res, err)
-		return res
-	}
+	caller = mocker.MatchPanicCall(key, match)
+	return
+}
+
+func MockerSetRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.SetRangeCall(key, start, end, to...)
+	return
+}
+
+func MockerPanicRangeCall(key string, start, end int, to ...int) (caller *MockerCaller) {
+	caller = mocker.PanicRangeCall(key, start, end, to...)
 	return
 }
 
-func MockerSetRangeCall(key string, start, end int) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		for i := start; i < end; i++ {
-			MockerSet(key, i)
-			res, err := call(0)
-			MockerClear()
-			caller.Shoulder.Valid(4, res, err)
+var mockRows = map[string][]*mockerData{}
+var mockRowsLck = sync.RWMutex{}
+
+func init() {
+	mocker.OnClear(func() {
+		mockRowsLck.Lock()
+		mockRows = map[string][]*mockerData{}
+		mockRowsLck.Unlock()
+	})
+}
+
+type mockerData struct {
+	match   *regexp.Regexp
+	columns []string
+	rows    [][]interface{}
+}
+
+// MockerRows will make Query/QueryRow on key(Pool.Query/Tx.Query) return rows without database when sql is matched, the rows is cleared by MockerClear
+func MockerRows(key, match string, columns []string, rows [][]interface{}) {
+	mockRowsLck.Lock()
+	mockRows[key] = append(mockRows[key], &mockerData{match: regexp.MustCompile(match), columns: columns, rows: rows})
+	mockRowsLck.Unlock()
+}
+
+func mockerDataRows(key, query string, args []interface{}) (rows *mockerRows) {
+	if !mocker.Running() {
+		return
+	}
+	mockRowsLck.RLock()
+	defer mockRowsLck.RUnlock()
+	for _, data := range mockRows[key] {
+		if data.match.MatchString(query) {
+			rows = &mockerRows{SQL: query, args: args, columns: data.columns, rows: data.rows}
+			break
 		}
-		return nil
 	}
 	return
 }
 
-func MockerPanicRangeCall(key string, start, end int) (caller *MockerCaller) {
-	caller = &MockerCaller{}
-	caller.Call = func(call func(trigger int) (res xmap.M, err error)) xmap.M {
-		for i := start; i < end; i++ {
-			MockerPanic(key, i)
-			res, err := call(0)
-			MockerClear()
-			caller.Shoulder.Valid(4, res, err)
+type mockerRows struct {
+	SQL     string
+	args    []interface{}
+	columns []string
+	rows    [][]interface{}
+	index   int
+	closed  bool
+}
+
+func (m *mockerRows) Next() bool {
+	if m.closed || m.index >= len(m.rows) {
+		m.closed = true
+		return false
+	}
+	m.index++
+	return true
+}
+
+func (m *mockerRows) Scan(dest ...interface{}) (err error) {
+	if err = mockerCheck("Rows.Scan", m.SQL, m.args...); err != nil {
+		return
+	}
+	if m.index < 1 || m.index > len(m.rows) {
+		err = fmt.Errorf("mocker rows is not having current row")
+		return
+	}
+	row := m.rows[m.index-1]
+	if len(dest) != len(row) {
+		err = fmt.Errorf("mocker rows dest count %v is not equal to row values %v", len(dest), len(row))
+		return
+	}
+	for i, value := range row {
+		if err = mocker.Assign(dest[i], value); err != nil {
+			err = fmt.Errorf("mocker rows scan %v fail with %v", i, err)
+			break
 		}
-		return nil
 	}
 	return
 }
+
+func (m *mockerRows) Err() error {
+	return mockerCheck("Rows.Err", m.SQL, m.args...)
+}
+
+func (m *mockerRows) Columns() ([]string, error) {
+	return m.columns, nil
+}
+
+func (m *mockerRows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return nil, fmt.Errorf("column types is not supported by mocker rows")
+}
+
+func (m *mockerRows) Close() error {
+	m.closed = true
+	return nil
+}
+
+type mockerRow struct {
+	rows      *mockerRows
+	ErrNoRows error
+}
+
+func (m mockerRow) Scan(dest ...interface{}) (err error) {
+	defer m.rows.Close()
+	if !m.rows.Next() {
+		err = m.ErrNoRows
+		return
+	}
+	err = m.rows.Scan(dest...)
+	return
+}
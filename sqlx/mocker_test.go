@@ -1,34 +1,20 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/codingeasygo/crud"
 	"github.com/codingeasygo/util/xmap"
+	"github.com/lib/pq"
 )
 
-func TestRangeArgs(t *testing.T) {
-	rangeArgs([]interface{}{"a", 1, 2}, func(key string, trigger int) {
-		if key != "a" {
-			panic(key)
-		}
-		if trigger != 1 && trigger != 2 {
-			panic(trigger)
-		}
-	})
-	rangeArgs([]interface{}{"a", 1, 2, "b", 3}, func(key string, trigger int) {
-		if key != "a" && key != "b" {
-			panic(key)
-		}
-		if key == "a" && trigger != 1 && trigger != 2 {
-			panic(trigger)
-		}
-		if key == "b" && trigger != 3 {
-			panic(trigger)
-		}
-	})
-}
-
 func TestMocker(t *testing.T) {
+	MockerTest(t)
 	Should(t, "ok", 1).OnlyLog(true).Call(func(trigger int) (res xmap.M, err error) {
 		res = xmap.M{"a": 1}
 		return
@@ -58,3 +44,276 @@ func TestMocker(t *testing.T) {
 		return
 	})
 }
+
+func TestMockerMatchSQL(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerStart()
+	defer MockerStop()
+	MockerMatchSetCall("Pool.Exec", "^update crud_object").Should(t, "abc", nil).Call(func(trigger int) (res xmap.M, err error) {
+		_, _, err = queryer.Exec(ctx, "select 1")
+		res = xmap.M{"abc": err}
+		return
+	})
+	MockerMatchSetCall("Pool.Exec", "^update crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, _, err = queryer.Exec(ctx, "update crud_object set status=0 where 1=0")
+		return
+	})
+	MockerMatchSetCall("Pool.Exec", "^update crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = queryer.ExecRow(ctx, "update crud_object set status=0 where 1=0")
+		return
+	})
+	MockerMatchSetCall("Pool.Query", "from crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = queryer.Query(ctx, "select tid from crud_object")
+		return
+	})
+	MockerMatchSetCall("Rows.Scan", "from crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		var count int64
+		err = queryer.QueryRow(ctx, "select count(*) from crud_object").Scan(&count)
+		return
+	})
+	MockerMatchSetCall("Pool.Begin", "begin").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = queryer.Begin(ctx)
+		return
+	})
+	tx, err := queryer.Begin(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer tx.Rollback()
+	MockerMatchSetCall("Tx.Exec", "^update crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, _, err = tx.Exec(ctx, "update crud_object set status=0 where 1=0")
+		return
+	})
+	MockerMatchSetCall("Tx.Exec", "^update crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = tx.ExecRow(ctx, "update crud_object set status=0 where 1=0")
+		return
+	})
+	MockerMatchSetCall("Tx.Query", "from crud_object").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = tx.Query(ctx, "select tid from crud_object")
+		return
+	})
+	MockerMatchSetCall("Tx.Begin", "savepoint").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		_, err = tx.Begin(ctx)
+		return
+	})
+	MockerMatchSetCall("Tx.Commit", "commit").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		sub, _ := tx.Begin(ctx)
+		err = sub.Commit()
+		return
+	})
+	MockerMatchSetCall("Tx.Rollback", "rollback").ShouldError(t).Call(func(trigger int) (res xmap.M, err error) {
+		sub, _ := tx.Begin(ctx)
+		err = sub.Rollback()
+		return
+	})
+}
+
+func TestMockerError(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerStart()
+	defer MockerStop()
+	dupErr := &pq.Error{Code: "23505", Constraint: "crud_object_pkey"}
+	MockerSetError("Pool.Exec", 1, dupErr)
+	_, _, err := queryer.Exec(ctx, "update crud_object set status=0 where 1=0")
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr != dupErr || !IsDuplicate(err) {
+		t.Error(err)
+		return
+	}
+	MockerMatchError("Rows.Scan", "from crud_object", sql.ErrConnDone)
+	var count int64
+	if err = queryer.QueryRow(ctx, "select count(*) from crud_object").Scan(&count); err != sql.ErrConnDone {
+		t.Error(err)
+		return
+	}
+	MockerMatchSet("Rows.Scan", "from crud_object")
+	if err = queryer.QueryRow(ctx, "select count(*) from crud_object").Scan(&count); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	MockerClear()
+	if err = queryer.QueryRow(ctx, "select count(*) from crud_object").Scan(&count); err != nil {
+		t.Error(err)
+		return
+	}
+	MockerSetCall("Pool.Exec", 1).ShouldErrorIs(t, ErrMock).Call(func(trigger int) (res xmap.M, err error) {
+		_, _, err = queryer.Exec(ctx, "update crud_object set status=0 where 1=0")
+		return
+	})
+	MockerSetCall("Pool.Exec", 1).ShouldErrorContains(t, "23505").Call(func(trigger int) (res xmap.M, err error) {
+		MockerSetError("Pool.Exec", 1, dupErr)
+		if _, _, err = queryer.Exec(ctx, "update crud_object set status=0 where 1=0"); err != nil {
+			err = fmt.Errorf("update fail with %v(%v)", err, dupErr.Code)
+		}
+		return
+	})
+}
+
+func TestMockerRecord(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerStart()
+	defer MockerStop()
+	MockerRecord(true)
+	defer MockerRecord(false)
+	queryer.Exec(ctx, "update crud_object set status=0 where 1=0")
+	queryer.Exec(ctx, "update crud_object set status=1 where 1=0")
+	var count int64
+	queryer.QueryRow(ctx, "select count(*) from crud_object").Scan(&count)
+	if len(MockerCalls("Pool.Exec")) != 2 || MockerCallCount("Exec$", "^delete") != 0 || MockerCallCount("", "from crud_object") != 1 {
+		t.Error("error")
+		return
+	}
+	MockerAssertCalled(t, "Pool.Exec", "^update crud_object", 2)
+	MockerAssertCalled(t, "Pool.Exec", "^delete", 0)
+	MockerClear()
+	if len(MockerCalls("Pool.Exec")) != 0 {
+		t.Error("not cleared")
+		return
+	}
+}
+
+func TestMockerMatchArgs(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerTest(t)
+	MockerMatchArgs("Pool.Exec", func(sql string, args []interface{}) bool {
+		return len(args) > 0 && args[0] == 42
+	})
+	if _, _, err := queryer.Exec(ctx, "update crud_object set status=0 where tid=$1", 41); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, _, err := queryer.Exec(ctx, "update crud_object set status=0 where tid=$1", 42); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	MockerMatchArgs("Rows.Scan", func(sql string, args []interface{}) bool {
+		return len(args) > 0 && args[0] == 42
+	})
+	var count int64
+	if err := queryer.QueryRow(ctx, "select count(*) from crud_object where tid=$1", 41).Scan(&count); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := queryer.QueryRow(ctx, "select count(*) from crud_object where tid=$1", 42).Scan(&count); err != ErrMock {
+		t.Error(err)
+		return
+	}
+}
+
+func TestMockerSequence(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerTest(t)
+	MockerSequence("Pool.Query", OutcomeOk, OutcomeErr(nil), OutcomeOk)
+	for i := 0; i < 3; i++ {
+		rows, err := queryer.Query(ctx, "select tid from crud_object")
+		if (i == 1) != (err == ErrMock) {
+			t.Errorf("%v,%v", i, err)
+			return
+		}
+		if err == nil {
+			rows.Close()
+		}
+	}
+	MockerSequenceDone(t)
+}
+
+func TestMockerRows(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerTest(t)
+	MockerRows("Pool.Query", "from mock_object", []string{"tid", "name"}, [][]interface{}{{int64(1), "a"}, {int64(2), nil}})
+	MockerRows("Pool.Query", "from none_object", []string{"tid"}, nil)
+	rows, err := queryer.Query(ctx, "select tid,name from mock_object where tid>$1", 0)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	tids, names := []int64{}, []*string{}
+	for rows.Next() {
+		var tid int64
+		var name *string
+		if err = rows.Scan(&tid, &name); err != nil {
+			t.Error(err)
+			return
+		}
+		tids, names = append(tids, tid), append(names, name)
+	}
+	if columns, _ := rows.(*mockerRows).Columns(); len(tids) != 2 || tids[1] != 2 || *names[0] != "a" || names[1] != nil || len(columns) != 2 || rows.(*mockerRows).Err() != nil {
+		t.Errorf("%v,%v,%v", tids, names, columns)
+		return
+	}
+	rows.Close()
+	var tid int64
+	var name string
+	if err = queryer.QueryRow(ctx, "select tid,name from mock_object").Scan(&tid, &name); err != nil || tid != 1 || name != "a" {
+		t.Errorf("%v,%v,%v", err, tid, name)
+		return
+	}
+	if err = queryer.QueryRow(ctx, "select tid from none_object").Scan(&tid); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+	if err = queryer.QueryRow(ctx, "select tid from mock_object").Scan(&tid); err == nil {
+		t.Error(err)
+		return
+	}
+	MockerSet("Rows.Scan", -1)
+	if err = queryer.QueryRow(ctx, "select tid,name from mock_object").Scan(&tid, &name); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	MockerClear()
+	if _, err = queryer.Query(ctx, "select tid,name from mock_object"); err == nil {
+		t.Error(err)
+		return
+	}
+	//tx
+	tx, err := queryer.Begin(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer tx.Rollback()
+	MockerRows("Tx.Query", "from mock_object", []string{"tid"}, [][]interface{}{{int64(3)}})
+	if err = tx.QueryRow(ctx, "select tid from mock_object").Scan(&tid); err != nil || tid != 3 {
+		t.Errorf("%v,%v", err, tid)
+		return
+	}
+	if rows, err = tx.Query(ctx, "select tid from mock_object"); err != nil || !rows.Next() {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+}
+
+func TestMockerLogTo(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	MockerTest(t)
+	logs := []string{}
+	MockerLogTo(func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+	defer MockerLogTo(nil)
+	Verbose = true
+	defer func() { Verbose = false }()
+	MockerSet("Pool.Exec", 1)
+	queryer.Exec(ctx, "update crud_object set status=0 where 1=0")
+	if len(logs) != 1 || !strings.Contains(logs[0], "mocker_test.go:") || !strings.Contains(logs[0], "Pool.Exec") {
+		t.Error(logs)
+		return
+	}
+}
@@ -0,0 +1,36 @@
+package sqlx
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/codingeasygo/crud"
+)
+
+// QuoteMySQL will quote name by backtick for mysql, the name already quoted is returned directly
+func QuoteMySQL(name string) string {
+	if len(name) < 1 || strings.HasPrefix(name, "`") {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// ConfigureMySQL will configure crud to generate mysql sql, it uses ? arg, backtick quoted name and LastInsertId instead of returning
+func ConfigureMySQL(c *crud.CRUD) {
+	c.ArgFormat = "?"
+	c.NameConv = func(on, name string, field reflect.StructField) string {
+		return QuoteMySQL(name)
+	}
+	c.LastInsertID = true
+	c.Dialect = "mysql"
+	c.IdentQuote = "`"
+	c.ReservedWords = crud.ReservedMySQL
+}
+
+// NewMySQLCRUD will return new crud copied from crud.Default and configured by ConfigureMySQL
+func NewMySQLCRUD() (c *crud.CRUD) {
+	c = &crud.CRUD{}
+	*c = *crud.Default
+	ConfigureMySQL(c)
+	return
+}
@@ -0,0 +1,115 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const mysqlLatest = `
+DROP TABLE IF EXISTS crud_object;
+CREATE TABLE crud_object (
+  tid BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  title VARCHAR(255) NOT NULL,
+  time_value DATETIME NOT NULL,
+  update_time DATETIME NOT NULL,
+  create_time DATETIME NOT NULL,
+  status INT NOT NULL
+);
+`
+
+type mysqlObject struct {
+	T          string    `json:"-" table:"crud_object"`
+	TID        int64     `json:"tid"`
+	Title      string    `json:"title"`
+	TimeValue  time.Time `json:"time_value"`
+	UpdateTime time.Time `json:"update_time"`
+	CreateTime time.Time `json:"create_time"`
+	Status     int       `json:"status"`
+}
+
+func TestQuoteMySQL(t *testing.T) {
+	if v := QuoteMySQL("group"); v != "`group`" {
+		t.Error(v)
+		return
+	}
+	if v := QuoteMySQL("`group`"); v != "`group`" {
+		t.Error(v)
+		return
+	}
+	if v := QuoteMySQL("a`b"); v != "`a``b`" {
+		t.Error(v)
+		return
+	}
+	if v := QuoteMySQL(""); v != "" {
+		t.Error(v)
+		return
+	}
+}
+
+func testMySQLCRUD(t *testing.T, queryer *DbQueryer) {
+	ctx := context.Background()
+	c := NewMySQLCRUD()
+	object := &mysqlObject{Title: "mysql", TimeValue: time.Now(), UpdateTime: time.Now(), CreateTime: time.Now(), Status: 100}
+	insertId, err := c.InsertFilter(queryer, ctx, object, "^tid#all", "returning", "tid#all")
+	if err != nil || object.TID < 1 || object.TID != insertId {
+		t.Errorf("err:%v,tid:%v,insertId:%v", err, object.TID, insertId)
+		return
+	}
+	object.Title = "mysql2"
+	err = c.UpdateRowWheref(queryer, ctx, object, "title", "tid=?", object.TID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var loaded *mysqlObject
+	err = c.QueryRowWheref(queryer, ctx, &mysqlObject{}, "#all", "tid=?", []interface{}{object.TID}, &loaded)
+	if err != nil || loaded == nil || loaded.TID != object.TID || loaded.Title != "mysql2" {
+		t.Errorf("err:%v,loaded:%v", err, loaded)
+		return
+	}
+	//error
+	_, err = c.InsertFilter(queryer, ctx, object, "^tid#all", "returning", "tid,title#all")
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	_, err = c.InsertFilter(queryer, ctx, object, "^tid#all", "returning", "title#all")
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	_, err = c.InsertFilter(queryer, ctx, object, "^tid#all", "xxxx", "tid#all")
+	if err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestMySQLCRUDSQLITE(t *testing.T) {
+	useSQLITE(t)
+	testMySQLCRUD(t, getSQLITE())
+}
+
+func TestMySQLCRUD(t *testing.T) {
+	db, err := sql.Open("mysql", "dev:123@tcp(mysql.loc:3306)/crud?parseTime=true&multiStatements=true")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer db.Close()
+	queryer := NewDbQueryer(db)
+	if !queryer.Savepoint {
+		t.Error("savepoint")
+		return
+	}
+	_, _, err = queryer.Exec(context.Background(), mysqlLatest)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	testMySQLCRUD(t, queryer)
+}
@@ -0,0 +1,125 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codingeasygo/crud"
+)
+
+func isNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || ('0' <= c && c <= '9')
+}
+
+// Named will rewrite :name in sql to placeholder by argFormat and return args by appear order.
+// The token in quoted string/identifier, comment and cast like ::text is skipped, the unknown or unused param name will return error.
+// The same name is reused by index when argFormat is like $%v, or repeated when argFormat is like ?
+func Named(sql string, params map[string]interface{}, argFormat string) (outSQL string, args []interface{}, err error) {
+	indexed := strings.Contains(argFormat, "%")
+	used := map[string]int{}
+	out := strings.Builder{}
+	n := len(sql)
+	for i := 0; i < n; {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`': //quoted
+			end := i + 1
+			for end < n {
+				if sql[end] == c {
+					if end+1 < n && sql[end+1] == c { //escaped quote
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= n {
+				err = fmt.Errorf("quote %c at %v is not closed", c, i)
+				return
+			}
+			out.WriteString(sql[i : end+1])
+			i = end + 1
+		case c == '-' && i+1 < n && sql[i+1] == '-': //line comment
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = n - i
+			}
+			out.WriteString(sql[i : i+end])
+			i += end
+		case c == ':' && i+1 < n && sql[i+1] == ':': //cast
+			out.WriteString("::")
+			i += 2
+		case c == ':' && i+1 < n && isNameStart(sql[i+1]):
+			end := i + 1
+			for end < n && isNamePart(sql[end]) {
+				end++
+			}
+			name := sql[i+1 : end]
+			value, ok := params[name]
+			if !ok {
+				err = fmt.Errorf("named param %v is not found", name)
+				return
+			}
+			index, ok := used[name]
+			if !ok || !indexed {
+				args = append(args, value)
+				index = len(args)
+				used[name] = index
+			}
+			if indexed {
+				out.WriteString(fmt.Sprintf(argFormat, index))
+			} else {
+				out.WriteString(argFormat)
+			}
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	unused := []string{}
+	for name := range params {
+		if _, ok := used[name]; !ok {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		err = fmt.Errorf("named params %v is not used", strings.Join(unused, ","))
+		return
+	}
+	outSQL = out.String()
+	return
+}
+
+func (d *DbQueryer) argFormat() string {
+	if len(d.ArgFormat) > 0 {
+		return d.ArgFormat
+	}
+	return crud.Default.ArgFormat
+}
+
+// NamedExec will exec sql with :name params, see Named for detail
+func (d *DbQueryer) NamedExec(ctx context.Context, query string, params map[string]interface{}) (insertId, affected int64, err error) {
+	query, args, err := Named(query, params, d.argFormat())
+	if err == nil {
+		insertId, affected, err = d.Exec(ctx, query, args...)
+	}
+	return
+}
+
+// NamedQuery will query sql with :name params, see Named for detail
+func (d *DbQueryer) NamedQuery(ctx context.Context, query string, params map[string]interface{}) (rows crud.Rows, err error) {
+	query, args, err := Named(query, params, d.argFormat())
+	if err == nil {
+		rows, err = d.Query(ctx, query, args...)
+	}
+	return
+}
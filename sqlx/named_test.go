@@ -0,0 +1,85 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	params := map[string]interface{}{"tid": 1, "status": 100, "title": "abc"}
+	sql, args, err := Named("select * from crud_object where tid=:tid and (status=:status or :status=0) and title like :title", params, "$%v")
+	if err != nil || sql != "select * from crud_object where tid=$1 and (status=$2 or $2=0) and title like $3" || fmt.Sprintf("%v", args) != "[1 100 abc]" {
+		t.Errorf("err:%v,sql:%v,args:%v", err, sql, args)
+		return
+	}
+	sql, args, err = Named("select * from crud_object where tid=:tid and (status=:status or :status=0) and title like :title", params, "?")
+	if err != nil || sql != "select * from crud_object where tid=? and (status=? or ?=0) and title like ?" || fmt.Sprintf("%v", args) != "[1 100 100 abc]" {
+		t.Errorf("err:%v,sql:%v,args:%v", err, sql, args)
+		return
+	}
+	//skip
+	sql, args, err = Named(`select ':tid', "a:tid", 'it''s :tid', data::text, data ->> 'x' as x -- :xx
+from crud_object where tid=:tid::int8 and status=:status and title=:title`, params, "$%v")
+	if err != nil || sql != `select ':tid', "a:tid", 'it''s :tid', data::text, data ->> 'x' as x -- :xx
+from crud_object where tid=$1::int8 and status=$2 and title=$3` || len(args) != 3 {
+		t.Errorf("err:%v,sql:%v,args:%v", err, sql, args)
+		return
+	}
+	sql, _, err = Named("select 1 -- :comment", nil, "$%v")
+	if err != nil || sql != "select 1 -- :comment" {
+		t.Errorf("err:%v,sql:%v", err, sql)
+		return
+	}
+	sql, _, err = Named("select arr[1:2], :=, a: from x", nil, "$%v")
+	if err != nil || sql != "select arr[1:2], :=, a: from x" {
+		t.Errorf("err:%v,sql:%v", err, sql)
+		return
+	}
+	//error
+	if _, _, err = Named("select * from crud_object where tid=:tid and x=:xx", params, "$%v"); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, _, err = Named("select * from crud_object where tid=:tid", params, "$%v"); err == nil || err.Error() != "named params status,title is not used" {
+		t.Error(err)
+		return
+	}
+	if _, _, err = Named("select * from crud_object where title=':tid", params, "$%v"); err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestNamedSQLITE(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := getSQLITE()
+	_, _, err := queryer.NamedExec(ctx, "update crud_object set status=:status where 1=0", map[string]interface{}{"status": 100})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rows, err := queryer.NamedQuery(ctx, "select :value", map[string]interface{}{"value": 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	queryer.ArgFormat = "?"
+	rows, err = queryer.NamedQuery(ctx, "select :value,:value", map[string]interface{}{"value": 1})
+	queryer.ArgFormat = ""
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	if _, _, err = queryer.NamedExec(ctx, "update crud_object set status=:status where 1=0", nil); err == nil {
+		t.Error(err)
+		return
+	}
+	if _, err = queryer.NamedQuery(ctx, "select :value", nil); err == nil {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,75 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/codingeasygo/crud"
+)
+
+// SQLiteDriver is the driver name used by BootstrapSQLiteMemory, the driver must be imported by caller
+var SQLiteDriver = "sqlite3"
+
+// BusyDelayMin and BusyDelayMax is the backoff range between busy retry attempts
+var BusyDelayMin = 10 * time.Millisecond
+var BusyDelayMax = 500 * time.Millisecond
+
+// IsBusy will check if error is sqlite busy(5) or locked(6) error
+var IsBusy = func(err error) bool {
+	var coder interface{ Code() int }
+	if errors.As(err, &coder) && (coder.Code()&0xff == 5 || coder.Code()&0xff == 6) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+// ConfigureSQLite will configure crud to generate sqlite sql, it uses ?N arg and limits 999 params of one statement.
+// The RowLock is not supported by sqlite, the code generated by gen with sqlite is already empty
+func ConfigureSQLite(c *crud.CRUD) {
+	c.ArgFormat = "?%v"
+	c.MaxParams = 999
+	c.Dialect = "sqlite"
+	c.ReservedWords = crud.ReservedSQLite
+}
+
+// NewSQLiteCRUD will return new crud copied from crud.Default and configured by ConfigureSQLite
+func NewSQLiteCRUD() (c *crud.CRUD) {
+	c = &crud.CRUD{}
+	*c = *crud.Default
+	ConfigureSQLite(c)
+	return
+}
+
+// BootstrapSQLiteMemory will bootstrap Shared by in memory sqlite with SQLiteDriver, it is only one connection to keep the memory database
+func BootstrapSQLiteMemory() (db *sql.DB, err error) {
+	db, err = sql.Open(SQLiteDriver, "file::memory:?cache=shared")
+	if err == nil {
+		db.SetMaxOpenConns(1)
+		Shared = NewDbQueryer(db)
+	}
+	return
+}
+
+// retryBusy will call until success or not busy error, it is retried BusyRetry times
+func (d *DbQueryer) retryBusy(ctx context.Context, call func() error) (err error) {
+	delay := BusyDelayMin
+	for i := 0; ; i++ {
+		err = call()
+		if err == nil || i >= d.BusyRetry || !IsBusy(err) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > BusyDelayMax {
+			delay = BusyDelayMax
+		}
+	}
+	return
+}
@@ -0,0 +1,194 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codingeasygo/crud"
+	"github.com/codingeasygo/crud/testsql"
+)
+
+type sqliteObject struct {
+	T          string    `json:"-" table:"crud_object"`
+	TID        int64     `json:"tid"`
+	Title      string    `json:"title"`
+	TimeValue  time.Time `json:"time_value"`
+	UpdateTime time.Time `json:"update_time"`
+	CreateTime time.Time `json:"create_time"`
+	Status     int       `json:"status"`
+}
+
+func TestSQLiteMemory(t *testing.T) {
+	ctx := context.Background()
+	shared := Shared
+	defer func() {
+		Shared = shared
+	}()
+	db, err := BootstrapSQLiteMemory()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer db.Close()
+	queryer := Pool()
+	_, _, err = queryer.Exec(ctx, testsql.SQLITE_DROP)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, _, err = queryer.Exec(ctx, testsql.SQLITE_LATEST)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	c := NewSQLiteCRUD()
+	//insert
+	object := &sqliteObject{Title: "sqlite", TimeValue: time.Now(), UpdateTime: time.Now(), CreateTime: time.Now(), Status: 100}
+	_, err = c.InsertFilter(queryer, ctx, object, "^tid#all", "returning", "tid#all")
+	if err != nil || object.TID < 1 {
+		t.Error(err)
+		return
+	}
+	//update
+	object.Title = "sqlite2"
+	err = c.UpdateRowFilter(queryer, ctx, object, "title", []string{"tid=?1"}, "and", []interface{}{object.TID})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//query
+	var loaded *sqliteObject
+	err = c.QueryRowWheref(queryer, ctx, &sqliteObject{}, "#all", "tid=?%v", []interface{}{object.TID}, &loaded)
+	if err != nil || loaded == nil || loaded.Title != "sqlite2" {
+		t.Errorf("err:%v,loaded:%v", err, loaded)
+		return
+	}
+	var objects []*sqliteObject
+	err = c.QueryWheref(queryer, ctx, &sqliteObject{}, "#all", "status=?%v", []interface{}{100}, "", 0, 0, &objects)
+	if err != nil || len(objects) != 1 {
+		t.Error(err)
+		return
+	}
+	//bulk insert, it is split by MaxParams
+	bulk := []*sqliteObject{}
+	for i := 0; i < 400; i++ {
+		bulk = append(bulk, &sqliteObject{Title: fmt.Sprintf("bulk%v", i), TimeValue: time.Now(), UpdateTime: time.Now(), CreateTime: time.Now(), Status: 200})
+	}
+	affected, err := c.BulkInsertFilter(queryer, ctx, bulk, "^tid#all")
+	if err != nil || affected != 400 {
+		t.Errorf("err:%v,affected:%v", err, affected)
+		return
+	}
+	objects = nil
+	err = c.QueryWheref(queryer, ctx, &sqliteObject{}, "#all", "status=?%v", []interface{}{200}, "", 0, 0, &objects)
+	if err != nil || len(objects) != 400 {
+		t.Errorf("err:%v,objects:%v", err, len(objects))
+		return
+	}
+	args := []interface{}{}
+	for _, object := range objects {
+		args = append(args, object.TID, object.Title, object.Status)
+	}
+	err = c.Query(queryer, ctx, &sqliteObject{}, "#all", "select tid from crud_object where tid in (?"+strings.Repeat(",?", len(args)-1)+")", args, &objects)
+	if !errors.Is(err, crud.ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+	//no rows
+	queryer.ErrNoRows = fmt.Errorf("not found")
+	err = c.QueryRowWheref(queryer, ctx, &sqliteObject{}, "#all", "tid=?%v", []interface{}{-1}, &loaded)
+	if err != queryer.ErrNoRows {
+		t.Error(err)
+		return
+	}
+}
+
+func TestSQLiteBusy(t *testing.T) {
+	ctx := context.Background()
+	defer os.Remove("busy.sqlite")
+	locker, err := sql.Open("sqlite3", "file:busy.sqlite?_busy_timeout=0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer locker.Close()
+	_, err = locker.Exec("create table if not exists busy(tid integer primary key, title text)")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	db, err := sql.Open("sqlite3", "file:busy.sqlite?_busy_timeout=0")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer db.Close()
+	queryer := NewDbQueryer(db)
+	lock := func() *sql.Tx {
+		tx, err := locker.Begin()
+		if err != nil {
+			panic(err)
+		}
+		if _, err = tx.Exec("insert into busy(title) values('locker')"); err != nil {
+			panic(err)
+		}
+		return tx
+	}
+	//not retry
+	tx := lock()
+	_, _, err = queryer.Exec(ctx, "insert into busy(title) values('abc')")
+	if err == nil || !IsBusy(err) {
+		t.Error(err)
+		return
+	}
+	tx.Rollback()
+	//retry
+	queryer.BusyRetry = 10
+	tx = lock()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		tx.Commit()
+	}()
+	_, _, err = queryer.Exec(ctx, "insert into busy(title) values('abc')")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//retry fail
+	queryer.BusyRetry = 1
+	tx = lock()
+	_, _, err = queryer.Exec(ctx, "insert into busy(title) values('abc')")
+	tx.Rollback()
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	//context done
+	queryer.BusyRetry = 10
+	tx = lock()
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, _, err = queryer.Exec(timeout, "insert into busy(title) values('abc')")
+	tx.Rollback()
+	if err == nil {
+		t.Error(err)
+		return
+	}
+	//query
+	rows, err := queryer.Query(ctx, "select tid from busy")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	if IsBusy(fmt.Errorf("xxx")) || !IsBusy(fmt.Errorf("SQLITE_BUSY")) {
+		t.Error("error")
+		return
+	}
+}
@@ -3,12 +3,33 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/codingeasygo/crud"
 )
 
 var Shared *DbQueryer
 
+// ErrTxClosed is returned by Commit/Rollback on nested transaction which is already done
+var ErrTxClosed = sql.ErrTxDone
+
+// SavepointDrivers is the driver type name list which is supporting savepoint
+var SavepointDrivers = []string{"*pq.Driver", "*sqlite3.SQLiteDriver", "*mysql.MySQLDriver", "*stdlib.Driver"}
+
+// SavepointSupported will check if driver is supporting savepoint by SavepointDrivers
+func SavepointSupported(driver driver.Driver) bool {
+	name := fmt.Sprintf("%T", driver)
+	for _, d := range SavepointDrivers {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
 var Pool = func() *DbQueryer {
 	return Shared
 }
@@ -21,42 +42,93 @@ func Bootstrap(driverName, dataSourceName string) (db *sql.DB, err error) {
 	return
 }
 
+// ReadyDelayMin and ReadyDelayMax is the backoff range of ping in WaitReady
+var ReadyDelayMin = 100 * time.Millisecond
+var ReadyDelayMax = 5 * time.Second
+
+// BootstrapWait will bootstrap Shared like Bootstrap and wait database ready by ping with backoff until success or timeout
+func BootstrapWait(driverName, dataSourceName string, timeout time.Duration) (db *sql.DB, err error) {
+	db, err = Bootstrap(driverName, dataSourceName)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err = Shared.WaitReady(ctx)
+	return
+}
+
 type Row struct {
-	SQL string
+	SQL  string
+	args []interface{}
 	*sql.Row
+	ErrNoRows error
+	release   func(err error)
 }
 
 func (r Row) Scan(dest ...interface{}) (err error) {
 	defer func() {
 		xerr := r.Row.Scan(dest...)
+		if r.release != nil {
+			r.release(xerr)
+		}
+		if xerr == sql.ErrNoRows && r.ErrNoRows != nil {
+			xerr = r.ErrNoRows
+		}
 		if err == nil {
 			err = xerr
 		}
 	}()
-	err = mockerCheck("Rows.Scan", r.SQL)
+	err = mockerCheck("Rows.Scan", r.SQL, r.args...)
 	return
 }
 
 type Rows struct {
-	SQL string
+	SQL  string
+	args []interface{}
 	*sql.Rows
+	release func()
 }
 
 func (r *Rows) Scan(dest ...interface{}) error {
-	if err := mockerCheck("Rows.Scan", r.SQL); err != nil {
+	if err := mockerCheck("Rows.Scan", r.SQL, r.args...); err != nil {
 		return err
 	}
 	return r.Rows.Scan(dest...)
 }
 
+func (r *Rows) Err() error {
+	if err := mockerCheck("Rows.Err", r.SQL, r.args...); err != nil {
+		return err
+	}
+	return r.Rows.Err()
+}
+
+func (r *Rows) Columns() ([]string, error) {
+	return r.Rows.Columns()
+}
+
+func (r *Rows) ColumnTypes() ([]*sql.ColumnType, error) {
+	return r.Rows.ColumnTypes()
+}
+
 func (r *Rows) Close() (err error) {
 	err = r.Rows.Close()
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
 	return
 }
 
 type TxQueryer struct {
 	*sql.Tx
 	ErrNoRows error
+	Savepoint bool
+	savepoint string
+	sequence  *int
+	closed    bool
+	stmts     *stmtCache
 }
 
 func NewTxQueryer(tx *sql.Tx) (queryer *TxQueryer) {
@@ -73,27 +145,83 @@ func (t *TxQueryer) getErrNoRows() (err error) {
 	return
 }
 
+// Begin starts a nested transaction by savepoint, Commit/Rollback on nested transaction will release/rollback to the savepoint
+func (t *TxQueryer) Begin(ctx context.Context) (tx *TxQueryer, err error) {
+	if err := mockerCheck("Tx.Begin", "savepoint"); err != nil {
+		return nil, err
+	}
+	if !t.Savepoint {
+		err = fmt.Errorf("nested transaction is not supported")
+		return
+	}
+	if t.sequence == nil {
+		t.sequence = new(int)
+	}
+	*t.sequence++
+	savepoint := fmt.Sprintf("crud_sp_%v", *t.sequence)
+	_, err = t.Tx.ExecContext(ctx, "savepoint "+savepoint)
+	if err == nil {
+		tx = &TxQueryer{Tx: t.Tx, ErrNoRows: t.ErrNoRows, Savepoint: t.Savepoint, savepoint: savepoint, sequence: t.sequence, stmts: t.stmts}
+	}
+	return
+}
+
 func (t *TxQueryer) Commit() error {
-	if err := mockerCheck("Tx.Commit", ""); err != nil {
-		t.Tx.Rollback()
+	if err := mockerCheck("Tx.Commit", "commit"); err != nil {
+		t.rollback()
 		return err
 	}
-	return t.Tx.Commit()
+	if len(t.savepoint) < 1 {
+		t.closeStmts()
+		return t.Tx.Commit()
+	}
+	if t.closed {
+		return ErrTxClosed
+	}
+	t.closed = true
+	_, err := t.Tx.Exec("release savepoint " + t.savepoint)
+	return err
 }
 
 func (t *TxQueryer) Rollback() error {
-	if err := mockerCheck("Tx.Rollback", ""); err != nil {
-		t.Tx.Rollback()
+	if err := mockerCheck("Tx.Rollback", "rollback"); err != nil {
+		t.rollback()
 		return err
 	}
-	return t.Tx.Rollback()
+	return t.rollback()
+}
+
+func (t *TxQueryer) rollback() (err error) {
+	if len(t.savepoint) < 1 {
+		t.closeStmts()
+		err = t.Tx.Rollback()
+		return
+	}
+	if t.closed {
+		err = ErrTxClosed
+		return
+	}
+	t.closed = true
+	_, err = t.Tx.Exec("rollback to savepoint " + t.savepoint)
+	return
+}
+
+func (t *TxQueryer) closeStmts() {
+	if t.stmts != nil {
+		t.stmts.close()
+	}
 }
 
 func (t *TxQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Tx.Exec", ""); err != nil {
+	if err := mockerCheck("Tx.Exec", query, args...); err != nil {
 		return 0, 0, err
 	}
-	res, err := t.Tx.ExecContext(ctx, query, args...)
+	var res sql.Result
+	if t.stmts != nil {
+		res, err = t.stmts.execContext(ctx, t.Tx.ExecContext, query, args...)
+	} else {
+		res, err = t.Tx.ExecContext(ctx, query, args...)
+	}
 	if err == nil {
 		insertId, _ = res.LastInsertId() //ignore error for some driver is not supported
 	}
@@ -104,7 +232,7 @@ func (t *TxQueryer) Exec(ctx context.Context, query string, args ...interface{})
 }
 
 func (t *TxQueryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Tx.Exec", ""); err != nil {
+	if err := mockerCheck("Tx.Exec", query, args...); err != nil {
 		return 0, err
 	}
 	insertId, affected, err := t.Exec(ctx, query, args...)
@@ -115,32 +243,99 @@ func (t *TxQueryer) ExecRow(ctx context.Context, query string, args ...interface
 }
 
 func (t *TxQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Tx.Query", ""); err != nil {
+	if err := mockerCheck("Tx.Query", query, args...); err != nil {
 		return nil, err
 	}
-	raw, err := t.Tx.QueryContext(ctx, query, args...)
+	if data := mockerDataRows("Tx.Query", query, args); data != nil {
+		rows = data
+		return
+	}
+	var raw *sql.Rows
+	var release func()
+	if t.stmts != nil {
+		raw, release, err = t.stmts.queryContext(ctx, t.Tx.QueryContext, query, args...)
+	} else {
+		raw, err = t.Tx.QueryContext(ctx, query, args...)
+	}
 	if err == nil {
-		rows = &Rows{Rows: raw, SQL: query}
+		rows = &Rows{Rows: raw, SQL: query, args: args, release: release}
 	}
 	return
 }
 
 func (t *TxQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row crud.Row) {
+	if data := mockerDataRows("Tx.Query", query, args); data != nil {
+		row = mockerRow{rows: data, ErrNoRows: t.getErrNoRows()}
+		return
+	}
+	if t.stmts != nil {
+		raw, release := t.stmts.queryRowContext(ctx, t.Tx.QueryRowContext, query, args...)
+		row = &Row{Row: raw, SQL: query, args: args, ErrNoRows: t.getErrNoRows(), release: release}
+		return
+	}
 	raw := t.Tx.QueryRowContext(ctx, query, args...)
-	row = &Row{Row: raw, SQL: query}
+	row = &Row{Row: raw, SQL: query, args: args, ErrNoRows: t.getErrNoRows()}
 	return
 }
 
 type DbQueryer struct {
 	*sql.DB
 	ErrNoRows error
+	Savepoint bool
+	ArgFormat string // the arg format used by NamedExec/NamedQuery, default is crud.Default.ArgFormat
+	BusyRetry int    // the retry times of Exec/Query on sqlite busy error, default is 0 for disabled
+	Log       crud.LogF
+	stmts     *stmtCache
 }
 
 func NewDbQueryer(db *sql.DB) (queryer *DbQueryer) {
-	queryer = &DbQueryer{DB: db, ErrNoRows: crud.ErrNoRows}
+	queryer = &DbQueryer{DB: db, ErrNoRows: crud.ErrNoRows, Savepoint: SavepointSupported(db.Driver())}
+	return
+}
+
+// Option is the option to configure DbQueryer on NewDbQueryerWith
+type Option func(queryer *DbQueryer)
+
+// WithErrNoRows will set the error returned when no rows
+func WithErrNoRows(err error) Option {
+	return func(queryer *DbQueryer) {
+		queryer.ErrNoRows = err
+	}
+}
+
+// WithLogger will set the logger to log Exec/Query fail
+func WithLogger(log crud.LogF) Option {
+	return func(queryer *DbQueryer) {
+		queryer.Log = log
+	}
+}
+
+// WithStmtCache will enable prepared statement cache by size, see EnableStmtCache for detail
+func WithStmtCache(size int) Option {
+	return func(queryer *DbQueryer) {
+		queryer.EnableStmtCache(size)
+	}
+}
+
+// NewDbQueryerWith will create DbQueryer and apply all options
+func NewDbQueryerWith(db *sql.DB, opts ...Option) (queryer *DbQueryer) {
+	queryer = NewDbQueryer(db)
+	for _, opt := range opts {
+		opt(queryer)
+	}
 	return
 }
 
+// NewDBQueryer and NewDBQueryerWith is alias of NewDbQueryer and NewDbQueryerWith
+var NewDBQueryer = NewDbQueryer
+var NewDBQueryerWith = NewDbQueryerWith
+
+func (d *DbQueryer) logf(format string, args ...interface{}) {
+	if d.Log != nil {
+		d.Log(1, format, args...)
+	}
+}
+
 func (d *DbQueryer) getErrNoRows() (err error) {
 	if d.ErrNoRows == nil {
 		err = crud.ErrNoRows
@@ -150,23 +345,55 @@ func (d *DbQueryer) getErrNoRows() (err error) {
 	return
 }
 
+// EnableStmtCache will enable the prepared statement cache by lru with size, size < 1 will disable it, the transaction begin after is having own cache with same size.
+// It should be called before queryer is used
+func (d *DbQueryer) EnableStmtCache(size int) {
+	if d.stmts != nil {
+		d.stmts.close()
+		d.stmts = nil
+	}
+	if size > 0 {
+		d.stmts = newStmtCache(size, d.DB.PrepareContext)
+	}
+}
+
 func (d *DbQueryer) Begin(ctx context.Context) (tx *TxQueryer, err error) {
-	if err := mockerCheck("Pool.Begin", ""); err != nil {
+	tx, err = d.BeginTx(ctx, nil)
+	return
+}
+
+func (d *DbQueryer) BeginTx(ctx context.Context, opts *sql.TxOptions) (tx *TxQueryer, err error) {
+	if err := mockerCheck("Pool.Begin", "begin"); err != nil {
 		return nil, err
 	}
-	raw, err := d.DB.BeginTx(ctx, nil)
+	raw, err := d.DB.BeginTx(ctx, opts)
 	if err == nil {
 		tx = NewTxQueryer(raw)
 		tx.ErrNoRows = d.ErrNoRows
+		tx.Savepoint = d.Savepoint
+		if d.stmts != nil {
+			tx.stmts = newStmtCache(d.stmts.size, raw.PrepareContext)
+		}
 	}
 	return
 }
 
 func (d *DbQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
-	if err := mockerCheck("Pool.Exec", ""); err != nil {
+	if err := mockerCheck("Pool.Exec", query, args...); err != nil {
 		return 0, 0, err
 	}
-	res, err := d.DB.ExecContext(ctx, query, args...)
+	var res sql.Result
+	err = d.retryBusy(ctx, func() (err error) {
+		if d.stmts != nil {
+			res, err = d.stmts.execContext(ctx, d.DB.ExecContext, query, args...)
+		} else {
+			res, err = d.DB.ExecContext(ctx, query, args...)
+		}
+		return
+	})
+	if err != nil {
+		d.logf("DbQueryer exec sql:%v, args:%v fail with %v", query, args, err)
+	}
 	if err == nil {
 		insertId, _ = res.LastInsertId() //ignore error for some driver is not supported
 	}
@@ -177,7 +404,7 @@ func (d *DbQueryer) Exec(ctx context.Context, query string, args ...interface{})
 }
 
 func (d *DbQueryer) ExecRow(ctx context.Context, query string, args ...interface{}) (insertId int64, err error) {
-	if err := mockerCheck("Pool.Exec", ""); err != nil {
+	if err := mockerCheck("Pool.Exec", query, args...); err != nil {
 		return 0, err
 	}
 	insertId, affected, err := d.Exec(ctx, query, args...)
@@ -188,18 +415,101 @@ func (d *DbQueryer) ExecRow(ctx context.Context, query string, args ...interface
 }
 
 func (d *DbQueryer) Query(ctx context.Context, query string, args ...interface{}) (rows crud.Rows, err error) {
-	if err := mockerCheck("Pool.Query", ""); err != nil {
+	if err := mockerCheck("Pool.Query", query, args...); err != nil {
 		return nil, err
 	}
-	raw, err := d.DB.QueryContext(ctx, query, args...)
+	if data := mockerDataRows("Pool.Query", query, args); data != nil {
+		rows = data
+		return
+	}
+	var raw *sql.Rows
+	var release func()
+	err = d.retryBusy(ctx, func() (err error) {
+		if d.stmts != nil {
+			raw, release, err = d.stmts.queryContext(ctx, d.DB.QueryContext, query, args...)
+		} else {
+			raw, err = d.DB.QueryContext(ctx, query, args...)
+		}
+		return
+	})
+	if err != nil {
+		d.logf("DbQueryer query sql:%v, args:%v fail with %v", query, args, err)
+	}
 	if err == nil {
-		rows = &Rows{Rows: raw, SQL: query}
+		rows = &Rows{Rows: raw, SQL: query, args: args, release: release}
 	}
 	return
 }
 
 func (d *DbQueryer) QueryRow(ctx context.Context, query string, args ...interface{}) (row crud.Row) {
+	if data := mockerDataRows("Pool.Query", query, args); data != nil {
+		row = mockerRow{rows: data, ErrNoRows: d.getErrNoRows()}
+		return
+	}
+	if d.stmts != nil {
+		raw, release := d.stmts.queryRowContext(ctx, d.DB.QueryRowContext, query, args...)
+		row = &Row{Row: raw, SQL: query, args: args, ErrNoRows: d.getErrNoRows(), release: release}
+		return
+	}
 	raw := d.DB.QueryRowContext(ctx, query, args...)
-	row = &Row{Row: raw, SQL: query}
+	row = &Row{Row: raw, SQL: query, args: args, ErrNoRows: d.getErrNoRows()}
+	return
+}
+
+// Ping will ping the database to verify connection is alive
+func (d *DbQueryer) Ping(ctx context.Context) error {
+	if err := mockerCheck("Pool.Ping", "ping"); err != nil {
+		return err
+	}
+	return d.DB.PingContext(ctx)
+}
+
+// WaitReady will ping database with backoff until it is ready or ctx done
+func (d *DbQueryer) WaitReady(ctx context.Context) (err error) {
+	delay := ReadyDelayMin
+	for {
+		if err = d.Ping(ctx); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if delay *= 2; delay > ReadyDelayMax {
+			delay = ReadyDelayMax
+		}
+	}
+}
+
+// Stats will return the database statistics
+func (d *DbQueryer) Stats() sql.DBStats {
+	return d.DB.Stats()
+}
+
+// StartStatReporter will report database statistics on every interval until stop called, the stop is safe to call more than once
+func (d *DbQueryer) StartStatReporter(interval time.Duration, report func(stats sql.DBStats)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan int)
+	stopped := make(chan int)
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				report(d.Stats())
+			}
+		}
+	}()
+	stopOnce := sync.Once{}
+	stop = func() {
+		stopOnce.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+		<-stopped
+	}
 	return
 }
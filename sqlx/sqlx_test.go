@@ -3,6 +3,7 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -38,23 +39,33 @@ func nameConv(isTable bool, name string) string {
 
 var sharedSQLITE *DbQueryer
 
-func getSQLITE() *DbQueryer {
-	if sharedSQLITE != nil {
-		return sharedSQLITE
-	}
-	db, err := sql.Open("sqlite3", "file:crud.sqlite?cache=shared")
+// useSQLITE will open the sqlite test database in t.TempDir and init the tables, it is closed when test is done
+func useSQLITE(t testing.TB) *DbQueryer {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "crud.sqlite")+"?cache=shared")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 	db.SetMaxOpenConns(1)
-	sharedSQLITE = NewDbQueryer(db)
-	_, _, err = sharedSQLITE.Exec(context.Background(), testsql.SQLITE_DROP)
+	queryer := NewDbQueryer(db)
+	_, _, err = queryer.Exec(context.Background(), testsql.SQLITE_DROP)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	_, _, err = sharedSQLITE.Exec(context.Background(), testsql.SQLITE_LATEST)
+	_, _, err = queryer.Exec(context.Background(), testsql.SQLITE_LATEST)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	sharedSQLITE = queryer
+	t.Cleanup(func() {
+		sharedSQLITE = nil
+		db.Close()
+	})
+	return queryer
+}
+
+func getSQLITE() *DbQueryer {
+	if sharedSQLITE == nil {
+		panic("sqlite is not opened, call useSQLITE(t) first")
 	}
 	return sharedSQLITE
 }
@@ -340,6 +351,7 @@ var SqliteGen = gen.AutoGen{
 }
 
 func TestSqliteGen(t *testing.T) {
+	useSQLITE(t)
 	var err error
 	defer func() {
 		if err == nil {
@@ -366,6 +378,7 @@ func TestSqliteGen(t *testing.T) {
 }
 
 func TestQueryerSQLITE(t *testing.T) {
+	useSQLITE(t)
 	{ //exec test
 		var newID int64
 		err := getSQLITE().QueryRowContext(
@@ -423,3 +436,235 @@ func TestQueryerSQLITE(t *testing.T) {
 		tx.getErrNoRows()
 	}
 }
+
+func testNestedTx(t *testing.T, queryer *DbQueryer) {
+	ctx := context.Background()
+	insert := func(tx *TxQueryer, title string) {
+		_, err := tx.ExecRow(ctx, `insert into crud_object(title,time_value,update_time,create_time,status) values($1,$2,$3,$4,$5)`, title, time.Now(), time.Now(), time.Now(), 100)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	count := func(title string) (having int64) {
+		err := queryer.QueryRow(ctx, `select count(*) from crud_object where title=$1`, title).Scan(&having)
+		if err != nil {
+			t.Error(err)
+		}
+		return
+	}
+	tx, err := queryer.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	insert(tx, "nested_outer")
+	sub, err := tx.Begin(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	insert(sub, "nested_rollback")
+	if err = sub.Rollback(); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = sub.Rollback(); err != ErrTxClosed {
+		t.Error(err)
+		return
+	}
+	sub, err = tx.Begin(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	insert(sub, "nested_commit")
+	subsub, err := sub.Begin(ctx)
+	if err != nil || subsub.savepoint == sub.savepoint {
+		t.Error(err)
+		return
+	}
+	insert(subsub, "nested_rollback")
+	subsub.Rollback()
+	if err = sub.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+	if err = sub.Commit(); err != ErrTxClosed {
+		t.Error(err)
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		t.Error(err)
+		return
+	}
+	if count("nested_outer") != 1 || count("nested_commit") != 1 || count("nested_rollback") != 0 {
+		t.Error("error")
+		return
+	}
+	//mock
+	MockerStart()
+	defer MockerStop()
+	tx, _ = queryer.Begin(ctx)
+	defer tx.Rollback()
+	MockerSet("Tx.Begin", 1)
+	if _, err = tx.Begin(ctx); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	sub, _ = tx.Begin(ctx)
+	MockerSet("Tx.Commit", 1)
+	if err = sub.Commit(); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	sub, _ = tx.Begin(ctx)
+	MockerSet("Tx.Rollback", 1)
+	if err = sub.Rollback(); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	MockerSet("Pool.Begin", -1)
+	if _, err = queryer.BeginTx(ctx, nil); err != ErrMock {
+		t.Error(err)
+		return
+	}
+	//not supported
+	tx.Savepoint = false
+	if _, err = tx.Begin(ctx); err == nil {
+		t.Error(err)
+		return
+	}
+	if SavepointSupported(nil) {
+		t.Error("error")
+		return
+	}
+}
+
+func TestNestedTxPG(t *testing.T) {
+	testNestedTx(t, getPG())
+}
+
+func TestNestedTxSQLITE(t *testing.T) {
+	useSQLITE(t)
+	testNestedTx(t, getSQLITE())
+}
+
+func TestBootstrapWait(t *testing.T) {
+	shared := Shared
+	delayMin := ReadyDelayMin
+	defer func() {
+		Shared = shared
+		ReadyDelayMin = delayMin
+	}()
+	ReadyDelayMin = time.Millisecond
+	MockerStart()
+	defer MockerStop()
+	mockerSet("Pool.Ping", "", false, 1, 2)
+	dsn := "file:" + filepath.Join(t.TempDir(), "crud.sqlite") + "?cache=shared"
+	db, err := BootstrapWait("sqlite3", dsn, time.Second)
+	if err != nil || Pool().DB != db {
+		t.Error(err)
+		return
+	}
+	db.Close()
+	MockerSet("Pool.Ping", -1)
+	db, err = BootstrapWait("sqlite3", dsn, 50*time.Millisecond)
+	if err != ErrMock || Pool().DB != db {
+		t.Error(err)
+		return
+	}
+	db.Close()
+	if _, err = BootstrapWait("none", "", time.Second); err == nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestStatReporter(t *testing.T) {
+	useSQLITE(t)
+	queryer := getSQLITE()
+	if err := queryer.Ping(context.Background()); err != nil {
+		t.Error(err)
+		return
+	}
+	reported := make(chan sql.DBStats, 10)
+	stop := queryer.StartStatReporter(5*time.Millisecond, func(stats sql.DBStats) {
+		reported <- stats
+	})
+	stats := <-reported
+	stop()
+	stop() //stop again
+	if stats.MaxOpenConnections != 1 || queryer.Stats().MaxOpenConnections != 1 {
+		t.Error("error")
+		return
+	}
+}
+
+func TestNewDbQueryerWith(t *testing.T) {
+	useSQLITE(t)
+	errNoRows := fmt.Errorf("not found")
+	logged := 0
+	queryer := NewDBQueryerWith(
+		getSQLITE().DB,
+		WithErrNoRows(errNoRows),
+		WithLogger(func(caller int, format string, args ...interface{}) {
+			logged++
+		}),
+		WithStmtCache(10),
+	)
+	if queryer.ErrNoRows != errNoRows || queryer.stmts == nil || NewDBQueryer(getSQLITE().DB).stmts != nil {
+		t.Error("error")
+		return
+	}
+	if _, err := queryer.ExecRow(context.Background(), "update crud_object set status=0 where 1=0"); err != errNoRows {
+		t.Error(err)
+		return
+	}
+	if _, _, err := queryer.Exec(context.Background(), "xxx"); err == nil || logged != 1 {
+		t.Error(err)
+		return
+	}
+	if _, err := queryer.Query(context.Background(), "xxx"); err == nil || logged != 2 {
+		t.Error(err)
+		return
+	}
+}
+
+func TestRowsErrColumns(t *testing.T) {
+	useSQLITE(t)
+	var _ crud.RowsErr = &Rows{}
+	var _ crud.RowsColumns = &Rows{}
+	ctx := context.Background()
+	queryer := getSQLITE()
+	rows, err := queryer.Query(ctx, "select 1 as a, 'x' as b")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	columns, err := crud.Columns(rows)
+	if err != nil || strings.Join(columns, ",") != "a,b" {
+		t.Error(err)
+		return
+	}
+	types, err := rows.(*Rows).ColumnTypes()
+	if err != nil || len(types) != 2 {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	MockerStart()
+	defer MockerStop()
+	MockerSet("Rows.Err", 1)
+	rows, err = queryer.Query(ctx, "select 1 union all select 2")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var values []int64
+	err = crud.Scan(rows, int64(0), "#all", &values)
+	rows.Close()
+	if err != ErrMock {
+		t.Error(err)
+		return
+	}
+}
@@ -0,0 +1,158 @@
+package sqlx
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// StmtStale will check if error is indicating the prepared statement is stale, the stale statement is removed from cache
+var StmtStale = func(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "cached plan must not change result type") || //postgres after schema changed
+		(strings.Contains(msg, "prepared statement") && strings.Contains(msg, "does not exist")) ||
+		strings.Contains(msg, "statement is closed")
+}
+
+type stmtEntry struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// stmtCache is the prepared statement cache by lru, the statement is closed after evicted and not used
+type stmtCache struct {
+	size    int
+	prepare func(ctx context.Context, query string) (*sql.Stmt, error)
+	items   map[string]*list.Element
+	order   *list.List
+	lck     sync.Mutex
+}
+
+func newStmtCache(size int, prepare func(ctx context.Context, query string) (*sql.Stmt, error)) (cache *stmtCache) {
+	cache = &stmtCache{
+		size:    size,
+		prepare: prepare,
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+	}
+	return
+}
+
+func (s *stmtCache) lookup(query string) (entry *stmtEntry) {
+	if elem, ok := s.items[query]; ok {
+		s.order.MoveToFront(elem)
+		entry = elem.Value.(*stmtEntry)
+		entry.refs++
+	}
+	return
+}
+
+// acquire will return the cached statement or prepare it on miss, release must be called after statement is used
+func (s *stmtCache) acquire(ctx context.Context, query string) (entry *stmtEntry, err error) {
+	s.lck.Lock()
+	entry = s.lookup(query)
+	s.lck.Unlock()
+	if entry != nil {
+		return
+	}
+	stmt, err := s.prepare(ctx, query)
+	if err != nil {
+		return
+	}
+	s.lck.Lock()
+	defer s.lck.Unlock()
+	if entry = s.lookup(query); entry != nil { //prepared by other
+		stmt.Close()
+		return
+	}
+	entry = &stmtEntry{query: query, stmt: stmt, refs: 1}
+	s.items[query] = s.order.PushFront(entry)
+	for s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+	return
+}
+
+// release will decrease the statement reference and invalidate it when err is stale
+func (s *stmtCache) release(entry *stmtEntry, err error) {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+	entry.refs--
+	if !entry.evicted && err != nil && StmtStale(err) {
+		s.remove(s.items[entry.query])
+		return
+	}
+	if entry.evicted && entry.refs < 1 {
+		entry.stmt.Close()
+	}
+}
+
+func (s *stmtCache) remove(elem *list.Element) {
+	entry := s.order.Remove(elem).(*stmtEntry)
+	delete(s.items, entry.query)
+	entry.evicted = true
+	if entry.refs < 1 {
+		entry.stmt.Close()
+	}
+}
+
+// count will return the cached statement count
+func (s *stmtCache) count() int {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+	return s.order.Len()
+}
+
+func (s *stmtCache) close() {
+	s.lck.Lock()
+	defer s.lck.Unlock()
+	for s.order.Len() > 0 {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *stmtCache) execContext(ctx context.Context, direct func(ctx context.Context, query string, args ...interface{}) (sql.Result, error), query string, args ...interface{}) (res sql.Result, err error) {
+	entry, err := s.acquire(ctx, query)
+	if err != nil { //can't prepare, like multi statement
+		res, err = direct(ctx, query, args...)
+		return
+	}
+	res, err = entry.stmt.ExecContext(ctx, args...)
+	s.release(entry, err)
+	return
+}
+
+func (s *stmtCache) queryContext(ctx context.Context, direct func(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error), query string, args ...interface{}) (rows *sql.Rows, release func(), err error) {
+	entry, err := s.acquire(ctx, query)
+	if err != nil {
+		rows, err = direct(ctx, query, args...)
+		return
+	}
+	rows, err = entry.stmt.QueryContext(ctx, args...)
+	if err != nil {
+		s.release(entry, err)
+		return
+	}
+	release = func() { s.release(entry, nil) }
+	return
+}
+
+func (s *stmtCache) queryRowContext(ctx context.Context, direct func(ctx context.Context, query string, args ...interface{}) *sql.Row, query string, args ...interface{}) (row *sql.Row, release func(err error)) {
+	entry, err := s.acquire(ctx, query)
+	if err != nil {
+		row = direct(ctx, query, args...)
+		return
+	}
+	row = entry.stmt.QueryRowContext(ctx, args...)
+	release = func(err error) { s.release(entry, err) }
+	return
+}
@@ -0,0 +1,163 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/codingeasygo/crud"
+)
+
+func TestStmtCache(t *testing.T) {
+	useSQLITE(t)
+	ctx := context.Background()
+	queryer := NewDbQueryer(getSQLITE().DB)
+	queryer.EnableStmtCache(2)
+	var value int64
+	for i := 1; i <= 3; i++ {
+		err := queryer.QueryRow(ctx, fmt.Sprintf("select %v", i)).Scan(&value)
+		if err != nil || value != int64(i) {
+			t.Error(err)
+			return
+		}
+	}
+	if queryer.stmts.count() != 2 {
+		t.Error("error")
+		return
+	}
+	//reused
+	entry := queryer.stmts.items["select 3"].Value.(*stmtEntry)
+	rows, err := queryer.Query(ctx, "select 3")
+	if err != nil || entry.refs != 1 {
+		t.Error(err)
+		return
+	}
+	if !rows.(*Rows).Next() {
+		t.Error("error")
+		return
+	}
+	rows.Close()
+	rows.Close()
+	if entry.refs != 0 {
+		t.Error("error")
+		return
+	}
+	//evict in using
+	entry, _ = queryer.stmts.acquire(ctx, "select 3")
+	queryer.QueryRow(ctx, "select 4").Scan(&value)
+	queryer.QueryRow(ctx, "select 5").Scan(&value)
+	if !entry.evicted || entry.refs != 1 {
+		t.Error("error")
+		return
+	}
+	if err = entry.stmt.QueryRowContext(ctx).Scan(&value); err != nil || value != 3 {
+		t.Error(err)
+		return
+	}
+	queryer.stmts.release(entry, nil)
+	if entry.refs != 0 {
+		t.Error("error")
+		return
+	}
+	if err = entry.stmt.QueryRowContext(ctx).Scan(&value); err == nil {
+		t.Error(err)
+		return
+	}
+	//exec
+	_, err = queryer.ExecRow(ctx, "update crud_object set status=0 where 1=0")
+	if err != crud.ErrNoRows || queryer.stmts.items["update crud_object set status=0 where 1=0"] == nil {
+		t.Error(err)
+		return
+	}
+	//prepare fail
+	if _, _, err = queryer.Exec(ctx, "select 1;select 2"); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err = queryer.Query(ctx, "xxx"); err == nil {
+		t.Error(err)
+		return
+	}
+	if err = queryer.QueryRow(ctx, "xxx").Scan(&value); err == nil {
+		t.Error(err)
+		return
+	}
+	//stale
+	entry, _ = queryer.stmts.acquire(ctx, "select 1")
+	queryer.stmts.release(entry, driver.ErrBadConn)
+	if !entry.evicted || queryer.stmts.items["select 1"] != nil {
+		t.Error("error")
+		return
+	}
+	if StmtStale(fmt.Errorf("xxx")) || !StmtStale(fmt.Errorf(`pq: prepared statement "x" does not exist`)) {
+		t.Error("error")
+		return
+	}
+	//tx
+	tx, err := queryer.Begin(ctx)
+	if err != nil || tx.stmts == nil || tx.stmts == queryer.stmts {
+		t.Error(err)
+		return
+	}
+	if _, err = tx.ExecRow(ctx, "update crud_object set status=0 where 1=0"); err != crud.ErrNoRows {
+		t.Error(err)
+		return
+	}
+	if rows, err = tx.Query(ctx, "select 1"); err != nil {
+		t.Error(err)
+		return
+	}
+	rows.Close()
+	if err = tx.QueryRow(ctx, "select 2").Scan(&value); err != nil || value != 2 || tx.stmts.count() != 2 {
+		t.Error(err)
+		return
+	}
+	sub, err := tx.Begin(ctx)
+	if err != nil || sub.stmts != tx.stmts {
+		t.Error(err)
+		return
+	}
+	sub.Commit()
+	if tx.stmts.count() != 2 {
+		t.Error("error")
+		return
+	}
+	if err = tx.Commit(); err != nil || tx.stmts.count() != 0 {
+		t.Error(err)
+		return
+	}
+	tx, _ = queryer.Begin(ctx)
+	tx.Query(ctx, "select 1")
+	tx.Rollback()
+	//disable
+	queryer.EnableStmtCache(0)
+	if queryer.stmts != nil {
+		t.Error("error")
+		return
+	}
+}
+
+func benchmarkStmtCache(b *testing.B, size int) {
+	ctx := context.Background()
+	queryer := NewDbQueryer(getPG().DB)
+	queryer.EnableStmtCache(size)
+	defer queryer.EnableStmtCache(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var value int64
+		err := queryer.QueryRow(ctx, "select count(*) from crud_object where tid>$1 and status=$2", i, 100).Scan(&value)
+		if err != nil {
+			b.Error(err)
+			return
+		}
+	}
+}
+
+func BenchmarkStmtCacheDisabled(b *testing.B) {
+	benchmarkStmtCache(b, 0)
+}
+
+func BenchmarkStmtCacheEnabled(b *testing.B) {
+	benchmarkStmtCache(b, 64)
+}
@@ -0,0 +1,169 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func BuildInsert(v interface{}, filter string, suffix ...string) (stmt Stmt, err error) {
+	stmt, err = Default.buildInsert(1, context.Background(), v, filter, suffix...)
+	return
+}
+
+// BuildInsert will build insert stmt of v by filter without executing, it return ErrNoFields when no fields is matched by filter
+func (c *CRUD) BuildInsert(v interface{}, filter string, suffix ...string) (stmt Stmt, err error) {
+	stmt, err = c.buildInsert(1, context.Background(), v, filter, suffix...)
+	return
+}
+
+func (c *CRUD) buildInsert(caller int, ctx context.Context, v interface{}, filter string, suffix ...string) (stmt Stmt, err error) {
+	table, fields, param, args := c.insertArgs(caller+1, ctx, v, filter, nil)
+	stmt.SQL, err = c.insertValues(table, fields, param, v, filter)
+	if err != nil {
+		return
+	}
+	if len(suffix) > 0 {
+		stmt.SQL += " " + strings.Join(suffix, " ")
+	}
+	stmt.Args = args
+	return
+}
+
+func BuildUpdateWheref(v interface{}, filter, formats string, args ...interface{}) (stmt Stmt, err error) {
+	stmt, err = Default.buildUpdateWheref(1, context.Background(), v, filter, formats, args...)
+	return
+}
+
+// BuildUpdateWheref will build update stmt of v by filter and where formats without executing, it return ErrNoFields when no fields is matched by filter
+func (c *CRUD) BuildUpdateWheref(v interface{}, filter, formats string, args ...interface{}) (stmt Stmt, err error) {
+	stmt, err = c.buildUpdateWheref(1, context.Background(), v, filter, formats, args...)
+	return
+}
+
+func (c *CRUD) buildUpdateWheref(caller int, ctx context.Context, v interface{}, filter, formats string, args ...interface{}) (stmt Stmt, err error) {
+	table, sets, sqlArgs := c.updateArgs(caller+1, ctx, v, filter, nil)
+	if len(sets) < 1 {
+		err = c.errNoFields(v, filter)
+		return
+	}
+	sql := fmt.Sprintf(`update %v set %v`, table, strings.Join(sets, ","))
+	stmt.SQL, stmt.Args = c.joinWheref(caller+1, ctx, sql, sqlArgs, formats, args...)
+	return
+}
+
+func BuildDeleteWheref(v interface{}, formats string, args ...interface{}) (stmt Stmt) {
+	stmt = Default.buildDeleteWheref(1, context.Background(), v, formats, args...)
+	return
+}
+
+// BuildDeleteWheref will build delete stmt of v table by where formats without executing
+func (c *CRUD) BuildDeleteWheref(v interface{}, formats string, args ...interface{}) (stmt Stmt) {
+	stmt = c.buildDeleteWheref(1, context.Background(), v, formats, args...)
+	return
+}
+
+func (c *CRUD) buildDeleteWheref(caller int, ctx context.Context, v interface{}, formats string, args ...interface{}) (stmt Stmt) {
+	sql := fmt.Sprintf(`delete from %v`, c.Table(v))
+	stmt.SQL, stmt.Args = c.joinWheref(caller+1, ctx, sql, nil, formats, args...)
+	return
+}
+
+func ExecStmts(queryer interface{}, ctx context.Context, stmts []Stmt, transactional bool) (affected int64, err error) {
+	affected, err = Default.execStmts(1, queryer, ctx, stmts, transactional)
+	return
+}
+
+// ExecStmts will exec all stmts in order and return the total affected, it stops on first fail.
+// If transactional is true, the queryer must having Begin(ctx) method and all stmts is exec in the begined transaction
+func (c *CRUD) ExecStmts(queryer interface{}, ctx context.Context, stmts []Stmt, transactional bool) (affected int64, err error) {
+	affected, err = c.execStmts(1, queryer, ctx, stmts, transactional)
+	return
+}
+
+func (c *CRUD) execStmts(caller int, queryer interface{}, ctx context.Context, stmts []Stmt, transactional bool) (affected int64, err error) {
+	c = c.withContext(ctx)
+	if len(stmts) < 1 {
+		return
+	}
+	var tx interface{}
+	if transactional {
+		tx, err = c.queryerBegin(queryer, ctx)
+		if err != nil {
+			return
+		}
+		queryer = tx
+	}
+	for i, stmt := range stmts {
+		_, n, xerr := c.queryerExec(queryer, ctx, stmt.SQL, stmt.Args)
+		if xerr != nil {
+			err = fmt.Errorf("stmt[%v] fail with %w", i, xerr)
+			break
+		}
+		affected += n
+	}
+	if transactional {
+		if err == nil {
+			err = c.queryerFinish(tx, ctx, "Commit")
+		} else {
+			c.queryerFinish(tx, ctx, "Rollback")
+		}
+	}
+	if err != nil {
+		if c.Verbose {
+			c.logf(ctx, caller, "CRUD exec %v stmts by transactional:%v, result is fail:%v", len(stmts), transactional, err)
+		}
+		return
+	}
+	if c.Verbose {
+		c.logf(ctx, caller, "CRUD exec %v stmts by transactional:%v, result is success affected:%v", len(stmts), transactional, affected)
+	}
+	return
+}
+
+// queryerBegin will call Begin(ctx) (tx, error) method on queryer to begin transaction
+func (c *CRUD) queryerBegin(queryer interface{}, ctx context.Context) (tx interface{}, err error) {
+	reflectValue := reflect.ValueOf(queryer)
+	if reflectValue.Kind() == reflect.Func {
+		reflectValue = reflectValue.Call(nil)[0]
+	}
+	method := reflectValue.MethodByName("Begin")
+	if !method.IsValid() {
+		err = fmt.Errorf("queryer %v is not supported Begin", reflect.TypeOf(queryer))
+		return
+	}
+	methodType := method.Type()
+	if methodType.NumIn() != 1 || methodType.In(0) != contextType || methodType.NumOut() != 2 || methodType.Out(1) != errorType {
+		err = fmt.Errorf("queryer %v Begin %v is not supported", reflect.TypeOf(queryer), methodType)
+		return
+	}
+	out := method.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if !out[1].IsNil() {
+		err = out[1].Interface().(error)
+		return
+	}
+	tx = out[0].Interface()
+	return
+}
+
+// queryerFinish will call Commit/Rollback method on tx, the method can be with ctx or not
+func (c *CRUD) queryerFinish(tx interface{}, ctx context.Context, name string) (err error) {
+	method := reflect.ValueOf(tx).MethodByName(name)
+	if !method.IsValid() {
+		err = fmt.Errorf("tx %v is not supported %v", reflect.TypeOf(tx), name)
+		return
+	}
+	var in []reflect.Value
+	if method.Type().NumIn() == 1 {
+		in = append(in, reflect.ValueOf(ctx))
+	}
+	out := method.Call(in)
+	if len(out) > 0 {
+		err, _ = out[len(out)-1].Interface().(error)
+	}
+	return
+}
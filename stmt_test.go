@@ -0,0 +1,149 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testTxQueryer struct {
+	testRecordQueryer
+	failOn string
+	begin  error
+	status string
+}
+
+func (t *testTxQueryer) Exec(ctx context.Context, query string, args ...interface{}) (insertId, affected int64, err error) {
+	if len(t.failOn) > 0 && strings.HasPrefix(query, t.failOn) {
+		err = fmt.Errorf("exec fail")
+		return
+	}
+	return t.testRecordQueryer.Exec(ctx, query, args...)
+}
+
+func (t *testTxQueryer) Begin(ctx context.Context) (tx *testTxQueryer, err error) {
+	if t.begin != nil {
+		err = t.begin
+		return
+	}
+	tx = t
+	t.status = "begin"
+	return
+}
+
+func (t *testTxQueryer) Commit(ctx context.Context) error {
+	t.status = "commit"
+	return nil
+}
+
+func (t *testTxQueryer) Rollback() error {
+	t.status = "rollback"
+	return nil
+}
+
+func TestBuildStmt(t *testing.T) {
+	object := &CrudObject{TID: 100, Title: "title", Status: CrudObjectStatusNormal}
+	stmt, err := BuildInsert(object, "title,status", "returning tid")
+	if err != nil || stmt.SQL != "insert into crud_object(title,status) values($1,$2) returning tid" || len(stmt.Args) != 2 {
+		t.Errorf("%v,%v", err, stmt)
+		return
+	}
+	stmt, err = BuildUpdateWheref(object, "title", "tid=$%v,status=$%v", object.TID, 0)
+	if err != nil || stmt.SQL != "update crud_object set title=$1 where tid=$2" || len(stmt.Args) != 2 {
+		t.Errorf("%v,%v", err, stmt)
+		return
+	}
+	stmt = BuildDeleteWheref(object, "tid=$%v", object.TID)
+	if stmt.SQL != "delete from crud_object where tid=$1" || len(stmt.Args) != 1 {
+		t.Errorf("%v", stmt)
+		return
+	}
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	if stmt, err = c.BuildInsert(object, "title"); err != nil || stmt.SQL != "insert into crud_object(title) values($1)" {
+		t.Errorf("%v,%v", err, stmt)
+		return
+	}
+	if stmt, err = c.BuildUpdateWheref(object, "title", ""); err != nil || stmt.SQL != "update crud_object set title=$1" {
+		t.Errorf("%v,%v", err, stmt)
+		return
+	}
+	if stmt = c.BuildDeleteWheref(object, ""); stmt.SQL != "delete from crud_object" {
+		t.Errorf("%v", stmt)
+		return
+	}
+	//no fields
+	c.ParmConv = func(on, fieldName, fieldFunc string, field reflect.StructField, value interface{}) interface{} {
+		if fieldName == "title" {
+			return SkipField
+		}
+		return value
+	}
+	if _, err = c.BuildInsert(object, "title"); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	if _, err = c.BuildUpdateWheref(object, "title", "tid=$%v", object.TID); !errors.Is(err, ErrNoFields) {
+		t.Error(err)
+		return
+	}
+	c.InsertDefault = true
+	if stmt, err = c.BuildInsert(object, "title", "returning tid"); err != nil || stmt.SQL != "insert into crud_object default values returning tid" {
+		t.Errorf("%v,%v", err, stmt)
+		return
+	}
+}
+
+func TestExecStmts(t *testing.T) {
+	object := &CrudObject{TID: 100, Title: "title", Status: CrudObjectStatusNormal}
+	insertStmt, _ := BuildInsert(object, "title,status")
+	updateStmt, _ := BuildUpdateWheref(object, "title", "tid=$%v", object.TID)
+	stmts := []Stmt{
+		insertStmt,
+		updateStmt,
+		BuildDeleteWheref(object, "tid=$%v", object.TID),
+	}
+	ctx := context.Background()
+	queryer := &testTxQueryer{}
+	affected, err := ExecStmts(queryer, ctx, stmts, true)
+	if err != nil || affected != 3 || len(queryer.sqls) != 3 || queryer.status != "commit" {
+		t.Errorf("%v,%v,%v,%v", err, affected, queryer.sqls, queryer.status)
+		return
+	}
+	queryer = &testTxQueryer{failOn: "update"}
+	affected, err = Default.ExecStmts(queryer, ctx, stmts, true)
+	if err == nil || !strings.Contains(err.Error(), "stmt[1]") || affected != 1 || queryer.status != "rollback" {
+		t.Errorf("%v,%v,%v", err, affected, queryer.status)
+		return
+	}
+	queryer = &testTxQueryer{begin: fmt.Errorf("begin fail")}
+	if _, err = ExecStmts(queryer, ctx, stmts, true); err != queryer.begin {
+		t.Error(err)
+		return
+	}
+	queryer = &testTxQueryer{}
+	affected, err = ExecStmts(func() *testTxQueryer { return queryer }, ctx, stmts, false)
+	if err != nil || affected != 3 || queryer.status != "" {
+		t.Errorf("%v,%v,%v", err, affected, queryer.status)
+		return
+	}
+	if _, err = ExecStmts(&testRecordQueryer{}, ctx, stmts, true); err == nil {
+		t.Error(err)
+		return
+	}
+	if affected, err = ExecStmts(queryer, ctx, nil, true); err != nil || affected != 0 {
+		t.Error(err)
+		return
+	}
+	c := &CRUD{}
+	*c = *Default
+	c.MaxParams = 1
+	if _, err = c.ExecStmts(queryer, ctx, stmts, false); !errors.Is(err, ErrTooManyParams) {
+		t.Error(err)
+		return
+	}
+}
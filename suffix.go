@@ -0,0 +1,103 @@
+package crud
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Suffix is the sql part appended to query with bound args, the placeholder in SQL is ArgFormat like $%v,
+// it is numbered by the merged args when it is appended, so it never needs manual $n math
+type Suffix struct {
+	SQL  string
+	Args []interface{}
+}
+
+func SuffixWheref(formats string, args ...interface{}) (suffix Suffix) {
+	suffix = Default.SuffixWheref(formats, args...)
+	return
+}
+
+// SuffixWheref will return where suffix by formats like AppendWheref, the format with nil/zero value is skipped, it is empty when all is skipped
+func (c *CRUD) SuffixWheref(formats string, args ...interface{}) (suffix Suffix) {
+	var where []string
+	c.FilterFormatCall(formats, args, func(format string, arg interface{}) {
+		if arg = c.ParmConv("where", format, "", reflect.StructField{}, arg); arg == SkipField {
+			return
+		}
+		where = append(where, format)
+		suffix.Args = append(suffix.Args, arg)
+	})
+	if len(where) > 0 {
+		suffix.SQL = "where " + strings.Join(where, " "+wherefSep(formats)+" ")
+	}
+	return
+}
+
+func SuffixPage(orderby string, offset, limit int) (suffix Suffix) {
+	suffix = Default.SuffixPage(orderby, offset, limit)
+	return
+}
+
+// SuffixPage will return order and limit suffix like JoinPage
+func (c *CRUD) SuffixPage(orderby string, offset, limit int) (suffix Suffix) {
+	suffix.SQL = strings.TrimSpace(c.joinPage(1, context.Background(), "", orderby, offset, limit))
+	return
+}
+
+func QuerySQLArgs(v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql, args_ = Default.querySQLArgs(1, context.Background(), v, filter, args, suffixes...)
+	return
+}
+
+// QuerySQLArgs will return query sql of v by filter and append suffixes with bound args, the merged args is returned
+func (c *CRUD) QuerySQLArgs(v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql, args_ = c.querySQLArgs(1, context.Background(), v, filter, args, suffixes...)
+	return
+}
+
+func (c *CRUD) querySQLArgs(caller int, ctx context.Context, v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql = c.querySQL(caller+1, ctx, v, "", filter)
+	sql, args_ = c.joinSuffix(sql, args, suffixes...)
+	return
+}
+
+func CountSQLArgs(v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql, args_ = Default.countSQLArgs(1, context.Background(), v, filter, args, suffixes...)
+	return
+}
+
+// CountSQLArgs will return count sql of v by filter and append suffixes with bound args, the merged args is returned
+func (c *CRUD) CountSQLArgs(v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql, args_ = c.countSQLArgs(1, context.Background(), v, filter, args, suffixes...)
+	return
+}
+
+func (c *CRUD) countSQLArgs(caller int, ctx context.Context, v interface{}, filter string, args []interface{}, suffixes ...Suffix) (sql string, args_ []interface{}) {
+	sql = c.countSQL(caller+1, ctx, v, "", filter)
+	sql, args_ = c.joinSuffix(sql, args, suffixes...)
+	return
+}
+
+// joinSuffix will append suffixes to sql and number the placeholder of suffix by the merged args, the empty suffix is skipped
+func (c *CRUD) joinSuffix(sql string, args []interface{}, suffixes ...Suffix) (sql_ string, args_ []interface{}) {
+	sql_, args_ = sql, args
+	for _, suffix := range suffixes {
+		if len(suffix.SQL) < 1 {
+			continue
+		}
+		if n := strings.Count(suffix.SQL, c.ArgFormat); n != len(suffix.Args) {
+			panic(fmt.Sprintf("suffix %v placeholders=%v is not equal to args=%v", suffix.SQL, n, len(suffix.Args)))
+		}
+		part := suffix.SQL
+		for _, arg := range suffix.Args {
+			args_ = append(args_, arg)
+			if strings.Contains(c.ArgFormat, "%") {
+				part = strings.Replace(part, c.ArgFormat, fmt.Sprintf(c.ArgFormat, len(args_)), 1)
+			}
+		}
+		sql_ += " " + part
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package crud
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSuffix(t *testing.T) {
+	sql, args := QuerySQLArgs(&CrudObject{}, "tid,title#all", []interface{}{1},
+		SuffixWheref("user_id=$%v,type=$%v,status=any($%v)", int64(100), "", []int{100, 200}),
+		Suffix{SQL: "and (title like $%v or title like $%v)", Args: []interface{}{"a%", "b%"}},
+		Suffix{},
+		SuffixPage("order by tid desc", 10, 20),
+	)
+	if sql != "select tid,title from crud_object where user_id=$2 and status=any($3) and (title like $4 or title like $5) order by tid desc limit 20 offset 10" || len(args) != 5 || args[4] != "b%" {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	sql, args = CountSQLArgs(&CrudObject{}, "count(tid)#all", nil, SuffixWheref("user_id=$%v,type=$%v#+or", int64(100), "a"))
+	if sql != "select count(tid) from crud_object where user_id=$1 or type=$2" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	if suffix := SuffixWheref("user_id=$%v", int64(0)); len(suffix.SQL) > 0 || len(suffix.Args) > 0 {
+		t.Errorf("%v", suffix)
+		return
+	}
+	//mysql
+	c := &CRUD{}
+	*c = *Default
+	c.Verbose = false
+	c.ArgFormat = "?"
+	sql, args = c.QuerySQLArgs(&CrudObject{}, "tid#all", nil, c.SuffixWheref("user_id=?,type=?", int64(100), "a"), c.SuffixPage("", 0, 10))
+	if sql != "select tid from crud_object where user_id=? and type=? limit 10 offset 0" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	sql, args = c.CountSQLArgs(&CrudObject{}, "", []interface{}{1}, Suffix{SQL: "where status=?", Args: []interface{}{100}})
+	if sql != "select count(*) from crud_object where status=?" || len(args) != 2 {
+		t.Errorf("%v,%v", sql, args)
+		return
+	}
+	//error
+	func() {
+		defer func() {
+			if perr := recover(); perr == nil || !strings.Contains(fmt.Sprintf("%v", perr), "placeholders=1 is not equal to args=2") {
+				t.Errorf("panic is %v", perr)
+			}
+		}()
+		QuerySQLArgs(&CrudObject{}, "#all", nil, Suffix{SQL: "where tid=$%v", Args: []interface{}{1, 2}})
+	}()
+}
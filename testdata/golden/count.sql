@@ -0,0 +1,12 @@
+-- all
+select count(tid) from crud_object
+-- args: null
+
+-- multi
+select count(tid),max(user_id) from crud_object
+-- args: null
+
+-- where
+select count(tid) from crud_object where user_id=$1 and status=any($2)
+-- args: [100,"{100,200}"]
+
@@ -0,0 +1,20 @@
+-- all
+insert into crud_object(tid,user_id,type,level,title,image,data,int_value,int_array,int64_value,int64_array,float64_value,string_value,string_array,time_value,update_time,create_time,status) values($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18) 
+-- args: [1000,100,"1",1,"title","image",{"a":1},1,[1,2],2,[3,4],"1.5","value",["a","b"],1685608200000,1685608200000,1685608200000,100]
+
+-- skip_tid_all
+insert into crud_object(user_id,type,level,title,image,description,data,int_value,int_ptr,int_array,int64_value,int64_ptr,int64_array,float64_value,float64_ptr,float64_array,string_value,string_ptr,string_array,map_value,map_array,time_value,update_time,create_time,status) values($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25) returning tid
+-- args: [100,"1",1,"title","image",null,{"a":1},1,null,[1,2],2,null,[3,4],"1.5","0",null,"value",null,["a","b"],null,null,1685608200000,1685608200000,1685608200000,100]
+
+-- fields
+insert into crud_object(user_id,type,title,status) values($1,$2,$3,$4) 
+-- args: [100,"1","title",100]
+
+-- multi_filter
+insert into crud_object(title,status,type,user_id) values($1,$2,$3,$4) 
+-- args: ["title",100,"1",100]
+
+-- meta
+insert into crud_object(user_id,title,status) values($1,$2,$3) 
+-- args: [100,"title",100]
+
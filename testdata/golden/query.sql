@@ -0,0 +1,20 @@
+-- all
+select tid,user_id,type,level,title,image,description,data::text,int_value,int_ptr,int_array::text,int64_value,int64_ptr,int64_array::text,float64_value,float64_ptr,float64_array::text,string_value,string_ptr,string_array::text,map_value::text,map_array::text,time_value,update_time,create_time,status from crud_object
+-- args: null
+
+-- fields
+select tid,user_id,title from crud_object
+-- args: null
+
+-- alias_func
+select o.tid,count(o.user_id),max(o.level) from crud_object o
+-- args: null
+
+-- where_page
+select tid,title from crud_object where user_id=$1 and type=$2 and status=any($3) order by tid desc limit 20 offset 10
+-- args: [100,"1","{100,200}"]
+
+-- where_skip_zero
+select tid from crud_object where title like $1 and level=$2
+-- args: ["%abc%",0]
+
@@ -0,0 +1,24 @@
+-- search_query
+select tid,user_id,type,level,title,image,description,data::text,int_value,int_ptr,int_array::text,int64_value,int64_ptr,int64_array::text,float64_value,float64_ptr,float64_array::text,string_value,string_ptr,string_array::text,map_value::text,map_array::text,time_value,update_time,create_time,status from crud_object where user_id = $1 and type = $2 and (title like $3 or data::text like $4) and status=any($5)  order by tid desc limit 20 offset 10
+-- args: [100,"1","%abc%","%abc%","{100,200}"]
+
+-- search_count
+select count(tid),max(user_id) from crud_object where user_id = $1 and type = $2 and status=any($3) 
+-- args: [100,"","{100,200}"]
+
+-- search_empty
+select tid,user_id,type,level,title,image,description,data::text,int_value,int_ptr,int_array::text,int64_value,int64_ptr,int64_array::text,float64_value,float64_ptr,float64_array::text,string_value,string_ptr,string_array::text,map_value::text,map_array::text,time_value,update_time,create_time,status from crud_object where type = $1  order by tid desc
+-- args: [""]
+
+-- find_query_row
+select tid,user_id,type,level,title,image,description,data::text,int_value,int_ptr,int_array::text,int64_value,int64_ptr,int64_array::text,float64_value,float64_ptr,float64_array::text,string_value,string_ptr,string_array::text,map_value::text,map_array::text,time_value,update_time,create_time,status from crud_object where user_id= $1 and type = $2 and (title like $3 or data::text like $3) and status=any($4) 
+-- args: [100,"","%abc%","{100,200}"]
+
+-- find_apply_query_row
+select tid,user_id,type,level,title,image,description,data::text,int_value,int_ptr,int_array::text,int64_value,int64_ptr,int64_array::text,float64_value,float64_ptr,float64_array::text,string_value,string_ptr,string_array::text,map_value::text,map_array::text,time_value,update_time,create_time,status from crud_object where user_id= $1 and type = $2 
+-- args: [100,""]
+
+-- join_where
+select tid from crud_object where user_id= $2 and type = $3 and (title like $4 or data::text like $4) and status=any($5)
+-- args: [1,100,"","%abc%","{100,200}"]
+
@@ -0,0 +1,20 @@
+-- all
+update crud_object set tid=$1,user_id=$2,type=$3,level=$4,title=$5,image=$6,data=$7,int_value=$8,int_array=$9,int64_value=$10,int64_array=$11,float64_value=$12,string_value=$13,string_array=$14,time_value=$15,update_time=$16,create_time=$17,status=$18 
+-- args: [1000,100,"1",1,"title","image",{"a":1},1,[1,2],2,[3,4],"1.5","value",["a","b"],1685608200000,1685608200000,1685608200000,100]
+
+-- skip_tid_all
+update crud_object set user_id=$1,type=$2,level=$3,title=$4,image=$5,description=$6,data=$7,int_value=$8,int_ptr=$9,int_array=$10,int64_value=$11,int64_ptr=$12,int64_array=$13,float64_value=$14,float64_ptr=$15,float64_array=$16,string_value=$17,string_ptr=$18,string_array=$19,map_value=$20,map_array=$21,time_value=$22,update_time=$23,create_time=$24,status=$25 
+-- args: [100,"1",1,"title","image",null,{"a":1},1,null,[1,2],2,null,[3,4],"1.5","0",null,"value",null,["a","b"],null,null,1685608200000,1685608200000,1685608200000,100]
+
+-- fields_where
+update crud_object set title=$1,update_time=$2,status=$3  where tid=$4 and user_id=$5
+-- args: ["title",1685608200000,100,1000,100]
+
+-- zero_skipped
+update crud_object set title=$1 
+-- args: ["title"]
+
+-- zero_all
+update crud_object set level=$1,title=$2,image=$3 
+-- args: [0,"title",null]
+
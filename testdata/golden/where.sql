@@ -0,0 +1,8 @@
+-- filter_where
+[user_id = $1 type = $2 title = $3 status = $4]
+-- args: [100,"1","title",100]
+
+-- filter_where_cmp
+[user_id = $2 level = $3]
+-- args: [1,100,1]
+
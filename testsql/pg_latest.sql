@@ -4,6 +4,8 @@
 
 
 
+DROP VIEW IF EXISTS crud_object_view;
+DROP TABLE IF EXISTS crud_uuid_object;
 ALTER TABLE IF EXISTS crud_object ALTER COLUMN tid DROP DEFAULT;
 DROP SEQUENCE IF EXISTS crud_simple_tid_seq;
 DROP TABLE IF EXISTS crud_object;
@@ -43,6 +45,24 @@ CREATE TABLE crud_object (
 );
 
 
+--
+-- Name: crud_uuid_object; Type: TABLE; Schema: public;
+--
+
+CREATE TABLE crud_uuid_object (
+    tid uuid DEFAULT gen_random_uuid() NOT NULL,
+    title character varying(255) NOT NULL,
+    code character varying(255),
+    object_id bigint,
+    data jsonb DEFAULT '{}'::jsonb NOT NULL,
+    created_by bigint DEFAULT 0 NOT NULL,
+    updated_by bigint,
+    update_time timestamp with time zone NOT NULL,
+    create_time timestamp with time zone NOT NULL,
+    status integer NOT NULL,
+    CONSTRAINT crud_uuid_object_status_check CHECK (status >= 0 AND status <= 1000)
+);
+
 --
 -- Name: COLUMN crud_object.type; Type: COMMENT; Schema: public;
 --
@@ -91,6 +111,38 @@ ALTER TABLE IF EXISTS ONLY crud_object
     ADD CONSTRAINT crud_simple_pkey PRIMARY KEY (tid);
 
 
+--
+-- Name: crud_uuid_object crud_uuid_object_pkey; Type: CONSTRAINT; Schema: public;
+--
+
+ALTER TABLE IF EXISTS ONLY crud_uuid_object
+    ADD CONSTRAINT crud_uuid_object_pkey PRIMARY KEY (tid);
+
+
+--
+-- Name: crud_uuid_object crud_uuid_object_code_key; Type: CONSTRAINT; Schema: public;
+--
+
+ALTER TABLE IF EXISTS ONLY crud_uuid_object
+    ADD CONSTRAINT crud_uuid_object_code_key UNIQUE (code);
+
+
+--
+-- Name: crud_uuid_object crud_uuid_object_object_id_fkey; Type: FK CONSTRAINT; Schema: public;
+--
+
+ALTER TABLE IF EXISTS ONLY crud_uuid_object
+    ADD CONSTRAINT crud_uuid_object_object_id_fkey FOREIGN KEY (object_id) REFERENCES crud_object(tid);
+
+
+--
+-- Name: crud_object_view; Type: VIEW; Schema: public;
+--
+
+CREATE VIEW crud_object_view AS
+ SELECT tid, user_id, type, title, status, update_time FROM crud_object;
+
+
 --
 -- PostgreSQL database dump complete
 --
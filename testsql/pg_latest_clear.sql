@@ -1,3 +1,5 @@
+DROP VIEW IF EXISTS crud_object_view;
+DROP TABLE IF EXISTS crud_uuid_object;
 ALTER TABLE IF EXISTS crud_object ALTER COLUMN tid DROP DEFAULT;
 DROP SEQUENCE IF EXISTS crud_simple_tid_seq;
 DROP TABLE IF EXISTS crud_object;
@@ -29,12 +29,29 @@ CREATE TABLE IF NOT EXISTS "crud_object" (
   "create_time" DATE NOT NULL,
   "status" INT4 NOT NULL
 );
+CREATE TABLE IF NOT EXISTS "crud_uuid_object" (
+  "tid" TEXT NOT NULL PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+  "title" VARCHAR(255) NOT NULL,
+  "code" TEXT UNIQUE,
+  "object_id" INTEGER REFERENCES "crud_object"("tid"),
+  "data" TEXT NOT NULL DEFAULT '{}',
+  "created_by" INTEGER NOT NULL DEFAULT 0,
+  "updated_by" INTEGER,
+  "update_time" DATE NOT NULL,
+  "create_time" DATE NOT NULL,
+  "status" INT4 NOT NULL CHECK ("status" >= 0 AND "status" <= 1000)
+);
+CREATE VIEW IF NOT EXISTS "crud_object_view" AS
+  SELECT "tid","user_id","type","title","status","update_time" FROM "crud_object";
 `
 
 const SQLITE_DROP = `
+DROP VIEW IF EXISTS "crud_object_view";
 DROP TABLE IF EXISTS "crud_object";
+DROP TABLE IF EXISTS "crud_uuid_object";
 `
 
 const SQLITE_CLEAR = `
 DELETE FROM "crud_object";
+DELETE FROM "crud_uuid_object";
 `
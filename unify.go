@@ -0,0 +1,240 @@
+package crud
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UnifyWhere is the field description of Where section in unify struct
+type UnifyWhere struct {
+	Name   string // the struct field name
+	Field  string // the field name by tag
+	Cmp    string // the cmp tag
+	Filter string // the filter tag
+	Join   string // the join tag of nested where struct
+}
+
+// UnifyOrder is the description of Order field in Page section of unify struct
+type UnifyOrder struct {
+	Index     []int  // the Order field index in Page section
+	Default   string // the default tag, it is used when order is empty or not supported
+	Supported string // the supported tag, it is the order whitelist which is built by BuildOrderby, the order is used as raw sql when it is empty
+}
+
+// UnifyPage is the description of Page section in unify struct, the index is nil if field is not exists
+type UnifyPage struct {
+	Index        []int
+	OffsetIndex  []int // the Offset or Skip field index
+	LimitIndex   []int
+	LimitDefault int // the default tag of Limit, it is used when limit is not setted
+}
+
+// UnifyDest is the scan dest field of target section in unify struct
+type UnifyDest struct {
+	Name  string
+	Index []int
+	Scan  string
+}
+
+// UnifyTarget is the target section in unify struct, like Query/QueryRow/Count or other section with apply tag
+type UnifyTarget struct {
+	Name          string // the section field name
+	Apply         string // the apply type in Query/QueryRow/Count, it is empty for custom target
+	Index         []int
+	From          string
+	Filter        string
+	Select        string
+	Group         string
+	EnabledIndex  []int // the Enabled field index, nil if not exists
+	FilterIndex   []int // the FilterValue/FilterGetter field index, nil if not exists
+	FromArgsIndex []int // the FromArgs field index, nil if not exists
+	Dests         []*UnifyDest
+}
+
+// UnifyPlan is the description of unify struct, it is resolved once for each struct type
+type UnifyPlan struct {
+	Type       reflect.Type
+	ModelType  reflect.Type
+	ModelIndex []int
+	ModelFrom  string
+	WhereIndex []int
+	WhereJoin  string
+	Where      []*UnifyWhere
+	Order      *UnifyOrder // nil if Page section or Order field is not exists
+	Page       *UnifyPage  // nil if Page section is not exists
+	Targets    []*UnifyTarget
+}
+
+// Target will return target section by name, it return nil if not exists
+func (u *UnifyPlan) Target(name string) *UnifyTarget {
+	for _, target := range u.Targets {
+		if target.Name == name {
+			return target
+		}
+	}
+	return nil
+}
+
+type unifyPlanKey struct {
+	typ reflect.Type
+	tag string
+}
+
+var unifyPlanCache = sync.Map{}
+
+var filterValueType = reflect.TypeOf(FilterValue(""))
+var filterGetterType = reflect.TypeOf((*FilterGetter)(nil)).Elem()
+
+func PlanUnify(v interface{}) (plan *UnifyPlan, err error) {
+	plan, err = Default.PlanUnify(v)
+	return
+}
+
+// PlanUnify will return the plan of unify struct v, the plan is cached by struct type
+func (c *CRUD) PlanUnify(v interface{}) (plan *UnifyPlan, err error) {
+	reflectType := reflect.TypeOf(v)
+	for reflectType != nil && reflectType.Kind() == reflect.Ptr {
+		reflectType = reflectType.Elem()
+	}
+	if reflectType == nil || reflectType.Kind() != reflect.Struct {
+		err = fmt.Errorf("unify %v is not struct", reflect.TypeOf(v))
+		return
+	}
+	key := unifyPlanKey{typ: reflectType, tag: c.Tag}
+	if cached, ok := unifyPlanCache.Load(key); ok {
+		plan = cached.(*UnifyPlan)
+		return
+	}
+	plan, err = c.newUnifyPlan(reflectType)
+	if err == nil {
+		unifyPlanCache.Store(key, plan)
+	}
+	return
+}
+
+func (c *CRUD) planUnify(v interface{}) (plan *UnifyPlan) {
+	plan, err := c.PlanUnify(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return
+}
+
+func (c *CRUD) newUnifyPlan(reflectType reflect.Type) (plan *UnifyPlan, err error) {
+	modelType, ok := reflectType.FieldByName("Model")
+	if !ok {
+		err = fmt.Errorf("unify %v is not having Model field", reflectType)
+		return
+	}
+	plan = &UnifyPlan{
+		Type:       reflectType,
+		ModelType:  modelType.Type,
+		ModelIndex: modelType.Index,
+		ModelFrom:  modelType.Tag.Get("from"),
+	}
+	if whereType, ok := reflectType.FieldByName("Where"); ok && whereType.Type.Kind() == reflect.Struct {
+		plan.WhereIndex = whereType.Index
+		plan.WhereJoin = whereType.Tag.Get("join")
+		for i := 0; i < whereType.Type.NumField(); i++ {
+			field := whereType.Type.Field(i)
+			plan.Where = append(plan.Where, &UnifyWhere{
+				Name:   field.Name,
+				Field:  strings.SplitN(field.Tag.Get(c.Tag), ",", 2)[0],
+				Cmp:    field.Tag.Get("cmp"),
+				Filter: field.Tag.Get("filter"),
+				Join:   field.Tag.Get("join"),
+			})
+		}
+	}
+	if pageType, ok := reflectType.FieldByName("Page"); ok && pageType.Type.Kind() == reflect.Struct {
+		plan.Page = &UnifyPage{Index: pageType.Index}
+		if orderType, ok := pageType.Type.FieldByName("Order"); ok {
+			plan.Order = &UnifyOrder{
+				Index:     orderType.Index,
+				Default:   orderType.Tag.Get("default"),
+				Supported: orderType.Tag.Get("supported"),
+			}
+		}
+		if offsetType, ok := pageType.Type.FieldByName("Offset"); ok {
+			plan.Page.OffsetIndex = offsetType.Index
+		} else if skipType, ok := pageType.Type.FieldByName("Skip"); ok {
+			plan.Page.OffsetIndex = skipType.Index
+		}
+		if limitType, ok := pageType.Type.FieldByName("Limit"); ok {
+			plan.Page.LimitIndex = limitType.Index
+			if limitDefault := limitType.Tag.Get("default"); len(limitDefault) > 0 {
+				plan.Page.LimitDefault, err = strconv.Atoi(limitDefault)
+				if err != nil {
+					err = fmt.Errorf("unify %v Page.Limit default tag %v is not int", reflectType, limitDefault)
+					return
+				}
+			}
+		}
+	}
+	for i := 0; i < reflectType.NumField(); i++ {
+		fieldType := reflectType.Field(i)
+		if fieldType.Type.Kind() != reflect.Struct || fieldType.Name == "Model" || fieldType.Name == "Where" || fieldType.Name == "Page" {
+			continue
+		}
+		plan.Targets = append(plan.Targets, newUnifyTarget(fieldType))
+	}
+	return
+}
+
+func newUnifyTarget(fieldType reflect.StructField) (target *UnifyTarget) {
+	target = &UnifyTarget{
+		Name:   fieldType.Name,
+		Apply:  fieldType.Tag.Get("apply"),
+		Index:  fieldType.Index,
+		From:   fieldType.Tag.Get("from"),
+		Filter: fieldType.Tag.Get("filter"),
+		Select: fieldType.Tag.Get("select"),
+		Group:  fieldType.Tag.Get("group"),
+	}
+	if len(target.Apply) < 1 && (target.Name == "Query" || target.Name == "QueryRow" || target.Name == "Count") {
+		target.Apply = target.Name
+	}
+	for i := 0; i < fieldType.Type.NumField(); i++ {
+		field := fieldType.Type.Field(i)
+		if field.Name == "Enabled" {
+			target.EnabledIndex = field.Index
+		}
+		if (field.Name == "Filter" && field.Type == filterValueType) || field.Type.Implements(filterGetterType) {
+			target.FilterIndex = field.Index
+			continue
+		}
+		if field.Name == "FromArgs" {
+			target.FromArgsIndex = field.Index
+			continue
+		}
+		scan := field.Tag.Get("scan")
+		if scan == "-" {
+			continue
+		}
+		target.Dests = append(target.Dests, &UnifyDest{Name: field.Name, Index: field.Index, Scan: scan})
+	}
+	return
+}
+
+// filter will return the filter of target, it is replaced by FilterValue/FilterGetter field value if setted
+func (u *UnifyTarget) filter(v interface{}, targetValue reflect.Value) (filter string) {
+	filter = u.Filter
+	if u.FilterIndex == nil {
+		return
+	}
+	fieldValue := targetValue.FieldByIndex(u.FilterIndex)
+	if value, ok := fieldValue.Interface().(FilterValue); ok && len(value) > 0 {
+		filter = string(value)
+	} else if getter, ok := fieldValue.Interface().(FilterGetter); ok && getter != nil {
+		filter = getter.GetFilter(v, fieldValue, fieldValue)
+	}
+	return
+}
+
+// enabled will return the Enabled field value of target, it is true if Enabled field is not exists
+func (u *UnifyTarget) enabled(targetValue reflect.Value) bool {
+	return u.EnabledIndex == nil || targetValue.FieldByIndex(u.EnabledIndex).Bool()
+}